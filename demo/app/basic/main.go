@@ -14,6 +14,7 @@ import "C"
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime"
 	"time"
@@ -86,6 +87,32 @@ func AutoDetect() {}
 
 func (MyStruct) Unnamed(int, float32) {}
 
+// EarlyReturn leaves through several return statements, one of them bare, and
+// a deferred call rewrites the result of the last ones. Its After hook must
+// observe the values the caller actually receives on every path.
+func EarlyReturn(n int) (label string, err error) {
+	if n < 0 {
+		return "negative", errors.New("negative input")
+	}
+	if n == 0 {
+		label = "zero"
+		return
+	}
+	defer func() { label += "!" }()
+	if n > 100 {
+		return "large", nil
+	}
+	return "small", nil
+}
+
+// Parity returns early through unnamed results.
+func Parity(n int) string {
+	if n%2 == 0 {
+		return "even"
+	}
+	return "odd"
+}
+
 func main() {
 	ctx := &traceContext{
 		traceID: "123",
@@ -126,4 +153,10 @@ func main() {
 
 	AutoDetect()
 	MyStruct{}.Unnamed(42, 2.7)
+
+	for _, n := range []int{-1, 0, 7, 1000} {
+		_, _ = EarlyReturn(n)
+	}
+	_ = Parity(2)
+	_ = Parity(3)
 }
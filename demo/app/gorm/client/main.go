@@ -0,0 +1,118 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package main provides a GORM client demo for demonstrating OpenTelemetry
+// compile-time instrumentation with gorm.io/gorm.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const (
+	requestDelayDuration = 500 * time.Millisecond
+)
+
+var (
+	dsn      = flag.String("dsn", "file::memory:?cache=shared", "SQLite data source name")
+	count    = flag.Int("count", 1, "Number of iterations to run")
+	logLevel = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+	logger   *slog.Logger
+)
+
+// Product is the model the demo creates, queries, updates and deletes.
+type Product struct {
+	ID    uint
+	Code  string
+	Price uint
+}
+
+func runIteration(ctx context.Context, db *gorm.DB, iteration int) error {
+	db = db.WithContext(ctx)
+	code := fmt.Sprintf("D%d", iteration)
+
+	// INSERT
+	product := Product{Code: code, Price: 100}
+	if err := db.Create(&product).Error; err != nil {
+		logger.Error("create failed", "code", code, "error", err)
+		return err
+	}
+	logger.Info("created product", "id", product.ID, "code", code)
+
+	// SELECT
+	var found Product
+	if err := db.Where("code = ?", code).First(&found).Error; err != nil {
+		logger.Error("query failed", "code", code, "error", err)
+		return err
+	}
+	logger.Info("found product", "id", found.ID, "price", found.Price)
+
+	// UPDATE
+	if err := db.Model(&found).Update("price", 200).Error; err != nil {
+		logger.Error("update failed", "id", found.ID, "error", err)
+		return err
+	}
+	logger.Info("updated product", "id", found.ID, "price", 200)
+
+	// Raw statement
+	var total int64
+	if err := db.Raw("SELECT COUNT(*) FROM products").Scan(&total).Error; err != nil {
+		logger.Error("count failed", "error", err)
+		return err
+	}
+	logger.Info("counted products", "total", total)
+
+	// DELETE
+	if err := db.Delete(&found).Error; err != nil {
+		logger.Error("delete failed", "id", found.ID, "error", err)
+		return err
+	}
+	logger.Info("deleted product", "id", found.ID)
+
+	// A lookup without rows is reported as gorm.ErrRecordNotFound
+	if err := db.First(&Product{}, found.ID).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Error("deleted product still found", "id", found.ID, "error", err)
+		return err
+	}
+	return nil
+}
+
+func main() {
+	flag.Parse()
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(*logLevel)); err != nil {
+		level = slog.LevelInfo
+	}
+	logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level}))
+
+	db, err := gorm.Open(sqlite.Open(*dsn), &gorm.Config{})
+	if err != nil {
+		logger.Error("failed to open database", "dsn", *dsn, "error", err)
+		os.Exit(1)
+	}
+	if err := db.AutoMigrate(&Product{}); err != nil {
+		logger.Error("failed to migrate schema", "error", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	for i := 1; i <= *count; i++ {
+		if err := runIteration(ctx, db, i); err != nil {
+			os.Exit(1)
+		}
+		if i < *count {
+			time.Sleep(requestDelayDuration)
+		}
+	}
+	logger.Info("GORM demo completed", "iterations", *count)
+}
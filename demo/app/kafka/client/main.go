@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package main provides a Kafka producer and consumer demo for demonstrating
+// OpenTelemetry compile-time instrumentation with segmentio/kafka-go.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	requestDelayDuration = 500 * time.Millisecond
+	readTimeout          = 10 * time.Second
+)
+
+var (
+	broker    = flag.String("broker", "localhost:9092", "Kafka broker address")
+	topic     = flag.String("topic", "demo-orders", "Kafka topic")
+	group     = flag.String("group", "demo-consumer", "Kafka consumer group")
+	count     = flag.Int("count", 1, "Number of iterations to run")
+	batchSize = flag.Int("batch-size", 3, "Number of messages written in a batch")
+	logLevel  = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+	logger    *slog.Logger
+)
+
+// produce writes a single message, then a batch of messages.
+func produce(ctx context.Context, w *kafka.Writer, iteration int) (int, error) {
+	key := fmt.Sprintf("order-%d", iteration)
+	err := w.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(key),
+		Value: []byte(fmt.Sprintf("Hello OpenTelemetry #%d", iteration)),
+	})
+	if err != nil {
+		logger.Error("write failed", "key", key, "error", err)
+		return 0, err
+	}
+	logger.Info("wrote message", "key", key)
+
+	batch := make([]kafka.Message, *batchSize)
+	for i := range batch {
+		batch[i] = kafka.Message{
+			Key:   []byte(fmt.Sprintf("%s-item-%d", key, i)),
+			Value: []byte(fmt.Sprintf("item %d of order #%d", i, iteration)),
+		}
+	}
+	if err := w.WriteMessages(ctx, batch...); err != nil {
+		logger.Error("batch write failed", "key", key, "error", err)
+		return 1, err
+	}
+	logger.Info("wrote batch", "key", key, "messages", len(batch))
+
+	return 1 + len(batch), nil
+}
+
+// consume reads n messages, committing them through the consumer group.
+func consume(ctx context.Context, r *kafka.Reader, n int) error {
+	for range n {
+		readCtx, cancel := context.WithTimeout(ctx, readTimeout)
+		msg, err := r.ReadMessage(readCtx)
+		cancel()
+		if err != nil {
+			logger.Error("read failed", "error", err)
+			return err
+		}
+		logger.Info("read message",
+			"key", string(msg.Key),
+			"partition", msg.Partition,
+			"offset", msg.Offset)
+	}
+	return nil
+}
+
+func main() {
+	defer func() {
+		// Wait for OpenTelemetry SDK to flush spans before exit
+		time.Sleep(2 * time.Second)
+	}()
+
+	flag.Parse()
+
+	// Initialize logger with appropriate level
+	var level slog.Level
+	switch *logLevel {
+	case "debug":
+		level = slog.LevelDebug
+	case "info":
+		level = slog.LevelInfo
+	case "warn":
+		level = slog.LevelWarn
+	case "error":
+		level = slog.LevelError
+	default:
+		level = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{
+		Level: level,
+	}
+	logger = slog.New(slog.NewJSONHandler(os.Stdout, opts))
+
+	logger.Info("client starting",
+		"kafka_broker", *broker,
+		"kafka_topic", *topic,
+		"request_count", *count,
+		"log_level", *logLevel)
+
+	w := &kafka.Writer{
+		Addr:                   kafka.TCP(*broker),
+		Topic:                  *topic,
+		AllowAutoTopicCreation: true,
+	}
+	defer w.Close()
+
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{*broker},
+		Topic:   *topic,
+		GroupID: *group,
+	})
+	defer r.Close()
+
+	ctx := context.Background()
+
+	successCount := 0
+	failureCount := 0
+
+	for i := 1; i <= *count; i++ {
+		logger.Info("starting iteration",
+			"iteration", i,
+			"total", *count)
+
+		written, err := produce(ctx, w, i)
+		if err != nil {
+			failureCount++
+			continue
+		}
+
+		if err := consume(ctx, r, written); err != nil {
+			failureCount++
+			continue
+		}
+
+		successCount++
+
+		// Add delay between iterations
+		if i < *count {
+			time.Sleep(requestDelayDuration)
+		}
+	}
+
+	logger.Info("client finished",
+		"total_iterations", *count,
+		"successful", successCount,
+		"failed", failureCount)
+}
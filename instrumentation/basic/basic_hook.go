@@ -14,8 +14,10 @@ import (
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
 )
 
 func init() {
@@ -138,9 +140,12 @@ func AutoDetectBefore(ictx hook.HookContext) {
 	fmt.Printf("AutoDetect: %s\n", uuid.Nil.String())
 }
 
+// FunctionABefore starts the internal span of FunctionA, named after the
+// hooked function: main.FunctionA.
 func FunctionABefore(ictx hook.HookContext, ctx context.Context) {
-	ctx, span := tracer.Start(ctx, "FunctionA")
+	ctx = runtime.StartInternalSpan(ictx, ctx)
 	ictx.SetParam(0, ctx)
+	span := oteltrace.SpanFromContext(ctx)
 	fmt.Printf(
 		"FunctionABefore: TraceID: %s, SpanID: %s\n",
 		span.SpanContext().TraceID().String(),
@@ -148,9 +153,14 @@ func FunctionABefore(ictx hook.HookContext, ctx context.Context) {
 	)
 }
 
+func FunctionAAfter(ictx hook.HookContext) {
+	runtime.EndInternalSpan(ictx, nil)
+}
+
 func FunctionBBefore(ictx hook.HookContext, ctx context.Context) {
-	ctx, span := tracer.Start(ctx, "FunctionB")
+	ctx = runtime.StartInternalSpan(ictx, ctx)
 	ictx.SetParam(0, ctx)
+	span := oteltrace.SpanFromContext(ctx)
 	fmt.Printf(
 		"FunctionBBefore: TraceID: %s, SpanID: %s\n",
 		span.SpanContext().TraceID().String(),
@@ -158,6 +168,20 @@ func FunctionBBefore(ictx hook.HookContext, ctx context.Context) {
 	)
 }
 
+func FunctionBAfter(ictx hook.HookContext) {
+	runtime.EndInternalSpan(ictx, nil)
+}
+
 func UnnamedBefore(ictx hook.HookContext, recv interface{}, arg1 int, arg2 float32) {
 	fmt.Printf("UnnamedBefore %v %v\n", arg1, arg2)
 }
+
+// EarlyReturnAfter prints the results EarlyReturn returned, whichever return
+// statement it left through.
+func EarlyReturnAfter(ictx hook.HookContext, label string, err error) {
+	fmt.Printf("EarlyReturnAfter %q %v\n", label, err)
+}
+
+func ParityAfter(ictx hook.HookContext, ret string) {
+	fmt.Printf("ParityAfter %q\n", ret)
+}
@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bufio
+
+import (
+	"bufio"
+	"io"
+	"os"
+	goruntime "runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+)
+
+const (
+	instrumentationName = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/bufio"
+	instrumentationKey  = "bufio"
+	readerIndex         = 0
+
+	// envStreamFuncs lists, comma separated, the fully qualified functions
+	// (e.g. "main.ingest" or "example.com/etl.(*Pipeline).Run") whose streams
+	// are metered. Nothing is metered when it is empty.
+	envStreamFuncs = "OTEL_GO_STREAM_METRICS_FUNCS"
+
+	// callerDepth bounds the frames walked to find the function that created
+	// the scanner or reader.
+	callerDepth = 8
+)
+
+var (
+	logger   = runtime.Logger()
+	initOnce sync.Once
+
+	funcsOnce   sync.Once
+	streamFuncs map[string]bool
+)
+
+// moduleVersion extracts the version from the Go module system.
+// Falls back to "dev" if version cannot be determined.
+func moduleVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+
+	// Return the main module version
+	if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		return bi.Main.Version
+	}
+
+	return "dev"
+}
+
+func initInstrumentation() {
+	initOnce.Do(func() {
+		version := moduleVersion()
+		if err := runtime.SetupOTelSDK(
+			"go.opentelemetry.io/compile-instrumentation/bufio",
+			version,
+		); err != nil {
+			logger.Error("failed to setup OTel SDK", "error", err)
+		}
+		initStreamMetrics(version)
+		logger.Info("bufio stream instrumentation initialized")
+	})
+}
+
+// loadStreamFuncs parses envStreamFuncs once. It must not touch the SDK: the
+// hooks run for every scanner and reader of the process, including those the
+// SDK itself creates while it is being set up.
+func loadStreamFuncs() map[string]bool {
+	funcsOnce.Do(func() {
+		streamFuncs = nil
+		for _, name := range strings.Split(os.Getenv(envStreamFuncs), ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			if streamFuncs == nil {
+				streamFuncs = make(map[string]bool)
+			}
+			streamFuncs[name] = true
+		}
+	})
+	return streamFuncs
+}
+
+// callerFunc returns the name of the function that created the scanner or
+// reader: the first function on the stack, past the hook calling meterParam,
+// outside of package bufio, which holds the trampolines.
+func callerFunc() string {
+	var pcs [callerDepth]uintptr
+	// Skip runtime.Callers, callerFunc, meterParam and the hook
+	frames := goruntime.CallersFrames(pcs[:goruntime.Callers(4, pcs[:])])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "bufio.") {
+			return frame.Function
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// meterParam replaces the reader parameter with a metered one when the
+// function creating the scanner or reader is listed in envStreamFuncs.
+func meterParam(ictx hook.HookContext, r io.Reader) {
+	funcs := loadStreamFuncs()
+	if len(funcs) == 0 || r == nil {
+		return
+	}
+	caller := callerFunc()
+	if !funcs[caller] || !runtime.Instrumented(instrumentationKey) {
+		return
+	}
+	if _, buffered := r.(*bufio.Reader); buffered {
+		// Its own source is metered if it was created by a listed function
+		return
+	}
+	initInstrumentation()
+	ictx.SetParam(readerIndex, newMeteredReader(r, caller))
+}
+
+// BeforeNewScanner meters the stream a bufio.Scanner reads.
+func BeforeNewScanner(ictx hook.HookContext, r io.Reader) {
+	meterParam(ictx, r)
+}
+
+// BeforeNewReaderSize meters the stream a bufio.Reader reads. bufio.NewReader
+// delegates to NewReaderSize and is covered as well.
+func BeforeNewReaderSize(ictx hook.HookContext, rd io.Reader, _ int) {
+	meterParam(ictx, rd)
+}
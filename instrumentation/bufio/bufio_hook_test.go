@@ -0,0 +1,130 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bufio
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+)
+
+const testCaller = instrumentationName + ".TestBeforeNewScanner_Throughput"
+
+func setupStreamFuncs(t *testing.T, funcs string) {
+	t.Helper()
+	t.Setenv(envStreamFuncs, funcs)
+	funcsOnce = *new(sync.Once)
+	t.Cleanup(func() { funcsOnce = *new(sync.Once) })
+}
+
+func setupTestMeter(t *testing.T) *sdkmetric.ManualReader {
+	t.Helper()
+	initInstrumentation()
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { _ = mp.Shutdown(context.Background()) })
+	otel.SetMeterProvider(mp)
+	initStreamMetrics("")
+	return reader
+}
+
+func TestBeforeNewScanner_Throughput(t *testing.T) {
+	setupStreamFuncs(t, "main.other, "+testCaller)
+	reader := setupTestMeter(t)
+
+	input := strings.Repeat("a line of the stream\n", 1000)
+	ictx := hooktest.NewMockHookContext(strings.NewReader(input))
+	BeforeNewScanner(ictx, strings.NewReader(input))
+	r, ok := ictx.GetParam(readerIndex).(*meteredReader)
+	require.True(t, ok, "the reader is metered")
+
+	lines := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines++
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, 1000, lines)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	metrics := make(map[string]metricdata.Metrics)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m
+	}
+
+	size, ok := metrics[streamBytesMetric].Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, size.DataPoints, 1)
+	assert.Equal(t, int64(len(input)), size.DataPoints[0].Value, "every byte read is counted")
+	caller, ok := size.DataPoints[0].Attributes.Value(codeFunctionKey)
+	require.True(t, ok)
+	assert.Equal(t, testCaller, caller.AsString())
+
+	throughput, ok := metrics[streamThroughputMetric].Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, throughput.DataPoints, 1)
+	assert.Equal(t, uint64(1), throughput.DataPoints[0].Count, "recorded once, at EOF")
+	assert.Positive(t, throughput.DataPoints[0].Sum)
+}
+
+func TestBeforeNewReaderSize_NotListed(t *testing.T) {
+	tests := []struct {
+		name  string
+		funcs string
+		rd    io.Reader
+	}{
+		{name: "no functions listed", rd: strings.NewReader("data")},
+		{name: "caller not listed", funcs: "main.ingest", rd: strings.NewReader("data")},
+		{
+			name:  "already buffered",
+			funcs: instrumentationName + ".TestBeforeNewReaderSize_NotListed.func1",
+			rd:    bufio.NewReader(strings.NewReader("data")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupStreamFuncs(t, tt.funcs)
+			ictx := hooktest.NewMockHookContext(tt.rd, 4096)
+			BeforeNewReaderSize(ictx, tt.rd, 4096)
+			assert.Same(t, tt.rd, ictx.GetParam(readerIndex), "the reader is left untouched")
+		})
+	}
+}
+
+func TestMeteredReader_RecordsOnce(t *testing.T) {
+	reader := setupTestMeter(t)
+
+	r := newMeteredReader(strings.NewReader("0123456789"), "main.ingest")
+	_, err := io.ReadAll(r)
+	require.NoError(t, err)
+	// Reading past EOF again must not count the stream twice
+	_, err = r.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name != streamBytesMetric {
+			continue
+		}
+		size, ok := m.Data.(metricdata.Sum[int64])
+		require.True(t, ok)
+		require.Len(t, size.DataPoints, 1)
+		assert.Equal(t, int64(10), size.DataPoints[0].Value)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bufio
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+)
+
+const (
+	streamBytesMetric      = "stream.read.size"
+	streamThroughputMetric = "stream.read.throughput"
+
+	codeFunctionKey = attribute.Key("code.function.name")
+)
+
+var (
+	streamBytes      metric.Int64Counter
+	streamThroughput metric.Float64Histogram
+)
+
+// initStreamMetrics creates the stream instruments.
+func initStreamMetrics(version string) {
+	streamBytes, streamThroughput = nil, nil
+	meter := runtime.Meter(
+		instrumentationName,
+		metric.WithInstrumentationVersion(version),
+	)
+	counter, err := meter.Int64Counter(
+		streamBytesMetric,
+		metric.WithUnit("By"),
+		metric.WithDescription("Bytes read from metered streams."),
+	)
+	if err != nil {
+		logger.Error("failed to create stream size counter", "error", err)
+		return
+	}
+	histogram, err := meter.Float64Histogram(
+		streamThroughputMetric,
+		metric.WithUnit("By/s"),
+		metric.WithDescription("Read throughput of metered streams, from their first read until they end."),
+	)
+	if err != nil {
+		logger.Error("failed to create stream throughput histogram", "error", err)
+		return
+	}
+	streamBytes, streamThroughput = counter, histogram
+}
+
+// meteredReader counts the bytes read through it and records them, with the
+// throughput, once the underlying reader returns an error, io.EOF included.
+// Streams abandoned before that are not recorded.
+type meteredReader struct {
+	r      io.Reader
+	caller string
+
+	n          int64
+	start      time.Time
+	recordOnce sync.Once
+}
+
+func newMeteredReader(r io.Reader, caller string) *meteredReader {
+	return &meteredReader{r: r, caller: caller}
+}
+
+func (m *meteredReader) Read(p []byte) (int, error) {
+	if m.start.IsZero() {
+		m.start = time.Now()
+	}
+	n, err := m.r.Read(p)
+	m.n += int64(n)
+	if err != nil {
+		m.recordOnce.Do(m.record)
+	}
+	return n, err
+}
+
+func (m *meteredReader) record() {
+	if streamBytes == nil {
+		return
+	}
+	ctx := context.Background()
+	attrs := metric.WithAttributes(codeFunctionKey.String(m.caller))
+	streamBytes.Add(ctx, m.n, attrs)
+	if elapsed := time.Since(m.start).Seconds(); elapsed > 0 {
+		streamThroughput.Record(ctx, float64(m.n)/elapsed, attrs)
+	}
+}
@@ -6,11 +6,14 @@ package db
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"os"
 	"runtime/debug"
-	"strings"
 	"sync"
 	"time"
 
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/database/sql/dbregistry"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/database/sql/driverwrap"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/database/sql/semconv"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
@@ -22,12 +25,24 @@ import (
 const (
 	instrumentationName = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/database/sql"
 	instrumentationKey  = "DATABASE"
+
+	// envDriverSpans additionally traces the operations database/sql runs on
+	// the connections of the registered drivers, e.g. the statements prepared
+	// behind a query, as children of the spans of the sql.DB API.
+	envDriverSpans = "OTEL_GO_DB_DRIVER_SPANS"
+
+	// ctxParamIndex is the index of the context among the parameters of the
+	// instrumented methods taking one, after their receiver.
+	ctxParamIndex = 1
 )
 
 var (
 	logger   = runtime.Logger()
 	tracer   trace.Tracer
 	initOnce sync.Once
+
+	// openDBs holds the DBs opened through sql.Open that are not closed yet.
+	openDBs = dbregistry.New()
 )
 
 // dbClientEnabler controls whether client instrumentation is enabled
@@ -39,6 +54,28 @@ func (n dbClientEnabler) Enable() bool {
 
 var clientEnabler = dbClientEnabler{}
 
+// beforeRegisterInstrumentation records the system of every driver as it is
+// registered, for the db.system.name of its spans, and wraps it when driver
+// spans are enabled. Drivers register from their package init, before this
+// package may be initialized: it must not use the package variables, logger
+// included.
+func beforeRegisterInstrumentation(ictx hook.HookContext, name string, drv driver.Driver) {
+	semconv.RegisterDriver(name, drv)
+	if drv != nil && os.Getenv(envDriverSpans) == "true" {
+		ictx.SetParam(1, driverwrap.Wrap(name, drv, driverTracer))
+	}
+}
+
+// driverTracer returns the tracer of the spans of wrapped drivers, once the
+// application runs and this package is initialized.
+func driverTracer() trace.Tracer {
+	if !clientEnabler.Enable() {
+		return nil
+	}
+	initInstrumentation()
+	return tracer
+}
+
 func beforeOpenInstrumentation(ictx hook.HookContext, driverName, dataSourceName string) {
 	info := ParseDSN(driverName, dataSourceName)
 	addr := info.Addr()
@@ -81,6 +118,13 @@ func afterOpenInstrumentation(ictx hook.HookContext, db *sql.DB, err error) {
 	if ok {
 		db.DbName = dbName
 	}
+	if err == nil {
+		openDBs.Add(db, db.Endpoint)
+	}
+}
+
+func beforeCloseInstrumentation(ictx hook.HookContext, db *sql.DB) {
+	openDBs.Remove(db)
 }
 
 func beforePingContextInstrumentation(ictx hook.HookContext, db *sql.DB, ctx context.Context) {
@@ -90,7 +134,7 @@ func beforePingContextInstrumentation(ictx hook.HookContext, db *sql.DB, ctx con
 	if db == nil {
 		return
 	}
-	instrumentStart(ictx, ctx, "ping", "ping", db.Endpoint, db.DriverName, db.DSN, db.DbName)
+	instrumentStart(ictx, ctx, "ping", "ping", dbConnInfo(db))
 }
 
 func afterPingContextInstrumentation(ictx hook.HookContext, err error) {
@@ -149,7 +193,7 @@ func beforeExecContextInstrumentation(
 	if db == nil {
 		return
 	}
-	instrumentStart(ictx, ctx, "exec", query, db.Endpoint, db.DriverName, db.DSN, db.DbName, args...)
+	instrumentStart(ictx, ctx, "exec", query, dbConnInfo(db), args...)
 }
 
 func afterExecContextInstrumentation(ictx hook.HookContext, result sql.Result, err error) {
@@ -172,7 +216,7 @@ func beforeQueryContextInstrumentation(
 	if db == nil {
 		return
 	}
-	instrumentStart(ictx, ctx, "query", query, db.Endpoint, db.DriverName, db.DSN, db.DbName, args...)
+	instrumentStart(ictx, ctx, "query", query, dbConnInfo(db), args...)
 }
 
 func afterQueryContextInstrumentation(ictx hook.HookContext, rows *sql.Rows, err error) {
@@ -189,7 +233,7 @@ func beforeTxInstrumentation(ictx hook.HookContext, db *sql.DB, ctx context.Cont
 	if db == nil {
 		return
 	}
-	instrumentStart(ictx, ctx, "begin", "START TRANSACTION", db.Endpoint, db.DriverName, db.DSN, db.DbName)
+	instrumentStart(ictx, ctx, "begin", "START TRANSACTION", dbConnInfo(db))
 }
 
 func afterTxInstrumentation(ictx hook.HookContext, tx *sql.Tx, err error) {
@@ -211,6 +255,7 @@ func afterTxInstrumentation(ictx hook.HookContext, tx *sql.Tx, err error) {
 	tx.DriverName = dbRequest.DriverName
 	tx.DSN = dbRequest.Dsn
 	tx.DbName = dbRequest.DbName
+	setTxContext(ictx, tx)
 	instrumentEnd(ictx, err)
 }
 
@@ -265,7 +310,7 @@ func beforeConnPingContextInstrumentation(ictx hook.HookContext, conn *sql.Conn,
 	if conn == nil {
 		return
 	}
-	instrumentStart(ictx, ctx, "ping", "ping", conn.Endpoint, conn.DriverName, conn.DSN, conn.DbName)
+	instrumentStart(ictx, ctx, "ping", "ping", connConnInfo(conn))
 }
 
 func afterConnPingContextInstrumentation(ictx hook.HookContext, err error) {
@@ -324,7 +369,7 @@ func beforeConnExecContextInstrumentation(
 	if conn == nil {
 		return
 	}
-	instrumentStart(ictx, ctx, "exec", query, conn.Endpoint, conn.DriverName, conn.DSN, conn.DbName, args...)
+	instrumentStart(ictx, ctx, "exec", query, connConnInfo(conn), args...)
 }
 
 func afterConnExecContextInstrumentation(ictx hook.HookContext, result sql.Result, err error) {
@@ -347,7 +392,7 @@ func beforeConnQueryContextInstrumentation(
 	if conn == nil {
 		return
 	}
-	instrumentStart(ictx, ctx, "query", query, conn.Endpoint, conn.DriverName, conn.DSN, conn.DbName, args...)
+	instrumentStart(ictx, ctx, "query", query, connConnInfo(conn), args...)
 }
 
 func afterConnQueryContextInstrumentation(ictx hook.HookContext, rows *sql.Rows, err error) {
@@ -364,13 +409,16 @@ func beforeConnTxInstrumentation(ictx hook.HookContext, conn *sql.Conn, ctx cont
 	if conn == nil {
 		return
 	}
-	instrumentStart(ictx, ctx, "start", "START TRANSACTION", conn.Endpoint, conn.DriverName, conn.DSN, conn.DbName)
+	instrumentStart(ictx, ctx, "start", "START TRANSACTION", connConnInfo(conn))
 }
 
 func afterConnTxInstrumentation(ictx hook.HookContext, tx *sql.Tx, err error) {
 	if !clientEnabler.Enable() {
 		return
 	}
+	if tx != nil {
+		setTxContext(ictx, tx)
+	}
 	instrumentEnd(ictx, err)
 }
 
@@ -469,7 +517,8 @@ func beforeTxExecContextInstrumentation(
 	if tx == nil {
 		return
 	}
-	instrumentStart(ictx, ctx, "exec", query, tx.Endpoint, tx.DriverName, tx.DSN, tx.DbName, args...)
+	instrumentStart(ictx, ctx, "exec", query, txConnInfo(tx), args...)
+	addTxStatementEvent(ictx, tx)
 }
 
 func afterTxExecContextInstrumentation(ictx hook.HookContext, result sql.Result, err error) {
@@ -492,7 +541,8 @@ func beforeTxQueryContextInstrumentation(
 	if tx == nil {
 		return
 	}
-	instrumentStart(ictx, ctx, "query", query, tx.Endpoint, tx.DriverName, tx.DSN, tx.DbName, args...)
+	instrumentStart(ictx, ctx, "query", query, txConnInfo(tx), args...)
+	addTxStatementEvent(ictx, tx)
 }
 
 func afterTxQueryContextInstrumentation(ictx hook.HookContext, rows *sql.Rows, err error) {
@@ -509,7 +559,10 @@ func beforeTxCommitInstrumentation(ictx hook.HookContext, tx *sql.Tx) {
 	if tx == nil {
 		return
 	}
-	instrumentStart(ictx, context.Background(), "commit", "COMMIT", tx.Endpoint, tx.DriverName, tx.DSN, tx.DbName)
+	instrumentStart(ictx, txContext(tx), "commit", "COMMIT", txConnInfo(tx))
+	if span, ok := ictx.GetKeyData("span").(trace.Span); ok {
+		semconv.AddTxEndEvent(span, tx.Statements.Load())
+	}
 }
 
 func afterTxCommitInstrumentation(ictx hook.HookContext, err error) {
@@ -526,7 +579,10 @@ func beforeTxRollbackInstrumentation(ictx hook.HookContext, tx *sql.Tx) {
 	if tx == nil {
 		return
 	}
-	instrumentStart(ictx, context.Background(), "rollback", "ROLLBACK", tx.Endpoint, tx.DriverName, tx.DSN, tx.DbName)
+	instrumentStart(ictx, txContext(tx), "rollback", "ROLLBACK", txConnInfo(tx))
+	if span, ok := ictx.GetKeyData("span").(trace.Span); ok {
+		semconv.AddTxEndEvent(span, tx.Statements.Load())
+	}
 }
 
 func afterTxRollbackInstrumentation(ictx hook.HookContext, err error) {
@@ -548,11 +604,7 @@ func beforeStmtExecContextInstrumentation(
 	if stmt == nil {
 		return
 	}
-	sql1, endpoint, driverName, dsn, dbName := "", "", "", "", ""
-	if stmt.Data != nil {
-		sql1, endpoint, driverName, dsn, dbName = stmt.Data["sql"], stmt.Data["endpoint"], stmt.Data["driver"], stmt.DSN, stmt.Data["dbName"]
-	}
-	instrumentStart(ictx, ctx, "exec", sql1, endpoint, driverName, dsn, dbName, args...)
+	instrumentStart(ictx, ctx, "exec", stmt.Data["sql"], stmtConnInfo(stmt), args...)
 }
 
 func afterStmtExecContextInstrumentation(ictx hook.HookContext, result sql.Result, err error) {
@@ -574,11 +626,7 @@ func beforeStmtQueryContextInstrumentation(
 	if stmt == nil {
 		return
 	}
-	sql1, endpoint, driverName, dsn, dbName := "", "", "", "", ""
-	if stmt.Data != nil {
-		sql1, endpoint, driverName, dsn, dbName = stmt.Data["sql"], stmt.Data["endpoint"], stmt.Data["driver"], stmt.DSN, stmt.Data["dbName"]
-	}
-	instrumentStart(ictx, ctx, "query", sql1, endpoint, driverName, dsn, dbName, args...)
+	instrumentStart(ictx, ctx, "query", stmt.Data["sql"], stmtConnInfo(stmt), args...)
 }
 
 func afterStmtQueryContextInstrumentation(ictx hook.HookContext, rows *sql.Rows, err error) {
@@ -588,10 +636,78 @@ func afterStmtQueryContextInstrumentation(ictx hook.HookContext, rows *sql.Rows,
 	instrumentEnd(ictx, err)
 }
 
+// setTxContext keeps on tx the context of the span of its BeginTx, stored by
+// instrumentStart, for the spans of its commit or rollback to be its
+// children. Only its values are kept: a commit after the context of BeginTx
+// is canceled still fails on its own.
+func setTxContext(ictx hook.HookContext, tx *sql.Tx) {
+	if ctx, ok := ictx.GetKeyData("ctx").(context.Context); ok {
+		tx.Ctx = context.WithoutCancel(ctx)
+	}
+}
+
+// txContext returns the context the spans of the commit or rollback of tx
+// start from, the one setTxContext kept when its BeginTx was traced.
+func txContext(tx *sql.Tx) context.Context {
+	if tx.Ctx != nil {
+		return tx.Ctx
+	}
+	return context.Background()
+}
+
+// addTxStatementEvent marks the span of a statement run within tx with its
+// position in the transaction.
+func addTxStatementEvent(ictx hook.HookContext, tx *sql.Tx) {
+	span, ok := ictx.GetKeyData("span").(trace.Span)
+	if !ok {
+		return
+	}
+	semconv.AddTxStatementEvent(span, tx.Statements.Add(1))
+}
+
+func dbConnInfo(db *sql.DB) semconv.DatabaseSqlConnInfo {
+	return semconv.DatabaseSqlConnInfo{
+		Endpoint:   db.Endpoint,
+		DriverName: db.DriverName,
+		Dsn:        db.DSN,
+		DbName:     db.DbName,
+	}
+}
+
+func connConnInfo(conn *sql.Conn) semconv.DatabaseSqlConnInfo {
+	return semconv.DatabaseSqlConnInfo{
+		Endpoint:   conn.Endpoint,
+		DriverName: conn.DriverName,
+		Dsn:        conn.DSN,
+		DbName:     conn.DbName,
+	}
+}
+
+func txConnInfo(tx *sql.Tx) semconv.DatabaseSqlConnInfo {
+	return semconv.DatabaseSqlConnInfo{
+		Endpoint:   tx.Endpoint,
+		DriverName: tx.DriverName,
+		Dsn:        tx.DSN,
+		DbName:     tx.DbName,
+	}
+}
+
+// stmtConnInfo reads the metadata a prepare hook stored on stmt. A nil
+// stmt.Data reads as empty strings.
+func stmtConnInfo(stmt *sql.Stmt) semconv.DatabaseSqlConnInfo {
+	return semconv.DatabaseSqlConnInfo{
+		Endpoint:   stmt.Data["endpoint"],
+		DriverName: stmt.Data["driver"],
+		Dsn:        stmt.DSN,
+		DbName:     stmt.Data["dbName"],
+	}
+}
+
 func instrumentStart(
 	ictx hook.HookContext,
 	ctx context.Context,
-	spanName, query, endpoint, driverName, dsn, dbName string,
+	spanName, query string,
+	conn semconv.DatabaseSqlConnInfo,
 	args ...interface{},
 ) {
 	if !clientEnabler.Enable() {
@@ -599,24 +715,31 @@ func instrumentStart(
 		return
 	}
 	initInstrumentation()
-	req := semconv.DatabaseSqlRequest{
-		OpType:     calOp(query),
-		Sql:        query,
-		Endpoint:   endpoint,
-		DriverName: driverName,
-		Dsn:        dsn,
-		Params:     args,
-		DbName:     dbName,
-	}
+	req := semconv.NewDatabaseSqlRequest(conn, query, args)
 	// Get trace attributes from semconv
 	attrs := semconv.DbClientRequestTraceAttrs(req)
 
-	// Start span
+	// Start span, named after the query summary unless the rule has a span_name
+	// template
+	name := runtime.SpanNameWithContext(ctx, ictx, req.Summary, map[string]string{
+		"operation": req.OpType,
+		"table":     semconv.TableName(query),
+		"db":        req.DbName,
+		"driver":    req.DriverName,
+	})
 	ctx, span := tracer.Start(ctx,
-		req.OpType,
+		name,
 		trace.WithSpanKind(trace.SpanKindClient),
 		trace.WithAttributes(attrs...),
 	)
+	semconv.AddDbQueryEvent(span, req)
+	// Run the call within the span, so that the spans of the driver it calls,
+	// when wrapped, are its children
+	if ictx.GetParamCount() > ctxParamIndex {
+		if _, ok := ictx.GetParam(ctxParamIndex).(context.Context); ok {
+			ictx.SetParam(ctxParamIndex, ctx)
+		}
+	}
 
 	// Store data for after hook
 	ictx.SetData(map[string]interface{}{
@@ -641,23 +764,17 @@ func instrumentEnd(ictx hook.HookContext, err error) {
 		return
 	}
 	defer span.End()
+	defer runtime.RecordOverhead(ictx, span)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
+		if ctx, ok := ictx.GetKeyData("ctx").(context.Context); ok {
+			if cause := runtime.RecordCancelCause(ctx, span); cause != nil {
+				span.SetStatus(codes.Error, cause.Error())
+			}
+		}
 	}
 }
 
-func calOp(sql string) string {
-	trimmed := strings.TrimSpace(sql)
-	if trimmed == "" {
-		return ""
-	}
-	fields := strings.Fields(trimmed)
-	if len(fields) == 0 {
-		return ""
-	}
-	return strings.ToUpper(fields[0])
-}
-
 // moduleVersion extracts the version from the Go module system.
 // Falls back to "dev" if version cannot be determined.
 func moduleVersion() string {
@@ -688,11 +805,6 @@ func initInstrumentation() {
 			trace.WithInstrumentationVersion(version),
 		)
 
-		// Start runtime metrics (respects OTEL_GO_ENABLED/DISABLED_INSTRUMENTATIONS)
-		if err := runtime.StartRuntimeMetrics(); err != nil {
-			logger.Error("failed to start runtime metrics", "error", err)
-		}
-
 		logger.Info("DB client instrumentation initialized")
 	})
 }
@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package dbregistry keeps track of the sql.DB handles an instrumented
+// application has open, so that observable instruments such as connection
+// pool gauges can report on each of them.
+package dbregistry
+
+import (
+	"database/sql"
+	"runtime"
+	"sync"
+	"weak"
+)
+
+// Registry is a set of live *sql.DB handles, each tagged with the endpoint it
+// connects to. It is safe for concurrent use.
+//
+// The registry holds its handles weakly: a DB the application drops without
+// closing is removed once it is garbage collected, so registering does not
+// keep it alive.
+type Registry struct {
+	mu  sync.RWMutex
+	dbs map[weak.Pointer[sql.DB]]string
+}
+
+// New returns an empty Registry.
+func New() *Registry {
+	return &Registry{dbs: make(map[weak.Pointer[sql.DB]]string)}
+}
+
+// Add registers db under endpoint. Adding a registered db again only updates
+// its endpoint.
+func (r *Registry) Add(db *sql.DB, endpoint string) {
+	if db == nil {
+		return
+	}
+	key := weak.Make(db)
+	r.mu.Lock()
+	_, found := r.dbs[key]
+	r.dbs[key] = endpoint
+	r.mu.Unlock()
+	if !found {
+		runtime.AddCleanup(db, r.remove, key)
+	}
+}
+
+// Remove unregisters db, typically because it was closed.
+func (r *Registry) Remove(db *sql.DB) {
+	if db == nil {
+		return
+	}
+	r.remove(weak.Make(db))
+}
+
+func (r *Registry) remove(key weak.Pointer[sql.DB]) {
+	r.mu.Lock()
+	delete(r.dbs, key)
+	r.mu.Unlock()
+}
+
+// Range calls fn for every registered DB that is still alive. It works on a
+// snapshot, so fn may add or remove DBs.
+func (r *Registry) Range(fn func(endpoint string, db *sql.DB)) {
+	type entry struct {
+		endpoint string
+		key      weak.Pointer[sql.DB]
+	}
+	r.mu.RLock()
+	entries := make([]entry, 0, len(r.dbs))
+	for key, endpoint := range r.dbs {
+		entries = append(entries, entry{endpoint: endpoint, key: key})
+	}
+	r.mu.RUnlock()
+
+	for _, e := range entries {
+		if db := e.key.Value(); db != nil {
+			fn(e.endpoint, db)
+		}
+	}
+}
+
+// Len returns the number of registered DBs.
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.dbs)
+}
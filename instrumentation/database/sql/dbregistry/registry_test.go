@@ -0,0 +1,95 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dbregistry
+
+import (
+	"database/sql"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newDB returns a handle that is never used to connect; the registry only
+// needs distinct pointers.
+func newDB() *sql.DB {
+	return &sql.DB{}
+}
+
+func endpoints(r *Registry) map[*sql.DB]string {
+	seen := make(map[*sql.DB]string)
+	r.Range(func(endpoint string, db *sql.DB) {
+		seen[db] = endpoint
+	})
+	return seen
+}
+
+func TestRegistry(t *testing.T) {
+	r := New()
+	mysql, postgres := newDB(), newDB()
+
+	r.Add(mysql, "127.0.0.1:3306")
+	r.Add(postgres, "127.0.0.1:5432")
+	r.Add(nil, "ignored")
+	assert.Equal(t, map[*sql.DB]string{mysql: "127.0.0.1:3306", postgres: "127.0.0.1:5432"}, endpoints(r))
+
+	r.Add(mysql, "10.0.0.1:3306")
+	assert.Equal(t, 2, r.Len())
+	assert.Equal(t, "10.0.0.1:3306", endpoints(r)[mysql])
+
+	r.Remove(mysql)
+	r.Remove(nil)
+	assert.Equal(t, map[*sql.DB]string{postgres: "127.0.0.1:5432"}, endpoints(r))
+
+	runtime.KeepAlive(postgres)
+}
+
+func TestRegistry_Concurrent(t *testing.T) {
+	r := New()
+	const workers, perWorker = 8, 50
+	kept := make([][]*sql.DB, workers)
+
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perWorker {
+				db := newDB()
+				r.Add(db, fmt.Sprintf("host-%d:%d", w, i))
+				// Iterate while others mutate the registry
+				r.Range(func(string, *sql.DB) {})
+				if i%2 == 0 {
+					r.Remove(db)
+					continue
+				}
+				kept[w] = append(kept[w], db)
+			}
+		}()
+	}
+	wg.Wait()
+
+	expected := make(map[*sql.DB]string)
+	for w, dbs := range kept {
+		for j, db := range dbs {
+			expected[db] = fmt.Sprintf("host-%d:%d", w, 2*j+1)
+		}
+	}
+	assert.Equal(t, expected, endpoints(r))
+	assert.Equal(t, workers*perWorker/2, r.Len())
+	runtime.KeepAlive(kept)
+}
+
+func TestRegistry_DropsCollectedDBs(t *testing.T) {
+	r := New()
+	r.Add(newDB(), "127.0.0.1:3306")
+
+	assert.Eventually(t, func() bool {
+		runtime.GC()
+		return r.Len() == 0
+	}, 5*time.Second, 10*time.Millisecond, "a garbage collected DB must be unregistered")
+}
@@ -0,0 +1,193 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package driverwrap
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+)
+
+// The errors database/sql returns for options a driver without BeginTx cannot
+// honor.
+var (
+	errIsolationLevel = errors.New("sql: driver does not support non-default isolation level")
+	errReadOnly       = errors.New("sql: driver does not support read-only transactions")
+)
+
+// wrappedConn traces the operations of a driver connection. database/sql
+// looks up the optional interfaces on the connection it is handed, so
+// wrappedConn implements them all and, when conn lacks one, behaves as
+// database/sql does in its absence: ErrSkip falls back to preparing the query,
+// and a missing checker, resetter or validator is a no-op.
+type wrappedConn struct {
+	conn driver.Conn
+	t    *tracing
+}
+
+var (
+	_ driver.Conn               = (*wrappedConn)(nil)
+	_ driver.ConnPrepareContext = (*wrappedConn)(nil)
+	_ driver.ConnBeginTx        = (*wrappedConn)(nil)
+	_ driver.ExecerContext      = (*wrappedConn)(nil)
+	_ driver.QueryerContext     = (*wrappedConn)(nil)
+	_ driver.Pinger             = (*wrappedConn)(nil)
+	_ driver.SessionResetter    = (*wrappedConn)(nil)
+	_ driver.Validator          = (*wrappedConn)(nil)
+	_ driver.NamedValueChecker  = (*wrappedConn)(nil)
+)
+
+func (c *wrappedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *wrappedConn) PrepareContext(ctx context.Context, query string) (stmt driver.Stmt, err error) {
+	ctx, end := c.t.start(ctx, SpanPrepare, query)
+	defer func() { end(err) }()
+	if pc, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = pc.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedStmt{stmt: stmt, conn: c.conn, query: query, t: c.t}, nil
+}
+
+func (c *wrappedConn) Close() error { return c.conn.Close() }
+
+//nolint:staticcheck // Begin is part of driver.Conn
+func (c *wrappedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *wrappedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (tx driver.Tx, err error) {
+	ctx, end := c.t.start(ctx, SpanBegin, "")
+	defer func() { end(err) }()
+	if bc, ok := c.conn.(driver.ConnBeginTx); ok {
+		tx, err = bc.BeginTx(ctx, opts)
+	} else {
+		tx, err = beginLegacy(ctx, c.conn, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedTx{ctx: ctx, tx: tx, t: c.t}, nil
+}
+
+// beginLegacy begins a transaction on a driver without BeginTx the way
+// database/sql does: options it cannot honor are rejected, and the transaction
+// is rolled back when ctx is done meanwhile.
+func beginLegacy(ctx context.Context, conn driver.Conn, opts driver.TxOptions) (driver.Tx, error) {
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, errIsolationLevel
+	}
+	if opts.ReadOnly {
+		return nil, errReadOnly
+	}
+	//nolint:staticcheck // the driver has no BeginTx
+	tx, err := conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-ctx.Done():
+		_ = tx.Rollback()
+		return nil, ctx.Err()
+	default:
+		return tx, nil
+	}
+}
+
+func (c *wrappedConn) ExecContext(
+	ctx context.Context,
+	query string,
+	args []driver.NamedValue,
+) (res driver.Result, err error) {
+	ec, ok := c.conn.(driver.ExecerContext)
+	//nolint:staticcheck // legacy Execer drivers are still supported
+	e, legacy := c.conn.(driver.Execer)
+	if !ok && !legacy {
+		return nil, driver.ErrSkip
+	}
+	ctx, end := c.t.start(ctx, SpanConnExec, query)
+	defer func() { end(err) }()
+	if ok {
+		return ec.ExecContext(ctx, query, args)
+	}
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return e.Exec(query, values)
+}
+
+func (c *wrappedConn) QueryContext(
+	ctx context.Context,
+	query string,
+	args []driver.NamedValue,
+) (rows driver.Rows, err error) {
+	qc, ok := c.conn.(driver.QueryerContext)
+	//nolint:staticcheck // legacy Queryer drivers are still supported
+	q, legacy := c.conn.(driver.Queryer)
+	if !ok && !legacy {
+		return nil, driver.ErrSkip
+	}
+	ctx, end := c.t.start(ctx, SpanConnQuery, query)
+	defer func() { end(err) }()
+	if ok {
+		return qc.QueryContext(ctx, query, args)
+	}
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return q.Query(query, values)
+}
+
+func (c *wrappedConn) Ping(ctx context.Context) (err error) {
+	p, ok := c.conn.(driver.Pinger)
+	if !ok {
+		return nil
+	}
+	ctx, end := c.t.start(ctx, SpanPing, "")
+	defer func() { end(err) }()
+	return p.Ping(ctx)
+}
+
+func (c *wrappedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *wrappedConn) IsValid() bool {
+	if v, ok := c.conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *wrappedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// namedValuesToValues converts the arguments of a query for a legacy driver
+// interface, which has no named arguments.
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errNamedArgs
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
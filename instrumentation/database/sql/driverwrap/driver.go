@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package driverwrap wraps the drivers registered with sql.Register so that
+// the operations database/sql runs on their connections, statements and
+// transactions are traced, below the spans of the sql.DB API.
+package driverwrap
+
+import (
+	"context"
+	"database/sql/driver"
+
+	dbsemconv "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/database/sql/semconv"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span names of the driver operations, following the sql.<object>.<method>
+// convention of the otelsql instrumentation.
+const (
+	SpanConnect   = "sql.connector.connect"
+	SpanPing      = "sql.conn.ping"
+	SpanPrepare   = "sql.conn.prepare"
+	SpanBegin     = "sql.conn.begin_tx"
+	SpanConnExec  = "sql.conn.exec"
+	SpanConnQuery = "sql.conn.query"
+	SpanStmtExec  = "sql.stmt.exec"
+	SpanStmtQuery = "sql.stmt.query"
+	SpanCommit    = "sql.tx.commit"
+	SpanRollback  = "sql.tx.rollback"
+)
+
+// TracerFunc returns the tracer of the driver spans, or nil when they are
+// disabled. It is called for every operation, never when the driver is wrapped:
+// drivers register from package init functions, before the tracer is set up.
+type TracerFunc func() trace.Tracer
+
+// Wrap returns drv, registered under name, wrapped so that the operations on
+// its connections are traced with the tracer returned by tracer. The wrapped
+// connections implement every optional driver interface, falling back to what
+// database/sql does without it when the connection of drv does not.
+func Wrap(name string, drv driver.Driver, tracer TracerFunc) driver.Driver {
+	if _, ok := drv.(*wrappedDriver); ok {
+		return drv
+	}
+	return &wrappedDriver{driver: drv, t: &tracing{name: name, tracer: tracer}}
+}
+
+// Unwrap returns the driver drv wraps, or drv itself when it is not wrapped.
+func Unwrap(drv driver.Driver) driver.Driver {
+	if w, ok := drv.(*wrappedDriver); ok {
+		return w.driver
+	}
+	return drv
+}
+
+// tracing starts the spans of the operations of a wrapped driver.
+type tracing struct {
+	name   string
+	tracer TracerFunc
+}
+
+// start starts the span of an operation running query, if any. The returned
+// end function ends it, failed with the error of the operation, if any.
+func (t *tracing) start(ctx context.Context, spanName, query string) (context.Context, func(error)) {
+	tracer := t.tracer()
+	if tracer == nil {
+		return ctx, func(error) {}
+	}
+	attrs := []attribute.KeyValue{dbsemconv.DBSystemName(t.name)}
+	if query != "" {
+		attrs = append(attrs, semconv.DBQueryText(query))
+	}
+	ctx, span := tracer.Start(ctx, spanName,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx, func(err error) {
+		// ErrSkip and ErrRemoveArgument steer database/sql, they are no failures
+		if err != nil && err != driver.ErrSkip && err != driver.ErrRemoveArgument {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+type wrappedDriver struct {
+	driver driver.Driver
+	t      *tracing
+}
+
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedConn{conn: conn, t: d.t}, nil
+}
+
+// OpenConnector lets sql.Open connect through the connector of the driver,
+// when it has one, as it would without the wrapper.
+func (d *wrappedDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.driver.(driver.DriverContext); ok {
+		connector, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &wrappedConnector{connector: connector, driver: d}, nil
+	}
+	return &dsnConnector{dsn: name, driver: d}, nil
+}
+
+type wrappedConnector struct {
+	connector driver.Connector
+	driver    *wrappedDriver
+}
+
+func (c *wrappedConnector) Connect(ctx context.Context) (conn driver.Conn, err error) {
+	ctx, end := c.driver.t.start(ctx, SpanConnect, "")
+	defer func() { end(err) }()
+	conn, err = c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedConn{conn: conn, t: c.driver.t}, nil
+}
+
+func (c *wrappedConnector) Driver() driver.Driver { return c.driver }
+
+// dsnConnector is the connector of a driver without one, as database/sql
+// builds it.
+type dsnConnector struct {
+	dsn    string
+	driver *wrappedDriver
+}
+
+func (c *dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open(c.dsn) }
+
+func (c *dsnConnector) Driver() driver.Driver { return c.driver }
@@ -0,0 +1,275 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package driverwrap
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var errBrokenQuery = errors.New("broken query")
+
+// memDriver is an in-memory driver keeping a list of values. Its connections
+// only run the queries "insert", "select" and "broken", through
+// ExecerContext and QueryerContext unless prepareOnly is set.
+type memDriver struct {
+	mu          sync.Mutex
+	values      []int64
+	prepareOnly bool
+	checkPoints bool
+	rollbacks   int
+}
+
+func (d *memDriver) Open(string) (driver.Conn, error) {
+	if d.checkPoints {
+		return &memCheckConn{memPrepareConn{memConn{d: d}}}, nil
+	}
+	if d.prepareOnly {
+		return &memPrepareConn{memConn{d: d}}, nil
+	}
+	return &memConn{d: d}, nil
+}
+
+type memConn struct{ d *memDriver }
+
+func (c *memConn) Prepare(query string) (driver.Stmt, error) {
+	return &memStmt{c: c, query: query}, nil
+}
+
+func (c *memConn) Close() error { return nil }
+
+func (c *memConn) Begin() (driver.Tx, error) { return memTx{d: c.d}, nil }
+
+func (c *memConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if query != "insert" {
+		return nil, errBrokenQuery
+	}
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	for _, arg := range args {
+		c.d.values = append(c.d.values, arg.Value.(int64))
+	}
+	return driver.RowsAffected(len(args)), nil
+}
+
+func (c *memConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	return &memRows{values: append([]int64(nil), c.d.values...)}, nil
+}
+
+// memPrepareConn hides the ExecerContext and QueryerContext of memConn, so
+// that database/sql prepares every query.
+type memPrepareConn struct{ c memConn }
+
+func (c *memPrepareConn) Prepare(query string) (driver.Stmt, error) { return c.c.Prepare(query) }
+
+func (c *memPrepareConn) Close() error { return nil }
+
+func (c *memPrepareConn) Begin() (driver.Tx, error) { return c.c.Begin() }
+
+// point is not a driver.Value: only memCheckConn accepts it.
+type point struct{ v int64 }
+
+// memCheckConn is a memPrepareConn whose NamedValueChecker converts points.
+type memCheckConn struct{ memPrepareConn }
+
+func (c *memCheckConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if p, ok := nv.Value.(point); ok {
+		nv.Value = p.v
+		return nil
+	}
+	return driver.ErrSkip
+}
+
+type memStmt struct {
+	c     *memConn
+	query string
+}
+
+func (s *memStmt) Close() error { return nil }
+
+func (s *memStmt) NumInput() int { return -1 }
+
+func (s *memStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.c.ExecContext(context.Background(), s.query, valuesToNamedValues(args))
+}
+
+func (s *memStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.c.QueryContext(context.Background(), s.query, valuesToNamedValues(args))
+}
+
+type memTx struct{ d *memDriver }
+
+func (memTx) Commit() error { return nil }
+
+func (tx memTx) Rollback() error {
+	tx.d.mu.Lock()
+	defer tx.d.mu.Unlock()
+	tx.d.rollbacks++
+	return nil
+}
+
+type memRows struct{ values []int64 }
+
+func (r *memRows) Columns() []string { return []string{"value"} }
+
+func (r *memRows) Close() error { return nil }
+
+func (r *memRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
+
+// openDB opens a database on drv wrapped under name, recording the driver
+// spans with the returned recorder.
+func openDB(t *testing.T, name string, drv driver.Driver) (*sql.DB, *tracetest.SpanRecorder) {
+	t.Helper()
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	tracer := tp.Tracer("driverwrap-test")
+
+	sql.Register(name, Wrap(name, drv, func() trace.Tracer { return tracer }))
+	db, err := sql.Open(name, "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db, sr
+}
+
+func spanNames(sr *tracetest.SpanRecorder) []string {
+	var names []string
+	for _, s := range sr.Ended() {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+func TestWrap_DriverSpans(t *testing.T) {
+	db, sr := openDB(t, "driverwrap-mem", &memDriver{})
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, "insert", int64(1), int64(2))
+	require.NoError(t, err)
+	var sum int64
+	rows, err := db.QueryContext(ctx, "select")
+	require.NoError(t, err)
+	for rows.Next() {
+		var v int64
+		require.NoError(t, rows.Scan(&v))
+		sum += v
+	}
+	require.NoError(t, rows.Close())
+	assert.Equal(t, int64(3), sum)
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	assert.Equal(t, []string{SpanConnExec, SpanConnQuery, SpanBegin, SpanCommit}, spanNames(sr))
+	spans := sr.Ended()
+	assert.Contains(t, spans[0].Attributes(), semconv.DBQueryText("insert"))
+	assert.Equal(t, trace.SpanKindInternal, spans[0].SpanKind())
+	// The commit is traced within the context the transaction was begun with
+	assert.Equal(t, spans[2].SpanContext().SpanID(), spans[3].Parent().SpanID())
+}
+
+func TestWrap_PreparedStatements(t *testing.T) {
+	db, sr := openDB(t, "driverwrap-mem-prepare", &memDriver{prepareOnly: true})
+
+	_, err := db.Exec("insert", int64(1))
+	require.NoError(t, err)
+
+	// database/sql prepared the query, since the connection cannot execute it
+	assert.Equal(t, []string{SpanPrepare, SpanStmtExec}, spanNames(sr))
+	assert.Contains(t, sr.Ended()[1].Attributes(), semconv.DBQueryText("insert"))
+}
+
+func TestWrap_PreparedStatementsCheckConnValues(t *testing.T) {
+	drv := &memDriver{checkPoints: true}
+	db, sr := openDB(t, "driverwrap-mem-check", drv)
+
+	// The statement has no checker, so the one of the connection converts
+	// the point, and the default converter the int
+	_, err := db.Exec("insert", point{v: 1}, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{1, 2}, drv.values)
+	assert.Equal(t, []string{SpanPrepare, SpanStmtExec}, spanNames(sr))
+}
+
+func TestWrap_LegacyBeginTxOptions(t *testing.T) {
+	// memConn has no BeginTx, so database/sql rejects the options it cannot
+	// honor, and so does the wrapper
+	db, _ := openDB(t, "driverwrap-mem-legacy-begin", &memDriver{})
+
+	_, err := db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelSerializable})
+	require.ErrorIs(t, err, errIsolationLevel)
+	_, err = db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	require.ErrorIs(t, err, errReadOnly)
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+}
+
+func TestBeginLegacy_Canceled(t *testing.T) {
+	drv := &memDriver{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tx, err := beginLegacy(ctx, &memConn{d: drv}, driver.TxOptions{})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, tx)
+	assert.Equal(t, 1, drv.rollbacks, "the transaction begun meanwhile is rolled back")
+}
+
+func TestWrap_Error(t *testing.T) {
+	db, sr := openDB(t, "driverwrap-mem-error", &memDriver{})
+
+	_, err := db.Exec("broken")
+	require.ErrorIs(t, err, errBrokenQuery)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, errBrokenQuery.Error(), spans[0].Status().Description)
+}
+
+func TestWrap_Disabled(t *testing.T) {
+	drv := &memDriver{}
+	name := "driverwrap-mem-disabled"
+	sql.Register(name, Wrap(name, drv, func() trace.Tracer { return nil }))
+	db, err := sql.Open(name, "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec("insert", int64(1))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, drv.values)
+}
+
+func TestWrap_Unwrap(t *testing.T) {
+	drv := &memDriver{}
+	wrapped := Wrap("mem", drv, func() trace.Tracer { return nil })
+	assert.Same(t, wrapped, Wrap("mem", wrapped, nil), "wrapping twice")
+	assert.Same(t, drv, Unwrap(wrapped))
+	assert.Same(t, drv, Unwrap(drv))
+}
@@ -0,0 +1,122 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package driverwrap
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+)
+
+var errNamedArgs = errors.New("driverwrap: driver does not support named arguments")
+
+// wrappedStmt traces the executions of a prepared statement of conn.
+type wrappedStmt struct {
+	stmt  driver.Stmt
+	conn  driver.Conn
+	query string
+	t     *tracing
+}
+
+var (
+	_ driver.Stmt              = (*wrappedStmt)(nil)
+	_ driver.StmtExecContext   = (*wrappedStmt)(nil)
+	_ driver.StmtQueryContext  = (*wrappedStmt)(nil)
+	_ driver.NamedValueChecker = (*wrappedStmt)(nil)
+	_ driver.ColumnConverter   = (*wrappedStmt)(nil)
+)
+
+func (s *wrappedStmt) Close() error { return s.stmt.Close() }
+
+func (s *wrappedStmt) NumInput() int { return s.stmt.NumInput() }
+
+//nolint:staticcheck // Exec is part of driver.Stmt
+func (s *wrappedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), valuesToNamedValues(args))
+}
+
+//nolint:staticcheck // Query is part of driver.Stmt
+func (s *wrappedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), valuesToNamedValues(args))
+}
+
+func (s *wrappedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (res driver.Result, err error) {
+	ctx, end := s.t.start(ctx, SpanStmtExec, s.query)
+	defer func() { end(err) }()
+	if ec, ok := s.stmt.(driver.StmtExecContext); ok {
+		return ec.ExecContext(ctx, args)
+	}
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:staticcheck // the statement has no ExecContext
+	return s.stmt.Exec(values)
+}
+
+func (s *wrappedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (rows driver.Rows, err error) {
+	ctx, end := s.t.start(ctx, SpanStmtQuery, s.query)
+	defer func() { end(err) }()
+	if qc, ok := s.stmt.(driver.StmtQueryContext); ok {
+		return qc.QueryContext(ctx, args)
+	}
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:staticcheck // the statement has no QueryContext
+	return s.stmt.Query(values)
+}
+
+// CheckNamedValue defers to the statement, then to the connection, the order
+// database/sql looks for a checker in. Since wrappedStmt always has one, the
+// checker of the connection would be skipped otherwise.
+func (s *wrappedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := s.stmt.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	if nvc, ok := s.conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// ColumnConverter defers to the statement, which database/sql falls back to
+// when CheckNamedValue skips an argument.
+//
+//nolint:staticcheck // ColumnConverter is still honored by database/sql
+func (s *wrappedStmt) ColumnConverter(idx int) driver.ValueConverter {
+	if cc, ok := s.stmt.(driver.ColumnConverter); ok {
+		return cc.ColumnConverter(idx)
+	}
+	return driver.DefaultParameterConverter
+}
+
+func valuesToNamedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+// wrappedTx traces the end of a transaction, within the context it was begun
+// with.
+type wrappedTx struct {
+	ctx context.Context
+	tx  driver.Tx
+	t   *tracing
+}
+
+func (tx *wrappedTx) Commit() (err error) {
+	_, end := tx.t.start(tx.ctx, SpanCommit, "")
+	defer func() { end(err) }()
+	return tx.tx.Commit()
+}
+
+func (tx *wrappedTx) Rollback() (err error) {
+	_, end := tx.t.start(tx.ctx, SpanRollback, "")
+	defer func() { end(err) }()
+	return tx.tx.Rollback()
+}
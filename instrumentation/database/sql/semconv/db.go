@@ -5,14 +5,47 @@ package semconv
 
 import (
 	"net"
+	"os"
 	"strconv"
+	"strings"
 
 	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	// EnvStatementAsEvent records the statement as the QueryEventName span
+	// event instead of the db.query.text attribute when set to "true". Some
+	// backends handle large events better than large attributes.
+	EnvStatementAsEvent = "OTEL_GO_DB_STATEMENT_AS_EVENT"
+
+	// QueryEventName is the name of the span event carrying the statement.
+	QueryEventName = "db.query"
+)
+
+// statementAsEvent is true when EnvStatementAsEvent is "true".
+var statementAsEvent bool
+
+func init() {
+	ReadStatementAsEvent()
+}
+
+// ReadStatementAsEvent reads EnvStatementAsEvent again. It is read once, at
+// init, so tests changing it, here and in the instrumentations sharing this
+// package, call it after setting it.
+func ReadStatementAsEvent() {
+	statementAsEvent = os.Getenv(EnvStatementAsEvent) == "true"
+}
+
+// StatementAsEvent reports whether the statement is recorded as a span event.
+func StatementAsEvent() bool {
+	return statementAsEvent
+}
+
 type DatabaseSqlRequest struct {
 	OpType     string
+	Summary    string
 	Sql        string
 	Endpoint   string
 	DriverName string
@@ -21,6 +54,131 @@ type DatabaseSqlRequest struct {
 	DbName     string
 }
 
+// DatabaseSqlConnInfo is the connection metadata that the instrumentation
+// carries on sql.DB, sql.Conn, sql.Tx and sql.Stmt.
+type DatabaseSqlConnInfo struct {
+	Endpoint   string
+	DriverName string
+	Dsn        string
+	DbName     string
+}
+
+// NewDatabaseSqlRequest builds the request for running query with params over
+// the connection described by conn. The operation type is the upper-cased
+// first word of query.
+func NewDatabaseSqlRequest(conn DatabaseSqlConnInfo, query string, params []any) DatabaseSqlRequest {
+	return DatabaseSqlRequest{
+		OpType:     operationType(query),
+		Summary:    QuerySummary(query),
+		Sql:        query,
+		Endpoint:   conn.Endpoint,
+		DriverName: conn.DriverName,
+		Dsn:        conn.Dsn,
+		Params:     params,
+		DbName:     conn.DbName,
+	}
+}
+
+func operationType(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
+
+// tableKeywords maps an operation type to the keyword its table name follows.
+var tableKeywords = map[string]string{
+	"SELECT":  "FROM",
+	"DELETE":  "FROM",
+	"INSERT":  "INTO",
+	"REPLACE": "INTO",
+	"UPDATE":  "UPDATE",
+}
+
+// identQuotes strips the quotes around SQL identifiers.
+var identQuotes = strings.NewReplacer("`", "", `"`, "", "[", "", "]", "")
+
+// TableName returns a best-effort guess of the table the simple query operates
+// on, e.g. "users" for "SELECT * FROM users WHERE id = ?", or "" when it
+// cannot tell. It is meant for span names, not for parsing SQL.
+func TableName(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	keyword, ok := tableKeywords[strings.ToUpper(fields[0])]
+	if !ok {
+		return ""
+	}
+	for i, field := range fields[:len(fields)-1] {
+		if strings.EqualFold(field, keyword) {
+			return tableIdent(fields[i+1])
+		}
+	}
+	return ""
+}
+
+// multiWordOperations maps the operations spelled with two keywords to
+// whether a table name follows them.
+var multiWordOperations = map[string]bool{
+	"START TRANSACTION": false,
+	"CREATE TABLE":      true,
+	"ALTER TABLE":       true,
+	"DROP TABLE":        true,
+	"TRUNCATE TABLE":    true,
+}
+
+// QuerySummary returns the low-cardinality db.query.summary of query: its
+// operation followed by its table when TableName can tell it, e.g.
+// "SELECT users" for "SELECT * FROM users WHERE id = 42". Literal values never
+// make it into the summary.
+func QuerySummary(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	operation := strings.ToUpper(strings.TrimRight(fields[0], ";"))
+	table := TableName(query)
+	if len(fields) > 1 {
+		words := operation + " " + strings.ToUpper(strings.TrimRight(fields[1], ";"))
+		if hasTable, ok := multiWordOperations[words]; ok {
+			operation, table = words, ""
+			if hasTable {
+				table = tableAfter(fields[2:])
+			}
+		}
+	}
+	if table == "" {
+		return operation
+	}
+	return operation + " " + table
+}
+
+// tableAfter returns the table named by the first of fields, skipping the
+// IF [NOT] EXISTS of DDL statements.
+func tableAfter(fields []string) string {
+	for len(fields) > 0 {
+		switch strings.ToUpper(fields[0]) {
+		case "IF", "NOT", "EXISTS":
+			fields = fields[1:]
+			continue
+		}
+		return tableIdent(fields[0])
+	}
+	return ""
+}
+
+// tableIdent returns the unquoted table name that field starts with.
+func tableIdent(field string) string {
+	table, _, _ := strings.Cut(field, "(")
+	return identQuotes.Replace(strings.TrimRight(table, ";,"))
+}
+
+// dbOperationParameterCountKey records how many parameters a query was
+// executed with. Unlike their values, see EnvStatementParams, it is always on.
+const dbOperationParameterCountKey = attribute.Key("db.operation.parameter.count")
+
 func DbClientRequestTraceAttrs(req DatabaseSqlRequest) []attribute.KeyValue {
 	host, portStr, err := net.SplitHostPort(req.Endpoint)
 	if err != nil {
@@ -32,7 +190,16 @@ func DbClientRequestTraceAttrs(req DatabaseSqlRequest) []attribute.KeyValue {
 		semconv.DBNamespace(req.DbName),
 		semconv.ServerAddress(host),
 		semconv.NetworkTransportTCP,
-		semconv.DBQueryText(req.Sql),
+	}
+	if req.Summary != "" {
+		attrs = append(attrs, semconv.DBQuerySummary(req.Summary))
+	}
+	if !StatementAsEvent() {
+		attrs = append(attrs, semconv.DBQueryText(req.Sql))
+	}
+	attrs = append(attrs, dbOperationParameterCountKey.Int(len(req.Params)))
+	if len(req.Params) > 0 && StatementParams() {
+		attrs = append(attrs, queryParametersAttrs(req.Params)...)
 	}
 
 	if err == nil {
@@ -41,16 +208,16 @@ func DbClientRequestTraceAttrs(req DatabaseSqlRequest) []attribute.KeyValue {
 		}
 	}
 
-	switch req.DriverName {
-	case "mysql":
-		attrs = append(attrs, semconv.DBSystemNameMySQL)
-	case "postgres":
-		attrs = append(attrs, semconv.DBSystemNamePostgreSQL)
-	case "sqlite3":
-		attrs = append(attrs, semconv.DBSystemNameSQLite)
-	default:
-		attrs = append(attrs, semconv.DBSystemNameOtherSQL)
-	}
+	attrs = append(attrs, DBSystemName(req.DriverName))
 
 	return attrs
 }
+
+// AddDbQueryEvent records the statement of req as the QueryEventName event of
+// span when StatementAsEvent, as DbClientRequestTraceAttrs leaves it out then.
+func AddDbQueryEvent(span trace.Span, req DatabaseSqlRequest) {
+	if !StatementAsEvent() {
+		return
+	}
+	span.AddEvent(QueryEventName, trace.WithAttributes(semconv.DBQueryText(req.Sql)))
+}
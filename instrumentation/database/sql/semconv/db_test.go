@@ -4,10 +4,18 @@
 package semconv
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 )
 
 func TestDbClientRequestTraceAttrs(t *testing.T) {
@@ -20,6 +28,7 @@ func TestDbClientRequestTraceAttrs(t *testing.T) {
 			name: "basic select query",
 			req: DatabaseSqlRequest{
 				OpType:     "SELECT",
+				Summary:    "SELECT users",
 				Sql:        "SELECT * FROM users WHERE id=?",
 				Endpoint:   "127.0.0.1:3306",
 				DriverName: "mysql",
@@ -28,13 +37,15 @@ func TestDbClientRequestTraceAttrs(t *testing.T) {
 				Params:     []any{1},
 			},
 			expected: map[string]interface{}{
-				"db.system.name":    "mysql",
-				"db.operation.name": "SELECT",
-				"db.namespace":      "testdb",
-				"server.address":    "127.0.0.1",
-				"server.port":       int64(3306),
-				"network.transport": "tcp",
-				"db.query.text":     "SELECT * FROM users WHERE id=?",
+				"db.system.name":               "mysql",
+				"db.operation.name":            "SELECT",
+				"db.query.summary":             "SELECT users",
+				"db.namespace":                 "testdb",
+				"server.address":               "127.0.0.1",
+				"server.port":                  int64(3306),
+				"network.transport":            "tcp",
+				"db.query.text":                "SELECT * FROM users WHERE id=?",
+				"db.operation.parameter.count": int64(1),
 			},
 		},
 		{
@@ -49,13 +60,14 @@ func TestDbClientRequestTraceAttrs(t *testing.T) {
 				Params:     []any{"john", "john@example.com"},
 			},
 			expected: map[string]interface{}{
-				"db.system.name":    "postgresql",
-				"db.operation.name": "INSERT",
-				"db.namespace":      "mydb",
-				"server.address":    "10.0.0.1",
-				"server.port":       int64(5432),
-				"network.transport": "tcp",
-				"db.query.text":     "INSERT INTO users (name, email) VALUES (?, ?)",
+				"db.system.name":               "postgresql",
+				"db.operation.name":            "INSERT",
+				"db.namespace":                 "mydb",
+				"server.address":               "10.0.0.1",
+				"server.port":                  int64(5432),
+				"network.transport":            "tcp",
+				"db.query.text":                "INSERT INTO users (name, email) VALUES (?, ?)",
+				"db.operation.parameter.count": int64(2),
 			},
 		},
 		{
@@ -69,12 +81,13 @@ func TestDbClientRequestTraceAttrs(t *testing.T) {
 				DbName:     "test",
 			},
 			expected: map[string]interface{}{
-				"db.system.name":    "sqlite",
-				"db.operation.name": "SELECT",
-				"db.namespace":      "test",
-				"server.address":    "sqlite3",
-				"network.transport": "tcp",
-				"db.query.text":     "SELECT * FROM items",
+				"db.system.name":               "sqlite",
+				"db.operation.name":            "SELECT",
+				"db.namespace":                 "test",
+				"server.address":               "sqlite3",
+				"network.transport":            "tcp",
+				"db.query.text":                "SELECT * FROM items",
+				"db.operation.parameter.count": int64(0),
 			},
 		},
 		{
@@ -218,6 +231,81 @@ func TestDatabaseSqlRequest_Struct(t *testing.T) {
 	assert.Equal(t, "testdb", req.DbName)
 }
 
+func TestNewDatabaseSqlRequest(t *testing.T) {
+	conn := DatabaseSqlConnInfo{
+		Endpoint:   "127.0.0.1:3306",
+		DriverName: "mysql",
+		Dsn:        "user:pass@tcp(127.0.0.1:3306)/testdb",
+		DbName:     "testdb",
+	}
+	tests := []struct {
+		name     string
+		query    string
+		params   []any
+		expected DatabaseSqlRequest
+	}{
+		{
+			name:   "exec",
+			query:  "insert into users (name) values (?)",
+			params: []any{"john"},
+			expected: DatabaseSqlRequest{
+				OpType:     "INSERT",
+				Summary:    "INSERT users",
+				Sql:        "insert into users (name) values (?)",
+				Endpoint:   "127.0.0.1:3306",
+				DriverName: "mysql",
+				Dsn:        "user:pass@tcp(127.0.0.1:3306)/testdb",
+				Params:     []any{"john"},
+				DbName:     "testdb",
+			},
+		},
+		{
+			name:   "query",
+			query:  "  SELECT * FROM users WHERE id=?",
+			params: []any{1},
+			expected: DatabaseSqlRequest{
+				OpType:     "SELECT",
+				Summary:    "SELECT users",
+				Sql:        "  SELECT * FROM users WHERE id=?",
+				Endpoint:   "127.0.0.1:3306",
+				DriverName: "mysql",
+				Dsn:        "user:pass@tcp(127.0.0.1:3306)/testdb",
+				Params:     []any{1},
+				DbName:     "testdb",
+			},
+		},
+		{
+			name:  "tx",
+			query: "START TRANSACTION",
+			expected: DatabaseSqlRequest{
+				OpType:     "START",
+				Summary:    "START TRANSACTION",
+				Sql:        "START TRANSACTION",
+				Endpoint:   "127.0.0.1:3306",
+				DriverName: "mysql",
+				Dsn:        "user:pass@tcp(127.0.0.1:3306)/testdb",
+				DbName:     "testdb",
+			},
+		},
+		{
+			name:  "empty query",
+			query: " ",
+			expected: DatabaseSqlRequest{
+				Sql:        " ",
+				Endpoint:   "127.0.0.1:3306",
+				DriverName: "mysql",
+				Dsn:        "user:pass@tcp(127.0.0.1:3306)/testdb",
+				DbName:     "testdb",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NewDatabaseSqlRequest(conn, tt.query, tt.params))
+		})
+	}
+}
+
 func TestDbClientRequestTraceAttrs_ContainsExpectedKeys(t *testing.T) {
 	req := DatabaseSqlRequest{
 		OpType:     "query",
@@ -244,9 +332,205 @@ func TestDbClientRequestTraceAttrs_ContainsExpectedKeys(t *testing.T) {
 		"server.port",
 		"network.transport",
 		"db.query.text",
+		"db.operation.parameter.count",
 	}
 
 	for _, key := range expectedKeys {
 		assert.True(t, keySet[key], "expected key %s not found in attributes", key)
 	}
 }
+
+func TestStatementAsEvent(t *testing.T) {
+	req := NewDatabaseSqlRequest(DatabaseSqlConnInfo{DriverName: "mysql"}, "SELECT * FROM users", nil)
+	recordSpan := func() sdktrace.ReadOnlySpan {
+		exporter := tracetest.NewInMemoryExporter()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+		_, span := tp.Tracer("test").Start(context.Background(), "SELECT")
+		span.SetAttributes(DbClientRequestTraceAttrs(req)...)
+		AddDbQueryEvent(span, req)
+		span.End()
+		spans := exporter.GetSpans().Snapshots()
+		require.Len(t, spans, 1)
+		return spans[0]
+	}
+
+	span := recordSpan()
+	assert.Contains(t, span.Attributes(), semconv.DBQueryText("SELECT * FROM users"))
+	assert.Empty(t, span.Events(), "the statement is an attribute by default")
+
+	// Cleanups run last in first out, so this one runs after the env is restored
+	t.Cleanup(ReadStatementAsEvent)
+	t.Setenv(EnvStatementAsEvent, "true")
+	ReadStatementAsEvent()
+	span = recordSpan()
+	for _, attr := range span.Attributes() {
+		assert.NotEqual(t, semconv.DBQueryTextKey, attr.Key, "the statement must not be an attribute")
+	}
+	require.Len(t, span.Events(), 1)
+	assert.Equal(t, QueryEventName, span.Events()[0].Name)
+	assert.Equal(t, []attribute.KeyValue{semconv.DBQueryText("SELECT * FROM users")}, span.Events()[0].Attributes)
+}
+
+func TestDbClientRequestTraceAttrs_ParameterCount(t *testing.T) {
+	for _, params := range [][]any{nil, {1}, {"a", 2, 3.5, nil}} {
+		attrs := DbClientRequestTraceAttrs(DatabaseSqlRequest{
+			OpType: "SELECT",
+			Sql:    "SELECT 1",
+			Params: params,
+		})
+		var count int64 = -1
+		for _, attr := range attrs {
+			if attr.Key == dbOperationParameterCountKey {
+				count = attr.Value.AsInt64()
+			}
+			// Parameter values must never be recorded
+			for _, p := range params {
+				if s, ok := p.(string); ok {
+					assert.NotEqual(t, s, attr.Value.Emit())
+				}
+			}
+		}
+		assert.Equal(t, int64(len(params)), count)
+	}
+}
+
+func TestDbClientRequestTraceAttrs_Parameters(t *testing.T) {
+	long := strings.Repeat("x", queryParameterMaxLength) + "tail"
+	req := DatabaseSqlRequest{
+		OpType: "SELECT",
+		Sql:    "SELECT * FROM users WHERE id = ? AND name = ?",
+		Params: []any{
+			42,
+			nil,
+			[]byte("raw"),
+			[]byte{0x00, 0xff},
+			long,
+			driver.NamedValue{Name: "id", Ordinal: 1, Value: int64(7)},
+			driver.NamedValue{Ordinal: 2, Value: nil},
+			sql.Named("name", "alice"),
+		},
+	}
+	parameters := func() (attribute.Value, bool) {
+		for _, attr := range DbClientRequestTraceAttrs(req) {
+			if attr.Key == dbQueryParametersKey {
+				return attr.Value, true
+			}
+		}
+		return attribute.Value{}, false
+	}
+
+	_, ok := parameters()
+	assert.False(t, ok, "parameters are not recorded by default")
+
+	// Cleanups run last in first out, so this one runs after the env is restored
+	t.Cleanup(initStatementParams)
+	t.Setenv(EnvStatementParams, "true")
+	initStatementParams()
+	value, ok := parameters()
+	require.True(t, ok)
+	assert.Equal(t, []string{
+		"42",
+		"NULL",
+		"0x726177",
+		"0x00ff",
+		long[:queryParameterMaxLength],
+		"id=7",
+		"NULL",
+		"name=alice",
+	}, value.AsStringSlice())
+
+	req.Params = nil
+	_, ok = parameters()
+	assert.False(t, ok, "no attribute without parameters")
+}
+
+func TestDbClientRequestTraceAttrs_ParametersTruncated(t *testing.T) {
+	t.Cleanup(initStatementParams)
+	t.Setenv(EnvStatementParams, "true")
+	initStatementParams()
+	params := make([]any, defaultMaxParams+5)
+	for i := range params {
+		params[i] = i
+	}
+	attrs := func() map[attribute.Key]attribute.Value {
+		m := make(map[attribute.Key]attribute.Value)
+		for _, attr := range DbClientRequestTraceAttrs(DatabaseSqlRequest{OpType: "INSERT", Params: params}) {
+			m[attr.Key] = attr.Value
+		}
+		return m
+	}
+
+	got := attrs()
+	assert.Len(t, got[dbQueryParametersKey].AsStringSlice(), defaultMaxParams)
+	assert.True(t, got[dbOperationParameterTruncatedKey].AsBool())
+	assert.Equal(t, int64(len(params)), got[dbOperationParameterCountKey].AsInt64(), "the count is not capped")
+
+	t.Cleanup(initMaxParams)
+	t.Setenv(EnvMaxParams, "3")
+	initMaxParams()
+	got = attrs()
+	assert.Equal(t, []string{"0", "1", "2"}, got[dbQueryParametersKey].AsStringSlice())
+	assert.True(t, got[dbOperationParameterTruncatedKey].AsBool())
+
+	t.Setenv(EnvMaxParams, "invalid")
+	initMaxParams()
+	assert.Len(t, attrs()[dbQueryParametersKey].AsStringSlice(), defaultMaxParams)
+
+	params = params[:3]
+	t.Setenv(EnvMaxParams, "3")
+	initMaxParams()
+	got = attrs()
+	assert.Len(t, got[dbQueryParametersKey].AsStringSlice(), 3)
+	assert.NotContains(t, got, dbOperationParameterTruncatedKey, "not truncated at the cap")
+}
+
+func TestQuerySummary(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: "SELECT * FROM users WHERE id = 42", expected: "SELECT users"},
+		{query: "select id, name from users where name = 'alice'", expected: "SELECT users"},
+		{query: "SELECT o.id FROM `shop`.`orders` o JOIN items i ON i.order_id = o.id", expected: "SELECT shop.orders"},
+		{query: "SELECT 1", expected: "SELECT"},
+		{query: "INSERT INTO users (name, email) VALUES ('bob', 'bob@example.com')", expected: "INSERT users"},
+		{query: "insert into \"events\"(id) values ($1)", expected: "INSERT events"},
+		{query: "UPDATE accounts SET balance = 100 WHERE id = 7", expected: "UPDATE accounts"},
+		{query: "UPDATE [dbo].[accounts] SET balance = 0", expected: "UPDATE dbo.accounts"},
+		{query: "DELETE FROM sessions WHERE expires < '2026-01-01';", expected: "DELETE sessions"},
+		{query: "START TRANSACTION", expected: "START TRANSACTION"},
+		{query: "start transaction;", expected: "START TRANSACTION"},
+		{query: "COMMIT", expected: "COMMIT"},
+		{query: "ping", expected: "PING"},
+		{query: "CREATE TABLE IF NOT EXISTS \"users\" (id int)", expected: "CREATE TABLE users"},
+		{query: "DROP TABLE IF EXISTS users;", expected: "DROP TABLE users"},
+		{query: "  ", expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.expected, QuerySummary(tt.query))
+		})
+	}
+}
+
+func TestTableName(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: "SELECT * FROM users WHERE id = ?", expected: "users"},
+		{query: "select id from `shop`.`orders`", expected: "shop.orders"},
+		{query: "INSERT INTO users(name) VALUES (?)", expected: "users"},
+		{query: "insert into \"events\" (id) values ($1)", expected: "events"},
+		{query: "UPDATE accounts SET balance = 0", expected: "accounts"},
+		{query: "DELETE FROM sessions;", expected: "sessions"},
+		{query: "SELECT 1", expected: ""},
+		{query: "CREATE TABLE users (id int)", expected: ""},
+		{query: "", expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.expected, TableName(tt.query))
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package semconv
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+)
+
+const (
+	// EnvStatementParams records the values a statement is executed with as
+	// the db.query.parameters attribute when set to "true". They may hold
+	// personal data, so they are left out by default.
+	EnvStatementParams = "OTEL_GO_INSTRUMENTATION_DB_STATEMENT_PARAMS"
+
+	// EnvMaxParams caps how many of the values of a statement are recorded,
+	// 20 by default. Statements executed with more, such as bulk inserts,
+	// record the first ones and db.operation.parameter.truncated.
+	EnvMaxParams = "OTEL_GO_DB_MAX_PARAMS"
+
+	// defaultMaxParams is the cap when EnvMaxParams is unset or invalid.
+	defaultMaxParams = 20
+
+	// queryParameterMaxLength caps the length, in bytes, of every recorded
+	// parameter value.
+	queryParameterMaxLength = 256
+
+	dbQueryParametersKey             = attribute.Key("db.query.parameters")
+	dbOperationParameterTruncatedKey = attribute.Key("db.operation.parameter.truncated")
+)
+
+var (
+	// statementParams is true when EnvStatementParams is "true".
+	statementParams bool
+	// maxParams is the positive number EnvMaxParams is set to, or
+	// defaultMaxParams.
+	maxParams int
+)
+
+func init() {
+	initStatementParams()
+	initMaxParams()
+}
+
+func initStatementParams() {
+	statementParams = os.Getenv(EnvStatementParams) == "true"
+}
+
+// StatementParams reports whether the statement parameters are recorded.
+func StatementParams() bool {
+	return statementParams
+}
+
+func initMaxParams() {
+	maxParams = defaultMaxParams
+	if n, err := strconv.Atoi(os.Getenv(EnvMaxParams)); err == nil && n > 0 {
+		maxParams = n
+	}
+}
+
+// queryParametersAttrs returns the db.query.parameters of params, holding the
+// first maxParams ones along with db.operation.parameter.truncated when there
+// are more.
+func queryParametersAttrs(params []any) []attribute.KeyValue {
+	limit := maxParams
+	if len(params) <= limit {
+		return []attribute.KeyValue{dbQueryParametersKey.StringSlice(queryParameters(params))}
+	}
+	return []attribute.KeyValue{
+		dbQueryParametersKey.StringSlice(queryParameters(params[:limit])),
+		dbOperationParameterTruncatedKey.Bool(true),
+	}
+}
+
+// queryParameters renders params, one string per parameter, each cut to
+// queryParameterMaxLength bytes. Named parameters read as name=value.
+func queryParameters(params []any) []string {
+	values := make([]string, 0, len(params))
+	for _, param := range params {
+		var name string
+		switch p := param.(type) {
+		case driver.NamedValue:
+			name, param = p.Name, p.Value
+		case sql.NamedArg:
+			name, param = p.Name, p.Value
+		}
+		value := formatParameter(param)
+		if name != "" {
+			value = name + "=" + value
+		}
+		values = append(values, runtime.TruncateUTF8(value, queryParameterMaxLength))
+	}
+	return values
+}
+
+// formatParameter renders a parameter value. Byte slices, which may hold
+// binary data, are hex encoded as 0x literals.
+func formatParameter(param any) string {
+	switch p := param.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		if p == nil {
+			return "NULL"
+		}
+		return "0x" + hex.EncodeToString(p)
+	case string:
+		return p
+	default:
+		return fmt.Sprint(p)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package semconv
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// EnvDriverSystems maps driver names to db.system.name values, as a comma
+// separated list of name=system pairs, e.g. "tenantdb=postgresql". It takes
+// precedence over the system detected from the registered driver.
+const EnvDriverSystems = "OTEL_GO_DB_DRIVER_SYSTEMS"
+
+// driverNameSystems are the systems of the usual names drivers register under.
+var driverNameSystems = map[string]attribute.KeyValue{
+	"mysql":      semconv.DBSystemNameMySQL,
+	"postgres":   semconv.DBSystemNamePostgreSQL,
+	"postgresql": semconv.DBSystemNamePostgreSQL,
+	"pgx":        semconv.DBSystemNamePostgreSQL,
+	"sqlite3":    semconv.DBSystemNameSQLite,
+	"sqlite":     semconv.DBSystemNameSQLite,
+	"sqlserver":  semconv.DBSystemNameMicrosoftSQLServer,
+	"mssql":      semconv.DBSystemNameMicrosoftSQLServer,
+	"oracle":     semconv.DBSystemNameOracleDB,
+}
+
+// driverPackageSystems are the systems of well-known driver packages, looked
+// up by the import path of the type of the registered driver, so that a driver
+// registered under a custom name is still recognized.
+var driverPackageSystems = []struct {
+	prefix string
+	system attribute.KeyValue
+}{
+	{"github.com/go-sql-driver/mysql", semconv.DBSystemNameMySQL},
+	{"github.com/lib/pq", semconv.DBSystemNamePostgreSQL},
+	{"github.com/jackc/pgx", semconv.DBSystemNamePostgreSQL},
+	{"github.com/mattn/go-sqlite3", semconv.DBSystemNameSQLite},
+	{"modernc.org/sqlite", semconv.DBSystemNameSQLite},
+	{"github.com/microsoft/go-mssqldb", semconv.DBSystemNameMicrosoftSQLServer},
+	{"github.com/denisenkom/go-mssqldb", semconv.DBSystemNameMicrosoftSQLServer},
+	{"github.com/sijms/go-ora", semconv.DBSystemNameOracleDB},
+	{"github.com/ClickHouse/clickhouse-go", semconv.DBSystemNameClickHouse},
+	{"github.com/trinodb/trino-go-client", semconv.DBSystemNameTrino},
+}
+
+// registeredDrivers holds the package of the driver registered under every
+// name with sql.Register. Drivers register from package init functions,
+// possibly before this package is initialized, so only the zero value of
+// registeredDrivers may be used when registering, not the tables above.
+var registeredDrivers sync.Map // driver name -> package path
+
+// RegisterDriver records the package of the driver registered under name, from
+// which DBSystemName detects its system. The system of drivers of other
+// packages is left to EnvDriverSystems and to the name they register under.
+func RegisterDriver(name string, drv any) {
+	t := reflect.TypeOf(drv)
+	if t == nil {
+		return
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	registeredDrivers.Store(name, t.PkgPath())
+}
+
+// registeredSystem returns the system of the well-known package of the driver
+// registered under driverName.
+func registeredSystem(driverName string) (attribute.KeyValue, bool) {
+	pkg, ok := registeredDrivers.Load(driverName)
+	if !ok {
+		return attribute.KeyValue{}, false
+	}
+	for _, p := range driverPackageSystems {
+		if pkg == p.prefix || strings.HasPrefix(pkg.(string), p.prefix+"/") {
+			return p.system, true
+		}
+	}
+	return attribute.KeyValue{}, false
+}
+
+// DBSystemName returns the db.system.name attribute of the driver registered
+// under driverName: the system configured with EnvDriverSystems, else the one
+// detected when the driver was registered, else the one its name suggests,
+// else other_sql.
+func DBSystemName(driverName string) attribute.KeyValue {
+	if system, ok := configuredSystem(driverName); ok {
+		return semconv.DBSystemNameKey.String(system)
+	}
+	if system, ok := registeredSystem(driverName); ok {
+		return system
+	}
+	if system, ok := driverNameSystems[driverName]; ok {
+		return system
+	}
+	return semconv.DBSystemNameOtherSQL
+}
+
+// configuredSystems holds the systems set with EnvDriverSystems, by driver
+// name.
+var configuredSystems map[string]string
+
+func init() {
+	initConfiguredSystems()
+}
+
+// initConfiguredSystems parses EnvDriverSystems. The first pair of a driver
+// name wins.
+func initConfiguredSystems() {
+	configuredSystems = make(map[string]string)
+	for pair := range strings.SplitSeq(os.Getenv(EnvDriverSystems), ",") {
+		name, system, ok := strings.Cut(pair, "=")
+		name, system = strings.TrimSpace(name), strings.TrimSpace(system)
+		if !ok || system == "" {
+			continue
+		}
+		if _, seen := configuredSystems[name]; !seen {
+			configuredSystems[name] = system
+		}
+	}
+}
+
+func configuredSystem(driverName string) (string, bool) {
+	system, ok := configuredSystems[driverName]
+	return system, ok
+}
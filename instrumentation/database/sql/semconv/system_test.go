@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package semconv
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+type fakeDriver struct{}
+
+func (*fakeDriver) Open(string) (driver.Conn, error) { return nil, driver.ErrSkip }
+
+func dbSystemOf(t *testing.T, driverName string) string {
+	t.Helper()
+	conn := DatabaseSqlConnInfo{DriverName: driverName, Endpoint: "db.local:5432", DbName: "shop"}
+	for _, attr := range DbClientRequestTraceAttrs(NewDatabaseSqlRequest(conn, "SELECT 1", nil)) {
+		if attr.Key == semconv.DBSystemNameKey {
+			return attr.Value.AsString()
+		}
+	}
+	return ""
+}
+
+func TestRegisterDriver_PackageDetection(t *testing.T) {
+	// Pretend the package of fakeDriver is a well-known PostgreSQL driver
+	pkg := reflect.TypeFor[fakeDriver]().PkgPath()
+	orig := driverPackageSystems
+	driverPackageSystems = append(driverPackageSystems[:len(driverPackageSystems):len(driverPackageSystems)],
+		struct {
+			prefix string
+			system attribute.KeyValue
+		}{pkg, semconv.DBSystemNamePostgreSQL})
+	t.Cleanup(func() {
+		driverPackageSystems = orig
+		registeredDrivers.Delete("tenantpg")
+	})
+
+	assert.Equal(t, "other_sql", dbSystemOf(t, "tenantpg"), "unknown before registration")
+	RegisterDriver("tenantpg", &fakeDriver{})
+	assert.Equal(t, "postgresql", dbSystemOf(t, "tenantpg"))
+}
+
+func TestRegisterDriver_Configured(t *testing.T) {
+	// Cleanups run last in first out, so this one runs after the env is restored
+	t.Cleanup(initConfiguredSystems)
+	t.Setenv(EnvDriverSystems, "other=mysql, tenantdb = cockroachdb, tenantdb=mysql")
+	initConfiguredSystems()
+	t.Cleanup(func() { registeredDrivers.Delete("tenantdb") })
+
+	RegisterDriver("tenantdb", &fakeDriver{})
+	assert.Equal(t, "cockroachdb", dbSystemOf(t, "tenantdb"))
+}
+
+func TestDBSystemName(t *testing.T) {
+	tests := []struct {
+		driverName string
+		expected   string
+	}{
+		{"mysql", "mysql"},
+		{"postgres", "postgresql"},
+		{"pgx", "postgresql"},
+		{"sqlite3", "sqlite"},
+		{"sqlserver", "microsoft.sql_server"},
+		{"custom", "other_sql"},
+		{"", "other_sql"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.driverName, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DBSystemName(tt.driverName).Value.AsString())
+		})
+	}
+
+	t.Cleanup(initConfiguredSystems)
+	t.Setenv(EnvDriverSystems, "mysql=mariadb")
+	assert.Equal(t, "mysql", DBSystemName("mysql").Value.AsString(), "the env is read once")
+	initConfiguredSystems()
+	assert.Equal(t, "mariadb", DBSystemName("mysql").Value.AsString(), "configuration wins")
+}
@@ -0,0 +1,33 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package semconv
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TxStatementEventName is the name of the event marking, on the span of a
+	// statement run within a transaction, its position in the transaction.
+	TxStatementEventName = "db.transaction.statement"
+	// TxEndEventName is the name of the event marking, on the span of the
+	// commit or rollback of a transaction, how many statements it ended.
+	TxEndEventName = "db.transaction.end"
+
+	txStatementIndexKey = attribute.Key("db.transaction.statement.index")
+	txStatementCountKey = attribute.Key("db.transaction.statement.count")
+)
+
+// AddTxStatementEvent records on span that it runs the index-th statement,
+// counted from 1, of its transaction.
+func AddTxStatementEvent(span trace.Span, index int64) {
+	span.AddEvent(TxStatementEventName, trace.WithAttributes(txStatementIndexKey.Int64(index)))
+}
+
+// AddTxEndEvent records on the span of a commit or rollback the number of
+// statements run within the transaction it ends.
+func AddTxEndEvent(span trace.Span, statements int64) {
+	span.AddEvent(TxEndEventName, trace.WithAttributes(txStatementCountKey.Int64(statements)))
+}
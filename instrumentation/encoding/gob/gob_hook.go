@@ -0,0 +1,131 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gob
+
+import (
+	"encoding/gob"
+	"os"
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+)
+
+const (
+	instrumentationName = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/encoding/gob"
+	instrumentationKey  = "gob"
+
+	// envGobMetrics enables the serialization metrics when set to "true".
+	// Values are encoded everywhere, so nothing is recorded by default.
+	envGobMetrics = "OTEL_GO_GOB_METRICS"
+
+	// Keys of the data carried from the Before to the After hooks.
+	startKeyData     = "start"
+	valueTypeKeyData = "value_type"
+)
+
+var (
+	logger   = runtime.Logger()
+	initOnce sync.Once
+
+	enabledOnce sync.Once
+	enabled     bool
+)
+
+// moduleVersion extracts the version from the Go module system.
+// Falls back to "dev" if version cannot be determined.
+func moduleVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+
+	// Return the main module version
+	if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		return bi.Main.Version
+	}
+
+	return "dev"
+}
+
+func initInstrumentation() {
+	initOnce.Do(func() {
+		version := moduleVersion()
+		if err := runtime.SetupOTelSDK(
+			"go.opentelemetry.io/compile-instrumentation/encoding/gob",
+			version,
+		); err != nil {
+			logger.Error("failed to setup OTel SDK", "error", err)
+		}
+		initSerializationMetrics(version)
+		logger.Info("gob serialization instrumentation initialized")
+	})
+}
+
+// metricsEnabled reports, once, whether the metrics are enabled. The hooks run
+// for every value of the process, so the disabled path is kept to this check.
+func metricsEnabled() bool {
+	enabledOnce.Do(func() {
+		enabled = os.Getenv(envGobMetrics) == "true" && runtime.Instrumented(instrumentationKey)
+	})
+	return enabled
+}
+
+// valueType returns the type of the value gob transmits, pointers being
+// indirected as gob does, or "" when there is no value.
+func valueType(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	t := v.Type()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.String()
+}
+
+// start keeps what the After hook needs to record the operation on v.
+func start(ictx hook.HookContext, v reflect.Value) {
+	if !metricsEnabled() {
+		return
+	}
+	initInstrumentation()
+	ictx.SetKeyData(valueTypeKeyData, valueType(v))
+	ictx.SetKeyData(startKeyData, time.Now())
+}
+
+// finish records the operation started by start.
+func finish(ictx hook.HookContext, operation string, err error) {
+	began, ok := ictx.GetKeyData(startKeyData).(time.Time)
+	if !ok {
+		return
+	}
+	name, _ := ictx.GetKeyData(valueTypeKeyData).(string)
+	record(operation, name, time.Since(began), err)
+}
+
+// BeforeEncodeValue starts timing (*gob.Encoder).EncodeValue, which Encode
+// calls as well.
+func BeforeEncodeValue(ictx hook.HookContext, _ *gob.Encoder, value reflect.Value) {
+	start(ictx, value)
+}
+
+// AfterEncodeValue records the time the encoding took.
+func AfterEncodeValue(ictx hook.HookContext, err error) {
+	finish(ictx, operationEncode, err)
+}
+
+// BeforeDecodeValue starts timing (*gob.Decoder).DecodeValue, which Decode
+// calls as well.
+func BeforeDecodeValue(ictx hook.HookContext, _ *gob.Decoder, v reflect.Value) {
+	start(ictx, v)
+}
+
+// AfterDecodeValue records the time the decoding took.
+func AfterDecodeValue(ictx hook.HookContext, err error) {
+	finish(ictx, operationDecode, err)
+}
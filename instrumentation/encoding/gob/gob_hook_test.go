@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gob
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+)
+
+type order struct {
+	ID    int
+	Items []string
+}
+
+func setupEnabled(t *testing.T, value string) {
+	t.Helper()
+	t.Setenv(envGobMetrics, value)
+	enabledOnce = *new(sync.Once)
+	t.Cleanup(func() { enabledOnce = *new(sync.Once) })
+}
+
+func setupTestMeter(t *testing.T) *sdkmetric.ManualReader {
+	t.Helper()
+	setupEnabled(t, "true")
+	initInstrumentation()
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { _ = mp.Shutdown(context.Background()) })
+	otel.SetMeterProvider(mp)
+	initSerializationMetrics("")
+	return reader
+}
+
+func durationPoints(t *testing.T, reader *sdkmetric.ManualReader) []metricdata.HistogramDataPoint[float64] {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != operationDurationMetric {
+				continue
+			}
+			duration, ok := m.Data.(metricdata.Histogram[float64])
+			require.True(t, ok)
+			return duration.DataPoints
+		}
+	}
+	return nil
+}
+
+func TestEncodeValue_RecordsDuration(t *testing.T) {
+	reader := setupTestMeter(t)
+
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	value := reflect.ValueOf(&order{ID: 7, Items: []string{"book"}})
+	ictx := hooktest.NewMockHookContext(enc, value)
+	BeforeEncodeValue(ictx, enc, value)
+	err := enc.EncodeValue(value)
+	require.NoError(t, err)
+	AfterEncodeValue(ictx, err)
+
+	var decoded order
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&decoded))
+	assert.Equal(t, order{ID: 7, Items: []string{"book"}}, decoded, "the encoding is left untouched")
+
+	points := durationPoints(t, reader)
+	require.Len(t, points, 1)
+	assert.Equal(t, uint64(1), points[0].Count)
+	operation, _ := points[0].Attributes.Value(operationKey)
+	assert.Equal(t, operationEncode, operation.AsString())
+	name, _ := points[0].Attributes.Value(valueTypeKey)
+	assert.Equal(t, "gob.order", name.AsString(), "pointers are indirected as gob does")
+}
+
+func TestDecodeValue_RecordsError(t *testing.T) {
+	reader := setupTestMeter(t)
+
+	dec := gob.NewDecoder(bytes.NewReader([]byte{0x03, 0xff, 0xff, 0xff}))
+	var decoded order
+	value := reflect.ValueOf(&decoded)
+	ictx := hooktest.NewMockHookContext(dec, value)
+	BeforeDecodeValue(ictx, dec, value)
+	err := dec.DecodeValue(value)
+	require.Error(t, err)
+	AfterDecodeValue(ictx, err)
+
+	points := durationPoints(t, reader)
+	require.Len(t, points, 1)
+	operation, _ := points[0].Attributes.Value(operationKey)
+	assert.Equal(t, operationDecode, operation.AsString())
+	_, hasErrorType := points[0].Attributes.Value("error.type")
+	assert.True(t, hasErrorType)
+}
+
+func TestDecodeValue_DiscardedValue(t *testing.T) {
+	setupTestMeter(t)
+
+	dec := gob.NewDecoder(&bytes.Buffer{})
+	ictx := hooktest.NewMockHookContext(dec, reflect.Value{})
+	BeforeDecodeValue(ictx, dec, reflect.Value{})
+	assert.Empty(t, ictx.GetKeyData(valueTypeKeyData), "a discarded value has no type")
+}
+
+func TestEncodeValue_NotEnabled(t *testing.T) {
+	setupEnabled(t, "")
+
+	enc := gob.NewEncoder(&bytes.Buffer{})
+	value := reflect.ValueOf(1)
+	ictx := hooktest.NewMockHookContext(enc, value)
+	BeforeEncodeValue(ictx, enc, value)
+	assert.False(t, ictx.HasKeyData(startKeyData))
+}
@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gob
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+)
+
+const (
+	operationDurationMetric = "gob.operation.duration"
+
+	operationKey = attribute.Key("gob.operation")
+	valueTypeKey = attribute.Key("gob.value.type")
+
+	operationEncode = "encode"
+	operationDecode = "decode"
+)
+
+var operationDuration metric.Float64Histogram
+
+// initSerializationMetrics creates the serialization instruments.
+func initSerializationMetrics(version string) {
+	operationDuration = nil
+	meter := runtime.Meter(
+		instrumentationName,
+		metric.WithInstrumentationVersion(version),
+	)
+	duration, err := meter.Float64Histogram(
+		operationDurationMetric,
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of gob encode and decode operations."),
+	)
+	if err != nil {
+		logger.Error("failed to create operation duration histogram", "error", err)
+		return
+	}
+	operationDuration = duration
+}
+
+// record records one operation on a value of type valueType.
+func record(operation, valueType string, elapsed time.Duration, err error) {
+	if operationDuration == nil {
+		return
+	}
+	attrs := []attribute.KeyValue{
+		operationKey.String(operation),
+		valueTypeKey.String(valueType),
+	}
+	if err != nil {
+		attrs = append(attrs, semconv.ErrorTypeKey.String(fmt.Sprintf("%T", err)))
+	}
+	operationDuration.Record(context.Background(), elapsed.Seconds(), metric.WithAttributes(attrs...))
+}
@@ -0,0 +1,59 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awssdk
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/github.com/aws/aws-sdk-go-v2/semconv"
+)
+
+// attributeSetter returns the attributes specific to the operations of one
+// service, derived from the input parameters of the operation.
+type attributeSetter func(params interface{}) []attribute.KeyValue
+
+// attributeSetters enrich the spans of the operations of a service, keyed by
+// its service ID. Recording e.g. the bucket of S3 operations or the table of
+// DynamoDB ones takes an entry here.
+var attributeSetters = map[string]attributeSetter{
+	sqs.ServiceID: sqsAttributes,
+	sns.ServiceID: snsAttributes,
+}
+
+func sqsAttributes(params interface{}) []attribute.KeyValue {
+	var queueURL *string
+	switch in := params.(type) {
+	case *sqs.SendMessageInput:
+		queueURL = in.QueueUrl
+	case *sqs.SendMessageBatchInput:
+		queueURL = in.QueueUrl
+	case *sqs.ReceiveMessageInput:
+		queueURL = in.QueueUrl
+	case *sqs.DeleteMessageInput:
+		queueURL = in.QueueUrl
+	case *sqs.DeleteMessageBatchInput:
+		queueURL = in.QueueUrl
+	case *sqs.ChangeMessageVisibilityInput:
+		queueURL = in.QueueUrl
+	default:
+		return nil
+	}
+	return semconv.SQSTraceAttrs(aws.ToString(queueURL))
+}
+
+func snsAttributes(params interface{}) []attribute.KeyValue {
+	var topicARN *string
+	switch in := params.(type) {
+	case *sns.PublishInput:
+		topicARN = in.TopicArn
+	case *sns.PublishBatchInput:
+		topicARN = in.TopicArn
+	default:
+		return nil
+	}
+	return semconv.SNSTraceAttrs(aws.ToString(topicARN))
+}
@@ -0,0 +1,95 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awssdk
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/smithy-go/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+)
+
+const (
+	instrumentationName = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/github.com/aws/aws-sdk-go-v2"
+	instrumentationKey  = "AWSSDK"
+	optFnsParamIndex    = 1
+)
+
+type awsSDKEnabler struct{}
+
+func (a awsSDKEnabler) Enable() bool {
+	return runtime.Instrumented(instrumentationKey)
+}
+
+var (
+	logger     = runtime.Logger()
+	enabler    = awsSDKEnabler{}
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	initOnce   sync.Once
+)
+
+// moduleVersion extracts the version from the Go module system.
+// Falls back to "dev" if version cannot be determined.
+func moduleVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+
+	// Return the main module version
+	if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		return bi.Main.Version
+	}
+
+	return "dev"
+}
+
+func initInstrumentation() {
+	initOnce.Do(func() {
+		version := moduleVersion()
+		if err := runtime.SetupOTelSDK(
+			"go.opentelemetry.io/compile-instrumentation/github.com/aws/aws-sdk-go-v2",
+			version,
+		); err != nil {
+			logger.Error("failed to setup OTel SDK", "error", err)
+		}
+		tracer = otel.GetTracerProvider().Tracer(
+			instrumentationName,
+			trace.WithInstrumentationVersion(version),
+		)
+		propagator = runtime.Propagator(instrumentationKey)
+
+		logger.Info("AWS SDK instrumentation initialized")
+	})
+}
+
+// BeforeLoadDefaultConfig intercepts config.LoadDefaultConfig and appends the
+// OTel middlewares to the API options of the configuration it loads, so that
+// every service client created from it traces its operations. The API options
+// the application passes are appended to, not replaced.
+func BeforeLoadDefaultConfig(
+	ictx hook.HookContext,
+	ctx context.Context,
+	optFns ...func(*config.LoadOptions) error,
+) {
+	if !enabler.Enable() {
+		return
+	}
+
+	initInstrumentation()
+
+	optFns = append(optFns, config.WithAPIOptions([]func(*middleware.Stack) error{appendMiddlewares}))
+
+	// Explicitly set parameter to ensure otelc compiles and applies it
+	ictx.SetParam(optFnsParamIndex, optFns)
+}
@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awssdk
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+)
+
+// stubSQS replies to every request like SQS replies to SendMessage, and keeps
+// the body of the last request.
+type stubSQS struct {
+	body string
+}
+
+func (s *stubSQS) Do(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	s.body = string(body)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Content-Type":     {"application/x-amz-json-1.0"},
+			"X-Amzn-Requestid": {"4d7c3ea0-f4b3-5d3e-8bb4-ea2b7e0c7d6e"},
+		},
+		Body:    io.NopCloser(strings.NewReader(`{"MessageId":"1","MD5OfMessageBody":"5d41402abc4b2a76b9719d911017c592"}`)),
+		Request: req,
+	}, nil
+}
+
+// loadConfig loads a configuration through the hook, as an instrumented
+// application calling config.LoadDefaultConfig(ctx, optFns...) would.
+func loadConfig(t *testing.T, optFns ...func(*config.LoadOptions) error) aws.Config {
+	t.Helper()
+	// Keep the configuration of the machine running the tests out
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	optFns = append(optFns,
+		config.WithRegion("eu-west-1"),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider("AKID", "SECRET", "")),
+	)
+	ictx := hooktest.NewMockHookContext(context.Background(), optFns)
+
+	BeforeLoadDefaultConfig(ictx, context.Background(), optFns...)
+
+	hookedOptFns, ok := ictx.GetParam(optFnsParamIndex).([]func(*config.LoadOptions) error)
+	require.True(t, ok)
+	cfg, err := config.LoadDefaultConfig(context.Background(), hookedOptFns...)
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestBeforeLoadDefaultConfig_TracesClients(t *testing.T) {
+	sr := setupTestTracer(t)
+	cfg := loadConfig(t)
+	stub := &stubSQS{}
+	client := sqs.NewFromConfig(cfg, func(o *sqs.Options) {
+		o.HTTPClient = stub
+		o.BaseEndpoint = aws.String("http://sqs.test")
+	})
+
+	_, err := client.SendMessage(context.Background(), &sqs.SendMessageInput{
+		QueueUrl:    aws.String("http://sqs.test/123456789012/orders"),
+		MessageBody: aws.String("hello"),
+	})
+	require.NoError(t, err)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "SQS.SendMessage", spans[0].Name())
+	attrs := spanAttrs(spans[0])
+	assert.Equal(t, "4d7c3ea0-f4b3-5d3e-8bb4-ea2b7e0c7d6e", attrs["aws.request_id"].AsString())
+	assert.Equal(t, "orders", attrs["messaging.destination.name"].AsString())
+	assert.Contains(t, stub.body, `"traceparent"`, "the trace context travels with the message")
+	assert.Contains(t, stub.body, spans[0].SpanContext().TraceID().String())
+}
+
+func TestBeforeLoadDefaultConfig_KeepsApplicationAPIOptions(t *testing.T) {
+	setupTestTracer(t)
+	appOption := func(*middleware.Stack) error { return nil }
+
+	cfg := loadConfig(t, config.WithAPIOptions([]func(*middleware.Stack) error{appOption}))
+
+	assert.Len(t, cfg.APIOptions, 2)
+}
+
+func TestBeforeLoadDefaultConfig_Disabled(t *testing.T) {
+	t.Setenv("OTEL_GO_DISABLED_INSTRUMENTATIONS", "awssdk")
+	optFns := []func(*config.LoadOptions) error{config.WithRegion("eu-west-1")}
+	ictx := hooktest.NewMockHookContext(context.Background(), optFns)
+
+	BeforeLoadDefaultConfig(ictx, context.Background(), optFns...)
+
+	assert.Len(t, ictx.GetParam(optFnsParamIndex), 1)
+}
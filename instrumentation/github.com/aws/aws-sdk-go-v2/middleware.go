@@ -0,0 +1,101 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awssdk
+
+import (
+	"context"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/github.com/aws/aws-sdk-go-v2/semconv"
+)
+
+const (
+	initializeMiddlewareID  = "OTelInitializeMiddleware"
+	deserializeMiddlewareID = "OTelDeserializeMiddleware"
+)
+
+// appendMiddlewares adds the OTel middlewares to the stack of an operation.
+// It is an API option, so it runs once per operation, after the service client
+// has built the stack.
+func appendMiddlewares(stack *middleware.Stack) error {
+	// The options of a configuration loaded more than once may already carry
+	// the middlewares; adding them again would fail the operation.
+	if _, ok := stack.Initialize.Get(initializeMiddlewareID); ok {
+		return nil
+	}
+	// The service client registers the service and operation names at the
+	// start of the initialize step, so the span starts at its end. The retry
+	// loop runs in the finalize step: the span covers every attempt.
+	if err := stack.Initialize.Add(initializeMiddleware{}, middleware.After); err != nil {
+		return err
+	}
+	return stack.Deserialize.Add(deserializeMiddleware{}, middleware.Before)
+}
+
+// initializeMiddleware starts the client span of an operation, and injects
+// its context into the messages that SQS and SNS operations send.
+type initializeMiddleware struct{}
+
+func (initializeMiddleware) ID() string { return initializeMiddlewareID }
+
+func (initializeMiddleware) HandleInitialize(
+	ctx context.Context,
+	in middleware.InitializeInput,
+	next middleware.InitializeHandler,
+) (middleware.InitializeOutput, middleware.Metadata, error) {
+	request := semconv.AWSRequest{
+		Service:   awsmiddleware.GetServiceID(ctx),
+		Operation: awsmiddleware.GetOperationName(ctx),
+		Region:    awsmiddleware.GetRegion(ctx),
+	}
+	attrs := semconv.AWSClientRequestTraceAttrs(request)
+	if setter, ok := attributeSetters[request.Service]; ok {
+		attrs = append(attrs, setter(in.Parameters)...)
+	}
+	ctx, span := tracer.Start(ctx,
+		semconv.AWSSpanName(request),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End()
+
+	in.Parameters = injectMessageAttributes(ctx, in.Parameters)
+
+	out, metadata, err := next.HandleInitialize(ctx, in)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(semconv.AWSErrorType(err))
+	}
+	return out, metadata, err
+}
+
+// deserializeMiddleware records the response of the last attempt of an
+// operation on its span: the request ID AWS assigned and the HTTP status.
+type deserializeMiddleware struct{}
+
+func (deserializeMiddleware) ID() string { return deserializeMiddlewareID }
+
+func (deserializeMiddleware) HandleDeserialize(
+	ctx context.Context,
+	in middleware.DeserializeInput,
+	next middleware.DeserializeHandler,
+) (middleware.DeserializeOutput, middleware.Metadata, error) {
+	out, metadata, err := next.HandleDeserialize(ctx, in)
+
+	span := trace.SpanFromContext(ctx)
+	requestID, _ := awsmiddleware.GetRequestIDMetadata(metadata)
+	statusCode := 0
+	if res, ok := out.RawResponse.(*smithyhttp.Response); ok {
+		statusCode = res.StatusCode
+	}
+	span.SetAttributes(semconv.AWSClientResponseTraceAttrs(requestID, statusCode)...)
+
+	return out, metadata, err
+}
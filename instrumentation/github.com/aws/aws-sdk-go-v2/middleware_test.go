@@ -0,0 +1,190 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awssdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupTestTracer(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	initOnce = *new(sync.Once)
+	initInstrumentation()
+	return sr
+}
+
+func spanAttrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+// reply is the response to an operation handleOperation hands its handler.
+type reply struct {
+	status    int
+	requestID string
+	err       error
+}
+
+// paramsKey keeps the input parameters of the operation for the handler; the
+// initialize step is the last to see them.
+type paramsKey struct{}
+
+// handleOperation runs an operation of the SQS client on a stack carrying the
+// OTel middlewares, with a handler replying r instead of sending the request.
+// It returns the input parameters the request would have been built from.
+func handleOperation(t *testing.T, operation string, params interface{}, r reply) (interface{}, error) {
+	t.Helper()
+	stack := middleware.NewStack(operation, smithyhttp.NewStackRequest)
+	require.NoError(t, stack.Initialize.Add(&awsmiddleware.RegisterServiceMetadata{
+		ServiceID:     sqs.ServiceID,
+		OperationName: operation,
+		Region:        "eu-west-1",
+	}, middleware.Before))
+	require.NoError(t, appendMiddlewares(stack))
+	require.NoError(t, stack.Initialize.Add(middleware.InitializeMiddlewareFunc("captureParams",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (
+			middleware.InitializeOutput, middleware.Metadata, error,
+		) {
+			return next.HandleInitialize(middleware.WithStackValue(ctx, paramsKey{}, in.Parameters), in)
+		}), middleware.After))
+
+	var sent interface{}
+	handler := middleware.HandlerFunc(func(ctx context.Context, _ interface{}) (interface{}, middleware.Metadata, error) {
+		sent = middleware.GetStackValue(ctx, paramsKey{})
+		var metadata middleware.Metadata
+		awsmiddleware.SetRequestIDMetadata(&metadata, r.requestID)
+		return &smithyhttp.Response{Response: &http.Response{StatusCode: r.status}}, metadata, r.err
+	})
+	_, _, err := stack.HandleMiddleware(context.Background(), params, handler)
+	return sent, err
+}
+
+func TestMiddleware_ClientSpan(t *testing.T) {
+	sr := setupTestTracer(t)
+
+	_, err := handleOperation(t, "ListQueues", &sqs.ListQueuesInput{},
+		reply{status: http.StatusOK, requestID: "req-1"})
+	require.NoError(t, err)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "SQS.ListQueues", span.Name())
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	assert.Equal(t, codes.Unset, span.Status().Code)
+	attrs := spanAttrs(span)
+	assert.Equal(t, "aws-api", attrs["rpc.system"].AsString())
+	assert.Equal(t, "SQS", attrs["rpc.service"].AsString())
+	assert.Equal(t, "ListQueues", attrs["rpc.method"].AsString())
+	assert.Equal(t, "eu-west-1", attrs["cloud.region"].AsString())
+	assert.Equal(t, "req-1", attrs["aws.request_id"].AsString())
+	assert.Equal(t, int64(http.StatusOK), attrs["http.response.status_code"].AsInt64())
+}
+
+func TestMiddleware_Error(t *testing.T) {
+	sr := setupTestTracer(t)
+
+	_, err := handleOperation(t, "GetQueueUrl", &sqs.GetQueueUrlInput{QueueName: aws.String("missing")}, reply{
+		status:    http.StatusBadRequest,
+		requestID: "req-2",
+		err:       &smithy.GenericAPIError{Code: "QueueDoesNotExist", Message: "no such queue"},
+	})
+	require.Error(t, err)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	attrs := spanAttrs(spans[0])
+	assert.Equal(t, "QueueDoesNotExist", attrs["error.type"].AsString())
+	assert.Equal(t, "req-2", attrs["aws.request_id"].AsString())
+	assert.Equal(t, int64(http.StatusBadRequest), attrs["http.response.status_code"].AsInt64())
+}
+
+func TestMiddleware_InjectsSQSMessageAttributes(t *testing.T) {
+	sr := setupTestTracer(t)
+
+	input := &sqs.SendMessageInput{
+		QueueUrl:    aws.String("https://sqs.eu-west-1.amazonaws.com/123456789012/orders"),
+		MessageBody: aws.String("hello"),
+		MessageAttributes: map[string]sqstypes.MessageAttributeValue{
+			"tenant": {DataType: aws.String("String"), StringValue: aws.String("acme")},
+		},
+	}
+	sent, err := handleOperation(t, "SendMessage", input, reply{status: http.StatusOK})
+	require.NoError(t, err)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	attrs := spanAttrs(spans[0])
+	assert.Equal(t, "aws_sqs", attrs["messaging.system"].AsString())
+	assert.Equal(t, "orders", attrs["messaging.destination.name"].AsString())
+
+	sentInput, ok := sent.(*sqs.SendMessageInput)
+	require.True(t, ok)
+	require.Contains(t, sentInput.MessageAttributes, "traceparent")
+	assert.Contains(t, sentInput.MessageAttributes, "tenant")
+	assert.Equal(t,
+		fmt.Sprintf("00-%s-%s-01", spans[0].SpanContext().TraceID(), spans[0].SpanContext().SpanID()),
+		aws.ToString(sentInput.MessageAttributes["traceparent"].StringValue))
+	assert.NotContains(t, input.MessageAttributes, "traceparent", "the application's input is left untouched")
+}
+
+func TestInjectMessageAttributes_NoRoomLeft(t *testing.T) {
+	sr := setupTestTracer(t)
+	ctx, span := otel.Tracer("test").Start(context.Background(), "parent")
+	defer span.End()
+
+	full := make(map[string]sqstypes.MessageAttributeValue, maxMessageAttributes)
+	for i := range maxMessageAttributes {
+		full[fmt.Sprint(i)] = sqsAttribute("v")
+	}
+	input := &sqs.SendMessageBatchInput{Entries: []sqstypes.SendMessageBatchRequestEntry{
+		{Id: aws.String("full"), MessageAttributes: full},
+		{Id: aws.String("empty")},
+	}}
+
+	injected, ok := injectMessageAttributes(ctx, input).(*sqs.SendMessageBatchInput)
+	require.True(t, ok)
+	assert.Len(t, injected.Entries[0].MessageAttributes, maxMessageAttributes)
+	assert.Contains(t, injected.Entries[1].MessageAttributes, "traceparent")
+	assert.Nil(t, input.Entries[1].MessageAttributes)
+	assert.Empty(t, sr.Ended())
+}
+
+func TestAppendMiddlewares_Twice(t *testing.T) {
+	stack := middleware.NewStack("ListQueues", smithyhttp.NewStackRequest)
+	require.NoError(t, appendMiddlewares(stack))
+	require.NoError(t, appendMiddlewares(stack))
+	assert.Len(t, stack.Initialize.List(), 1)
+	assert.Len(t, stack.Deserialize.List(), 1)
+}
@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awssdk
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// maxMessageAttributes is the number of message attributes SQS accepts per
+// message, and SNS per message it delivers to SQS.
+const maxMessageAttributes = 10
+
+// injectMessageAttributes returns the input of an operation sending messages
+// to SQS or SNS with the trace context of ctx injected into the attributes of
+// every message, so that consumers continue the trace. The input the
+// application passed is copied, not modified; the input of other operations is
+// returned as is.
+func injectMessageAttributes(ctx context.Context, params interface{}) interface{} {
+	fields := propagation.MapCarrier{}
+	propagator.Inject(ctx, fields)
+	if len(fields) == 0 {
+		return params
+	}
+
+	switch in := params.(type) {
+	case *sqs.SendMessageInput:
+		cp := *in
+		cp.MessageAttributes = withFields(in.MessageAttributes, fields, sqsAttribute)
+		return &cp
+	case *sqs.SendMessageBatchInput:
+		cp := *in
+		cp.Entries = slices.Clone(in.Entries)
+		for i := range cp.Entries {
+			cp.Entries[i].MessageAttributes = withFields(cp.Entries[i].MessageAttributes, fields, sqsAttribute)
+		}
+		return &cp
+	case *sns.PublishInput:
+		cp := *in
+		cp.MessageAttributes = withFields(in.MessageAttributes, fields, snsAttribute)
+		return &cp
+	case *sns.PublishBatchInput:
+		cp := *in
+		cp.PublishBatchRequestEntries = slices.Clone(in.PublishBatchRequestEntries)
+		for i := range cp.PublishBatchRequestEntries {
+			entry := &cp.PublishBatchRequestEntries[i]
+			entry.MessageAttributes = withFields(entry.MessageAttributes, fields, snsAttribute)
+		}
+		return &cp
+	}
+	return params
+}
+
+// withFields returns a copy of the message attributes attrs with the
+// propagation fields added as string attributes. A message with no room left
+// for them keeps its attributes: the service would reject it otherwise.
+func withFields[V any](attrs map[string]V, fields propagation.MapCarrier, value func(string) V) map[string]V {
+	added := 0
+	for key := range fields {
+		if _, ok := attrs[key]; !ok {
+			added++
+		}
+	}
+	if len(attrs)+added > maxMessageAttributes {
+		logger.Debug("No room left in the message attributes for the trace context", "attributes", len(attrs))
+		return attrs
+	}
+
+	out := make(map[string]V, len(attrs)+added)
+	maps.Copy(out, attrs)
+	for key, val := range fields {
+		out[key] = value(val)
+	}
+	return out
+}
+
+func sqsAttribute(val string) sqstypes.MessageAttributeValue {
+	return sqstypes.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(val)}
+}
+
+func snsAttribute(val string) snstypes.MessageAttributeValue {
+	return snstypes.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(val)}
+}
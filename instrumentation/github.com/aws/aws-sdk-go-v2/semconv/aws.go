@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package semconv
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aws/smithy-go"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// RPCSystemAWSAPI is the rpc.system of the calls to AWS services.
+var RPCSystemAWSAPI = semconv.RPCSystemKey.String("aws-api")
+
+type AWSRequest struct {
+	Service   string
+	Operation string
+	Region    string
+}
+
+// AWSSpanName returns "{service}.{operation}", e.g. "S3.GetObject".
+func AWSSpanName(req AWSRequest) string {
+	return req.Service + "." + req.Operation
+}
+
+// AWSClientRequestTraceAttrs returns trace attributes for an operation of an
+// AWS service.
+func AWSClientRequestTraceAttrs(req AWSRequest) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		RPCSystemAWSAPI,
+		semconv.RPCService(req.Service),
+		semconv.RPCMethod(req.Operation),
+	}
+	if req.Region != "" {
+		attrs = append(attrs, semconv.CloudRegion(req.Region))
+	}
+	return attrs
+}
+
+// AWSClientResponseTraceAttrs returns trace attributes for the response to an
+// operation. The request ID and the status are left out when not known, i.e.
+// empty or zero.
+func AWSClientResponseTraceAttrs(requestID string, statusCode int) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if requestID != "" {
+		attrs = append(attrs, semconv.AWSRequestID(requestID))
+	}
+	if statusCode > 0 {
+		attrs = append(attrs, semconv.HTTPResponseStatusCode(statusCode))
+	}
+	return attrs
+}
+
+// AWSErrorType returns the error.type of a failed operation: the error code
+// the service replied with, e.g. "NoSuchBucket", or the type of err when the
+// service did not reply.
+func AWSErrorType(err error) attribute.KeyValue {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() != "" {
+		return semconv.ErrorTypeKey.String(apiErr.ErrorCode())
+	}
+	return semconv.ErrorType(err)
+}
+
+// SQSTraceAttrs returns trace attributes for an operation on an SQS queue,
+// named after the last element of its URL.
+func SQSTraceAttrs(queueURL string) []attribute.KeyValue {
+	if queueURL == "" {
+		return []attribute.KeyValue{semconv.MessagingSystemAWSSQS}
+	}
+	return []attribute.KeyValue{
+		semconv.MessagingSystemAWSSQS,
+		semconv.AWSSQSQueueURL(queueURL),
+		semconv.MessagingDestinationName(queueURL[strings.LastIndex(queueURL, "/")+1:]),
+	}
+}
+
+// SNSTraceAttrs returns trace attributes for an operation on an SNS topic,
+// named after the last element of its ARN.
+func SNSTraceAttrs(topicARN string) []attribute.KeyValue {
+	if topicARN == "" {
+		return []attribute.KeyValue{semconv.MessagingSystemAWSSNS}
+	}
+	return []attribute.KeyValue{
+		semconv.MessagingSystemAWSSNS,
+		semconv.AWSSNSTopicARN(topicARN),
+		semconv.MessagingDestinationName(topicARN[strings.LastIndex(topicARN, ":")+1:]),
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package semconv
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func attrMap(attrs []attribute.KeyValue) map[string]interface{} {
+	m := make(map[string]interface{}, len(attrs))
+	for _, kv := range attrs {
+		m[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	return m
+}
+
+func TestAWSSpanName(t *testing.T) {
+	assert.Equal(t, "S3.GetObject", AWSSpanName(AWSRequest{Service: "S3", Operation: "GetObject"}))
+}
+
+func TestAWSClientRequestTraceAttrs(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{
+		"rpc.system":   "aws-api",
+		"rpc.service":  "DynamoDB",
+		"rpc.method":   "GetItem",
+		"cloud.region": "us-east-1",
+	}, attrMap(AWSClientRequestTraceAttrs(AWSRequest{Service: "DynamoDB", Operation: "GetItem", Region: "us-east-1"})))
+
+	assert.NotContains(t, attrMap(AWSClientRequestTraceAttrs(AWSRequest{Service: "S3", Operation: "ListBuckets"})),
+		"cloud.region")
+}
+
+func TestAWSClientResponseTraceAttrs(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{
+		"aws.request_id":            "req-1",
+		"http.response.status_code": int64(200),
+	}, attrMap(AWSClientResponseTraceAttrs("req-1", 200)))
+	assert.Empty(t, AWSClientResponseTraceAttrs("", 0))
+}
+
+func TestAWSErrorType(t *testing.T) {
+	assert.Equal(t, "NoSuchBucket",
+		AWSErrorType(&smithy.GenericAPIError{Code: "NoSuchBucket"}).Value.AsString())
+	assert.Equal(t, "*errors.errorString", AWSErrorType(errors.New("connection refused")).Value.AsString())
+}
+
+func TestSQSTraceAttrs(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{
+		"messaging.system":           "aws_sqs",
+		"aws.sqs.queue.url":          "https://sqs.eu-west-1.amazonaws.com/123456789012/orders",
+		"messaging.destination.name": "orders",
+	}, attrMap(SQSTraceAttrs("https://sqs.eu-west-1.amazonaws.com/123456789012/orders")))
+	assert.Equal(t, map[string]interface{}{"messaging.system": "aws_sqs"}, attrMap(SQSTraceAttrs("")))
+}
+
+func TestSNSTraceAttrs(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{
+		"messaging.system":           "aws.sns",
+		"aws.sns.topic.arn":          "arn:aws:sns:eu-west-1:123456789012:orders",
+		"messaging.destination.name": "orders",
+	}, attrMap(SNSTraceAttrs("arn:aws:sns:eu-west-1:123456789012:orders")))
+}
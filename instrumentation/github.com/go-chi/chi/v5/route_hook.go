@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v5
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+)
+
+// requestParamIndex is the index of the request among the parameters of
+// (*chi.Mux).routeHTTP, after the receiver and the response writer.
+const requestParamIndex = 2
+
+// AfterRouteHTTP runs after (*chi.Mux).routeHTTP, which finds the route of the
+// request and serves it. The route context then holds the whole pattern the
+// request matched, across mounted routers, e.g. /users/{id}; the span of the
+// net/http server instrumentation is renamed from "METHOD" to
+// "METHOD /users/{id}" and gets the http.route attribute.
+//
+// (*chi.Mux).ServeHTTP is not hooked: it puts the route context back into its
+// pool before returning, where another request may already reuse it.
+func AfterRouteHTTP(ictx hook.HookContext) {
+	r, ok := ictx.GetParam(requestParamIndex).(*http.Request)
+	// routeHTTP sets the pattern of the request only when a route matched
+	if !ok || r == nil || r.Pattern == "" {
+		return
+	}
+
+	route := chi.RouteContext(r.Context()).RoutePattern()
+	if route == "" {
+		return
+	}
+
+	span := trace.SpanFromContext(r.Context())
+	if !span.IsRecording() {
+		return
+	}
+
+	// Mounted routers run their own routeHTTP, the innermost names the span
+	// first and the others find the same pattern
+	span.SetName(r.Method + " " + route)
+	span.SetAttributes(semconv.HTTPRouteKey.String(route))
+
+	logger.Debug("chi route resolved", "route", route)
+}
@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v5
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+)
+
+func newRouter() *chi.Mux {
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }
+	r := chi.NewRouter()
+	r.Get("/users/{id}", ok)
+	r.Route("/api", func(r chi.Router) {
+		r.Get("/orders/{orderID}/items", ok)
+	})
+	return r
+}
+
+// serve routes a request for url through router within the server span,
+// then runs the hook as routeHTTP returns, and returns the ended span.
+func serve(t *testing.T, router *chi.Mux, method, url string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	// The span the net/http server instrumentation starts, named after the
+	// method only
+	ctx, span := tp.Tracer("test").Start(context.Background(), method, trace.WithSpanKind(trace.SpanKindServer))
+	// The route context the hook reads, kept out of the pool of the router
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
+	req := httptest.NewRequest(method, url, nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	AfterRouteHTTP(hooktest.NewMockHookContext(router, rec, req))
+	span.End()
+
+	require.Len(t, sr.Ended(), 1, "no second span")
+	return sr.Ended()[0]
+}
+
+func httpRoute(span sdktrace.ReadOnlySpan) (string, bool) {
+	for _, attr := range span.Attributes() {
+		if attr.Key == semconv.HTTPRouteKey {
+			return attr.Value.AsString(), true
+		}
+	}
+	return "", false
+}
+
+func TestAfterRouteHTTP(t *testing.T) {
+	router := newRouter()
+
+	t.Run("route template", func(t *testing.T) {
+		span := serve(t, router, http.MethodGet, "/users/42")
+		assert.Equal(t, "GET /users/{id}", span.Name())
+		route, ok := httpRoute(span)
+		assert.True(t, ok)
+		assert.Equal(t, "/users/{id}", route)
+	})
+
+	t.Run("mounted router", func(t *testing.T) {
+		span := serve(t, router, http.MethodGet, "/api/orders/7/items")
+		assert.Equal(t, "GET /api/orders/{orderID}/items", span.Name())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		span := serve(t, router, http.MethodGet, "/no-such-path")
+		assert.Equal(t, "GET", span.Name())
+		_, ok := httpRoute(span)
+		assert.False(t, ok)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		span := serve(t, router, http.MethodPost, "/users/42")
+		assert.Equal(t, "POST", span.Name())
+	})
+}
+
+func TestAfterRouteHTTP_NoSpan(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chi.NewRouteContext()))
+	router := newRouter()
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.NotPanics(t, func() {
+		AfterRouteHTTP(hooktest.NewMockHookContext(router, httptest.NewRecorder(), req))
+		AfterRouteHTTP(hooktest.NewMockHookContext(router, httptest.NewRecorder(), nil))
+	})
+}
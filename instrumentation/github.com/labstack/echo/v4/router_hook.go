@@ -0,0 +1,38 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"github.com/labstack/echo/v4"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+)
+
+// AfterFind runs after (*echo.Router).Find, which echo calls once per request
+// to route it. Find stores the template of the route it matched on the
+// context, c.Path(). We use it to update the span name from the initial
+// "METHOD" to "METHOD /route/:param" and record the http.route attribute.
+func AfterFind(ictx hook.HookContext) {
+	// Find(method, path string, c echo.Context): the receiver is param 0.
+	c, ok := ictx.GetParam(3).(echo.Context)
+	if !ok || c == nil || c.Request() == nil {
+		return
+	}
+
+	route := c.Path()
+	if route == "" {
+		// No route matched (e.g. 404). Leave the span name as the method only.
+		return
+	}
+
+	span := trace.SpanFromContext(c.Request().Context())
+	if !span.IsRecording() {
+		return
+	}
+	span.SetName(c.Request().Method + " " + route)
+	span.SetAttributes(semconv.HTTPRouteKey.String(route))
+	logger.Debug("echo route resolved", "route", route)
+}
@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+)
+
+func setupTestTracer(t *testing.T) (*tracetest.SpanRecorder, trace.Tracer) {
+	t.Helper()
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() {
+		_ = tp.Shutdown(context.Background())
+	})
+	return sr, tp.Tracer("test")
+}
+
+// statusWriter stands in for the net/http server instrumentation's writer
+// wrapper, which echo wraps in its own echo.Response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(statusCode int) {
+	w.status = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// serve routes a request for url through e the way the instrumented net/http
+// server does: with a server span named after the method in the request
+// context and the response writer wrapped. The AfterFind hook is called from
+// a middleware, which echo runs right after Find.
+func serve(t *testing.T, tr trace.Tracer, e *echo.Echo, url string) *statusWriter {
+	t.Helper()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			AfterFind(hooktest.NewMockHookContext(e.Router(), c.Request().Method, url, c))
+			return next(c)
+		}
+	})
+
+	ctx, span := tr.Start(context.Background(), http.MethodGet, trace.WithSpanKind(trace.SpanKindServer))
+	req := httptest.NewRequest(http.MethodGet, url, nil).WithContext(ctx)
+	w := &statusWriter{ResponseWriter: httptest.NewRecorder()}
+	e.ServeHTTP(w, req)
+	span.End()
+	return w
+}
+
+func spanAttrs(span sdktrace.ReadOnlySpan) map[string]interface{} {
+	attrs := make(map[string]interface{})
+	for _, a := range span.Attributes() {
+		attrs[string(a.Key)] = a.Value.AsInterface()
+	}
+	return attrs
+}
+
+func TestAfterFind_UpdatesSpanNameAndRoute(t *testing.T) {
+	sr, tr := setupTestTracer(t)
+	e := echo.New()
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.String(http.StatusOK, c.Param("id"))
+	})
+
+	w := serve(t, tr, e, "/users/42")
+
+	assert.Equal(t, http.StatusOK, w.status)
+	require.Len(t, sr.Ended(), 1)
+	ended := sr.Ended()[0]
+	assert.Equal(t, "GET /users/:id", ended.Name(), "span name should include route pattern")
+	assert.Equal(t, "/users/:id", spanAttrs(ended)["http.route"], "http.route attribute should be the pattern, not the URL")
+}
+
+func TestAfterFind_StatusReachesWrappedWriter(t *testing.T) {
+	sr, tr := setupTestTracer(t)
+	e := echo.New()
+	e.GET("/users/:id", func(echo.Context) error {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "try later")
+	})
+
+	w := serve(t, tr, e, "/users/42")
+
+	// echo writes the status set by its error handler through echo.Response
+	// to the writer it was handed.
+	assert.Equal(t, http.StatusServiceUnavailable, w.status)
+	require.Len(t, sr.Ended(), 1)
+	assert.Equal(t, "GET /users/:id", sr.Ended()[0].Name())
+}
+
+func TestAfterFind_UnmatchedRouteIsNoop(t *testing.T) {
+	sr, tr := setupTestTracer(t)
+	e := echo.New()
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	w := serve(t, tr, e, "/no-such-route")
+
+	assert.Equal(t, http.StatusNotFound, w.status)
+	require.Len(t, sr.Ended(), 1)
+	assert.Equal(t, "GET", sr.Ended()[0].Name(), "span name must not be modified when no route matched")
+	assert.NotContains(t, spanAttrs(sr.Ended()[0]), "http.route")
+}
+
+func TestAfterFind_NonRecordingSpanIsNoop(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/users/42", nil), httptest.NewRecorder())
+	c.SetPath("/users/:id")
+
+	assert.NotPanics(t, func() {
+		AfterFind(hooktest.NewMockHookContext(e.Router(), http.MethodGet, "/users/42", c))
+	})
+}
+
+func TestAfterFind_UnexpectedParamIsNoop(t *testing.T) {
+	assert.NotPanics(t, func() {
+		AfterFind(hooktest.NewMockHookContext(nil, http.MethodGet, "/users/42", nil))
+	})
+}
@@ -56,10 +56,6 @@ func initInstrumentation() {
 			trace.WithInstrumentationVersion(version),
 		)
 
-		if err := runtime.StartRuntimeMetrics(); err != nil {
-			logger.Error("failed to start runtime metrics", "error", err)
-		}
-
 		logger.Info("OpenAI v1 instrumentation initialized")
 	})
 }
@@ -4,6 +4,10 @@
 package v9
 
 import (
+	"reflect"
+	goruntime "runtime"
+	"sync"
+
 	redis "github.com/redis/go-redis/v9"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
@@ -24,30 +28,81 @@ func (g redisClientEnabler) Enable() bool {
 
 var redisEnabler = redisClientEnabler{}
 
+// closeHooks maps the address of the baseClient of each instrumented client,
+// which Close is declared on, to its hook, so that the spans it still
+// coalesces are ended when the client is closed.
+var closeHooks sync.Map
+
+// baseClientOf returns the address of the baseClient embedded by client.
+func baseClientOf(client any) uintptr {
+	v := reflect.ValueOf(client)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return 0
+	}
+	base := v.Elem().FieldByName("baseClient")
+	switch base.Kind() {
+	case reflect.Pointer:
+		return base.Pointer()
+	case reflect.Struct:
+		return base.UnsafeAddr()
+	}
+	return 0
+}
+
+// addHook adds a new hook to client and registers it to be flushed on Close.
+func addHook[C any, P interface {
+	*C
+	AddHook(redis.Hook)
+}](client P, addr string) {
+	h := newOtelRedisHook(addr)
+	client.AddHook(h)
+	key := baseClientOf(client)
+	if key == 0 {
+		return
+	}
+	closeHooks.Store(key, h)
+	// A client dropped without Close must not leave its hook behind
+	goruntime.AddCleanup((*C)(client), func(key uintptr) {
+		closeHooks.CompareAndDelete(key, h)
+	}, key)
+}
+
 func afterNewRedisClientV9(ictx hook.HookContext, client *redis.Client) {
-	client.AddHook(newOtelRedisHook(client.Options().Addr))
+	addHook(client, client.Options().Addr)
 }
 
 func afterNewFailOverRedisClientV9(call hook.HookContext, client *redis.Client) {
-	client.AddHook(newOtelRedisHook(client.Options().Addr))
+	addHook(client, client.Options().Addr)
 }
 
 func afterNewRingClientV9(call hook.HookContext, client *redis.Ring) {
 	client.OnNewNode(func(rdb *redis.Client) {
-		rdb.AddHook(newOtelRedisHook(rdb.Options().Addr))
+		addHook(rdb, rdb.Options().Addr)
 	})
 }
 
 func afterNewClusterClientV9(call hook.HookContext, client *redis.ClusterClient) {
 	client.OnNewNode(func(rdb *redis.Client) {
-		rdb.AddHook(newOtelRedisHook(rdb.Options().Addr))
+		addHook(rdb, rdb.Options().Addr)
 	})
 }
 
 func afterNewSentinelClientV9(call hook.HookContext, client *redis.SentinelClient) {
-	client.AddHook(newOtelRedisHook(client.String()))
+	addHook(client, client.String())
 }
 
 func afterClientConnV9(call hook.HookContext, client *redis.Conn) {
-	client.AddHook(newOtelRedisHook(client.String()))
+	addHook(client, client.String())
+}
+
+// beforeBaseClientCloseV9 ends the spans the hook of the closed client still
+// coalesces. The receiver is a *baseClient, which is unexported.
+func beforeBaseClientCloseV9(call hook.HookContext, c interface{}) {
+	v := reflect.ValueOf(c)
+	if v.Kind() != reflect.Pointer {
+		return
+	}
+	if h, ok := closeHooks.LoadAndDelete(v.Pointer()); ok {
+		h.(*otelRedisHook).coalescer.flush()
+	}
 }
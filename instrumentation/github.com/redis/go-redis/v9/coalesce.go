@@ -0,0 +1,163 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v9
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// envCoalesceWindow enables coalescing: identical commands a client runs
+	// one after the other, each within this duration of the previous one
+	// completing, share a single span, e.g. "100ms" for clients polling a key
+	// in a tight loop. Coalescing is off when it is unset.
+	envCoalesceWindow = "OTEL_GO_REDIS_COALESCE_WINDOW"
+
+	repeatCountKey = attribute.Key("redis.command.repeat_count")
+)
+
+var coalesceWindow time.Duration
+
+// durationFromEnv returns the positive duration the environment variable env
+// is set to, or zero when it is unset or invalid.
+func durationFromEnv(env string) time.Duration {
+	value := os.Getenv(env)
+	if value == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		logger.Warn("ignoring invalid value", "env", env, "value", value)
+		return 0
+	}
+	return d
+}
+
+// coalesceKey identifies the span a run of commands is a child of. Only the
+// commands of a same parent are coalesced.
+type coalesceKey struct {
+	traceID trace.TraceID
+	spanID  trace.SpanID
+}
+
+func coalesceKeyOf(parent trace.SpanContext) coalesceKey {
+	return coalesceKey{traceID: parent.TraceID(), spanID: parent.SpanID()}
+}
+
+// coalescedSpan is the span of a run of identical commands, kept open while
+// the next command may still repeat the statement.
+type coalescedSpan struct {
+	span      trace.Span
+	key       coalesceKey
+	statement string
+	count     int
+	window    time.Duration
+	last      time.Time // completion of the latest command of the run
+	timer     *time.Timer
+}
+
+func (c *coalescedSpan) end() {
+	c.span.SetAttributes(repeatCountKey.Int(c.count))
+	c.span.End(trace.WithTimestamp(c.last))
+}
+
+// coalescer holds the pending coalesced spans of a hook, one per parent span,
+// so that the requests sharing a client do not end each other's runs. A
+// pending span is ended once a different command runs under its parent, the
+// window passes without a repeat, or the client is closed. The connections of
+// a pooled client are not visible to its hooks, so runs cannot be kept per
+// connection.
+type coalescer struct {
+	mu      sync.Mutex
+	pending map[coalesceKey]*coalescedSpan
+}
+
+// repeat counts a command running statement into the pending span of key when
+// it repeats its statement within the window, and reports whether it did.
+// Otherwise that pending span, if any, is ended.
+func (c *coalescer) repeat(key coalesceKey, statement string, now time.Time) bool {
+	c.mu.Lock()
+	p := c.pending[key]
+	if p != nil && p.statement == statement && now.Sub(p.last) <= p.window {
+		p.count++
+		c.mu.Unlock()
+		return true
+	}
+	delete(c.pending, key)
+	c.mu.Unlock()
+	if p != nil {
+		p.timer.Stop()
+		p.end()
+	}
+	return false
+}
+
+// done records the completion of a repeated command of the pending span of
+// key, marking it failed with err, if any. The span may have ended meanwhile,
+// when a different command ran concurrently.
+func (c *coalescer) done(key coalesceKey, statement string, now time.Time, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p := c.pending[key]; p != nil && p.statement == statement {
+		if now.After(p.last) {
+			p.last = now
+		}
+		if err != nil {
+			p.span.SetStatus(codes.Error, err.Error())
+		}
+	}
+}
+
+// start makes span, of a command that completed at now, the pending span of
+// key.
+func (c *coalescer) start(key coalesceKey, span trace.Span, statement string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := &coalescedSpan{span: span, key: key, statement: statement, count: 1, window: coalesceWindow, last: now}
+	// A command started meanwhile and finished first is ended right away
+	if prev := c.pending[key]; prev != nil {
+		prev.timer.Stop()
+		defer prev.end()
+	}
+	if c.pending == nil {
+		c.pending = make(map[coalesceKey]*coalescedSpan)
+	}
+	c.pending[key] = p
+	p.timer = time.AfterFunc(p.window, func() { c.expire(p) })
+}
+
+// expire ends p once the window passed since its latest command.
+func (c *coalescer) expire(p *coalescedSpan) {
+	c.mu.Lock()
+	if c.pending[p.key] != p {
+		c.mu.Unlock()
+		return
+	}
+	if remaining := time.Until(p.last.Add(p.window)); remaining > 0 {
+		p.timer.Reset(remaining)
+		c.mu.Unlock()
+		return
+	}
+	delete(c.pending, p.key)
+	c.mu.Unlock()
+	p.end()
+}
+
+// flush ends all the pending spans.
+func (c *coalescer) flush() {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+	for _, p := range pending {
+		p.timer.Stop()
+		p.end()
+	}
+}
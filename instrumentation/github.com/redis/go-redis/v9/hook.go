@@ -57,18 +57,17 @@ func initInstrumentation() {
 			instrumentationName,
 			trace.WithInstrumentationVersion(version),
 		)
-
-		// Start runtime metrics (respects OTEL_GO_ENABLED/DISABLED_INSTRUMENTATIONS)
-		if err := runtime.StartRuntimeMetrics(); err != nil {
-			logger.Error("failed to start runtime metrics", "error", err)
-		}
+		pipelineQueryMaxCommands = positiveIntFromEnv(envPipelineQueryMaxCommands, defaultPipelineQueryMaxCommands)
+		pipelineQueryMaxLength = positiveIntFromEnv(envPipelineQueryMaxLength, defaultPipelineQueryMaxLength)
+		coalesceWindow = durationFromEnv(envCoalesceWindow)
 
 		logger.Info("Redis v9 client instrumentation initialized")
 	})
 }
 
 type otelRedisHook struct {
-	Addr string
+	Addr      string
+	coalescer coalescer
 }
 
 func newOtelRedisHook(addr string) *otelRedisHook {
@@ -90,6 +89,9 @@ func (o *otelRedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
 			FullName:  fullName,
 			Statement: getRedisV9Statement(cmd),
 		}
+		if coalesceWindow > 0 {
+			return o.processCoalesced(ctx, cmd, request, next)
+		}
 		// Get trace attributes from semconv
 		attrs := semconv.RedisClientRequestTraceAttrs(request)
 
@@ -110,6 +112,38 @@ func (o *otelRedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
 	}
 }
 
+// processCoalesced runs cmd under the span of the identical commands run just
+// before it under the same parent span, if any, and leaves its own span open
+// for the commands repeating it. A failed command ends its span right away.
+func (o *otelRedisHook) processCoalesced(
+	ctx context.Context, cmd redis.Cmder, request semconv.RedisRequest, next redis.ProcessHook,
+) error {
+	key := coalesceKeyOf(trace.SpanContextFromContext(ctx))
+	if o.coalescer.repeat(key, request.Statement, time.Now()) {
+		err := next(ctx, cmd)
+		if errors.Is(err, redis.Nil) {
+			o.coalescer.done(key, request.Statement, time.Now(), nil)
+		} else {
+			o.coalescer.done(key, request.Statement, time.Now(), err)
+		}
+		return err
+	}
+
+	ctx, span := tracer.Start(ctx,
+		request.FullName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.RedisClientRequestTraceAttrs(request)...),
+	)
+	err := next(ctx, cmd)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+		return err
+	}
+	o.coalescer.start(key, span, request.Statement, time.Now())
+	return err
+}
+
 func (o *otelRedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
 	return func(ctx context.Context, cmds []redis.Cmder) error {
 		if !redisEnabler.Enable() {
@@ -118,27 +152,15 @@ func (o *otelRedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redi
 		}
 		initInstrumentation()
 
-		summary := ""
-		summaryCmds := cmds
-		if len(summaryCmds) > 10 {
-			summaryCmds = summaryCmds[:10]
-		}
-		for i := range summaryCmds {
-			summary += summaryCmds[i].FullName() + "/"
-		}
-		if len(cmds) > 10 {
-			summary += "..."
-		}
-		cmd := redis.NewCmd(ctx, "pipeline", summary)
-		fullName := cmd.FullName()
 		request := semconv.RedisRequest{
 			Endpoint:  o.Addr,
-			FullName:  fullName,
-			Statement: getRedisV9Statement(cmd),
+			FullName:  "pipeline",
+			Statement: pipelineQueryText(cmds),
 		}
 
 		// Get trace attributes from semconv
 		attrs := semconv.RedisClientRequestTraceAttrs(request)
+		attrs = append(attrs, semconv.RedisPipelineTraceAttrs(len(cmds))...)
 
 		// Start span
 		spanName := request.FullName
@@ -148,6 +170,7 @@ func (o *otelRedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redi
 			trace.WithAttributes(attrs...),
 		)
 		defer span.End()
+		addCommandEvents(span, cmds)
 
 		err := next(ctx, cmds)
 		if err != nil && !errors.Is(err, redis.Nil) {
@@ -6,7 +6,9 @@ package v9
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -18,6 +20,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func setupTestTracer(t *testing.T) *tracetest.SpanRecorder {
@@ -286,26 +289,93 @@ func TestProcessPipelineHook_CreatesSpan(t *testing.T) {
 }
 
 func TestProcessPipelineHook_TruncatesLongPipeline(t *testing.T) {
-	initOnce = *new(sync.Once)
-	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "redis")
-
-	sr := setupTestTracer(t)
-
-	hook := newOtelRedisHook("localhost:6379")
-	pipelineHook := hook.ProcessPipelineHook(func(ctx context.Context, cmds []redis.Cmder) error {
-		return nil
-	})
-
-	// Create more than 10 commands
-	cmds := make([]redis.Cmder, 15)
-	for i := range cmds {
-		cmds[i] = redis.NewCmd(context.Background(), "get", "key")
+	tests := []struct {
+		name              string
+		maxCommands       string
+		maxLength         string
+		expectedQueryText string
+	}{
+		{
+			name: "defaults",
+			expectedQueryText: "get key0: get\nset key1 val1: set\nget key2: get\nset key3 val3: set\n" +
+				"get key4: get\nset key5 val5: set\nget key6: get\nset key7 val7: set\n" +
+				"get key8: get\nset key9 val9: set\n...",
+		},
+		{
+			name:              "fewer commands",
+			maxCommands:       "2",
+			expectedQueryText: "get key0: get\nset key1 val1: set\n...",
+		},
+		{
+			name:              "shorter query text",
+			maxLength:         "20",
+			expectedQueryText: "get key0: get\nset ke",
+		},
+		{
+			name:        "invalid settings",
+			maxCommands: "0",
+			maxLength:   "many",
+			expectedQueryText: "get key0: get\nset key1 val1: set\nget key2: get\nset key3 val3: set\n" +
+				"get key4: get\nset key5 val5: set\nget key6: get\nset key7 val7: set\n" +
+				"get key8: get\nset key9 val9: set\n...",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initOnce = *new(sync.Once)
+			t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "redis")
+			t.Setenv(envPipelineQueryMaxCommands, tt.maxCommands)
+			t.Setenv(envPipelineQueryMaxLength, tt.maxLength)
+
+			sr := setupTestTracer(t)
+
+			hook := newOtelRedisHook("localhost:6379")
+			pipelineHook := hook.ProcessPipelineHook(func(ctx context.Context, cmds []redis.Cmder) error {
+				return nil
+			})
+
+			// 15 commands, alternating between 8 gets and 7 sets
+			cmds := make([]redis.Cmder, 15)
+			for i := range cmds {
+				key := fmt.Sprintf("key%d", i)
+				if i%2 == 0 {
+					cmds[i] = redis.NewCmd(context.Background(), "get", key)
+				} else {
+					cmds[i] = redis.NewCmd(context.Background(), "set", key, fmt.Sprintf("val%d", i))
+				}
+			}
+			err := pipelineHook(context.Background(), cmds)
+			assert.NoError(t, err)
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			span := spans[0]
+
+			attrMap := make(map[string]interface{})
+			for _, attr := range span.Attributes() {
+				attrMap[string(attr.Key)] = attr.Value.AsInterface()
+			}
+			assert.Equal(t, "pipeline", attrMap["db.operation.name"])
+			assert.Equal(t, int64(15), attrMap["db.redis.pipeline.length"])
+			assert.Equal(t, int64(15), attrMap["db.operation.batch.size"])
+			assert.Equal(t, tt.expectedQueryText, attrMap["db.query.text"])
+
+			counts := make(map[string]int64)
+			var order []string
+			for _, event := range span.Events() {
+				require.Equal(t, "db.redis.command", event.Name)
+				eventAttrs := make(map[string]interface{})
+				for _, attr := range event.Attributes {
+					eventAttrs[string(attr.Key)] = attr.Value.AsInterface()
+				}
+				name, _ := eventAttrs["db.operation.name"].(string)
+				order = append(order, name)
+				counts[name], _ = eventAttrs["db.redis.command.count"].(int64)
+			}
+			assert.Equal(t, []string{"get", "set"}, order, "one event per distinct command, in order")
+			assert.Equal(t, map[string]int64{"get": 8, "set": 7}, counts)
+		})
 	}
-	err := pipelineHook(context.Background(), cmds)
-	assert.NoError(t, err)
-
-	spans := sr.Ended()
-	require.Len(t, spans, 1)
 }
 
 func TestProcessPipelineHook_RecordsError(t *testing.T) {
@@ -383,3 +453,162 @@ func TestDialHook_Error(t *testing.T) {
 	assert.Equal(t, expectedErr, err)
 	assert.Nil(t, conn)
 }
+
+func TestProcessHook_CoalescesRepeatedCommands(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "redis")
+	t.Setenv(envCoalesceWindow, "1s")
+	t.Cleanup(func() { coalesceWindow = 0 })
+
+	sr := setupTestTracer(t)
+
+	hook := newOtelRedisHook("localhost:6379")
+	processHook := hook.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+		return nil
+	})
+
+	const polls = 5
+	for range polls {
+		require.NoError(t, processHook(context.Background(), redis.NewCmd(context.Background(), "get", "mykey")))
+	}
+	assert.Empty(t, sr.Ended(), "the span stays open while the command may repeat")
+
+	// A different command ends the run of GETs
+	require.NoError(t, processHook(context.Background(), redis.NewCmd(context.Background(), "get", "otherkey")))
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "get", spans[0].Name())
+	attrMap := make(map[string]interface{})
+	for _, attr := range spans[0].Attributes() {
+		attrMap[string(attr.Key)] = attr.Value.AsInterface()
+	}
+	assert.Equal(t, "get mykey: get", attrMap["db.query.text"])
+	assert.Equal(t, int64(polls), attrMap["redis.command.repeat_count"])
+}
+
+func TestProcessHook_CoalesceWindowExpires(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "redis")
+	t.Setenv(envCoalesceWindow, "20ms")
+	t.Cleanup(func() { coalesceWindow = 0 })
+
+	sr := setupTestTracer(t)
+
+	hook := newOtelRedisHook("localhost:6379")
+	processHook := hook.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+		return nil
+	})
+
+	require.NoError(t, processHook(context.Background(), redis.NewCmd(context.Background(), "get", "mykey")))
+	require.Eventually(t, func() bool { return len(sr.Ended()) == 1 }, time.Second, 5*time.Millisecond)
+
+	// The next GET, past the window, gets a span of its own
+	require.NoError(t, processHook(context.Background(), redis.NewCmd(context.Background(), "get", "mykey")))
+	require.Eventually(t, func() bool { return len(sr.Ended()) == 2 }, time.Second, 5*time.Millisecond)
+	for _, span := range sr.Ended() {
+		assert.Contains(t, span.Attributes(), repeatCountKey.Int(1))
+	}
+}
+
+func TestProcessHook_CoalesceEndsFailedCommand(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "redis")
+	t.Setenv(envCoalesceWindow, "1s")
+	t.Cleanup(func() { coalesceWindow = 0 })
+
+	sr := setupTestTracer(t)
+
+	hook := newOtelRedisHook("localhost:6379")
+	expectedErr := errors.New("connection refused")
+	processHook := hook.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+		return expectedErr
+	})
+
+	err := processHook(context.Background(), redis.NewCmd(context.Background(), "get", "mykey"))
+	assert.Equal(t, expectedErr, err)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
+
+func TestProcessHook_CoalescesPerParentSpan(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "redis")
+	t.Setenv(envCoalesceWindow, "1s")
+	t.Cleanup(func() { coalesceWindow = 0 })
+
+	sr := setupTestTracer(t)
+
+	hook := newOtelRedisHook("localhost:6379")
+	processHook := hook.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+		return nil
+	})
+
+	parent := func(id byte) context.Context {
+		return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{id},
+			SpanID:     trace.SpanID{id},
+			TraceFlags: trace.FlagsSampled,
+		}))
+	}
+	ctx1, ctx2 := parent(1), parent(2)
+
+	// The requests sharing the client do not end each other's runs
+	for range 3 {
+		require.NoError(t, processHook(ctx1, redis.NewCmd(ctx1, "get", "mykey")))
+		require.NoError(t, processHook(ctx2, redis.NewCmd(ctx2, "get", "mykey")))
+	}
+	assert.Empty(t, sr.Ended(), "the commands of different parents are not coalesced")
+
+	// A different command ends only the run of its own parent
+	require.NoError(t, processHook(ctx2, redis.NewCmd(ctx2, "get", "otherkey")))
+	require.Len(t, sr.Ended(), 1)
+	assert.Equal(t, trace.SpanID{2}, sr.Ended()[0].Parent().SpanID())
+
+	hook.coalescer.flush()
+
+	spans := sr.Ended()
+	require.Len(t, spans, 3)
+	counts := map[trace.SpanID][]int64{}
+	for _, span := range spans {
+		for _, attr := range span.Attributes() {
+			if attr.Key == repeatCountKey {
+				counts[span.Parent().SpanID()] = append(counts[span.Parent().SpanID()], attr.Value.AsInt64())
+			}
+		}
+	}
+	assert.Equal(t, []int64{3}, counts[trace.SpanID{1}])
+	assert.ElementsMatch(t, []int64{3, 1}, counts[trace.SpanID{2}])
+}
+
+func TestCloseFlushesCoalescedSpans(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "redis")
+	t.Setenv(envCoalesceWindow, "1s")
+	t.Cleanup(func() { coalesceWindow = 0 })
+
+	sr := setupTestTracer(t)
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	afterNewRedisClientV9(nil, client)
+	key := baseClientOf(client)
+	h, ok := closeHooks.Load(key)
+	require.True(t, ok, "the hook is registered for Close")
+
+	processHook := h.(*otelRedisHook).ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+		return nil
+	})
+	require.NoError(t, processHook(context.Background(), redis.NewCmd(context.Background(), "get", "mykey")))
+	assert.Empty(t, sr.Ended())
+
+	// The receiver of Close is the unexported *baseClient the client embeds
+	base := reflect.ValueOf(client).Elem().FieldByName("baseClient")
+	beforeBaseClientCloseV9(nil, reflect.NewAt(base.Type().Elem(), base.UnsafePointer()).Interface())
+	require.NoError(t, client.Close())
+
+	require.Len(t, sr.Ended(), 1)
+	_, ok = closeHooks.Load(key)
+	assert.False(t, ok, "the hook is dropped once the client is closed")
+}
@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v9
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+)
+
+const (
+	// envPipelineQueryMaxCommands sets how many commands of a pipeline are
+	// listed in the db.query.text of its span before the rest is elided.
+	envPipelineQueryMaxCommands = "OTEL_GO_REDIS_PIPELINE_QUERY_MAX_COMMANDS"
+	// envPipelineQueryMaxLength caps the length, in bytes, of the db.query.text
+	// of pipeline spans.
+	envPipelineQueryMaxLength = "OTEL_GO_REDIS_PIPELINE_QUERY_MAX_LENGTH"
+
+	defaultPipelineQueryMaxCommands = 10
+	defaultPipelineQueryMaxLength   = 1024
+
+	pipelineElided = "..."
+
+	commandEventName   = "db.redis.command"
+	commandNameAttrKey = attribute.Key("db.operation.name")
+	commandCountKey    = attribute.Key("db.redis.command.count")
+)
+
+var (
+	pipelineQueryMaxCommands = defaultPipelineQueryMaxCommands
+	pipelineQueryMaxLength   = defaultPipelineQueryMaxLength
+)
+
+// positiveIntFromEnv returns the positive integer the environment variable
+// env is set to, or def when it is unset or invalid.
+func positiveIntFromEnv(env string, def int) int {
+	value := os.Getenv(env)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		logger.Warn("ignoring invalid value", "env", env, "value", value)
+		return def
+	}
+	return n
+}
+
+// pipelineQueryText returns the statements of the first
+// pipelineQueryMaxCommands commands of cmds, one per line, cut to
+// pipelineQueryMaxLength bytes.
+func pipelineQueryText(cmds []redis.Cmder) string {
+	statements := make([]string, 0, min(len(cmds), pipelineQueryMaxCommands)+1)
+	for i, cmd := range cmds {
+		if i == pipelineQueryMaxCommands {
+			statements = append(statements, pipelineElided)
+			break
+		}
+		statements = append(statements, getRedisV9Statement(cmd))
+	}
+	return runtime.TruncateUTF8(strings.Join(statements, "\n"), pipelineQueryMaxLength)
+}
+
+// addCommandEvents adds to span an event per distinct command of cmds, in the
+// order they first appear, with the number of times the pipeline runs it.
+func addCommandEvents(span trace.Span, cmds []redis.Cmder) {
+	var names []string
+	counts := make(map[string]int)
+	for _, cmd := range cmds {
+		name := cmd.FullName()
+		if counts[name] == 0 {
+			names = append(names, name)
+		}
+		counts[name]++
+	}
+	for _, name := range names {
+		span.AddEvent(commandEventName, trace.WithAttributes(
+			commandNameAttrKey.String(name),
+			commandCountKey.Int(counts[name]),
+		))
+	}
+}
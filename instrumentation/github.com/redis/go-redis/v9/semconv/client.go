@@ -40,3 +40,14 @@ func RedisClientRequestTraceAttrs(req RedisRequest) []attribute.KeyValue {
 
 	return attrs
 }
+
+// RedisPipelineTraceAttrs returns the trace attributes describing a pipeline
+// of length commands.
+func RedisPipelineTraceAttrs(length int) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.Int("db.redis.pipeline.length", length)}
+	// db.operation.batch.size is only set for batches of two or more operations
+	if length >= 2 {
+		attrs = append(attrs, semconv.DBOperationBatchSize(length))
+	}
+	return attrs
+}
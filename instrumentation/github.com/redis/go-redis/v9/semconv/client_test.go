@@ -169,3 +169,21 @@ func TestRedisClientRequestTraceAttrs_ContainsNetworkTransportTCP(t *testing.T)
 	}
 	assert.True(t, found, "should contain network.transport=tcp attribute")
 }
+
+func TestRedisPipelineTraceAttrs(t *testing.T) {
+	attrMap := func(length int) map[string]interface{} {
+		m := make(map[string]interface{})
+		for _, attr := range RedisPipelineTraceAttrs(length) {
+			m[string(attr.Key)] = attr.Value.AsInterface()
+		}
+		return m
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"db.redis.pipeline.length": int64(3),
+		"db.operation.batch.size":  int64(3),
+	}, attrMap(3))
+	assert.Equal(t, map[string]interface{}{
+		"db.redis.pipeline.length": int64(1),
+	}, attrMap(1), "a single operation is not a batch")
+}
@@ -0,0 +1,115 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkago
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/github.com/segmentio/kafka-go/semconv"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+)
+
+// contextParamIndex is the index of ctx in (*kafka.Reader).ReadMessage and
+// FetchMessage, after the receiver.
+const contextParamIndex = 1
+
+// readMessageKey marks the context ReadMessage passes to FetchMessage, which
+// it calls internally, so that a message is recorded only once.
+type readMessageKey struct{}
+
+// BeforeReadMessage hooks before (*kafka.Reader).ReadMessage.
+func BeforeReadMessage(ictx hook.HookContext, r *kafka.Reader, ctx context.Context) {
+	if !kafkaEnabler.Enable() {
+		logger.Debug("Kafka client instrumentation disabled")
+		return
+	}
+	if ctx == nil {
+		return
+	}
+	ctx = context.WithValue(ctx, readMessageKey{}, true)
+	ictx.SetParam(contextParamIndex, ctx)
+	beforeReceive(ictx, r, ctx)
+}
+
+// AfterReadMessage records the message returned by (*kafka.Reader).ReadMessage.
+func AfterReadMessage(ictx hook.HookContext, msg kafka.Message, err error) {
+	afterReceive(ictx, msg, err)
+}
+
+// BeforeFetchMessage hooks before (*kafka.Reader).FetchMessage.
+func BeforeFetchMessage(ictx hook.HookContext, r *kafka.Reader, ctx context.Context) {
+	if !kafkaEnabler.Enable() {
+		logger.Debug("Kafka client instrumentation disabled")
+		return
+	}
+	if ctx == nil || ctx.Value(readMessageKey{}) != nil {
+		// Recorded by the ReadMessage hooks
+		return
+	}
+	beforeReceive(ictx, r, ctx)
+}
+
+// AfterFetchMessage records the message returned by (*kafka.Reader).FetchMessage.
+func AfterFetchMessage(ictx hook.HookContext, msg kafka.Message, err error) {
+	afterReceive(ictx, msg, err)
+}
+
+func beforeReceive(ictx hook.HookContext, r *kafka.Reader, ctx context.Context) {
+	if r == nil {
+		return
+	}
+	initInstrumentation()
+	ictx.SetData(map[string]interface{}{
+		"ctx":    ctx,
+		"reader": r,
+		"start":  time.Now(),
+	})
+}
+
+// afterReceive records a consumer span from the start of the call until the
+// message was returned, as a child of the producer span whose context the
+// message carries. The reader retries broker errors itself, so calls that
+// fail return no message, only because the caller gave up or the reader was
+// closed, and are not recorded.
+func afterReceive(ictx hook.HookContext, msg kafka.Message, err error) {
+	r, ok := ictx.GetKeyData("reader").(*kafka.Reader)
+	if !ok {
+		return
+	}
+	if err != nil {
+		logger.Debug("no Kafka message received", "error", err)
+		return
+	}
+	ctx, _ := ictx.GetKeyData("ctx").(context.Context)
+	start, _ := ictx.GetKeyData("start").(time.Time)
+
+	config := r.Config()
+	req := semconv.KafkaConsumerRequest{
+		Topic:         msg.Topic,
+		ConsumerGroup: config.GroupID,
+		Partition:     msg.Partition,
+		Offset:        msg.Offset,
+		Key:           string(msg.Key),
+		BodySize:      len(msg.Value),
+	}
+	if req.Topic == "" {
+		req.Topic = config.Topic
+	}
+	if len(config.Brokers) > 0 {
+		req.Broker = config.Brokers[0]
+	}
+
+	ctx = propagator.Extract(ctx, headerCarrier{&msg.Headers})
+	_, span := tracer.Start(ctx,
+		semconv.KafkaSpanName(semconv.OperationReceive, req.Topic),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(semconv.KafkaConsumerTraceAttrs(req)...),
+	)
+	span.End()
+}
@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkago
+
+import (
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+)
+
+const (
+	instrumentationName = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/github.com/segmentio/kafka-go"
+	instrumentationKey  = "KAFKA"
+)
+
+var (
+	logger     = runtime.Logger()
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	initOnce   sync.Once
+)
+
+// kafkaClientEnabler controls whether producer and consumer instrumentation is enabled
+type kafkaClientEnabler struct{}
+
+func (g kafkaClientEnabler) Enable() bool {
+	return runtime.Instrumented(instrumentationKey)
+}
+
+var kafkaEnabler = kafkaClientEnabler{}
+
+// moduleVersion extracts the version from the Go module system.
+// Falls back to "dev" if version cannot be determined.
+func moduleVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+
+	// Return the main module version
+	if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		return bi.Main.Version
+	}
+
+	return "dev"
+}
+
+func initInstrumentation() {
+	initOnce.Do(func() {
+		version := moduleVersion()
+		if err := runtime.SetupOTelSDK(
+			"go.opentelemetry.io/compile-instrumentation/github.com/segmentio/kafka-go",
+			version,
+		); err != nil {
+			logger.Error("failed to setup OTel SDK", "error", err)
+		}
+		tracer = otel.GetTracerProvider().Tracer(
+			instrumentationName,
+			trace.WithInstrumentationVersion(version),
+		)
+		propagator = runtime.Propagator(instrumentationKey)
+
+		logger.Info("Kafka client instrumentation initialized")
+	})
+}
+
+// headerCarrier adapts the headers of a kafka.Message to a
+// propagation.TextMapCarrier, so that trace context rides along with it.
+type headerCarrier struct {
+	headers *[]kafka.Header
+}
+
+func (c headerCarrier) Get(key string) string {
+	for _, h := range *c.headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// Set replaces the header if it is already present, as when a message is
+// written again.
+func (c headerCarrier) Set(key, value string) {
+	for i, h := range *c.headers {
+		if h.Key == key {
+			(*c.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.headers = append(*c.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.headers))
+	for _, h := range *c.headers {
+		keys = append(keys, h.Key)
+	}
+	return keys
+}
+
+// firstBroker returns the first of the comma separated broker addresses.
+func firstBroker(addrs string) string {
+	broker, _, _ := strings.Cut(addrs, ",")
+	return broker
+}
@@ -0,0 +1,194 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkago
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+)
+
+func setupTestTracer(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "kafka")
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+	initInstrumentation()
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	tracer = tp.Tracer(instrumentationName)
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return sr
+}
+
+func TestWriteMessages(t *testing.T) {
+	sr := setupTestTracer(t)
+
+	w := &kafka.Writer{Addr: kafka.TCP("localhost:9092"), Topic: "orders"}
+	msgs := []kafka.Message{
+		{Key: []byte("a"), Value: []byte("first")},
+		{Key: []byte("b"), Value: []byte("second")},
+	}
+	ictx := hooktest.NewMockHookContext(w, context.Background(), msgs)
+	BeforeWriteMessages(ictx, w, context.Background(), msgs...)
+	AfterWriteMessages(ictx, errors.New("broker unavailable"))
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "send orders", span.Name())
+	assert.Equal(t, trace.SpanKindProducer, span.SpanKind())
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Contains(t, span.Attributes(), semconv.MessagingBatchMessageCount(2))
+	assert.Contains(t, span.Attributes(), semconv.MessagingDestinationName("orders"))
+	assert.Contains(t, span.Attributes(), semconv.ServerAddress("localhost"))
+
+	traced, ok := ictx.GetParam(messagesParamIndex).([]kafka.Message)
+	require.True(t, ok)
+	require.Len(t, traced, 2)
+	for _, msg := range traced {
+		carrier := headerCarrier{&msg.Headers}
+		sc := trace.SpanContextFromContext(
+			propagation.TraceContext{}.Extract(context.Background(), carrier),
+		)
+		assert.Equal(t, span.SpanContext().SpanID(), sc.SpanID())
+	}
+	for _, msg := range msgs {
+		assert.Empty(t, msg.Headers, "the caller's messages are left untouched")
+	}
+}
+
+func TestWriteMessages_InstrumentationPropagator(t *testing.T) {
+	setupTestTracer(t)
+	// A propagator set for kafka replaces the global one
+	runtime.SetInstrumentationPropagator("kafka", propagation.NewCompositeTextMapPropagator())
+	t.Cleanup(func() { runtime.SetInstrumentationPropagator("kafka", nil) })
+
+	w := &kafka.Writer{Addr: kafka.TCP("localhost:9092"), Topic: "orders"}
+	msgs := []kafka.Message{{Value: []byte("first")}}
+	ictx := hooktest.NewMockHookContext(w, context.Background(), msgs)
+	BeforeWriteMessages(ictx, w, context.Background(), msgs...)
+	AfterWriteMessages(ictx, nil)
+
+	traced, ok := ictx.GetParam(messagesParamIndex).([]kafka.Message)
+	require.True(t, ok)
+	require.Len(t, traced, 1)
+	assert.Empty(t, traced[0].Headers)
+}
+
+func TestWriteMessages_TopicPerMessage(t *testing.T) {
+	w := &kafka.Writer{}
+	assert.Equal(t, "orders", writerTopic(w, []kafka.Message{{Topic: "orders"}, {Topic: "orders"}}))
+	assert.Empty(t, writerTopic(w, []kafka.Message{{Topic: "orders"}, {Topic: "refunds"}}))
+}
+
+func TestFetchMessage(t *testing.T) {
+	sr := setupTestTracer(t)
+
+	producerCtx, producer := otel.Tracer("test").Start(context.Background(), "send orders")
+	producer.End()
+	msg := kafka.Message{Topic: "orders", Partition: 1, Offset: 7, Value: []byte("payload")}
+	otel.GetTextMapPropagator().Inject(producerCtx, headerCarrier{&msg.Headers})
+
+	r := kafka.NewReader(kafka.ReaderConfig{Brokers: []string{"localhost:9092"}, Topic: "orders"})
+	t.Cleanup(func() { _ = r.Close() })
+
+	ictx := hooktest.NewMockHookContext(r, context.Background())
+	BeforeFetchMessage(ictx, r, context.Background())
+	AfterFetchMessage(ictx, msg, nil)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	consumer := spans[1]
+	assert.Equal(t, "receive orders", consumer.Name())
+	assert.Equal(t, trace.SpanKindConsumer, consumer.SpanKind())
+	assert.Equal(t, producer.SpanContext().TraceID(), consumer.SpanContext().TraceID())
+	assert.Equal(t, producer.SpanContext().SpanID(), consumer.Parent().SpanID())
+	assert.Contains(t, consumer.Attributes(), semconv.MessagingKafkaOffset(7))
+	assert.Contains(t, consumer.Attributes(), semconv.MessagingDestinationPartitionID("1"))
+	assert.Contains(t, consumer.Attributes(), semconv.MessagingMessageBodySize(7))
+}
+
+func TestReadMessage_RecordedOnce(t *testing.T) {
+	sr := setupTestTracer(t)
+
+	r := kafka.NewReader(kafka.ReaderConfig{Brokers: []string{"localhost:9092"}, Topic: "orders"})
+	t.Cleanup(func() { _ = r.Close() })
+	msg := kafka.Message{Topic: "orders"}
+
+	readCtx := hooktest.NewMockHookContext(r, context.Background())
+	BeforeReadMessage(readCtx, r, context.Background())
+	ctx, ok := readCtx.GetParam(contextParamIndex).(context.Context)
+	require.True(t, ok)
+
+	// ReadMessage calls FetchMessage with the context it was given
+	fetchCtx := hooktest.NewMockHookContext(r, ctx)
+	BeforeFetchMessage(fetchCtx, r, ctx)
+	AfterFetchMessage(fetchCtx, msg, nil)
+	AfterReadMessage(readCtx, msg, nil)
+
+	assert.Len(t, sr.Ended(), 1)
+}
+
+func TestReadMessage_Error(t *testing.T) {
+	sr := setupTestTracer(t)
+
+	r := kafka.NewReader(kafka.ReaderConfig{Brokers: []string{"localhost:9092"}, Topic: "orders"})
+	t.Cleanup(func() { _ = r.Close() })
+
+	ictx := hooktest.NewMockHookContext(r, context.Background())
+	BeforeReadMessage(ictx, r, context.Background())
+	AfterReadMessage(ictx, kafka.Message{}, context.Canceled)
+
+	assert.Empty(t, sr.Ended())
+}
+
+func TestKafkaEnabler_Disabled(t *testing.T) {
+	sr := setupTestTracer(t)
+	t.Setenv("OTEL_GO_DISABLED_INSTRUMENTATIONS", "kafka")
+
+	w := &kafka.Writer{Addr: kafka.TCP("localhost:9092"), Topic: "orders"}
+	msgs := []kafka.Message{{Value: []byte("first")}}
+	ictx := hooktest.NewMockHookContext(w, context.Background(), msgs)
+	BeforeWriteMessages(ictx, w, context.Background(), msgs...)
+	AfterWriteMessages(ictx, nil)
+
+	assert.Empty(t, sr.Ended())
+	assert.Equal(t, msgs, ictx.GetParam(messagesParamIndex))
+}
+
+func TestHeaderCarrier(t *testing.T) {
+	headers := []kafka.Header{{Key: "traceparent", Value: []byte("old")}, {Key: "other", Value: []byte("v")}}
+	carrier := headerCarrier{&headers}
+
+	carrier.Set("traceparent", "new")
+	carrier.Set("tracestate", "s")
+
+	assert.Equal(t, "new", carrier.Get("traceparent"))
+	assert.Equal(t, "s", carrier.Get("tracestate"))
+	assert.Empty(t, carrier.Get("missing"))
+	assert.Equal(t, []string{"traceparent", "other", "tracestate"}, carrier.Keys())
+}
+
+func TestFirstBroker(t *testing.T) {
+	assert.Equal(t, "a:9092", firstBroker("a:9092,b:9092"))
+	assert.Equal(t, "a:9092", firstBroker("a:9092"))
+}
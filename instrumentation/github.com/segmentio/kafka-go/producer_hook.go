@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkago
+
+import (
+	"context"
+	"slices"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/github.com/segmentio/kafka-go/semconv"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+)
+
+// messagesParamIndex is the index of msgs in (*kafka.Writer).WriteMessages,
+// after the receiver and the context.
+const messagesParamIndex = 2
+
+// BeforeWriteMessages starts a producer span for the batch and injects its
+// context into the headers of every message.
+func BeforeWriteMessages(ictx hook.HookContext, w *kafka.Writer, ctx context.Context, msgs ...kafka.Message) {
+	if !kafkaEnabler.Enable() {
+		logger.Debug("Kafka client instrumentation disabled")
+		return
+	}
+	if w == nil || len(msgs) == 0 {
+		return
+	}
+	initInstrumentation()
+
+	req := semconv.KafkaProducerRequest{
+		Topic:        writerTopic(w, msgs),
+		MessageCount: len(msgs),
+	}
+	if w.Addr != nil {
+		req.Broker = firstBroker(w.Addr.String())
+	}
+	if len(msgs) == 1 {
+		req.Key = string(msgs[0].Key)
+		req.BodySize = len(msgs[0].Value)
+	}
+	ctx, span := tracer.Start(ctx,
+		semconv.KafkaSpanName(semconv.OperationSend, req.Topic),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(semconv.KafkaProducerTraceAttrs(req)...),
+	)
+
+	// The caller owns msgs and may write them again, so the headers are
+	// injected into copies
+	traced := make([]kafka.Message, len(msgs))
+	for i, msg := range msgs {
+		msg.Headers = slices.Clone(msg.Headers)
+		propagator.Inject(ctx, headerCarrier{&msg.Headers})
+		traced[i] = msg
+	}
+	ictx.SetParam(messagesParamIndex, traced)
+
+	ictx.SetData(map[string]interface{}{
+		"span": span,
+	})
+}
+
+// AfterWriteMessages ends the producer span.
+func AfterWriteMessages(ictx hook.HookContext, err error) {
+	span, ok := ictx.GetKeyData("span").(trace.Span)
+	if !ok || span == nil {
+		return
+	}
+	defer span.End()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// writerTopic returns the topic of the writer, or that of the messages when
+// they all share one.
+func writerTopic(w *kafka.Writer, msgs []kafka.Message) string {
+	if w.Topic != "" {
+		return w.Topic
+	}
+	topic := msgs[0].Topic
+	for _, msg := range msgs[1:] {
+		if msg.Topic != topic {
+			return ""
+		}
+	}
+	return topic
+}
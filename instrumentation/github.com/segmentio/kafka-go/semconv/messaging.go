@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package semconv
+
+import (
+	"net"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+const (
+	OperationSend    = "send"
+	OperationReceive = "receive"
+)
+
+// KafkaProducerRequest describes a (*kafka.Writer).WriteMessages call.
+type KafkaProducerRequest struct {
+	Broker       string
+	Topic        string
+	MessageCount int
+	// Key and BodySize describe the message of single message writes.
+	Key      string
+	BodySize int
+}
+
+// KafkaConsumerRequest describes a message returned by a *kafka.Reader.
+type KafkaConsumerRequest struct {
+	Broker        string
+	Topic         string
+	ConsumerGroup string
+	Partition     int
+	Offset        int64
+	Key           string
+	BodySize      int
+}
+
+// KafkaSpanName returns the span name of a messaging operation, e.g. "send orders".
+func KafkaSpanName(operation, topic string) string {
+	if topic == "" {
+		return operation
+	}
+	return operation + " " + topic
+}
+
+// KafkaProducerTraceAttrs returns trace attributes for a Kafka producer request.
+// Batches of more than one message carry messaging.batch.message_count instead
+// of the attributes of an individual message.
+func KafkaProducerTraceAttrs(req KafkaProducerRequest) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKafka,
+		semconv.MessagingOperationName(OperationSend),
+		semconv.MessagingOperationTypeSend,
+	}
+	if req.Topic != "" {
+		attrs = append(attrs, semconv.MessagingDestinationName(req.Topic))
+	}
+	if req.MessageCount > 1 {
+		attrs = append(attrs, semconv.MessagingBatchMessageCount(req.MessageCount))
+	} else {
+		attrs = append(attrs, messageAttrs(req.Key, req.BodySize)...)
+	}
+	return append(attrs, serverAttrs(req.Broker)...)
+}
+
+// KafkaConsumerTraceAttrs returns trace attributes for a Kafka consumer request.
+func KafkaConsumerTraceAttrs(req KafkaConsumerRequest) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKafka,
+		semconv.MessagingOperationName(OperationReceive),
+		semconv.MessagingOperationTypeReceive,
+		semconv.MessagingDestinationPartitionID(strconv.Itoa(req.Partition)),
+		semconv.MessagingKafkaOffset(int(req.Offset)),
+	}
+	if req.Topic != "" {
+		attrs = append(attrs, semconv.MessagingDestinationName(req.Topic))
+	}
+	if req.ConsumerGroup != "" {
+		attrs = append(attrs, semconv.MessagingConsumerGroupName(req.ConsumerGroup))
+	}
+	attrs = append(attrs, messageAttrs(req.Key, req.BodySize)...)
+	return append(attrs, serverAttrs(req.Broker)...)
+}
+
+func messageAttrs(key string, bodySize int) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.MessagingMessageBodySize(bodySize)}
+	if key != "" {
+		attrs = append(attrs, semconv.MessagingKafkaMessageKey(key))
+	}
+	return attrs
+}
+
+func serverAttrs(broker string) []attribute.KeyValue {
+	if broker == "" {
+		return nil
+	}
+	host, portStr, err := net.SplitHostPort(broker)
+	if err != nil {
+		return []attribute.KeyValue{semconv.ServerAddress(broker)}
+	}
+	attrs := []attribute.KeyValue{semconv.ServerAddress(host)}
+	if port, convErr := strconv.Atoi(portStr); convErr == nil && port > 0 {
+		attrs = append(attrs, semconv.ServerPort(port))
+	}
+	return attrs
+}
@@ -0,0 +1,115 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package semconv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func attrMap(attrs []attribute.KeyValue) map[string]interface{} {
+	m := make(map[string]interface{})
+	for _, attr := range attrs {
+		m[string(attr.Key)] = attr.Value.AsInterface()
+	}
+	return m
+}
+
+func TestKafkaProducerTraceAttrs(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      KafkaProducerRequest
+		expected map[string]interface{}
+	}{
+		{
+			name: "single message",
+			req: KafkaProducerRequest{
+				Broker:       "localhost:9092",
+				Topic:        "orders",
+				MessageCount: 1,
+				Key:          "order-1",
+				BodySize:     42,
+			},
+			expected: map[string]interface{}{
+				"messaging.system":            "kafka",
+				"messaging.operation.name":    "send",
+				"messaging.operation.type":    "send",
+				"messaging.destination.name":  "orders",
+				"messaging.message.body.size": int64(42),
+				"messaging.kafka.message.key": "order-1",
+				"server.address":              "localhost",
+				"server.port":                 int64(9092),
+			},
+		},
+		{
+			name: "batch",
+			req: KafkaProducerRequest{
+				Broker:       "kafka.example.com:9093",
+				Topic:        "orders",
+				MessageCount: 3,
+			},
+			expected: map[string]interface{}{
+				"messaging.system":              "kafka",
+				"messaging.operation.name":      "send",
+				"messaging.operation.type":      "send",
+				"messaging.destination.name":    "orders",
+				"messaging.batch.message_count": int64(3),
+				"server.address":                "kafka.example.com",
+				"server.port":                   int64(9093),
+			},
+		},
+		{
+			name: "no topic, key or port",
+			req: KafkaProducerRequest{
+				Broker:       "kafka",
+				MessageCount: 1,
+			},
+			expected: map[string]interface{}{
+				"messaging.system":            "kafka",
+				"messaging.operation.name":    "send",
+				"messaging.operation.type":    "send",
+				"messaging.message.body.size": int64(0),
+				"server.address":              "kafka",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, attrMap(KafkaProducerTraceAttrs(tt.req)))
+		})
+	}
+}
+
+func TestKafkaConsumerTraceAttrs(t *testing.T) {
+	req := KafkaConsumerRequest{
+		Broker:        "localhost:9092",
+		Topic:         "orders",
+		ConsumerGroup: "billing",
+		Partition:     2,
+		Offset:        1337,
+		Key:           "order-1",
+		BodySize:      42,
+	}
+	assert.Equal(t, map[string]interface{}{
+		"messaging.system":                   "kafka",
+		"messaging.operation.name":           "receive",
+		"messaging.operation.type":           "receive",
+		"messaging.destination.name":         "orders",
+		"messaging.destination.partition.id": "2",
+		"messaging.kafka.offset":             int64(1337),
+		"messaging.consumer.group.name":      "billing",
+		"messaging.message.body.size":        int64(42),
+		"messaging.kafka.message.key":        "order-1",
+		"server.address":                     "localhost",
+		"server.port":                        int64(9092),
+	}, attrMap(KafkaConsumerTraceAttrs(req)))
+}
+
+func TestKafkaSpanName(t *testing.T) {
+	assert.Equal(t, "send orders", KafkaSpanName(OperationSend, "orders"))
+	assert.Equal(t, "receive", KafkaSpanName(OperationReceive, ""))
+}
@@ -4,18 +4,20 @@
 package mongodb
 
 import (
-	"context"
 	"runtime/debug"
 	"sync"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
-	instrumentationKey = "MONGODB"
+	instrumentationName = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/go.mongodb.org/mongo-driver/mongo"
+	instrumentationKey  = "MONGODB"
 )
 
 type mongoEnabler struct{}
@@ -26,6 +28,7 @@ func (g mongoEnabler) Enable() bool {
 
 var (
 	enabler  = mongoEnabler{}
+	tracer   trace.Tracer
 	initOnce sync.Once
 )
 
@@ -43,38 +46,17 @@ func initInstrumentation() {
 		); err != nil {
 			runtime.Logger().Error("failed to setup OTel SDK", "error", err)
 		}
+		tracer = otel.GetTracerProvider().Tracer(
+			instrumentationName,
+			trace.WithInstrumentationVersion(version),
+		)
 	})
 }
 
-// BeforeConnect intercepts mongo.Connect and injects the OTel command monitor
-func BeforeConnect(ictx hook.HookContext, ctx context.Context, opts ...*options.ClientOptions) {
-	if !enabler.Enable() {
-		return
-	}
-
-	initInstrumentation()
-
-	monitor := otelmongo.NewMonitor()
-
-	// If no options were provided, create a default options struct
-	if len(opts) == 0 {
-		opts = []*options.ClientOptions{
-			options.Client(),
-		}
-	}
-
-	// Inject monitor to all existing options
-	for _, opt := range opts {
-		if opt.Monitor == nil {
-			opt.SetMonitor(monitor)
-		}
-	}
-
-	// Explicitly set parameter to ensure otelc compiles and applies it
-	ictx.SetParam(1, opts)
-}
-
-// BeforeNewClient intercepts mongo.NewClient and injects the OTel command monitor
+// BeforeNewClient intercepts mongo.NewClient, which mongo.Connect also goes
+// through, and injects the OTel command monitor. The monitor is appended as
+// its own options so the application's options are left untouched; it wraps
+// the command monitor the application set, if any.
 func BeforeNewClient(ictx hook.HookContext, opts ...*options.ClientOptions) {
 	if !enabler.Enable() {
 		return
@@ -82,21 +64,14 @@ func BeforeNewClient(ictx hook.HookContext, opts ...*options.ClientOptions) {
 
 	initInstrumentation()
 
-	monitor := otelmongo.NewMonitor()
-
-	// If no options were provided, create a default options struct
-	if len(opts) == 0 {
-		opts = []*options.ClientOptions{
-			options.Client(),
-		}
-	}
-
-	// Inject monitor to all existing options
+	// The options are merged in order, the last monitor set winning.
+	var appMonitor *event.CommandMonitor
 	for _, opt := range opts {
-		if opt.Monitor == nil {
-			opt.SetMonitor(monitor)
+		if opt != nil && opt.Monitor != nil {
+			appMonitor = opt.Monitor
 		}
 	}
+	opts = append(opts, options.Client().SetMonitor(newCommandMonitor(appMonitor)))
 
 	// Explicitly set parameter to ensure otelc compiles and applies it
 	ictx.SetParam(0, opts)
@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+)
+
+func TestBeforeNewClient_InjectsMonitor(t *testing.T) {
+	setupTestTracer(t)
+	appOpts := options.Client().ApplyURI("mongodb://localhost:27017")
+	ictx := hooktest.NewMockHookContext([]*options.ClientOptions{appOpts})
+
+	BeforeNewClient(ictx, appOpts)
+
+	opts, ok := ictx.GetParam(0).([]*options.ClientOptions)
+	require.True(t, ok)
+	require.Len(t, opts, 2)
+	assert.Nil(t, appOpts.Monitor, "the application's options are left untouched")
+	assert.NotNil(t, options.MergeClientOptions(opts...).Monitor)
+}
+
+func TestBeforeNewClient_WrapsApplicationMonitor(t *testing.T) {
+	setupTestTracer(t)
+	started := 0
+	app := &event.CommandMonitor{
+		Started: func(context.Context, *event.CommandStartedEvent) { started++ },
+	}
+	appOpts := []*options.ClientOptions{
+		options.Client().SetMonitor(app),
+		nil,
+		options.Client().SetAppName("app"),
+	}
+	ictx := hooktest.NewMockHookContext(appOpts)
+
+	BeforeNewClient(ictx, appOpts...)
+
+	opts, ok := ictx.GetParam(0).([]*options.ClientOptions)
+	require.True(t, ok)
+	monitor := options.MergeClientOptions(opts...).Monitor
+	require.NotSame(t, app, monitor)
+	monitor.Started(context.Background(), startedEvent(t, 1, bson.D{{Key: "ping", Value: 1}}))
+	assert.Equal(t, 1, started)
+}
+
+func TestBeforeNewClient_Disabled(t *testing.T) {
+	t.Setenv("OTEL_GO_DISABLED_INSTRUMENTATIONS", "mongodb")
+	ictx := hooktest.NewMockHookContext([]*options.ClientOptions{})
+
+	BeforeNewClient(ictx)
+
+	assert.Equal(t, []*options.ClientOptions{}, ictx.GetParam(0))
+}
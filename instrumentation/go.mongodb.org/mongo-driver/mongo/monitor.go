@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mongodb
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/go.mongodb.org/mongo-driver/mongo/semconv"
+)
+
+// spanKey pairs the started event of a command with its succeeded or failed
+// event. Request IDs are only unique per connection.
+type spanKey struct {
+	connectionID string
+	requestID    int64
+}
+
+// commandMonitor starts a client span when a command is sent and ends it when
+// its reply or failure is received. The events are passed on to the
+// application's monitor, if any, first.
+type commandMonitor struct {
+	next *event.CommandMonitor
+
+	// captureStatement records the commands as db.query.text.
+	captureStatement bool
+
+	mu    sync.Mutex
+	spans map[spanKey]trace.Span
+}
+
+func newCommandMonitor(next *event.CommandMonitor) *event.CommandMonitor {
+	return (&commandMonitor{
+		next:             next,
+		captureStatement: semconv.CaptureStatement(),
+		spans:            make(map[spanKey]trace.Span),
+	}).eventMonitor()
+}
+
+// eventMonitor returns the event.CommandMonitor reporting to m.
+func (m *commandMonitor) eventMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started:   m.started,
+		Succeeded: m.succeeded,
+		Failed:    m.failed,
+	}
+}
+
+func (m *commandMonitor) started(ctx context.Context, evt *event.CommandStartedEvent) {
+	if m.next != nil && m.next.Started != nil {
+		m.next.Started(ctx, evt)
+	}
+
+	request := semconv.MongoRequest{
+		CommandName: evt.CommandName,
+		Collection:  collectionName(evt),
+		Database:    evt.DatabaseName,
+		Endpoint:    endpoint(evt.ConnectionID),
+	}
+	if m.captureStatement {
+		request.Statement = evt.Command.String()
+	}
+	_, span := tracer.Start(ctx,
+		semconv.MongoSpanName(request),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.MongoClientRequestTraceAttrs(request)...),
+	)
+
+	m.mu.Lock()
+	m.spans[spanKey{evt.ConnectionID, evt.RequestID}] = span
+	m.mu.Unlock()
+}
+
+func (m *commandMonitor) succeeded(ctx context.Context, evt *event.CommandSucceededEvent) {
+	if m.next != nil && m.next.Succeeded != nil {
+		m.next.Succeeded(ctx, evt)
+	}
+	if span, ok := m.finish(evt.CommandFinishedEvent); ok {
+		span.End()
+	}
+}
+
+func (m *commandMonitor) failed(ctx context.Context, evt *event.CommandFailedEvent) {
+	if m.next != nil && m.next.Failed != nil {
+		m.next.Failed(ctx, evt)
+	}
+	if span, ok := m.finish(evt.CommandFinishedEvent); ok {
+		span.SetStatus(codes.Error, evt.Failure)
+		span.End()
+	}
+}
+
+// finish returns the span started for the command evt reports on, forgetting
+// it.
+func (m *commandMonitor) finish(evt event.CommandFinishedEvent) (trace.Span, bool) {
+	key := spanKey{evt.ConnectionID, evt.RequestID}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	span, ok := m.spans[key]
+	delete(m.spans, key)
+	return span, ok
+}
+
+// collectionName returns the collection the command operates on, the value of
+// its first element for the collection commands (e.g. {insert: "users"}), or
+// "" for the other commands.
+func collectionName(evt *event.CommandStartedEvent) string {
+	if evt.CommandName == "getMore" {
+		if v, ok := evt.Command.Lookup("collection").StringValueOK(); ok {
+			return v
+		}
+		return ""
+	}
+	elem, err := evt.Command.IndexErr(0)
+	if err != nil || elem.Key() != evt.CommandName {
+		return ""
+	}
+	if v := elem.Value(); v.Type == bson.TypeString {
+		return v.StringValue()
+	}
+	return ""
+}
+
+// endpoint returns the address of the server from the driver's connection ID,
+// e.g. "localhost:27017" for "localhost:27017[-3]".
+func endpoint(connectionID string) string {
+	addr, _, _ := strings.Cut(connectionID, "[")
+	return addr
+}
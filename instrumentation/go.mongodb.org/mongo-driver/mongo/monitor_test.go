@@ -0,0 +1,194 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mongodb
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupTestTracer(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	initOnce = *new(sync.Once)
+	initInstrumentation()
+	return sr
+}
+
+func startedEvent(t *testing.T, requestID int64, command bson.D) *event.CommandStartedEvent {
+	t.Helper()
+	raw, err := bson.Marshal(command)
+	require.NoError(t, err)
+	return &event.CommandStartedEvent{
+		Command:      raw,
+		DatabaseName: "testdb",
+		CommandName:  command[0].Key,
+		RequestID:    requestID,
+		ConnectionID: "localhost:27017[-1]",
+	}
+}
+
+func finishedEvent(started *event.CommandStartedEvent) event.CommandFinishedEvent {
+	return event.CommandFinishedEvent{
+		CommandName:  started.CommandName,
+		DatabaseName: started.DatabaseName,
+		RequestID:    started.RequestID,
+		ConnectionID: started.ConnectionID,
+	}
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[string]interface{} {
+	attrMap := make(map[string]interface{})
+	for _, attr := range span.Attributes() {
+		attrMap[string(attr.Key)] = attr.Value.AsInterface()
+	}
+	return attrMap
+}
+
+func TestCommandMonitor_Succeeded(t *testing.T) {
+	sr := setupTestTracer(t)
+	monitor := newCommandMonitor(nil)
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	started := startedEvent(t, 1, bson.D{{Key: "insert", Value: "users"}, {Key: "documents", Value: bson.A{bson.D{{Key: "name", Value: "secret"}}}}})
+	monitor.Started(ctx, started)
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finishedEvent(started)})
+	parent.End()
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "insert users", span.Name())
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	assert.Equal(t, codes.Unset, span.Status().Code)
+
+	attrs := attributes(span)
+	assert.Equal(t, "mongodb", attrs["db.system.name"])
+	assert.Equal(t, "insert", attrs["db.operation.name"])
+	assert.Equal(t, "users", attrs["db.collection.name"])
+	assert.Equal(t, "testdb", attrs["db.namespace"])
+	assert.Equal(t, "localhost", attrs["server.address"])
+	assert.Equal(t, int64(27017), attrs["server.port"])
+	assert.NotContains(t, attrs, "db.query.text", "documents are not recorded by default")
+}
+
+func TestCommandMonitor_Failed(t *testing.T) {
+	sr := setupTestTracer(t)
+	monitor := newCommandMonitor(nil)
+
+	started := startedEvent(t, 2, bson.D{{Key: "find", Value: "users"}})
+	monitor.Started(context.Background(), started)
+	monitor.Failed(context.Background(), &event.CommandFailedEvent{
+		CommandFinishedEvent: finishedEvent(started),
+		Failure:              "(Unauthorized) not authorized",
+	})
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "(Unauthorized) not authorized", spans[0].Status().Description)
+}
+
+func TestCommandMonitor_PairsByRequestID(t *testing.T) {
+	sr := setupTestTracer(t)
+	monitor := newCommandMonitor(nil)
+
+	insert := startedEvent(t, 10, bson.D{{Key: "insert", Value: "users"}})
+	find := startedEvent(t, 11, bson.D{{Key: "find", Value: "orders"}})
+	monitor.Started(context.Background(), insert)
+	monitor.Started(context.Background(), find)
+	monitor.Failed(context.Background(), &event.CommandFailedEvent{CommandFinishedEvent: finishedEvent(find), Failure: "boom"})
+	monitor.Succeeded(context.Background(), &event.CommandSucceededEvent{CommandFinishedEvent: finishedEvent(insert)})
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "find orders", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "insert users", spans[1].Name())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
+
+func TestCommandMonitor_CapturesStatement(t *testing.T) {
+	sr := setupTestTracer(t)
+	monitor := (&commandMonitor{
+		captureStatement: true,
+		spans:            make(map[spanKey]trace.Span),
+	}).eventMonitor()
+
+	started := startedEvent(t, 3, bson.D{{Key: "delete", Value: "users"}})
+	monitor.Started(context.Background(), started)
+	monitor.Succeeded(context.Background(), &event.CommandSucceededEvent{CommandFinishedEvent: finishedEvent(started)})
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, started.Command.String(), attributes(spans[0])["db.query.text"])
+}
+
+func TestCommandMonitor_ChainsApplicationMonitor(t *testing.T) {
+	sr := setupTestTracer(t)
+	var events []string
+	app := &event.CommandMonitor{
+		Started: func(context.Context, *event.CommandStartedEvent) { events = append(events, "started") },
+		Failed:  func(context.Context, *event.CommandFailedEvent) { events = append(events, "failed") },
+	}
+	monitor := newCommandMonitor(app)
+
+	ping := startedEvent(t, 4, bson.D{{Key: "ping", Value: 1}})
+	monitor.Started(context.Background(), ping)
+	monitor.Succeeded(context.Background(), &event.CommandSucceededEvent{CommandFinishedEvent: finishedEvent(ping)})
+	find := startedEvent(t, 5, bson.D{{Key: "find", Value: "users"}})
+	monitor.Started(context.Background(), find)
+	monitor.Failed(context.Background(), &event.CommandFailedEvent{CommandFinishedEvent: finishedEvent(find)})
+
+	assert.Equal(t, []string{"started", "started", "failed"}, events)
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "ping", spans[0].Name())
+	assert.NotContains(t, attributes(spans[0]), "db.collection.name")
+}
+
+func TestCommandMonitor_UnknownRequestIgnored(t *testing.T) {
+	sr := setupTestTracer(t)
+	monitor := newCommandMonitor(nil)
+
+	monitor.Succeeded(context.Background(), &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{RequestID: 42, ConnectionID: "localhost:27017[-1]"},
+	})
+	assert.Empty(t, sr.Ended())
+}
+
+func TestCollectionName(t *testing.T) {
+	tests := []struct {
+		command  bson.D
+		expected string
+	}{
+		{bson.D{{Key: "insert", Value: "users"}}, "users"},
+		{bson.D{{Key: "aggregate", Value: 1}}, ""},
+		{bson.D{{Key: "getMore", Value: int64(7)}, {Key: "collection", Value: "users"}}, "users"},
+		{bson.D{{Key: "hello", Value: 1}}, ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, collectionName(startedEvent(t, 1, tt.command)), "%v", tt.command)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	assert.Equal(t, "localhost:27017", endpoint("localhost:27017[-3]"))
+	assert.Equal(t, "mongo.local:27017", endpoint("mongo.local:27017"))
+}
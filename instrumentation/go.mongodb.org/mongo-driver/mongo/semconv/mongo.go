@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package semconv
+
+import (
+	"net"
+	"os"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// EnvCaptureStatement records the command sent to the server as the
+// db.query.text attribute when set to "true". Commands carry the documents
+// they insert and update, so they are left out by default.
+const EnvCaptureStatement = "OTEL_GO_MONGODB_CAPTURE_STATEMENT"
+
+// captureStatement is true when EnvCaptureStatement is "true".
+var captureStatement bool
+
+func init() {
+	initCaptureStatement()
+}
+
+func initCaptureStatement() {
+	captureStatement = os.Getenv(EnvCaptureStatement) == "true"
+}
+
+// CaptureStatement reports whether the command is recorded as db.query.text.
+func CaptureStatement() bool {
+	return captureStatement
+}
+
+type MongoRequest struct {
+	CommandName string
+	Collection  string
+	Database    string
+	Endpoint    string
+	Statement   string
+}
+
+// MongoSpanName returns "{command} {collection}", or the command alone for
+// the commands that do not operate on a collection.
+func MongoSpanName(req MongoRequest) string {
+	if req.Collection == "" {
+		return req.CommandName
+	}
+	return req.CommandName + " " + req.Collection
+}
+
+// MongoClientRequestTraceAttrs returns trace attributes for a MongoDB command.
+// The statement is recorded when set, which callers only do when
+// CaptureStatement.
+func MongoClientRequestTraceAttrs(req MongoRequest) []attribute.KeyValue {
+	host, portStr, err := net.SplitHostPort(req.Endpoint)
+	if err != nil {
+		host = req.Endpoint
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemNameMongoDB,
+		semconv.DBOperationName(req.CommandName),
+		semconv.DBNamespace(req.Database),
+		semconv.ServerAddress(host),
+		semconv.NetworkTransportTCP,
+	}
+	if req.Collection != "" {
+		attrs = append(attrs, semconv.DBCollectionName(req.Collection))
+	}
+	if req.Statement != "" {
+		attrs = append(attrs, semconv.DBQueryText(req.Statement))
+	}
+
+	if err == nil {
+		if port, convErr := strconv.Atoi(portStr); convErr == nil && port > 0 {
+			attrs = append(attrs, semconv.ServerPort(port))
+		}
+	}
+
+	return attrs
+}
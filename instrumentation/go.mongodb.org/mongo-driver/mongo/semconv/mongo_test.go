@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package semconv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMongoSpanName(t *testing.T) {
+	assert.Equal(t, "insert users", MongoSpanName(MongoRequest{CommandName: "insert", Collection: "users"}))
+	assert.Equal(t, "ping", MongoSpanName(MongoRequest{CommandName: "ping"}))
+}
+
+func TestMongoClientRequestTraceAttrs(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      MongoRequest
+		expected map[string]interface{}
+	}{
+		{
+			name: "collection command",
+			req: MongoRequest{
+				CommandName: "find",
+				Collection:  "users",
+				Database:    "testdb",
+				Endpoint:    "localhost:27017",
+			},
+			expected: map[string]interface{}{
+				"db.system.name":     "mongodb",
+				"db.operation.name":  "find",
+				"db.collection.name": "users",
+				"db.namespace":       "testdb",
+				"server.address":     "localhost",
+				"server.port":        int64(27017),
+				"network.transport":  "tcp",
+			},
+		},
+		{
+			name: "database command",
+			req: MongoRequest{
+				CommandName: "ping",
+				Database:    "admin",
+				Endpoint:    "mongo.local",
+			},
+			expected: map[string]interface{}{
+				"db.system.name":    "mongodb",
+				"db.operation.name": "ping",
+				"db.namespace":      "admin",
+				"server.address":    "mongo.local",
+				"network.transport": "tcp",
+			},
+		},
+		{
+			name: "statement captured",
+			req: MongoRequest{
+				CommandName: "insert",
+				Collection:  "users",
+				Database:    "testdb",
+				Endpoint:    "127.0.0.1:27018",
+				Statement:   `{"insert": "users"}`,
+			},
+			expected: map[string]interface{}{
+				"db.system.name":     "mongodb",
+				"db.operation.name":  "insert",
+				"db.collection.name": "users",
+				"db.namespace":       "testdb",
+				"db.query.text":      `{"insert": "users"}`,
+				"server.address":     "127.0.0.1",
+				"server.port":        int64(27018),
+				"network.transport":  "tcp",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrMap := make(map[string]interface{})
+			for _, attr := range MongoClientRequestTraceAttrs(tt.req) {
+				attrMap[string(attr.Key)] = attr.Value.AsInterface()
+			}
+			assert.Equal(t, tt.expected, attrMap)
+		})
+	}
+}
+
+func TestCaptureStatement(t *testing.T) {
+	assert.False(t, CaptureStatement(), "commands are left out by default")
+
+	// Cleanups run last in first out, so this one runs after the env is restored
+	t.Cleanup(initCaptureStatement)
+	t.Setenv(EnvCaptureStatement, "true")
+	assert.False(t, CaptureStatement(), "the env is read once")
+	initCaptureStatement()
+	assert.True(t, CaptureStatement())
+}
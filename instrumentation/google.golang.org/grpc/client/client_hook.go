@@ -14,6 +14,7 @@ import (
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
@@ -85,8 +86,8 @@ func initInstrumentation() {
 			instrumentationName,
 			trace.WithInstrumentationVersion(version),
 		)
-		propagator = otel.GetTextMapPropagator()
-		meter = otel.GetMeterProvider().Meter(
+		propagator = runtime.Propagator(instrumentationKey)
+		meter = runtime.Meter(
 			instrumentationName,
 			metric.WithInstrumentationVersion(version),
 			metric.WithSchemaURL(semconv.SchemaURL),
@@ -117,11 +118,7 @@ func initInstrumentation() {
 		if err != nil {
 			logger.Error("failed to create client responses per RPC metric", "error", err)
 		}
-
-		// Start runtime metrics (respects OTEL_GO_ENABLED/DISABLED_INSTRUMENTATIONS)
-		if err := runtime.StartRuntimeMetrics(); err != nil {
-			logger.Error("failed to start runtime metrics", "error", err)
-		}
+		initConnState(meter)
 
 		logger.Info("gRPC client instrumentation initialized")
 	})
@@ -157,9 +154,10 @@ func BeforeNewClient(ictx hook.HookContext, target string, opts ...grpc.DialOpti
 	initInstrumentation()
 
 	logger.Debug("BeforeNewClient called", "target", target)
+	ictx.SetKeyData(targetKeyData, target)
 
 	// Create and inject stats handler
-	handler := newClientStatsHandler()
+	handler := newClientStatsHandler(target)
 	newOpts := append([]grpc.DialOption{grpc.WithStatsHandler(handler)}, opts...)
 	ictx.SetParam(newClientOptionsParamIndex, newOpts)
 }
@@ -174,6 +172,7 @@ func AfterNewClient(ictx hook.HookContext, conn *grpc.ClientConn, err error) {
 	} else {
 		logger.Debug("AfterNewClient called")
 	}
+	afterConnCreated(ictx, conn, err)
 }
 
 // BeforeDialContext hooks before grpc.DialContext (v1.44-1.63)
@@ -186,9 +185,10 @@ func BeforeDialContext(ictx hook.HookContext, ctx context.Context, target string
 	initInstrumentation()
 
 	logger.Debug("BeforeDialContext called", "target", target)
+	ictx.SetKeyData(targetKeyData, target)
 
 	// Create and inject stats handler
-	handler := newClientStatsHandler()
+	handler := newClientStatsHandler(target)
 	newOpts := append([]grpc.DialOption{grpc.WithStatsHandler(handler)}, opts...)
 	ictx.SetParam(dialOptionsParamIndex, newOpts)
 }
@@ -203,6 +203,7 @@ func AfterDialContext(ictx hook.HookContext, conn *grpc.ClientConn, err error) {
 	} else {
 		logger.Debug("AfterDialContext called")
 	}
+	afterConnCreated(ictx, conn, err)
 }
 
 type gRPCContextKey struct{}
@@ -214,10 +215,14 @@ type gRPCContext struct {
 	metricAttrSet attribute.Set
 }
 
-type clientStatsHandler struct{}
+type clientStatsHandler struct {
+	// targetAttrs are the server address attributes derived from the dial
+	// target, naming the logical server rather than a resolved backend.
+	targetAttrs []attribute.KeyValue
+}
 
-func newClientStatsHandler() stats.Handler {
-	return &clientStatsHandler{}
+func newClientStatsHandler(target string) stats.Handler {
+	return &clientStatsHandler{targetAttrs: grpcsemconv.TargetAddrAttrs(target)}
 }
 
 // TagRPC is called at the beginning of an RPC to create a context
@@ -227,8 +232,10 @@ func (h *clientStatsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo)
 		return ctx
 	}
 
-	// Parse method name and get attributes
+	// Parse method name and get attributes, along with those derived from
+	// the caller's context such as the active feature flag
 	name, attrs := grpcsemconv.ParseFullMethod(info.FullMethodName)
+	attrs = append(attrs, runtime.ContextAttributes(ctx)...)
 
 	// Start span
 	ctx, _ = tracer.Start(
@@ -236,6 +243,7 @@ func (h *clientStatsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo)
 		name,
 		trace.WithSpanKind(trace.SpanKindClient),
 		trace.WithAttributes(attrs...),
+		trace.WithAttributes(h.targetAttrs...),
 	)
 
 	// Inject trace context into outgoing metadata
@@ -273,10 +281,15 @@ func (h *clientStatsHandler) HandleRPC(ctx context.Context, rs stats.RPCStats) {
 			}
 		}
 	case *stats.OutHeader:
-		// Add server address attributes
+		// Add the connection peer, as server address attributes unless
+		// the dial target already named the server
 		if span.IsRecording() {
 			if p, ok := peer.FromContext(ctx); ok {
-				span.SetAttributes(grpcsemconv.ServerAddrAttrs(p.Addr.String())...)
+				if len(h.targetAttrs) > 0 {
+					span.SetAttributes(grpcsemconv.NetworkPeerAttrs(p.Addr.String())...)
+				} else {
+					span.SetAttributes(grpcsemconv.ServerAddrAttrs(p.Addr.String())...)
+				}
 			}
 		}
 	case *stats.End:
@@ -294,6 +307,12 @@ func (h *clientStatsHandler) HandleRPC(ctx context.Context, rs stats.RPCStats) {
 		if span.IsRecording() {
 			if s != nil {
 				code, msg := grpcsemconv.ClientStatus(s)
+				if rs.Error != nil {
+					// A caller-supplied cancellation cause says more than "context canceled"
+					if cause := runtime.RecordCancelCause(ctx, span); cause != nil && code == codes.Error {
+						msg = cause.Error()
+					}
+				}
 				span.SetStatus(code, msg)
 			}
 			span.SetAttributes(statusAttr)
@@ -5,21 +5,36 @@ package client
 
 import (
 	"context"
+	"errors"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
 )
 
 func TestBeforeNewClient(t *testing.T) {
@@ -319,7 +334,7 @@ func TestClientStatsHandler_TagRPC(t *testing.T) {
 	// Re-initialize to use new tracer provider
 	tracer = tp.Tracer(instrumentationName, trace.WithInstrumentationVersion(moduleVersion()))
 
-	handler := newClientStatsHandler()
+	handler := newClientStatsHandler("")
 
 	tests := []struct {
 		name           string
@@ -368,6 +383,149 @@ func TestClientStatsHandler_TagRPC(t *testing.T) {
 	}
 }
 
+func TestClientStatsHandler_CancelCause(t *testing.T) {
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "grpc")
+	initInstrumentation()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	oldTP := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() {
+		_ = tp.Shutdown(context.Background())
+		otel.SetTracerProvider(oldTP)
+	})
+	tracer = tp.Tracer(instrumentationName, trace.WithInstrumentationVersion(moduleVersion()))
+
+	handler := newClientStatsHandler("")
+	ctx, cancel := context.WithCancelCause(t.Context())
+	newCtx := handler.TagRPC(ctx, &stats.RPCTagInfo{FullMethodName: "/grpc.testing.TestService/UnaryCall"})
+	cancel(errors.New("user navigated away"))
+	handler.HandleRPC(newCtx, &stats.End{
+		BeginTime: time.Now().Add(-100 * time.Millisecond),
+		EndTime:   time.Now(),
+		Error:     status.FromContextError(newCtx.Err()).Err(),
+	})
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, otelcodes.Error, spans[0].Status.Code)
+	assert.Equal(t, "user navigated away", spans[0].Status.Description)
+	assert.Contains(t, spans[0].Attributes, runtime.CancelCauseKey.String("user navigated away"))
+}
+
+func TestConnStateChanges(t *testing.T) {
+	t.Setenv(envConnState, "true")
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() {
+		_ = mp.Shutdown(context.Background())
+		connStateChanges = nil
+	})
+	initConnState(mp.Meter("test"))
+	require.NotNil(t, connStateChanges)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	target := lis.Addr().String()
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	ictx := hooktest.NewMockHookContext()
+	ictx.SetKeyData(targetKeyData, target)
+	afterConnCreated(ictx, conn, nil)
+	conn.Connect()
+
+	ready := attribute.NewSet(connStateKey.String("READY"), attribute.String("server.address", target))
+	require.Eventually(t, func() bool {
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(context.Background(), &rm))
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				if m.Name != connStateMetric {
+					continue
+				}
+				for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+					if dp.Attributes.Equals(&ready) && dp.Value > 0 {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond, "expected a READY transition")
+}
+
+func TestConnStateChanges_DialContext(t *testing.T) {
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "grpc")
+	t.Setenv(envConnState, "true")
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() {
+		_ = mp.Shutdown(context.Background())
+		connStateChanges = nil
+	})
+	initConnState(mp.Meter("test"))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	target := lis.Addr().String()
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	// grpc.DialContext calls grpc.NewClient, so the After hooks of both see
+	// the same connection
+	newClientCtx := hooktest.NewMockHookContext()
+	newClientCtx.SetKeyData(targetKeyData, target)
+	AfterNewClient(newClientCtx, conn, nil)
+	dialCtx := hooktest.NewMockHookContext()
+	dialCtx.SetKeyData(targetKeyData, target)
+	AfterDialContext(dialCtx, conn, nil)
+
+	counts := func() map[string]int64 {
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(context.Background(), &rm))
+		counts := make(map[string]int64)
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				if m.Name != connStateMetric {
+					continue
+				}
+				for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+					state, _ := dp.Attributes.Value(connStateKey)
+					counts[state.AsString()] += dp.Value
+				}
+			}
+		}
+		return counts
+	}
+	conn.Connect()
+	require.Eventually(t, func() bool { return counts()["READY"] > 0 },
+		5*time.Second, 10*time.Millisecond, "expected a READY transition")
+	require.NoError(t, conn.Close())
+	require.Eventually(t, func() bool { return counts()["SHUTDOWN"] > 0 },
+		5*time.Second, 10*time.Millisecond, "expected a SHUTDOWN transition")
+
+	assert.Equal(t, int64(1), counts()["READY"], "each transition is counted once")
+	assert.Equal(t, int64(1), counts()["SHUTDOWN"], "each transition is counted once")
+}
+
+func TestConnStateChanges_Disabled(t *testing.T) {
+	t.Setenv(envConnState, "")
+	initConnState(sdkmetric.NewMeterProvider().Meter("test"))
+	assert.Nil(t, connStateChanges)
+	assert.NotPanics(t, func() { monitorConnState(&grpc.ClientConn{}, "localhost:50051") })
+}
+
 func TestClientStatsHandler_Integration(t *testing.T) {
 	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "grpc")
 
@@ -396,7 +554,7 @@ func TestClientStatsHandler_Integration(t *testing.T) {
 }
 
 func TestClientStatsHandler_TagConn(t *testing.T) {
-	handler := newClientStatsHandler()
+	handler := newClientStatsHandler("")
 
 	ctx := t.Context()
 	info := &stats.ConnTagInfo{
@@ -411,7 +569,7 @@ func TestClientStatsHandler_TagConn(t *testing.T) {
 }
 
 func TestClientStatsHandler_HandleConn(t *testing.T) {
-	handler := newClientStatsHandler()
+	handler := newClientStatsHandler("")
 
 	ctx := t.Context()
 
@@ -440,7 +598,7 @@ func TestClientStatsHandler_OTELExporterFiltering(t *testing.T) {
 	// Re-initialize to use new tracer provider
 	tracer = tp.Tracer(instrumentationName, trace.WithInstrumentationVersion(moduleVersion()))
 
-	handler := newClientStatsHandler()
+	handler := newClientStatsHandler("")
 
 	tests := []struct {
 		name             string
@@ -508,3 +666,92 @@ func TestClientStatsHandler_OTELExporterFiltering(t *testing.T) {
 		})
 	}
 }
+
+func TestClientStatsHandler_Bufconn(t *testing.T) {
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "grpc")
+	initInstrumentation()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	tracer = tp.Tracer(instrumentationName, trace.WithInstrumentationVersion(moduleVersion()))
+	oldPropagator := propagator
+	propagator = propagation.TraceContext{}
+	t.Cleanup(func() { propagator = oldPropagator })
+
+	// Serve the health service in process, remembering the traceparent
+	// every call arrives with
+	traceparents := make(chan string, 1)
+	server := grpc.NewServer(grpc.UnaryInterceptor(
+		func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			traceparents <- strings.Join(md.Get("traceparent"), ",")
+			return handler(ctx, req)
+		},
+	))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	// Dial it with the options the hook returns
+	target := "passthrough:///bufnet"
+	opts := []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+	ictx := hooktest.NewMockHookContext(target, opts)
+	BeforeNewClient(ictx, target, opts...)
+	conn, err := grpc.NewClient(target, ictx.GetParam(newClientOptionsParamIndex).([]grpc.DialOption)...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	client := healthpb.NewHealthClient(conn)
+
+	waitForSpan := func(t *testing.T) sdktrace.ReadOnlySpan {
+		t.Helper()
+		var spans tracetest.SpanStubs
+		require.Eventually(t, func() bool {
+			spans = exporter.GetSpans()
+			return len(spans) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+		exporter.Reset()
+		return spans.Snapshots()[0]
+	}
+	attrs := func(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+		m := make(map[attribute.Key]attribute.Value)
+		for _, attr := range span.Attributes() {
+			m[attr.Key] = attr.Value
+		}
+		return m
+	}
+
+	t.Run("unary", func(t *testing.T) {
+		_, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+
+		span := waitForSpan(t)
+		assert.Equal(t, "grpc.health.v1.Health/Check", span.Name())
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+		assert.Equal(t, otelcodes.Unset, span.Status().Code)
+		got := attrs(span)
+		assert.Equal(t, "grpc", got[semconv.RPCSystemKey].AsString())
+		assert.Equal(t, "bufnet", got[semconv.ServerAddressKey].AsString(), "server.address names the dial target")
+		assert.Equal(t, int64(grpccodes.OK), got[semconv.RPCGRPCStatusCodeKey].AsInt64())
+
+		// The server got the context of the client span
+		sc := span.SpanContext()
+		assert.Equal(t, "00-"+sc.TraceID().String()+"-"+sc.SpanID().String()+"-01", <-traceparents)
+	})
+
+	t.Run("unary error", func(t *testing.T) {
+		_, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{Service: "unknown"})
+		require.Equal(t, grpccodes.NotFound, status.Code(err))
+		<-traceparents
+
+		span := waitForSpan(t)
+		assert.Equal(t, otelcodes.Error, span.Status().Code, "every non-OK status is a client error")
+		assert.Equal(t, int64(grpccodes.NotFound), attrs(span)[semconv.RPCGRPCStatusCodeKey].AsInt64())
+	})
+}
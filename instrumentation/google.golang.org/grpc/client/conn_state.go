@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+)
+
+const (
+	// envConnState enables the connection state change metric when set to "true".
+	envConnState = "OTEL_GO_GRPC_CLIENT_CONN_STATE"
+
+	connStateMetric = "rpc.client.connection.state_changes"
+
+	// targetKeyData carries the dial target from the Before to the After hooks.
+	targetKeyData = "target"
+
+	connStateKey = attribute.Key("rpc.grpc.connection.state")
+)
+
+// connStateChanges is nil unless envConnState is "true".
+var connStateChanges metric.Int64Counter
+
+// monitoredConns holds the connections being monitored. grpc.DialContext
+// creates its connection with grpc.NewClient, so both hooks see it.
+var monitoredConns sync.Map // *grpc.ClientConn -> struct{}
+
+// initConnState creates the connection state change counter when it is enabled.
+func initConnState(m metric.Meter) {
+	connStateChanges = nil
+	if os.Getenv(envConnState) != "true" {
+		return
+	}
+	counter, err := m.Int64Counter(
+		connStateMetric,
+		metric.WithUnit("{change}"),
+		metric.WithDescription("Connectivity state transitions of gRPC client connections."),
+	)
+	if err != nil {
+		logger.Error("failed to create connection state change counter", "error", err)
+		return
+	}
+	connStateChanges = counter
+}
+
+// monitorConnState records every state the connection moves to until it is
+// shut down. It returns immediately when the metric is disabled or the
+// connection is already monitored.
+func monitorConnState(conn *grpc.ClientConn, target string) {
+	counter := connStateChanges
+	if counter == nil || conn == nil {
+		return
+	}
+	if _, monitored := monitoredConns.LoadOrStore(conn, struct{}{}); monitored {
+		return
+	}
+	go func() {
+		defer monitoredConns.Delete(conn)
+		ctx := context.Background()
+		state := conn.GetState()
+		for state != connectivity.Shutdown {
+			if !conn.WaitForStateChange(ctx, state) {
+				return
+			}
+			state = conn.GetState()
+			counter.Add(ctx, 1, metric.WithAttributes(
+				connStateKey.String(state.String()),
+				semconv.ServerAddress(target),
+			))
+			logger.Debug("gRPC client connection state changed", "target", target, "state", state.String())
+		}
+	}()
+}
+
+// afterConnCreated starts monitoring a connection created by a hooked call.
+func afterConnCreated(ictx hook.HookContext, conn *grpc.ClientConn, err error) {
+	if err != nil {
+		return
+	}
+	if target, ok := ictx.GetKeyData(targetKeyData).(string); ok {
+		monitorConnState(conn, target)
+	}
+}
@@ -53,6 +53,53 @@ func GRPCStatusCodeAttr(code int) attribute.KeyValue {
 	return semconv.RPCGRPCStatusCodeKey.Int(code)
 }
 
+// RequestMessageCountKey and ResponseMessageCountKey record on the span of a
+// streaming RPC how many messages it received and sent.
+const (
+	RequestMessageCountKey  = attribute.Key("rpc.grpc.request.message.count")
+	ResponseMessageCountKey = attribute.Key("rpc.grpc.response.message.count")
+)
+
+// MessageCountAttrs returns the message count attributes of a streaming RPC
+// that carried the given numbers of request and response messages.
+func MessageCountAttrs(requests, responses int64) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		RequestMessageCountKey.Int64(requests),
+		ResponseMessageCountKey.Int64(responses),
+	}
+}
+
+// StatusMessageKey and StatusDetailsKey record on the span of a failed RPC the
+// message of its status and the types of the details the status carries.
+const (
+	StatusMessageKey = attribute.Key("rpc.grpc.status.message")
+	StatusDetailsKey = attribute.Key("rpc.grpc.status.details")
+)
+
+// StatusAttrs returns the message of s and, when details is true, the full
+// names of the protobuf types of its details, e.g. google.rpc.BadRequest.
+// It returns nothing for a status without message nor details.
+func StatusAttrs(s *status.Status, details bool) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if msg := s.Message(); msg != "" {
+		attrs = append(attrs, StatusMessageKey.String(msg))
+	}
+	if !details {
+		return attrs
+	}
+	var types []string
+	for _, detail := range s.Proto().GetDetails() {
+		// The type URL ends with the full name of the type,
+		// type.googleapis.com/google.rpc.BadRequest
+		typeURL := detail.GetTypeUrl()
+		types = append(types, typeURL[strings.LastIndex(typeURL, "/")+1:])
+	}
+	if len(types) > 0 {
+		attrs = append(attrs, StatusDetailsKey.StringSlice(types))
+	}
+	return attrs
+}
+
 // ServerStatus returns the appropriate span status based on gRPC status code
 func ServerStatus(s *status.Status) (codes.Code, string) {
 	// For servers, only codes.Unknown, codes.DeadlineExceeded,
@@ -120,6 +167,49 @@ func ServerAddrAttrs(addr string) []attribute.KeyValue {
 	return attrs
 }
 
+// TargetAddrAttrs returns the server address and port attributes of the
+// logical server named by a gRPC dial target, e.g. "api.example.com" and 443
+// for "dns:///api.example.com:443". Unix socket targets only have an address.
+func TargetAddrAttrs(target string) []attribute.KeyValue {
+	scheme, endpoint, ok := strings.Cut(target, ":")
+	if !ok || strings.ContainsAny(scheme, "[.") {
+		// No scheme, e.g. "localhost:50051" or "[::1]:50051"
+		return ServerAddrAttrs(target)
+	}
+	switch scheme {
+	case "unix", "unix-abstract":
+		path := strings.TrimPrefix(endpoint, "//")
+		if path == "" {
+			return nil
+		}
+		return []attribute.KeyValue{semconv.ServerAddress(path)}
+	}
+	if !strings.HasPrefix(endpoint, "//") {
+		// A host without scheme followed by its port, e.g. "localhost:50051"
+		return ServerAddrAttrs(target)
+	}
+	// scheme://[authority]/endpoint
+	_, endpoint, _ = strings.Cut(endpoint[2:], "/")
+	if endpoint == "" {
+		return nil
+	}
+	return ServerAddrAttrs(endpoint)
+}
+
+// NetworkPeerAttrs extracts the network peer address and port attributes from
+// the address of the connection peer.
+func NetworkPeerAttrs(addr string) []attribute.KeyValue {
+	host, port := splitHostPort(addr)
+	var attrs []attribute.KeyValue
+	if host != "" {
+		attrs = append(attrs, semconv.NetworkPeerAddress(host))
+	}
+	if port > 0 {
+		attrs = append(attrs, semconv.NetworkPeerPort(port))
+	}
+	return attrs
+}
+
 // ClientAddrAttrs extracts client address and port attributes from peer address
 func ClientAddrAttrs(addr string) []attribute.KeyValue {
 	host, port := splitHostPort(addr)
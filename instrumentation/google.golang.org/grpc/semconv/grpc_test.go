@@ -8,8 +8,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	grpc_codes "google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -81,6 +83,30 @@ func TestParseFullMethod(t *testing.T) {
 	}
 }
 
+func TestMessageCountAttrs(t *testing.T) {
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.Int64("rpc.grpc.request.message.count", 1),
+		attribute.Int64("rpc.grpc.response.message.count", 3),
+	}, MessageCountAttrs(1, 3))
+}
+
+func TestStatusAttrs(t *testing.T) {
+	s, err := status.New(grpc_codes.InvalidArgument, "bad order").WithDetails(
+		&errdetails.BadRequest{},
+		&errdetails.RetryInfo{},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("rpc.grpc.status.message", "bad order"),
+	}, StatusAttrs(s, false))
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("rpc.grpc.status.message", "bad order"),
+		attribute.StringSlice("rpc.grpc.status.details", []string{"google.rpc.BadRequest", "google.rpc.RetryInfo"}),
+	}, StatusAttrs(s, true))
+	assert.Empty(t, StatusAttrs(status.New(grpc_codes.OK, ""), true))
+}
+
 func TestServerStatus(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -238,6 +264,51 @@ func TestServerAddrAttrs(t *testing.T) {
 	}
 }
 
+func TestTargetAddrAttrs(t *testing.T) {
+	tests := []struct {
+		target   string
+		expected []attribute.KeyValue
+	}{
+		{
+			target:   "localhost:50051",
+			expected: []attribute.KeyValue{semconv.ServerAddress("localhost"), semconv.ServerPort(50051)},
+		},
+		{
+			target:   "127.0.0.1:8080",
+			expected: []attribute.KeyValue{semconv.ServerAddress("127.0.0.1"), semconv.ServerPort(8080)},
+		},
+		{
+			target:   "[::1]:8080",
+			expected: []attribute.KeyValue{semconv.ServerAddress("::1"), semconv.ServerPort(8080)},
+		},
+		{
+			target:   "dns:///api.example.com:443",
+			expected: []attribute.KeyValue{semconv.ServerAddress("api.example.com"), semconv.ServerPort(443)},
+		},
+		{
+			target:   "dns://8.8.8.8/api.example.com:443",
+			expected: []attribute.KeyValue{semconv.ServerAddress("api.example.com"), semconv.ServerPort(443)},
+		},
+		{
+			target:   "passthrough:///bufnet",
+			expected: []attribute.KeyValue{semconv.ServerAddress("bufnet")},
+		},
+		{
+			target:   "unix:///tmp/grpc.sock",
+			expected: []attribute.KeyValue{semconv.ServerAddress("/tmp/grpc.sock")},
+		},
+		{
+			target: "dns:///",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			assert.Equal(t, tt.expected, TargetAddrAttrs(tt.target))
+		})
+	}
+}
+
 func TestClientAddrAttrs(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -58,3 +59,27 @@ func TestExtract(t *testing.T) {
 	extractedCtx := Extract(ctx, propagator)
 	require.NotNil(t, extractedCtx)
 }
+
+func TestInjectExtract_TraceState(t *testing.T) {
+	propagator := propagation.TraceContext{}
+	traceState, err := trace.ParseTraceState("vendor=sampled:1,other=abc")
+	require.NoError(t, err)
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa},
+		TraceFlags: trace.FlagsSampled,
+		TraceState: traceState,
+	})
+
+	// Client side: inject into outgoing metadata
+	ctx := Inject(trace.ContextWithSpanContext(t.Context(), parent), propagator)
+	md, ok := metadata.FromOutgoingContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, []string{"vendor=sampled:1,other=abc"}, md.Get("tracestate"))
+
+	// Server side: extract from the same metadata received as incoming
+	ctx = Extract(metadata.NewIncomingContext(t.Context(), md), propagator)
+	extracted := trace.SpanContextFromContext(ctx)
+	assert.Equal(t, parent.TraceID(), extracted.TraceID())
+	assert.Equal(t, traceState, extracted.TraceState())
+}
@@ -0,0 +1,38 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"os"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	grpcsemconv "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/google.golang.org/grpc/semconv"
+)
+
+// envPeerAuth records who called the server when set to "true": the address
+// of the connection peer and, over mTLS, the subject of the certificate the
+// client authenticated with, for auditing inbound calls.
+const envPeerAuth = "OTEL_GO_GRPC_SERVER_PEER_AUTH"
+
+// peerAuth is true when envPeerAuth is "true".
+var peerAuth bool
+
+// peerAuthAttrs returns the network.peer.address and network.peer.port of p
+// and, when it authenticated with a TLS client certificate, its
+// tls.client.subject.
+func peerAuthAttrs(p *peer.Peer) []attribute.KeyValue {
+	attrs := grpcsemconv.NetworkPeerAttrs(p.Addr.String())
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.PeerCertificates) > 0 {
+		attrs = append(attrs, semconv.TLSClientSubject(info.State.PeerCertificates[0].Subject.String()))
+	}
+	return attrs
+}
+
+func initPeerAuth() {
+	peerAuth = os.Getenv(envPeerAuth) == "true"
+}
@@ -12,6 +12,7 @@ import (
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
@@ -44,6 +45,9 @@ var (
 	meter      metric.Meter
 	initOnce   sync.Once
 
+	// profileLabels labels the goroutines handling RPCs with their span name
+	profileLabels bool
+
 	// Metrics
 	serverDuration        rpcconv.ServerDuration
 	serverRequestSize     int64Hist
@@ -81,8 +85,8 @@ func initInstrumentation() {
 			instrumentationName,
 			trace.WithInstrumentationVersion(version),
 		)
-		propagator = otel.GetTextMapPropagator()
-		meter = otel.GetMeterProvider().Meter(
+		propagator = runtime.Propagator(instrumentationKey)
+		meter = runtime.Meter(
 			instrumentationName,
 			metric.WithInstrumentationVersion(version),
 			metric.WithSchemaURL(semconv.SchemaURL),
@@ -114,10 +118,9 @@ func initInstrumentation() {
 			logger.Error("failed to create server responses per RPC metric", "error", err)
 		}
 
-		// Start runtime metrics (respects OTEL_GO_ENABLED/DISABLED_INSTRUMENTATIONS)
-		if err := runtime.StartRuntimeMetrics(); err != nil {
-			logger.Error("failed to start runtime metrics", "error", err)
-		}
+		initPeerAuth()
+		initStatusDetails()
+		profileLabels = runtime.ProfileLabelsEnabled()
 
 		logger.Info("gRPC server instrumentation initialized")
 	})
@@ -162,8 +165,13 @@ type gRPCContextKey struct{}
 type gRPCContext struct {
 	inMessages    int64
 	outMessages   int64
+	streaming     bool
 	metricAttrs   []attribute.KeyValue
 	metricAttrSet attribute.Set
+	// spanName labels the goroutine handling the RPC, from Begin until
+	// restoreLabels runs at End, both called on that goroutine
+	spanName      string
+	restoreLabels func()
 }
 
 type serverStatsHandler struct{}
@@ -197,6 +205,7 @@ func (h *serverStatsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo)
 	gctx := &gRPCContext{
 		metricAttrs:   attrs,
 		metricAttrSet: attribute.NewSet(attrs...),
+		spanName:      name,
 	}
 
 	return context.WithValue(ctx, gRPCContextKey{}, gctx)
@@ -209,7 +218,14 @@ func (h *serverStatsHandler) HandleRPC(ctx context.Context, rs stats.RPCStats) {
 
 	switch rs := rs.(type) {
 	case *stats.Begin:
-		// RPC started
+		if gctx != nil {
+			gctx.streaming = rs.IsClientStream || rs.IsServerStream
+			// Attribute CPU time spent in the handler to this span in pprof
+			// profiles
+			if profileLabels {
+				_, gctx.restoreLabels = runtime.StartProfileLabels(ctx, gctx.spanName)
+			}
+		}
 	case *stats.InPayload:
 		if gctx != nil {
 			atomic.AddInt64(&gctx.inMessages, 1)
@@ -229,9 +245,15 @@ func (h *serverStatsHandler) HandleRPC(ctx context.Context, rs stats.RPCStats) {
 		if span.IsRecording() {
 			if p, ok := peer.FromContext(ctx); ok {
 				span.SetAttributes(grpcsemconv.ClientAddrAttrs(p.Addr.String())...)
+				if peerAuth {
+					span.SetAttributes(peerAuthAttrs(p)...)
+				}
 			}
 		}
 	case *stats.End:
+		if gctx != nil && gctx.restoreLabels != nil {
+			defer gctx.restoreLabels()
+		}
 		// End span
 		var s *status.Status
 		var statusAttr attribute.KeyValue
@@ -246,9 +268,24 @@ func (h *serverStatsHandler) HandleRPC(ctx context.Context, rs stats.RPCStats) {
 		if span.IsRecording() {
 			if s != nil {
 				code, msg := grpcsemconv.ServerStatus(s)
+				if rs.Error != nil {
+					// A caller-supplied cancellation cause says more than "context canceled"
+					if cause := runtime.RecordCancelCause(ctx, span); cause != nil && code == codes.Error {
+						msg = cause.Error()
+					}
+				}
 				span.SetStatus(code, msg)
+				// The span status drops the message of the codes that are no
+				// server errors, the attributes keep it
+				if rs.Error != nil {
+					span.SetAttributes(grpcsemconv.StatusAttrs(s, statusDetails)...)
+				}
 			}
 			span.SetAttributes(statusAttr)
+			if gctx != nil && gctx.streaming {
+				span.SetAttributes(grpcsemconv.MessageCountAttrs(
+					atomic.LoadInt64(&gctx.inMessages), atomic.LoadInt64(&gctx.outMessages))...)
+			}
 			span.End()
 		}
 
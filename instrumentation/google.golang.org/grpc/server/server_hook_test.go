@@ -4,22 +4,42 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
 	"net"
+	"runtime/pprof"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 
+	grpcsemconv "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/google.golang.org/grpc/semconv"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
 )
 
 func TestBeforeNewServer(t *testing.T) {
@@ -211,6 +231,65 @@ func TestServerStatsHandler_TagRPC(t *testing.T) {
 	}
 }
 
+func TestServerStatsHandler_CancelCause(t *testing.T) {
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "grpc")
+	initInstrumentation()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	tracer = tp.Tracer(instrumentationName, trace.WithInstrumentationVersion(moduleVersion()))
+
+	handler := newServerStatsHandler()
+	cause := errors.New("batch budget exhausted")
+
+	tests := []struct {
+		name        string
+		ctx         func(context.Context) (context.Context, context.CancelFunc)
+		code        codes.Code
+		description string
+	}{
+		{
+			name: "deadline exceeded",
+			ctx: func(ctx context.Context) (context.Context, context.CancelFunc) {
+				return context.WithDeadlineCause(ctx, time.Now().Add(-time.Second), cause)
+			},
+			code:        codes.Error,
+			description: "batch budget exhausted",
+		},
+		{
+			name: "cancelled",
+			ctx: func(ctx context.Context) (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancelCause(ctx)
+				cancel(cause)
+				return ctx, func() {}
+			},
+			// CANCELLED is not a server error, only the attribute is recorded
+			code: codes.Unset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+			ctx, cancel := tt.ctx(t.Context())
+			defer cancel()
+			newCtx := handler.TagRPC(ctx, &stats.RPCTagInfo{FullMethodName: "/grpc.health.v1.Health/Check"})
+			handler.HandleRPC(newCtx, &stats.End{
+				BeginTime: time.Now().Add(-100 * time.Millisecond),
+				EndTime:   time.Now(),
+				Error:     status.FromContextError(newCtx.Err()).Err(),
+			})
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+			assert.Equal(t, tt.code, spans[0].Status.Code)
+			assert.Equal(t, tt.description, spans[0].Status.Description)
+			assert.Contains(t, spans[0].Attributes, runtime.CancelCauseKey.String("batch budget exhausted"))
+		})
+	}
+}
+
 func TestServerStatsHandler_Integration(t *testing.T) {
 	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "grpc")
 
@@ -344,3 +423,256 @@ func TestServerStatsHandler_OTELExporterFiltering(t *testing.T) {
 		})
 	}
 }
+
+func TestServerStatsHandler_Bufconn(t *testing.T) {
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "grpc")
+	initInstrumentation()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	tracer = tp.Tracer(instrumentationName, trace.WithInstrumentationVersion(moduleVersion()))
+
+	// Serve the health service in process, with the options the hook returns
+	opts := []grpc.ServerOption{}
+	ictx := hooktest.NewMockHookContext(opts)
+	BeforeNewServer(ictx, opts...)
+	server := grpc.NewServer(ictx.GetParam(0).([]grpc.ServerOption)...)
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	client := healthpb.NewHealthClient(conn)
+
+	// waitForSpan returns the span of the RPC, which ends after the client
+	// got its response
+	waitForSpan := func(t *testing.T) sdktrace.ReadOnlySpan {
+		t.Helper()
+		var spans tracetest.SpanStubs
+		require.Eventually(t, func() bool {
+			spans = exporter.GetSpans()
+			return len(spans) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+		exporter.Reset()
+		return spans.Snapshots()[0]
+	}
+	attrs := func(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+		m := make(map[attribute.Key]attribute.Value)
+		for _, attr := range span.Attributes() {
+			m[attr.Key] = attr.Value
+		}
+		return m
+	}
+
+	t.Run("unary", func(t *testing.T) {
+		_, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+
+		span := waitForSpan(t)
+		assert.Equal(t, "grpc.health.v1.Health/Check", span.Name())
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+		assert.Equal(t, codes.Unset, span.Status().Code)
+		got := attrs(span)
+		assert.Equal(t, "grpc", got[semconv.RPCSystemKey].AsString())
+		assert.Equal(t, "grpc.health.v1.Health", got[semconv.RPCServiceKey].AsString())
+		assert.Equal(t, "Check", got[semconv.RPCMethodKey].AsString())
+		assert.Equal(t, int64(grpccodes.OK), got[semconv.RPCGRPCStatusCodeKey].AsInt64())
+		assert.NotContains(t, got, grpcsemconv.RequestMessageCountKey, "unary RPCs carry one message each way")
+	})
+
+	t.Run("unary error", func(t *testing.T) {
+		_, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{Service: "unknown"})
+		require.Equal(t, grpccodes.NotFound, status.Code(err))
+
+		span := waitForSpan(t)
+		assert.Equal(t, codes.Unset, span.Status().Code, "NOT_FOUND is not a server error")
+		assert.Equal(t, int64(grpccodes.NotFound), attrs(span)[semconv.RPCGRPCStatusCodeKey].AsInt64())
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+		healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+		resp, err = stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+		cancel()
+
+		span := waitForSpan(t)
+		assert.Equal(t, "grpc.health.v1.Health/Watch", span.Name())
+		got := attrs(span)
+		assert.Equal(t, int64(1), got[grpcsemconv.RequestMessageCountKey].AsInt64())
+		assert.Equal(t, int64(2), got[grpcsemconv.ResponseMessageCountKey].AsInt64())
+	})
+}
+
+func TestServerStatsHandler_PeerAuth(t *testing.T) {
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "grpc")
+	initInstrumentation()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	tracer = tp.Tracer(instrumentationName)
+
+	handler := newServerStatsHandler()
+	caller := &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 51234},
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "billing", Organization: []string{"Acme"}}}},
+		}},
+	}
+
+	serve := func() []attribute.KeyValue {
+		exporter.Reset()
+		ctx := peer.NewContext(t.Context(), caller)
+		ctx = handler.TagRPC(ctx, &stats.RPCTagInfo{FullMethodName: "/grpc.health.v1.Health/Check"})
+		handler.HandleRPC(ctx, &stats.OutHeader{})
+		handler.HandleRPC(ctx, &stats.End{BeginTime: time.Now(), EndTime: time.Now()})
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		return spans[0].Attributes
+	}
+
+	peerAuth = false
+	attrs := serve()
+	assert.NotContains(t, attrs, semconv.NetworkPeerAddress("10.0.0.7"))
+
+	peerAuth = true
+	t.Cleanup(func() { peerAuth = false })
+	attrs = serve()
+	assert.Contains(t, attrs, semconv.NetworkPeerAddress("10.0.0.7"))
+	assert.Contains(t, attrs, semconv.NetworkPeerPort(51234))
+	assert.Contains(t, attrs, semconv.TLSClientSubject("CN=billing,O=Acme"))
+}
+
+// failingHealthServer fails every check with the status err.
+type failingHealthServer struct {
+	healthpb.UnimplementedHealthServer
+	err error
+}
+
+func (s *failingHealthServer) Check(context.Context, *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	return nil, s.err
+}
+
+func TestServerStatsHandler_StatusDetails(t *testing.T) {
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "grpc")
+	initInstrumentation()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	tracer = tp.Tracer(instrumentationName)
+
+	st, err := status.New(grpccodes.InvalidArgument, "unknown service").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "service"}},
+	})
+	require.NoError(t, err)
+
+	opts := []grpc.ServerOption{}
+	ictx := hooktest.NewMockHookContext(opts)
+	BeforeNewServer(ictx, opts...)
+	server := grpc.NewServer(ictx.GetParam(0).([]grpc.ServerOption)...)
+	healthpb.RegisterHealthServer(server, &failingHealthServer{err: st.Err()})
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	client := healthpb.NewHealthClient(conn)
+
+	check := func(t *testing.T) []attribute.KeyValue {
+		t.Helper()
+		exporter.Reset()
+		_, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{})
+		require.Equal(t, grpccodes.InvalidArgument, status.Code(err))
+		var spans tracetest.SpanStubs
+		require.Eventually(t, func() bool {
+			spans = exporter.GetSpans()
+			return len(spans) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, codes.Unset, spans[0].Status.Code, "INVALID_ARGUMENT is not a server error")
+		return spans[0].Attributes
+	}
+
+	attrs := check(t)
+	assert.Contains(t, attrs, semconv.RPCGRPCStatusCodeKey.Int(int(grpccodes.InvalidArgument)))
+	assert.Contains(t, attrs, grpcsemconv.StatusMessageKey.String("unknown service"))
+	for _, attr := range attrs {
+		assert.NotEqual(t, grpcsemconv.StatusDetailsKey, attr.Key, "details are off by default")
+	}
+
+	statusDetails = true
+	t.Cleanup(func() { statusDetails = false })
+	attrs = check(t)
+	assert.Contains(t, attrs, grpcsemconv.StatusMessageKey.String("unknown service"))
+	assert.Contains(t, attrs, grpcsemconv.StatusDetailsKey.StringSlice([]string{"google.rpc.BadRequest"}))
+}
+
+// profilingHealthServer records the goroutine profile, with its labels, taken
+// while handling Check.
+type profilingHealthServer struct {
+	healthpb.UnimplementedHealthServer
+	profile bytes.Buffer
+}
+
+func (s *profilingHealthServer) Check(context.Context, *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	s.profile.Reset()
+	if err := pprof.Lookup("goroutine").WriteTo(&s.profile, 1); err != nil {
+		return nil, err
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func TestServerStatsHandler_ProfileLabels(t *testing.T) {
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "grpc")
+	initInstrumentation()
+	profileLabels = true
+	t.Cleanup(func() { profileLabels = false })
+
+	opts := []grpc.ServerOption{}
+	ictx := hooktest.NewMockHookContext(opts)
+	BeforeNewServer(ictx, opts...)
+	server := grpc.NewServer(ictx.GetParam(0).([]grpc.ServerOption)...)
+	profiling := &profilingHealthServer{}
+	healthpb.RegisterHealthServer(server, profiling)
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_, err = healthpb.NewHealthClient(conn).Check(t.Context(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Contains(t, profiling.profile.String(),
+		`"`+runtime.SpanNameProfileLabel+`":"grpc.health.v1.Health/Check"`)
+}
@@ -0,0 +1,18 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import "os"
+
+// envStatusDetails records the types of the details attached to the status
+// a handler fails with when set to "true", e.g. google.rpc.BadRequest, to tell
+// apart failures sharing a code without recording the details themselves.
+const envStatusDetails = "OTEL_GO_GRPC_SERVER_STATUS_DETAILS"
+
+// statusDetails is true when envStatusDetails is "true".
+var statusDetails bool
+
+func initStatusDetails() {
+	statusDetails = os.Getenv(envStatusDetails) == "true"
+}
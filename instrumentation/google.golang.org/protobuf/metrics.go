@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package protobuf
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+)
+
+const (
+	messageSizeMetric       = "protobuf.message.size"
+	operationDurationMetric = "protobuf.operation.duration"
+
+	operationKey   = attribute.Key("protobuf.operation")
+	messageTypeKey = attribute.Key("protobuf.message.type")
+
+	operationMarshal   = "marshal"
+	operationUnmarshal = "unmarshal"
+)
+
+var (
+	messageSize       metric.Int64Histogram
+	operationDuration metric.Float64Histogram
+)
+
+// initSerializationMetrics creates the serialization instruments.
+func initSerializationMetrics(version string) {
+	messageSize, operationDuration = nil, nil
+	meter := runtime.Meter(
+		instrumentationName,
+		metric.WithInstrumentationVersion(version),
+	)
+	size, err := meter.Int64Histogram(
+		messageSizeMetric,
+		metric.WithUnit("By"),
+		metric.WithDescription("Serialized size of the marshaled and unmarshaled protobuf messages."),
+	)
+	if err != nil {
+		logger.Error("failed to create message size histogram", "error", err)
+		return
+	}
+	duration, err := meter.Float64Histogram(
+		operationDurationMetric,
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of protobuf marshal and unmarshal operations."),
+	)
+	if err != nil {
+		logger.Error("failed to create operation duration histogram", "error", err)
+		return
+	}
+	messageSize, operationDuration = size, duration
+}
+
+// record records one operation on a message of type messageType. The size is
+// only recorded when the operation succeeded.
+func record(operation, messageType string, size int, elapsed time.Duration, err error) {
+	if messageSize == nil {
+		return
+	}
+	ctx := context.Background()
+	attrs := []attribute.KeyValue{
+		operationKey.String(operation),
+		messageTypeKey.String(messageType),
+	}
+	if err == nil {
+		messageSize.Record(ctx, int64(size), metric.WithAttributes(attrs...))
+	} else {
+		attrs = append(attrs, semconv.ErrorTypeKey.String(fmt.Sprintf("%T", err)))
+	}
+	operationDuration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(attrs...))
+}
@@ -0,0 +1,164 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package protobuf
+
+import (
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+)
+
+const (
+	instrumentationName = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/google.golang.org/protobuf"
+	instrumentationKey  = "protobuf"
+
+	// envProtobufMetrics enables the serialization metrics when set to
+	// "true". Messages are marshaled everywhere, so nothing is recorded by
+	// default.
+	envProtobufMetrics = "OTEL_GO_PROTOBUF_METRICS"
+
+	// otlpPackage prefixes the messages of the OTLP exporters, which are not
+	// recorded: exporting the metrics would otherwise record more of them.
+	otlpPackage = "opentelemetry.proto."
+
+	// Keys of the data carried from the Before to the After hooks.
+	startKeyData       = "start"
+	messageTypeKeyData = "message_type"
+	sizeKeyData        = "size"
+)
+
+var (
+	logger   = runtime.Logger()
+	initOnce sync.Once
+
+	enabledOnce sync.Once
+	enabled     bool
+)
+
+// moduleVersion extracts the version from the Go module system.
+// Falls back to "dev" if version cannot be determined.
+func moduleVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+
+	// Return the main module version
+	if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		return bi.Main.Version
+	}
+
+	return "dev"
+}
+
+func initInstrumentation() {
+	initOnce.Do(func() {
+		version := moduleVersion()
+		if err := runtime.SetupOTelSDK(
+			"go.opentelemetry.io/compile-instrumentation/google.golang.org/protobuf",
+			version,
+		); err != nil {
+			logger.Error("failed to setup OTel SDK", "error", err)
+		}
+		initSerializationMetrics(version)
+		logger.Info("protobuf serialization instrumentation initialized")
+	})
+}
+
+// metricsEnabled reports, once, whether the metrics are enabled. The hooks run
+// for every message of the process, so the disabled path is kept to this
+// check.
+func metricsEnabled() bool {
+	enabledOnce.Do(func() {
+		enabled = os.Getenv(envProtobufMetrics) == "true" && runtime.Instrumented(instrumentationKey)
+	})
+	return enabled
+}
+
+// messageType returns the full name of the message, or "" when it is not
+// recorded.
+func messageType(m proto.Message) string {
+	if m == nil {
+		return ""
+	}
+	name := string(m.ProtoReflect().Descriptor().FullName())
+	if strings.HasPrefix(name, otlpPackage) {
+		return ""
+	}
+	return name
+}
+
+// start keeps what the After hook needs to record the operation on m. It
+// returns false when the operation is not recorded.
+func start(ictx hook.HookContext, m proto.Message) bool {
+	if !metricsEnabled() {
+		return false
+	}
+	name := messageType(m)
+	if name == "" {
+		return false
+	}
+	initInstrumentation()
+	ictx.SetKeyData(messageTypeKeyData, name)
+	ictx.SetKeyData(startKeyData, time.Now())
+	return true
+}
+
+// finish records the operation started by start, size being the serialized
+// size of the message.
+func finish(ictx hook.HookContext, operation string, size int, err error) {
+	began, ok := ictx.GetKeyData(startKeyData).(time.Time)
+	if !ok {
+		return
+	}
+	name, _ := ictx.GetKeyData(messageTypeKeyData).(string)
+	record(operation, name, size, time.Since(began), err)
+}
+
+// BeforeMarshal starts timing proto.Marshal.
+func BeforeMarshal(ictx hook.HookContext, m proto.Message) {
+	start(ictx, m)
+}
+
+// AfterMarshal records the size of the encoded message and the time it took.
+func AfterMarshal(ictx hook.HookContext, b []byte, err error) {
+	finish(ictx, operationMarshal, len(b), err)
+}
+
+// BeforeMarshalAppend starts timing (proto.MarshalOptions).MarshalAppend,
+// keeping the length of the buffer appended to.
+func BeforeMarshalAppend(ictx hook.HookContext, _ proto.MarshalOptions, b []byte, m proto.Message) {
+	if start(ictx, m) {
+		ictx.SetKeyData(sizeKeyData, len(b))
+	}
+}
+
+// AfterMarshalAppend records the size of the appended encoding and the time
+// it took.
+func AfterMarshalAppend(ictx hook.HookContext, b []byte, err error) {
+	prefix, _ := ictx.GetKeyData(sizeKeyData).(int)
+	finish(ictx, operationMarshal, len(b)-prefix, err)
+}
+
+// BeforeUnmarshal starts timing proto.Unmarshal, keeping the size of the
+// encoded message.
+func BeforeUnmarshal(ictx hook.HookContext, b []byte, m proto.Message) {
+	if start(ictx, m) {
+		ictx.SetKeyData(sizeKeyData, len(b))
+	}
+}
+
+// AfterUnmarshal records the size of the decoded message and the time it
+// took.
+func AfterUnmarshal(ictx hook.HookContext, err error) {
+	size, _ := ictx.GetKeyData(sizeKeyData).(int)
+	finish(ictx, operationUnmarshal, size, err)
+}
@@ -0,0 +1,170 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package protobuf
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+)
+
+func setupEnabled(t *testing.T, value string) {
+	t.Helper()
+	t.Setenv(envProtobufMetrics, value)
+	enabledOnce = *new(sync.Once)
+	t.Cleanup(func() { enabledOnce = *new(sync.Once) })
+}
+
+func setupTestMeter(t *testing.T) *sdkmetric.ManualReader {
+	t.Helper()
+	setupEnabled(t, "true")
+	initInstrumentation()
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { _ = mp.Shutdown(context.Background()) })
+	otel.SetMeterProvider(mp)
+	initSerializationMetrics("")
+	return reader
+}
+
+func collect(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Metrics {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+	metrics := make(map[string]metricdata.Metrics)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m
+		}
+	}
+	return metrics
+}
+
+func sizePoint(t *testing.T, metrics map[string]metricdata.Metrics, operation string) metricdata.HistogramDataPoint[int64] {
+	t.Helper()
+	size, ok := metrics[messageSizeMetric].Data.(metricdata.Histogram[int64])
+	require.True(t, ok)
+	for _, dp := range size.DataPoints {
+		if v, _ := dp.Attributes.Value(operationKey); v.AsString() == operation {
+			return dp
+		}
+	}
+	require.Failf(t, "no data point", "operation %s", operation)
+	return metricdata.HistogramDataPoint[int64]{}
+}
+
+func TestMarshal_RecordsSize(t *testing.T) {
+	reader := setupTestMeter(t)
+
+	msg := wrapperspb.String("a message large enough to be measured")
+	ictx := hooktest.NewMockHookContext(msg)
+	BeforeMarshal(ictx, msg)
+	b, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	original := bytes.Clone(b)
+	AfterMarshal(ictx, b, nil)
+
+	assert.Equal(t, original, b, "the marshaled bytes are left untouched")
+	metrics := collect(t, reader)
+	dp := sizePoint(t, metrics, operationMarshal)
+	assert.Equal(t, uint64(1), dp.Count)
+	assert.Equal(t, int64(len(b)), dp.Sum, "the size is that of the marshaled bytes")
+	name, ok := dp.Attributes.Value(messageTypeKey)
+	require.True(t, ok)
+	assert.Equal(t, "google.protobuf.StringValue", name.AsString())
+
+	duration, ok := metrics[operationDurationMetric].Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, duration.DataPoints, 1)
+	assert.Equal(t, uint64(1), duration.DataPoints[0].Count)
+}
+
+func TestMarshalAppend_RecordsAppendedSize(t *testing.T) {
+	reader := setupTestMeter(t)
+
+	msg := wrapperspb.Int64(1 << 40)
+	prefix := []byte("header")
+	opts := proto.MarshalOptions{}
+	ictx := hooktest.NewMockHookContext(opts, prefix, msg)
+	BeforeMarshalAppend(ictx, opts, prefix, msg)
+	b, err := opts.MarshalAppend(prefix, msg)
+	require.NoError(t, err)
+	AfterMarshalAppend(ictx, b, nil)
+
+	assert.Equal(t, []byte("header"), b[:len(prefix)])
+	dp := sizePoint(t, collect(t, reader), operationMarshal)
+	assert.Equal(t, int64(proto.Size(msg)), dp.Sum, "only the appended encoding is counted")
+}
+
+func TestUnmarshal_RecordsSize(t *testing.T) {
+	reader := setupTestMeter(t)
+
+	b, err := proto.Marshal(wrapperspb.String("decoded"))
+	require.NoError(t, err)
+	original := bytes.Clone(b)
+
+	var msg wrapperspb.StringValue
+	ictx := hooktest.NewMockHookContext(b, &msg)
+	BeforeUnmarshal(ictx, b, &msg)
+	require.NoError(t, proto.Unmarshal(b, &msg))
+	AfterUnmarshal(ictx, nil)
+
+	assert.Equal(t, original, b, "the encoded bytes are left untouched")
+	assert.Equal(t, "decoded", msg.GetValue())
+	dp := sizePoint(t, collect(t, reader), operationUnmarshal)
+	assert.Equal(t, int64(len(b)), dp.Sum)
+}
+
+func TestUnmarshal_ErrorSkipsSize(t *testing.T) {
+	reader := setupTestMeter(t)
+
+	b := []byte{0xff, 0xff, 0xff}
+	var msg structpb.Struct
+	ictx := hooktest.NewMockHookContext(b, &msg)
+	BeforeUnmarshal(ictx, b, &msg)
+	err := proto.Unmarshal(b, &msg)
+	require.Error(t, err)
+	AfterUnmarshal(ictx, err)
+
+	metrics := collect(t, reader)
+	size, _ := metrics[messageSizeMetric].Data.(metricdata.Histogram[int64])
+	assert.Empty(t, size.DataPoints, "no size for a message that failed to decode")
+	duration, ok := metrics[operationDurationMetric].Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, duration.DataPoints, 1)
+	_, hasErrorType := duration.DataPoints[0].Attributes.Value("error.type")
+	assert.True(t, hasErrorType)
+}
+
+func TestMarshal_NotEnabled(t *testing.T) {
+	setupEnabled(t, "")
+
+	msg := wrapperspb.Bool(true)
+	ictx := hooktest.NewMockHookContext(msg)
+	BeforeMarshal(ictx, msg)
+	assert.False(t, ictx.HasKeyData(startKeyData))
+}
+
+func TestMarshal_SkipsNilAndOTLPMessages(t *testing.T) {
+	setupTestMeter(t)
+
+	for _, msg := range []proto.Message{nil, &collectortrace.ExportTraceServiceRequest{}} {
+		ictx := hooktest.NewMockHookContext(msg)
+		BeforeMarshal(ictx, msg)
+		assert.False(t, ictx.HasKeyData(startKeyData), "%T", msg)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gormdb
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/gorm.io/gorm/semconv"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+)
+
+const (
+	callbackPrefix = "otel:"
+
+	// Keys of the statement instance settings carrying the span from the
+	// before to the after callback.
+	spanInstanceKey      = "otel:span"
+	operationInstanceKey = "otel:operation"
+	parentInstanceKey    = "otel:parent_context"
+)
+
+// AfterOpen registers the callbacks tracing the statements run through the
+// opened *gorm.DB.
+func AfterOpen(ictx hook.HookContext, db *gorm.DB, err error) {
+	if !gormEnabler.Enable() {
+		logger.Debug("GORM instrumentation disabled")
+		return
+	}
+	if err != nil || db == nil || db.Dialector == nil {
+		return
+	}
+	initInstrumentation()
+
+	if err := registerCallbacks(db, newConnInfo(db.Dialector)); err != nil {
+		logger.Error("failed to register GORM callbacks", "error", err)
+	}
+}
+
+// registerCallbacks wraps the main callback of every GORM processor. The
+// operation of Row and Raw statements is only known from their SQL, once it
+// has run.
+func registerCallbacks(db *gorm.DB, conn connInfo) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register(callbackPrefix+"before_create", beforeStatement("INSERT")),
+		cb.Create().After("gorm:create").Register(callbackPrefix+"after_create", conn.afterStatement),
+		cb.Query().Before("gorm:query").Register(callbackPrefix+"before_query", beforeStatement("SELECT")),
+		cb.Query().After("gorm:query").Register(callbackPrefix+"after_query", conn.afterStatement),
+		cb.Update().Before("gorm:update").Register(callbackPrefix+"before_update", beforeStatement("UPDATE")),
+		cb.Update().After("gorm:update").Register(callbackPrefix+"after_update", conn.afterStatement),
+		cb.Delete().Before("gorm:delete").Register(callbackPrefix+"before_delete", beforeStatement("DELETE")),
+		cb.Delete().After("gorm:delete").Register(callbackPrefix+"after_delete", conn.afterStatement),
+		cb.Row().Before("gorm:row").Register(callbackPrefix+"before_row", beforeStatement("")),
+		cb.Row().After("gorm:row").Register(callbackPrefix+"after_row", conn.afterStatement),
+		cb.Raw().Before("gorm:raw").Register(callbackPrefix+"before_raw", beforeStatement("")),
+		cb.Raw().After("gorm:raw").Register(callbackPrefix+"after_raw", conn.afterStatement),
+	)
+}
+
+// beforeStatement starts the client span of a statement and makes it the
+// context of the statement, so that the database/sql spans nest under it.
+func beforeStatement(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if !gormEnabler.Enable() || db.Statement == nil {
+			return
+		}
+		parent := db.Statement.Context
+		if parent == nil {
+			parent = context.Background()
+		}
+		// Named after the operation for now, the table is set once known
+		ctx, span := tracer.Start(parent, operation, trace.WithSpanKind(trace.SpanKindClient))
+		db.Statement.Context = ctx
+		db.InstanceSet(spanInstanceKey, span)
+		db.InstanceSet(operationInstanceKey, operation)
+		db.InstanceSet(parentInstanceKey, parent)
+	}
+}
+
+// afterStatement completes and ends the span started by beforeStatement.
+func (c connInfo) afterStatement(db *gorm.DB) {
+	if db.Statement == nil {
+		return
+	}
+	v, _ := db.InstanceGet(spanInstanceKey)
+	span, ok := v.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+	if parent, ok := db.InstanceGet(parentInstanceKey); ok {
+		db.Statement.Context, _ = parent.(context.Context)
+	}
+
+	sql := db.Statement.SQL.String()
+	v, _ = db.InstanceGet(operationInstanceKey)
+	operation, _ := v.(string)
+	if operation == "" {
+		operation = semconv.OperationName(sql)
+	}
+	req := semconv.GormRequest{
+		Operation: operation,
+		Table:     db.Statement.Table,
+		Sql:       sql,
+		Dialector: c.dialector,
+		Endpoint:  c.endpoint,
+		DbName:    c.dbName,
+	}
+	span.SetName(semconv.GormSpanName(req))
+	span.SetAttributes(semconv.GormClientRequestTraceAttrs(req)...)
+	semconv.AddDbQueryEvent(span, req)
+
+	// A First or Take without rows is not a failed statement
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
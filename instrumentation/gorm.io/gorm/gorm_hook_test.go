@@ -0,0 +1,177 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gormdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+)
+
+type Product struct {
+	ID    uint
+	Code  string
+	Price uint
+}
+
+func setupTestTracer(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "gorm")
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+	initInstrumentation()
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	otel.SetTracerProvider(tp)
+	tracer = tp.Tracer(instrumentationName)
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return sr
+}
+
+// openDryRun opens a database that builds statements without running them,
+// and instruments it as the Open hook would.
+func openDryRun(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+	AfterOpen(hooktest.NewMockHookContext(), db, nil)
+	return db
+}
+
+func TestQuery_TableName(t *testing.T) {
+	sr := setupTestTracer(t)
+	db := openDryRun(t)
+
+	parentCtx, parent := tracer.Start(context.Background(), "parent")
+	var products []Product
+	require.NoError(t, db.WithContext(parentCtx).Where("code = ?", "D42").Find(&products).Error)
+	parent.End()
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "SELECT products", span.Name())
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	assert.Contains(t, span.Attributes(), semconv.DBOperationName("SELECT"))
+	assert.Contains(t, span.Attributes(), semconv.DBCollectionName("products"))
+	assert.Contains(t, span.Attributes(), semconv.DBQueryText("SELECT * FROM `products` WHERE code = ?"))
+	assert.Contains(t, span.Attributes(), semconv.DBSystemNameOtherSQL)
+	assert.Equal(t, codes.Unset, span.Status().Code)
+}
+
+func TestCreateUpdateDelete(t *testing.T) {
+	sr := setupTestTracer(t)
+	db := openDryRun(t)
+
+	product := Product{ID: 1, Code: "D42", Price: 100}
+	require.NoError(t, db.Create(&product).Error)
+	require.NoError(t, db.Model(&product).Update("price", 200).Error)
+	require.NoError(t, db.Delete(&product).Error)
+
+	var names []string
+	for _, span := range sr.Ended() {
+		names = append(names, span.Name())
+	}
+	assert.Equal(t, []string{"INSERT products", "UPDATE products", "DELETE products"}, names)
+}
+
+func TestRaw_OperationFromSQL(t *testing.T) {
+	sr := setupTestTracer(t)
+	db := openDryRun(t)
+
+	require.NoError(t, db.Exec("VACUUM").Error)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "VACUUM", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), semconv.DBOperationName("VACUUM"))
+}
+
+func TestStatementError(t *testing.T) {
+	sr := setupTestTracer(t)
+	db := openDryRun(t)
+
+	boom := errors.New("boom")
+	require.NoError(t, db.Callback().Query().Before("gorm:query").Register("test:fail", func(db *gorm.DB) {
+		_ = db.AddError(boom)
+	}))
+	require.ErrorIs(t, db.Find(&[]Product{}).Error, boom)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "boom", spans[0].Status().Description)
+}
+
+func TestRecordNotFound(t *testing.T) {
+	sr := setupTestTracer(t)
+	db := openDryRun(t)
+
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:not_found", func(db *gorm.DB) {
+		_ = db.AddError(gorm.ErrRecordNotFound)
+	}))
+	require.ErrorIs(t, db.First(&Product{}).Error, gorm.ErrRecordNotFound)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+}
+
+func TestDisabled(t *testing.T) {
+	sr := setupTestTracer(t)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "")
+	t.Setenv("OTEL_GO_DISABLED_INSTRUMENTATIONS", "gorm")
+	db := openDryRun(t)
+
+	require.NoError(t, db.Find(&[]Product{}).Error)
+	assert.Empty(t, sr.Ended())
+}
+
+type dsnDialector struct {
+	tests.DummyDialector
+	DSN string
+}
+
+func (dsnDialector) Name() string { return "mysql" }
+
+type dialectorConfig struct {
+	DSN string
+}
+
+type configDialector struct {
+	tests.DummyDialector
+	*dialectorConfig
+}
+
+func (configDialector) Name() string { return "postgres" }
+
+func TestNewConnInfo(t *testing.T) {
+	assert.Equal(t, connInfo{
+		dialector: "mysql",
+		endpoint:  "127.0.0.1:3306",
+		dbName:    "shop",
+	}, newConnInfo(dsnDialector{DSN: "user:pass@tcp(127.0.0.1:3306)/shop?parseTime=true"}))
+	assert.Equal(t, connInfo{
+		dialector: "postgres",
+		endpoint:  "db.internal:5432",
+		dbName:    "orders",
+	}, newConnInfo(&configDialector{dialectorConfig: &dialectorConfig{DSN: "postgres://u:p@db.internal:5432/orders"}}))
+	assert.Equal(t, connInfo{dialector: "postgres"}, newConnInfo(configDialector{}), "nil config")
+	assert.Equal(t, connInfo{dialector: "dummy"}, newConnInfo(tests.DummyDialector{}))
+}
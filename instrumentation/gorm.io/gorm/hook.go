@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gormdb
+
+import (
+	"reflect"
+	"runtime/debug"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/database/sql/dsnparse"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+)
+
+const (
+	instrumentationName = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/gorm.io/gorm"
+	instrumentationKey  = "GORM"
+)
+
+var (
+	logger   = runtime.Logger()
+	tracer   trace.Tracer
+	initOnce sync.Once
+)
+
+// gormClientEnabler controls whether GORM instrumentation is enabled
+type gormClientEnabler struct{}
+
+func (g gormClientEnabler) Enable() bool {
+	return runtime.Instrumented(instrumentationKey)
+}
+
+var gormEnabler = gormClientEnabler{}
+
+// moduleVersion extracts the version from the Go module system.
+// Falls back to "dev" if version cannot be determined.
+func moduleVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+
+	// Return the main module version
+	if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		return bi.Main.Version
+	}
+
+	return "dev"
+}
+
+func initInstrumentation() {
+	initOnce.Do(func() {
+		version := moduleVersion()
+		if err := runtime.SetupOTelSDK(
+			"go.opentelemetry.io/compile-instrumentation/gorm.io/gorm",
+			version,
+		); err != nil {
+			logger.Error("failed to setup OTel SDK", "error", err)
+		}
+		tracer = otel.GetTracerProvider().Tracer(
+			instrumentationName,
+			trace.WithInstrumentationVersion(version),
+		)
+
+		logger.Info("GORM instrumentation initialized")
+	})
+}
+
+// connInfo is the connection metadata of a *gorm.DB, resolved once when it is
+// opened.
+type connInfo struct {
+	dialector string
+	endpoint  string
+	dbName    string
+}
+
+func newConnInfo(d gorm.Dialector) connInfo {
+	conn := connInfo{dialector: d.Name()}
+	// Some parsers fall back to the driver defaults, e.g. localhost:5432 for
+	// postgres, which would be a guess for dialectors without a DSN
+	if dsn := dialectorDSN(d); dsn != "" {
+		info := dsnparse.ParseDSN(conn.dialector, dsn)
+		conn.endpoint, conn.dbName = info.Addr(), info.DBName
+	}
+	return conn
+}
+
+// dialectorDSN returns the data source name of d. The gorm.io/driver
+// dialectors keep it in a DSN field, either their own (sqlite) or that of an
+// embedded *Config (mysql, postgres, sqlserver). It returns "" for dialectors
+// built from an existing connection or without such a field.
+func dialectorDSN(d gorm.Dialector) string {
+	v := reflect.Indirect(reflect.ValueOf(d))
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	field, ok := v.Type().FieldByName("DSN")
+	if !ok {
+		return ""
+	}
+	// FieldByIndexErr fails rather than panics on a nil embedded *Config
+	dsn, err := v.FieldByIndexErr(field.Index)
+	if err != nil || dsn.Kind() != reflect.String {
+		return ""
+	}
+	return dsn.String()
+}
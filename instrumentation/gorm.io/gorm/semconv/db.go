@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package semconv
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	sqlsemconv "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/database/sql/semconv"
+)
+
+// GormRequest describes a statement GORM ran.
+type GormRequest struct {
+	// Operation is the SQL operation, e.g. "SELECT".
+	Operation string
+	// Table is the table of the model the statement operates on, if any.
+	Table string
+	// Sql is the statement as GORM built it, with placeholders for its
+	// parameters.
+	Sql string
+	// Dialector is the name of the GORM dialector, e.g. "postgres".
+	Dialector string
+	Endpoint  string
+	DbName    string
+}
+
+// OperationName returns the upper-cased first word of query, the operation of
+// the statements run through Row and Raw.
+func OperationName(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
+
+// GormSpanName returns "{operation} {table}", or only the operation when the
+// statement has no model.
+func GormSpanName(req GormRequest) string {
+	if req.Table == "" {
+		return req.Operation
+	}
+	return req.Operation + " " + req.Table
+}
+
+func GormClientRequestTraceAttrs(req GormRequest) []attribute.KeyValue {
+	host, portStr, err := net.SplitHostPort(req.Endpoint)
+	if err != nil {
+		host = req.Endpoint
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.DBOperationName(req.Operation),
+		semconv.DBNamespace(req.DbName),
+		dbSystemName(req.Dialector),
+	}
+	if req.Table != "" {
+		attrs = append(attrs, semconv.DBCollectionName(req.Table))
+	}
+	if req.Sql != "" && !sqlsemconv.StatementAsEvent() {
+		attrs = append(attrs, semconv.DBQueryText(req.Sql))
+	}
+	if host != "" {
+		attrs = append(attrs, semconv.ServerAddress(host))
+	}
+	if err == nil {
+		if port, convErr := strconv.Atoi(portStr); convErr == nil && port > 0 {
+			attrs = append(attrs, semconv.ServerPort(port))
+		}
+	}
+
+	return attrs
+}
+
+// AddDbQueryEvent records the statement of req as a span event when the
+// database/sql instrumentation does, as GormClientRequestTraceAttrs leaves it
+// out then.
+func AddDbQueryEvent(span trace.Span, req GormRequest) {
+	if req.Sql == "" || !sqlsemconv.StatementAsEvent() {
+		return
+	}
+	span.AddEvent(sqlsemconv.QueryEventName, trace.WithAttributes(semconv.DBQueryText(req.Sql)))
+}
+
+func dbSystemName(dialector string) attribute.KeyValue {
+	switch dialector {
+	case "mysql":
+		return semconv.DBSystemNameMySQL
+	case "postgres":
+		return semconv.DBSystemNamePostgreSQL
+	case "sqlite":
+		return semconv.DBSystemNameSQLite
+	case "sqlserver":
+		return semconv.DBSystemNameMicrosoftSQLServer
+	default:
+		return semconv.DBSystemNameOtherSQL
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package semconv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+
+	sqlsemconv "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/database/sql/semconv"
+)
+
+func TestGormClientRequestTraceAttrs(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      GormRequest
+		expected map[string]interface{}
+	}{
+		{
+			name: "select with table",
+			req: GormRequest{
+				Operation: "SELECT",
+				Table:     "users",
+				Sql:       "SELECT * FROM `users` WHERE `users`.`id` = ?",
+				Dialector: "mysql",
+				Endpoint:  "127.0.0.1:3306",
+				DbName:    "testdb",
+			},
+			expected: map[string]interface{}{
+				"db.system.name":     "mysql",
+				"db.operation.name":  "SELECT",
+				"db.collection.name": "users",
+				"db.namespace":       "testdb",
+				"db.query.text":      "SELECT * FROM `users` WHERE `users`.`id` = ?",
+				"server.address":     "127.0.0.1",
+				"server.port":        int64(3306),
+			},
+		},
+		{
+			name: "postgres insert",
+			req: GormRequest{
+				Operation: "INSERT",
+				Table:     "orders",
+				Sql:       `INSERT INTO "orders" ("amount") VALUES ($1)`,
+				Dialector: "postgres",
+				Endpoint:  "10.0.0.1:5432",
+				DbName:    "shop",
+			},
+			expected: map[string]interface{}{
+				"db.system.name":     "postgresql",
+				"db.operation.name":  "INSERT",
+				"db.collection.name": "orders",
+				"db.namespace":       "shop",
+				"db.query.text":      `INSERT INTO "orders" ("amount") VALUES ($1)`,
+				"server.address":     "10.0.0.1",
+				"server.port":        int64(5432),
+			},
+		},
+		{
+			name: "sqlite raw without table",
+			req: GormRequest{
+				Operation: "PRAGMA",
+				Sql:       "PRAGMA foreign_keys = ON",
+				Dialector: "sqlite",
+				DbName:    "app",
+			},
+			expected: map[string]interface{}{
+				"db.system.name":    "sqlite",
+				"db.operation.name": "PRAGMA",
+				"db.namespace":      "app",
+				"db.query.text":     "PRAGMA foreign_keys = ON",
+			},
+		},
+		{
+			name: "unknown dialector",
+			req: GormRequest{
+				Operation: "DELETE",
+				Table:     "items",
+				Dialector: "dummy",
+				Endpoint:  "db.internal",
+			},
+			expected: map[string]interface{}{
+				"db.system.name":     "other_sql",
+				"db.operation.name":  "DELETE",
+				"db.collection.name": "items",
+				"db.namespace":       "",
+				"server.address":     "db.internal",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs := GormClientRequestTraceAttrs(tt.req)
+
+			attrMap := make(map[string]interface{})
+			for _, attr := range attrs {
+				attrMap[string(attr.Key)] = attr.Value.AsInterface()
+			}
+			assert.Equal(t, tt.expected, attrMap)
+		})
+	}
+}
+
+func TestGormSpanName(t *testing.T) {
+	assert.Equal(t, "SELECT users", GormSpanName(GormRequest{Operation: "SELECT", Table: "users"}))
+	assert.Equal(t, "PRAGMA", GormSpanName(GormRequest{Operation: "PRAGMA"}))
+}
+
+func TestOperationName(t *testing.T) {
+	assert.Equal(t, "SELECT", OperationName("  select count(*) from users"))
+	assert.Empty(t, OperationName(""))
+}
+
+func TestStatementAsEvent(t *testing.T) {
+	req := GormRequest{Operation: "SELECT", Table: "users", Sql: "SELECT * FROM users"}
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	_, span := tp.Tracer("test").Start(context.Background(), "query")
+	AddDbQueryEvent(span, req)
+	span.End()
+	require.Len(t, sr.Ended(), 1)
+	assert.Empty(t, sr.Ended()[0].Events(), "the statement is an attribute by default")
+
+	// Cleanups run last in first out, so this one runs after the env is restored
+	t.Cleanup(sqlsemconv.ReadStatementAsEvent)
+	t.Setenv(sqlsemconv.EnvStatementAsEvent, "true")
+	sqlsemconv.ReadStatementAsEvent()
+	for _, attr := range GormClientRequestTraceAttrs(req) {
+		assert.NotEqual(t, semconv.DBQueryTextKey, attr.Key)
+	}
+	_, span = tp.Tracer("test").Start(context.Background(), "query")
+	AddDbQueryEvent(span, req)
+	span.End()
+	require.Len(t, sr.Ended(), 2)
+	events := sr.Ended()[1].Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, sqlsemconv.QueryEventName, events[0].Name)
+}
@@ -68,11 +68,6 @@ func initInstrumentation() {
 			trace.WithInstrumentationVersion(version),
 		)
 
-		// Start runtime metrics (respects OTEL_GO_ENABLED/DISABLED_INSTRUMENTATIONS)
-		if err := runtime.StartRuntimeMetrics(); err != nil {
-			logger.Error("failed to start runtime metrics", "error", err)
-		}
-
 		logger.Info("K8S client-go instrumentation initialized")
 	})
 }
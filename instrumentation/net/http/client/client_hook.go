@@ -4,6 +4,7 @@
 package client
 
 import (
+	"context"
 	"net/http"
 	"runtime/debug"
 	"strings"
@@ -63,12 +64,7 @@ func initInstrumentation() {
 			instrumentationName,
 			trace.WithInstrumentationVersion(version),
 		)
-		propagator = otel.GetTextMapPropagator()
-
-		// Start runtime metrics (respects OTEL_GO_ENABLED/DISABLED_INSTRUMENTATIONS)
-		if err := runtime.StartRuntimeMetrics(); err != nil {
-			logger.Error("failed to start runtime metrics", "error", err)
-		}
+		propagator = runtime.Propagator(instrumentationKey)
 
 		logger.Info("HTTP client instrumentation initialized")
 	})
@@ -83,7 +79,34 @@ func (n netHttpClientEnabler) Enable() bool {
 
 var clientEnabler = netHttpClientEnabler{}
 
+// clientSpanKey marks a request context whose client span was started by the
+// (*http.Client).Do hook. The RoundTrip hook underneath sees the marker and
+// does not start a second span for the same logical request.
+type clientSpanKey struct{}
+
+// BeforeClientDo starts the client span at the (*http.Client).Do level, which
+// covers clients with custom transports that the RoundTrip hook never sees.
+func BeforeClientDo(ictx hook.HookContext, client *http.Client, req *http.Request) {
+	beforeRequest(ictx, req, true)
+}
+
+func AfterClientDo(ictx hook.HookContext, res *http.Response, err error) {
+	afterRequest(ictx, res, err)
+}
+
 func BeforeRoundTrip(ictx hook.HookContext, transport *http.Transport, req *http.Request) {
+	if req.Context().Value(clientSpanKey{}) != nil {
+		// Client.Do already started the span and injected its context
+		return
+	}
+	beforeRequest(ictx, req, false)
+}
+
+func AfterRoundTrip(ictx hook.HookContext, res *http.Response, err error) {
+	afterRequest(ictx, res, err)
+}
+
+func beforeRequest(ictx hook.HookContext, req *http.Request, fromClientDo bool) {
 	if !clientEnabler.Enable() {
 		logger.Debug("HTTP client instrumentation disabled")
 		return
@@ -110,11 +133,17 @@ func BeforeRoundTrip(ictx hook.HookContext, transport *http.Transport, req *http
 
 	ctx := req.Context()
 
-	// Get trace attributes from semconv
+	// Get trace attributes from semconv, along with those derived from the
+	// caller's context such as the active feature flag
 	attrs := semconv.HTTPClientRequestTraceAttrs(req)
+	attrs = append(attrs, runtime.ContextAttributes(ctx)...)
 
-	// Start span
-	spanName := req.Method
+	// Start span, named by the rule's span_name template if it has one
+	spanName := runtime.SpanNameWithContext(ctx, ictx, req.Method, map[string]string{
+		"method": req.Method,
+		"host":   req.URL.Host,
+		"path":   req.URL.Path,
+	})
 	ctx, span := tracer.Start(ctx,
 		spanName,
 		trace.WithSpanKind(trace.SpanKindClient),
@@ -125,6 +154,9 @@ func BeforeRoundTrip(ictx hook.HookContext, transport *http.Transport, req *http
 	propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
 
 	// Update request with new context
+	if fromClientDo {
+		ctx = context.WithValue(ctx, clientSpanKey{}, true)
+	}
 	newReq := req.WithContext(ctx)
 	ictx.SetParam(requestParamIndex, newReq)
 
@@ -137,7 +169,7 @@ func BeforeRoundTrip(ictx hook.HookContext, transport *http.Transport, req *http
 	})
 }
 
-func AfterRoundTrip(ictx hook.HookContext, res *http.Response, err error) {
+func afterRequest(ictx hook.HookContext, res *http.Response, err error) {
 	if !clientEnabler.Enable() {
 		logger.Debug("HTTP client instrumentation disabled")
 		return
@@ -148,7 +180,14 @@ func AfterRoundTrip(ictx hook.HookContext, res *http.Response, err error) {
 		logger.Debug("AfterRoundTrip: no span from before hook")
 		return
 	}
-	defer span.End()
+	// The span covers reading the body too, so it ends with the body unless
+	// the response has none.
+	defer func() {
+		if res == nil || err != nil || !wrapBody(res, span) {
+			span.End()
+		}
+	}()
+	defer runtime.RecordOverhead(ictx, span)
 
 	// Add response attributes
 	if res != nil {
@@ -174,6 +213,12 @@ func AfterRoundTrip(ictx hook.HookContext, res *http.Response, err error) {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		span.SetAttributes(semconv.HTTPClientErrorType(err))
+		// A caller-supplied cancellation cause says more than "context canceled"
+		if ctx, ok := ictx.GetKeyData("ctx").(context.Context); ok {
+			if cause := runtime.RecordCancelCause(ctx, span); cause != nil {
+				span.SetStatus(codes.Error, cause.Error())
+			}
+		}
 		logger.Debug("AfterRoundTrip called with error", "error", err)
 	}
 
@@ -6,6 +6,7 @@ package client
 import (
 	"context"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"sync"
@@ -22,6 +23,7 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
 )
 
 func setupTestTracer(t *testing.T) (*tracetest.SpanRecorder, *sdktrace.TracerProvider) {
@@ -107,6 +109,25 @@ func TestBeforeRoundTrip(t *testing.T) {
 			},
 			expectSpan: true,
 		},
+		{
+			name: "feature flag from context",
+			setupEnv: func(t *testing.T) {
+				t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+			},
+			setupRequest: func() *http.Request {
+				// Set by upstream middleware
+				ctx := runtime.WithFeatureFlag(context.Background(), "new-checkout", "treatment")
+				req, _ := http.NewRequestWithContext(ctx, "GET", "http://example.com/checkout", nil)
+				return req
+			},
+			expectSpan: true,
+			validateSpan: func(t *testing.T, span trace.Span) {
+				roSpan, ok := span.(sdktrace.ReadOnlySpan)
+				require.True(t, ok)
+				assert.Contains(t, roSpan.Attributes(), runtime.FeatureFlagKeyKey.String("new-checkout"))
+				assert.Contains(t, roSpan.Attributes(), runtime.FeatureFlagVariantKey.String("treatment"))
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -228,6 +249,65 @@ func TestAfterRoundTrip(t *testing.T) {
 				assert.Equal(t, "exception", events[0].Name)
 			},
 		},
+		{
+			name: "cancelled with cause",
+			setupEnv: func(t *testing.T) {
+				t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+			},
+			setupContext: func(tp *sdktrace.TracerProvider) hook.HookContext {
+				testTracer := tp.Tracer(instrumentationName)
+				parent, cancel := context.WithCancelCause(context.Background())
+				req, _ := http.NewRequestWithContext(parent, "GET", "http://example.com/path", nil)
+				ctx, span := testTracer.Start(parent, "GET", trace.WithSpanKind(trace.SpanKindClient))
+				cancel(errors.New("upstream deadline budget exhausted"))
+
+				mockCtx := hooktest.NewMockHookContext()
+				mockCtx.SetData(map[string]interface{}{
+					"ctx":  ctx,
+					"span": span,
+					"req":  req,
+				})
+				return mockCtx
+			},
+			response: nil,
+			err:      context.Canceled,
+			validateSpan: func(t *testing.T, spans []sdktrace.ReadOnlySpan) {
+				require.Len(t, spans, 1)
+				span := spans[0]
+				assert.Equal(t, codes.Error, span.Status().Code)
+				assert.Equal(t, "upstream deadline budget exhausted", span.Status().Description)
+				assert.Contains(t, span.Attributes(),
+					runtime.CancelCauseKey.String("upstream deadline budget exhausted"))
+			},
+		},
+		{
+			name: "overhead measured",
+			setupEnv: func(t *testing.T) {
+				t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+			},
+			setupContext: func(tp *sdktrace.TracerProvider) hook.HookContext {
+				testTracer := tp.Tracer(instrumentationName)
+				req, _ := http.NewRequest("GET", "http://example.com/path", nil)
+				ctx, span := testTracer.Start(context.Background(), "GET", trace.WithSpanKind(trace.SpanKindClient))
+
+				mockCtx := hooktest.NewMockHookContext()
+				mockCtx.SetData(map[string]interface{}{
+					"ctx":                   ctx,
+					"span":                  span,
+					"req":                   req,
+					runtime.OverheadDataKey: func() int64 { return 4200 },
+				})
+				return mockCtx
+			},
+			response: &http.Response{
+				StatusCode: 200,
+				Request:    httptest.NewRequest("GET", "http://example.com/path", nil),
+			},
+			validateSpan: func(t *testing.T, spans []sdktrace.ReadOnlySpan) {
+				require.Len(t, spans, 1)
+				assert.Contains(t, spans[0].Attributes(), runtime.InstrumentationOverheadKey.Int64(4200))
+			},
+		},
 		{
 			name: "4xx client error",
 			setupEnv: func(t *testing.T) {
@@ -401,3 +481,189 @@ func TestClientEnabler(t *testing.T) {
 		})
 	}
 }
+
+// instrumentedTransport emulates (*http.Transport).RoundTrip after the
+// RoundTrip hooks were injected into it.
+type instrumentedTransport struct {
+	base *http.Transport
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ictx := hooktest.NewMockHookContext(nil, req)
+	BeforeRoundTrip(ictx, t.base, req)
+	if r, ok := ictx.GetParam(requestParamIndex).(*http.Request); ok {
+		req = r
+	}
+	res, err := t.base.RoundTrip(req)
+	AfterRoundTrip(ictx, res, err)
+	return res, err
+}
+
+// customTransport is a user RoundTripper the RoundTrip hooks never see.
+type customTransport struct{}
+
+func (customTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClientDo_SingleSpan(t *testing.T) {
+	tests := []struct {
+		name      string
+		transport http.RoundTripper
+	}{
+		{name: "default transport", transport: &instrumentedTransport{base: &http.Transport{}}},
+		{name: "custom transport", transport: customTransport{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initOnce = *new(sync.Once)
+			t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+			sr, _ := setupTestTracer(t)
+
+			var traceparent string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				traceparent = r.Header.Get("traceparent")
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: tt.transport}
+			req, err := http.NewRequest("GET", server.URL, nil)
+			require.NoError(t, err)
+
+			// Emulate (*http.Client).Do with its hooks injected
+			ictx := hooktest.NewMockHookContext(client, req)
+			BeforeClientDo(ictx, client, req)
+			if r, ok := ictx.GetParam(requestParamIndex).(*http.Request); ok {
+				req = r
+			}
+			res, err := client.Do(req)
+			AfterClientDo(ictx, res, err)
+			require.NoError(t, err)
+			_ = res.Body.Close()
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1, "one logical request must produce exactly one client span")
+			assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+			assert.Contains(t, traceparent, spans[0].SpanContext().SpanID().String())
+		})
+	}
+}
+
+// doRequest emulates (*http.Client).Do with its hooks injected, over a
+// transport with the RoundTrip hooks injected.
+func doRequest(t *testing.T, req *http.Request) *http.Response {
+	t.Helper()
+	client := &http.Client{Transport: &instrumentedTransport{base: &http.Transport{}}}
+	ictx := hooktest.NewMockHookContext(client, req)
+	BeforeClientDo(ictx, client, req)
+	if r, ok := ictx.GetParam(requestParamIndex).(*http.Request); ok {
+		req = r
+	}
+	res, err := client.Do(req)
+	AfterClientDo(ictx, res, err)
+	require.NoError(t, err)
+	return res
+}
+
+func TestClientSpan_EndsWithBody(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	sr, _ := setupTestTracer(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "payload")
+	}))
+	defer server.Close()
+
+	t.Run("closed", func(t *testing.T) {
+		sr.Reset()
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		res := doRequest(t, req)
+		assert.Empty(t, sr.Ended(), "the span must not end before the body is consumed")
+
+		require.NoError(t, res.Body.Close())
+		require.Len(t, sr.Ended(), 1)
+		require.NoError(t, res.Body.Close())
+		assert.Len(t, sr.Ended(), 1, "closing twice ends the span once")
+	})
+
+	t.Run("read to the end", func(t *testing.T) {
+		sr.Reset()
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		res := doRequest(t, req)
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "payload", string(body))
+		require.Len(t, sr.Ended(), 1)
+		assert.Equal(t, codes.Unset, sr.Ended()[0].Status().Code)
+		require.NoError(t, res.Body.Close())
+		assert.Len(t, sr.Ended(), 1)
+	})
+
+	t.Run("no body", func(t *testing.T) {
+		sr.Reset()
+		req, err := http.NewRequest(http.MethodHead, server.URL, nil)
+		require.NoError(t, err)
+		res := doRequest(t, req)
+
+		assert.Equal(t, http.NoBody, res.Body)
+		assert.Len(t, sr.Ended(), 1, "a response without a body ends the span right away")
+	})
+}
+
+type failingBody struct{ closed bool }
+
+func (*failingBody) Read([]byte) (int, error) { return 0, errors.New("connection reset") }
+func (b *failingBody) Close() error           { b.closed = true; return nil }
+
+func TestClientSpan_BodyReadError(t *testing.T) {
+	sr, tp := setupTestTracer(t)
+	_, span := tp.Tracer(instrumentationName).Start(context.Background(), "GET")
+	body := &failingBody{}
+	res := &http.Response{StatusCode: http.StatusOK, Body: body}
+	require.True(t, wrapBody(res, span))
+
+	_, err := io.ReadAll(res.Body)
+	require.Error(t, err)
+	require.Len(t, sr.Ended(), 1)
+	assert.Equal(t, codes.Error, sr.Ended()[0].Status().Code)
+	assert.Equal(t, "connection reset", sr.Ended()[0].Status().Description)
+	require.NoError(t, res.Body.Close())
+	assert.True(t, body.closed)
+}
+
+func TestClientSpan_RedirectsCountedOnce(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	sr, _ := setupTestTracer(t)
+
+	var traceparents []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		_, _ = io.WriteString(w, "moved")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/old", nil)
+	require.NoError(t, err)
+	res := doRequest(t, req)
+	_, _ = io.Copy(io.Discard, res.Body)
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1, "following a redirect must not start a second client span")
+	require.Len(t, traceparents, 2)
+	for _, tp := range traceparents {
+		assert.Contains(t, tp, spans[0].SpanContext().SpanID().String())
+	}
+}
@@ -7,15 +7,21 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/propagation"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
 )
 
 const (
@@ -214,3 +220,88 @@ func TestSpanParentChildRelationship(t *testing.T) {
 	assert.Equal(t, parentSpanCtx.SpanID(), childParentSpanID,
 		"child's parent span ID should match parent's span ID")
 }
+
+// acmePropagator carries the trace context in a proprietary
+// "X-Acme-Trace: {trace ID}/{span ID}" header.
+type acmePropagator struct{}
+
+func (acmePropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	sc := trace.SpanContextFromContext(ctx)
+	if sc.IsValid() {
+		carrier.Set("X-Acme-Trace", sc.TraceID().String()+"/"+sc.SpanID().String())
+	}
+}
+
+func (acmePropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	traceIDHex, spanIDHex, _ := strings.Cut(carrier.Get("X-Acme-Trace"), "/")
+	traceID, err := trace.TraceIDFromHex(traceIDHex)
+	if err != nil {
+		return ctx
+	}
+	spanID, err := trace.SpanIDFromHex(spanIDHex)
+	if err != nil {
+		return ctx
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+}
+
+func (acmePropagator) Fields() []string { return []string{"X-Acme-Trace"} }
+
+// TestClientRegisteredPropagator verifies that a propagator registered with
+// the runtime injects its headers alongside traceparent.
+func TestClientRegisteredPropagator(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	sr, _ := setupTestTracer(t)
+	runtime.RegisterPropagator(acmePropagator{})
+	otel.SetTextMapPropagator(runtime.TextMapPropagator())
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com/orders", nil)
+	require.NoError(t, err)
+	ictx := hooktest.NewMockHookContext(&http.Transport{}, req)
+	BeforeRoundTrip(ictx, &http.Transport{}, req)
+	AfterRoundTrip(ictx, nil, nil)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	sc := spans[0].SpanContext()
+	assert.NotEmpty(t, req.Header.Get("Traceparent"))
+	assert.Equal(t, sc.TraceID().String()+"/"+sc.SpanID().String(), req.Header.Get("X-Acme-Trace"))
+}
+
+func TestClientPropagatesBaggage(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	setupTestTracer(t)
+	otel.SetTextMapPropagator(runtime.TextMapPropagator())
+
+	member, err := baggage.NewMember("tenant", "acme")
+	require.NoError(t, err)
+	bag, err := baggage.New(member)
+	require.NoError(t, err)
+	ctx := baggage.ContextWithBaggage(t.Context(), bag)
+
+	roundTrip := func() http.Header {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com/orders", nil)
+		require.NoError(t, err)
+		ictx := hooktest.NewMockHookContext(&http.Transport{}, req)
+		BeforeRoundTrip(ictx, &http.Transport{}, req)
+		AfterRoundTrip(ictx, nil, nil)
+		return req.Header
+	}
+
+	header := roundTrip()
+	assert.NotEmpty(t, header.Get("Traceparent"))
+	assert.Equal(t, "tenant=acme", header.Get("Baggage"))
+
+	// Overridden for this instrumentation only, after it was initialized
+	runtime.SetInstrumentationPropagator("nethttp", propagation.TraceContext{})
+	t.Cleanup(func() { runtime.SetInstrumentationPropagator("nethttp", nil) })
+	header = roundTrip()
+	assert.NotEmpty(t, header.Get("Traceparent"))
+	assert.Empty(t, header.Get("Baggage"))
+}
@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// bodyWrapper wraps the body of a response so that the client span ends once
+// the body is consumed rather than when the headers arrive: at the end of the
+// body, on a read error or when the body is closed, whichever comes first.
+type bodyWrapper struct {
+	io.ReadCloser
+	span trace.Span
+	once sync.Once
+}
+
+// wrapBody makes res end span with its body, and reports whether it did.
+// Responses without a body, and the io.ReadWriteCloser body of a protocol
+// switch, are left untouched; the caller ends span for them.
+func wrapBody(res *http.Response, span trace.Span) bool {
+	if res.Body == nil || res.Body == http.NoBody || res.StatusCode == http.StatusSwitchingProtocols {
+		return false
+	}
+	res.Body = &bodyWrapper{ReadCloser: res.Body, span: span}
+	return true
+}
+
+func (b *bodyWrapper) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	switch {
+	case err == nil:
+	case errors.Is(err, io.EOF):
+		b.end(nil)
+	default:
+		b.end(err)
+	}
+	return n, err
+}
+
+func (b *bodyWrapper) Close() error {
+	err := b.ReadCloser.Close()
+	b.end(nil)
+	return err
+}
+
+func (b *bodyWrapper) end(err error) {
+	b.once.Do(func() {
+		if err != nil {
+			b.span.RecordError(err)
+			b.span.SetStatus(codes.Error, err.Error())
+		}
+		b.span.End()
+	})
+}
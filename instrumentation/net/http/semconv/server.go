@@ -20,6 +20,10 @@ import (
 type RequestTraceAttrsOpts struct {
 	// If set, this is used as value for the "client.address" attribute.
 	HTTPClientIP string
+	// If set, only the query parameter keys are recorded, as "url.query.keys",
+	// instead of the full "url.query". This keeps cardinality bounded for
+	// endpoints whose query values vary per request.
+	QueryKeysOnly bool
 }
 
 // ResponseTelemetry holds response telemetry data.
@@ -210,7 +214,11 @@ func (n HTTPServer) RequestTraceAttrs(
 	}
 
 	if req.URL != nil && req.URL.RawQuery != "" {
-		attrs = append(attrs, semconv.URLQuery(req.URL.RawQuery))
+		if opts.QueryKeysOnly {
+			attrs = append(attrs, URLQueryKeys(req.URL.RawQuery))
+		} else {
+			attrs = append(attrs, semconv.URLQuery(req.URL.RawQuery))
+		}
 	}
 
 	if protoName != "" && protoName != "http" {
@@ -426,10 +434,21 @@ func HTTPServerRequestTraceAttrs(server string, req *http.Request) []attribute.K
 	return defaultHTTPServer.RequestTraceAttrs(server, req, RequestTraceAttrsOpts{})
 }
 
+// HTTPServerRequestTraceAttrsWithOpts returns trace attributes for an HTTP
+// server request, honoring the given options.
+func HTTPServerRequestTraceAttrsWithOpts(
+	server string,
+	req *http.Request,
+	opts RequestTraceAttrsOpts,
+) []attribute.KeyValue {
+	return defaultHTTPServer.RequestTraceAttrs(server, req, opts)
+}
+
 // HTTPServerResponseTraceAttrs returns trace attributes for an HTTP server response.
-func HTTPServerResponseTraceAttrs(statusCode int, writeBytes int64) []attribute.KeyValue {
+func HTTPServerResponseTraceAttrs(statusCode int, readBytes, writeBytes int64) []attribute.KeyValue {
 	return defaultHTTPServer.ResponseTraceAttrs(ResponseTelemetry{
 		StatusCode: statusCode,
+		ReadBytes:  readBytes,
 		WriteBytes: writeBytes,
 	})
 }
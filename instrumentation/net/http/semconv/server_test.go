@@ -147,6 +147,28 @@ func TestHTTPServerRequestTraceAttrs(t *testing.T) {
 	}
 }
 
+func TestHTTPServerRequestTraceAttrs_QueryKeysOnly(t *testing.T) {
+	req := &http.Request{
+		Method:     "GET",
+		Host:       "example.com",
+		RemoteAddr: "192.168.1.1:12345",
+		URL: &url.URL{
+			Path:     "/search",
+			RawQuery: "q=secret&limit=10&q=other",
+		},
+		Proto: "HTTP/1.1",
+	}
+
+	attrs := NewHTTPServer(nil).RequestTraceAttrs("", req, RequestTraceAttrsOpts{QueryKeysOnly: true})
+
+	attrMap := make(map[string]interface{})
+	for _, attr := range attrs {
+		attrMap[string(attr.Key)] = attr.Value.AsInterface()
+	}
+	assert.Equal(t, []string{"limit", "q"}, attrMap["url.query.keys"])
+	assert.NotContains(t, attrMap, "url.query", "query values must not be recorded")
+}
+
 func TestHTTPServerResponseTraceAttrs(t *testing.T) {
 	tests := []struct {
 		name      string
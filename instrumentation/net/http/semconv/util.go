@@ -6,8 +6,11 @@
 package semconv
 
 import (
+	"maps"
 	"net"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -70,6 +73,18 @@ func RequiredHTTPPort(https bool, port int) int {
 	return -1
 }
 
+// URLQueryKeysKey is the attribute key for the query parameter names of a
+// request. It is not defined by the semantic conventions; it is recorded in
+// place of "url.query" when only the shape of the query should be kept.
+const URLQueryKeysKey = attribute.Key("url.query.keys")
+
+// URLQueryKeys returns the sorted, de-duplicated query parameter names of
+// rawQuery. Values are dropped. Malformed pairs are skipped.
+func URLQueryKeys(rawQuery string) attribute.KeyValue {
+	values, _ := url.ParseQuery(rawQuery) // keep whatever parsed
+	return URLQueryKeysKey.StringSlice(slices.Sorted(maps.Keys(values)))
+}
+
 // ServerClientIP extracts the client IP from X-Forwarded-For header.
 func ServerClientIP(xForwardedFor string) string {
 	if idx := strings.IndexByte(xForwardedFor, ','); idx >= 0 {
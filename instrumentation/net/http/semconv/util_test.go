@@ -165,3 +165,23 @@ func TestServerClientIP(t *testing.T) {
 		})
 	}
 }
+
+func TestURLQueryKeys(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"q=test&limit=10", []string{"limit", "q"}},
+		{"a=1&a=2&b", []string{"a", "b"}},
+		{"id=%zz&page=2", []string{"page"}},
+		{"", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			kv := URLQueryKeys(tt.input)
+			assert.Equal(t, URLQueryKeysKey, kv.Key)
+			assert.Equal(t, tt.expected, kv.Value.AsStringSlice())
+		})
+	}
+}
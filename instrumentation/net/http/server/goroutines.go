@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"os"
+	goruntime "runtime"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+)
+
+const (
+	// envGoroutineDelta records, for every request, how many goroutines the
+	// process gained while the handler ran.
+	envGoroutineDelta = "OTEL_GO_HTTP_SERVER_GOROUTINE_DELTA"
+
+	goroutineDeltaMetric = "process.runtime.go.goroutines.delta"
+)
+
+// goroutineDelta is nil unless the goroutine delta metric is enabled.
+var goroutineDelta metric.Int64Histogram
+
+// initGoroutineDelta creates the goroutine delta histogram when enabled.
+func initGoroutineDelta(version string) {
+	goroutineDelta = nil
+	if os.Getenv(envGoroutineDelta) != "true" {
+		return
+	}
+	meter := runtime.Meter(
+		instrumentationName,
+		metric.WithInstrumentationVersion(version),
+	)
+	histogram, err := meter.Int64Histogram(
+		goroutineDeltaMetric,
+		metric.WithUnit("{goroutine}"),
+		metric.WithDescription("Goroutines started but not finished while serving an HTTP request."),
+	)
+	if err != nil {
+		logger.Error("failed to create goroutine delta histogram", "error", err)
+		return
+	}
+	goroutineDelta = histogram
+}
+
+// startGoroutineDelta samples the goroutine count and returns a function that
+// records how much it changed since. The count is process-wide, so concurrent
+// requests blur it; a route whose delta stays positive is nonetheless a good
+// hint that its handler leaks goroutines.
+func startGoroutineDelta(ctx context.Context, attrs ...attribute.KeyValue) func() {
+	before := goruntime.NumGoroutine()
+	return func() {
+		delta := goruntime.NumGoroutine() - before
+		goroutineDelta.Record(ctx, int64(delta), metric.WithAttributes(attrs...))
+	}
+}
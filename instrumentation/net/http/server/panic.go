@@ -0,0 +1,61 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// envPanicLogs additionally emits an OpenTelemetry log record, carrying the
+	// stack trace, for each handler panic.
+	envPanicLogs = "OTEL_GO_HTTP_SERVER_PANIC_LOGS"
+
+	panicErrorType      = "panic"
+	exceptionEventName  = "exception"
+	exceptionTypeKey    = attribute.Key("exception.type")
+	exceptionMessageKey = attribute.Key("exception.message")
+	exceptionStackKey   = attribute.Key("exception.stacktrace")
+)
+
+// recordPanic marks span as failed by a handler panic and attaches the stack as
+// an exception event. The value the handler panicked with, captured by the
+// trampoline when the rule sets capture_panic, describes the failure. When
+// panic logs are enabled, the same stack is emitted as an OpenTelemetry log
+// record correlated with the span through ctx.
+func recordPanic(ctx context.Context, span trace.Span, spanName string, value any, captured bool) {
+	stack := string(debug.Stack())
+	message := panicErrorType
+	if captured {
+		message = fmt.Sprint(value)
+	}
+	span.SetAttributes(attribute.String("error.type", panicErrorType))
+	span.SetStatus(codes.Error, message)
+	span.AddEvent(exceptionEventName, trace.WithAttributes(
+		exceptionTypeKey.String(panicErrorType),
+		exceptionMessageKey.String(message),
+		exceptionStackKey.String(stack),
+	))
+
+	if !panicLogs {
+		return
+	}
+	var record otellog.Record
+	record.SetSeverity(otellog.SeverityError)
+	record.SetBody(otellog.StringValue("panic serving " + spanName))
+	record.AddAttributes(
+		otellog.String(string(exceptionTypeKey), panicErrorType),
+		otellog.String(string(exceptionMessageKey), message),
+		otellog.String(string(exceptionStackKey), stack),
+	)
+	global.Logger(instrumentationName).Emit(ctx, record)
+}
@@ -7,6 +7,8 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -17,6 +19,9 @@ import (
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+
 	// Import client package to enable client-side instrumentation hooks
 	_ "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/net/http/client"
 )
@@ -339,3 +344,106 @@ func TestServerDistributedTracing(t *testing.T) {
 	assert.Equal(t, rootTraceID, downstreamTraceID,
 		"downstream service should have received the same trace ID")
 }
+
+// acmePropagator carries the trace context in a proprietary
+// "X-Acme-Trace: {trace ID}/{span ID}" header.
+type acmePropagator struct{}
+
+func (acmePropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	sc := trace.SpanContextFromContext(ctx)
+	if sc.IsValid() {
+		carrier.Set("X-Acme-Trace", sc.TraceID().String()+"/"+sc.SpanID().String())
+	}
+}
+
+func (acmePropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	traceIDHex, spanIDHex, _ := strings.Cut(carrier.Get("X-Acme-Trace"), "/")
+	traceID, err := trace.TraceIDFromHex(traceIDHex)
+	if err != nil {
+		return ctx
+	}
+	spanID, err := trace.SpanIDFromHex(spanIDHex)
+	if err != nil {
+		return ctx
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+}
+
+func (acmePropagator) Fields() []string { return []string{"X-Acme-Trace"} }
+
+// TestServerRegisteredPropagator verifies that the server span continues a
+// trace whose context arrives in the headers of a registered propagator only.
+func TestServerRegisteredPropagator(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	sr, _ := setupTestTracer(t)
+	runtime.RegisterPropagator(acmePropagator{})
+	otel.SetTextMapPropagator(runtime.TextMapPropagator())
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/orders", nil)
+	req.Header.Set("X-Acme-Trace", "4bf92f3577b34da6a3ce929d0e0e4736/00f067aa0bb902b7")
+	ictx := hooktest.NewMockHookContext()
+	BeforeServeHTTP(ictx, nil, httptest.NewRecorder(), req)
+	AfterServeHTTP(ictx)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0bb902b7", spans[0].Parent().SpanID().String())
+	assert.True(t, spans[0].Parent().IsRemote())
+}
+
+// TestServerHonorsSampledParent verifies that, under the sampler configured
+// with OTEL_TRACES_SAMPLER=parentbased_traceidratio and a zero ratio, a request
+// its client sampled produces a sampled server span, even though every trace
+// starting in the server is dropped. An explicit traceidratio applies the ratio
+// to every span, parent or not.
+func TestServerHonorsSampledParent(t *testing.T) {
+	tests := []struct {
+		sampler string
+		sampled int
+	}{
+		{sampler: "parentbased_traceidratio", sampled: 1},
+		{sampler: "traceidratio", sampled: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sampler, func(t *testing.T) {
+			initOnce = *new(sync.Once)
+			t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+			t.Setenv("OTEL_TRACES_SAMPLER", tt.sampler)
+			t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0")
+			sr := tracetest.NewSpanRecorder()
+			// No sampler option: the SDK configures it from the env
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+			t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+			otel.SetTracerProvider(tp)
+			otel.SetTextMapPropagator(propagation.TraceContext{})
+
+			serve := func(traceparent string) {
+				req := httptest.NewRequest(http.MethodGet, "http://example.com/orders", nil)
+				if traceparent != "" {
+					req.Header.Set("Traceparent", traceparent)
+				}
+				ictx := hooktest.NewMockHookContext()
+				BeforeServeHTTP(ictx, nil, httptest.NewRecorder(), req)
+				AfterServeHTTP(ictx)
+			}
+			serve("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0bb902b7-01")
+			serve("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00")
+			serve("")
+
+			spans := sr.Ended()
+			require.Len(t, spans, tt.sampled)
+			if tt.sampled == 0 {
+				return
+			}
+			assert.True(t, spans[0].SpanContext().IsSampled())
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+			assert.Equal(t, "00f067aa0bb902b7", spans[0].Parent().SpanID().String())
+		})
+	}
+}
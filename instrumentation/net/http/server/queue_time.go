@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+)
+
+const (
+	// envQueueTime records, for every request, how long it waited between
+	// being read and its handler starting.
+	envQueueTime = "OTEL_GO_HTTP_SERVER_QUEUE_TIME"
+
+	queueTimeMetric = "http.server.request.queue_time"
+	queueTimeKey    = attribute.Key(queueTimeMetric)
+
+	// receivedKey is the context key under which the server_request_received
+	// rule stores when net/http read the request. net/http cannot import this
+	// package, so the key is a plain string.
+	receivedKey = "otel.http.server.request_received"
+)
+
+// queueTime is nil unless the queue time metric is enabled.
+var queueTime metric.Float64Histogram
+
+// initQueueTime creates the queue time histogram when enabled.
+func initQueueTime(version string) {
+	queueTime = nil
+	if os.Getenv(envQueueTime) != "true" {
+		return
+	}
+	meter := runtime.Meter(
+		instrumentationName,
+		metric.WithInstrumentationVersion(version),
+	)
+	histogram, err := meter.Float64Histogram(
+		queueTimeMetric,
+		metric.WithUnit("s"),
+		metric.WithDescription("Time HTTP server requests waited between being read and their handler starting."),
+	)
+	if err != nil {
+		logger.Error("failed to create queue time histogram", "error", err)
+		return
+	}
+	queueTime = histogram
+}
+
+// requestQueueTime returns how long ago net/http read the request. It is only
+// known for HTTP/1 requests read by an instrumented net/http.
+func requestQueueTime(r *http.Request) (time.Duration, bool) {
+	received, ok := r.Context().Value(receivedKey).(time.Time)
+	if !ok {
+		return 0, false
+	}
+	return max(time.Since(received), 0), true
+}
+
+// recordQueueTime records the queue time of a request, in seconds.
+func recordQueueTime(ctx context.Context, d time.Duration, attrs ...attribute.KeyValue) {
+	queueTime.Record(ctx, d.Seconds(), metric.WithAttributes(attrs...))
+}
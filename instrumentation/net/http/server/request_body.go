@@ -0,0 +1,43 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"io"
+	"net/http"
+)
+
+// bodyCounter wraps the body of a request of unknown length, such as a chunked
+// one, to count the bytes the handler reads from it.
+type bodyCounter struct {
+	io.ReadCloser
+	read int64
+}
+
+// countBody makes the body of r count the bytes read from it when r does not
+// declare its length, and returns the counter, or nil when there is nothing
+// to count.
+func countBody(r *http.Request) *bodyCounter {
+	if r.ContentLength >= 0 || r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	counter := &bodyCounter{ReadCloser: r.Body}
+	r.Body = counter
+	return counter
+}
+
+func (b *bodyCounter) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	return n, err
+}
+
+// requestBodySize returns the size of the body of r: its declared length, or
+// the bytes the handler read from it when counted.
+func requestBodySize(r *http.Request, counter *bodyCounter) int64 {
+	if counter != nil {
+		return counter.read
+	}
+	return max(r.ContentLength, 0)
+}
@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/net/http/semconv"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+)
+
+const (
+	// envRequestCount counts the requests served per route, method and status
+	// code, for per-endpoint traffic without deriving metrics from spans.
+	envRequestCount = "OTEL_GO_HTTP_SERVER_REQUEST_COUNT"
+
+	requestCountMetric = "http.server.request.count"
+)
+
+// requestCount is nil unless the request count metric is enabled.
+var requestCount metric.Int64Counter
+
+// initRequestCount creates the request counter when enabled.
+func initRequestCount(version string) {
+	requestCount = nil
+	if os.Getenv(envRequestCount) != "true" {
+		return
+	}
+	meter := runtime.Meter(
+		instrumentationName,
+		metric.WithInstrumentationVersion(version),
+	)
+	counter, err := meter.Int64Counter(
+		requestCountMetric,
+		metric.WithUnit("{request}"),
+		metric.WithDescription("Number of HTTP server requests."),
+	)
+	if err != nil {
+		logger.Error("failed to create request counter", "error", err)
+		return
+	}
+	requestCount = counter
+}
+
+// countRequest records a served request. The route is read from the request
+// once served, as ServeMux only sets the pattern when it dispatches it. A zero
+// status code, for a handler that panicked before responding, is omitted.
+func countRequest(ctx context.Context, r *http.Request, statusCode int) {
+	attrs := make([]attribute.KeyValue, 0, 3)
+	attrs = append(attrs, attribute.String("http.request.method", semconv.StandardizeHTTPMethod(r.Method)))
+	if route := semconv.HTTPRoute(r.Pattern); route != "" {
+		attrs = append(attrs, semconv.HTTPServerRoute(route))
+	}
+	if statusCode > 0 {
+		attrs = append(attrs, attribute.Int("http.response.status_code", statusCode))
+	}
+	requestCount.Add(ctx, 1, metric.WithAttributes(attrs...))
+}
@@ -8,13 +8,22 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"time"
 )
 
-// writerWrapper wraps http.ResponseWriter to capture the status code
+// writerWrapper wraps http.ResponseWriter to capture the status code and the
+// size of the response body
 type writerWrapper struct {
 	http.ResponseWriter
 	statusCode  int
 	wroteHeader bool
+	written     int64
+	// headerTime is when the headers were sent, the start of a stream
+	headerTime time.Time
+	// flushes counts the flushes that sent data, each one delivers an event of
+	// a Server-Sent Events stream
+	flushes   int64
+	unflushed bool
 }
 
 // WriteHeader captures the status code and forwards to the underlying ResponseWriter
@@ -25,16 +34,29 @@ func (w *writerWrapper) WriteHeader(statusCode int) {
 	}
 	w.statusCode = statusCode
 	w.wroteHeader = true
+	w.headerTime = time.Now()
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
-// Write implements http.ResponseWriter.Write and ensures WriteHeader is called
+// Write implements http.ResponseWriter.Write, ensures WriteHeader is called
+// and counts the bytes written, as the Content-Length of a streamed or chunked
+// response is unknown
 func (w *writerWrapper) Write(b []byte) (int, error) {
 	// If WriteHeader wasn't called yet, call it with 200 OK (default HTTP behavior)
 	if !w.wroteHeader {
 		w.WriteHeader(http.StatusOK)
 	}
-	return w.ResponseWriter.Write(b)
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	w.unflushed = w.unflushed || n > 0
+	return n, err
+}
+
+// Unwrap returns the original ResponseWriter. http.ResponseController uses it
+// to reach optional methods (e.g. SetWriteDeadline) the wrapper does not expose,
+// and middleware can use it to recover the writer's concrete type.
+func (w *writerWrapper) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
 }
 
 // Hijack implements the http.Hijacker interface
@@ -45,11 +67,19 @@ func (w *writerWrapper) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	return nil, nil, fmt.Errorf("responseWriter does not implement http.Hijacker")
 }
 
-// Flush implements the http.Flusher interface
+// Flush implements the http.Flusher interface. Flushing before any Write
+// sends the headers with 200 OK, which starts the stream.
 func (w *writerWrapper) Flush() {
 	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
 		f.Flush()
 	}
+	if w.unflushed {
+		w.flushes++
+		w.unflushed = false
+	}
 }
 
 // Pusher implements the http.Pusher interface
@@ -138,6 +138,25 @@ func TestWriterWrapper_Flush(t *testing.T) {
 
 	wrapper.Flush()
 	assert.True(t, mock.flushCalled)
+	// Flushing first sends the headers, as a Write would
+	assert.True(t, wrapper.wroteHeader)
+	assert.False(t, wrapper.headerTime.IsZero())
+	assert.Equal(t, http.StatusOK, mock.ResponseWriter.(*httptest.ResponseRecorder).Code)
+}
+
+func TestWriterWrapper_FlushAfterWriteHeader(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	wrapper := &writerWrapper{
+		ResponseWriter: recorder,
+		statusCode:     http.StatusOK,
+	}
+
+	wrapper.WriteHeader(http.StatusAccepted)
+	headerTime := wrapper.headerTime
+	wrapper.Flush()
+	assert.Equal(t, http.StatusAccepted, wrapper.statusCode)
+	assert.Equal(t, headerTime, wrapper.headerTime)
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
 }
 
 func TestWriterWrapper_Flush_NotSupported(t *testing.T) {
@@ -214,3 +233,10 @@ func TestWriterWrapper_MultipleStatusCodes(t *testing.T) {
 		})
 	}
 }
+
+func TestWriterWrapper_Unwrap(t *testing.T) {
+	rec := httptest.NewRecorder()
+	wrapper := &writerWrapper{ResponseWriter: rec, statusCode: http.StatusOK}
+
+	assert.Same(t, rec, wrapper.Unwrap())
+}
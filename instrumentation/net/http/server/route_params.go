@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// envRouteParams lists, comma separated, the route wildcards (e.g. "id"
+	// for "/users/{id}") whose matched values are recorded as
+	// "http.route.param.<name>" span attributes. Path values are unbounded in
+	// general, so only parameters known to take few distinct values should be
+	// listed.
+	envRouteParams = "OTEL_GO_HTTP_SERVER_ROUTE_PARAMS"
+
+	routeParamKeyPrefix = "http.route.param."
+)
+
+// routeParamsFromEnv returns the set of route parameters to record, or nil
+// when none is configured.
+func routeParamsFromEnv() map[string]bool {
+	var params map[string]bool
+	for _, name := range strings.Split(os.Getenv(envRouteParams), ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if params == nil {
+			params = make(map[string]bool)
+		}
+		params[name] = true
+	}
+	return params
+}
+
+// routeParamAttrs returns the attributes of the wildcards of the route r
+// matched that are listed in params. A wildcard that matched the empty string
+// is not recorded.
+func routeParamAttrs(r *http.Request, params map[string]bool) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for _, name := range routeWildcards(r.Pattern) {
+		if !params[name] {
+			continue
+		}
+		if value := r.PathValue(name); value != "" {
+			attrs = append(attrs, attribute.String(routeParamKeyPrefix+name, value))
+		}
+	}
+	return attrs
+}
+
+// routeWildcards returns the names of the wildcards in a ServeMux pattern,
+// such as "id" and "path" for "GET /users/{id}/files/{path...}". The "{$}"
+// end-of-path anchor is not a wildcard.
+func routeWildcards(pattern string) []string {
+	var names []string
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			return names
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			return names
+		}
+		name := strings.TrimSuffix(pattern[start+1:start+end], "...")
+		if name != "" && name != "$" {
+			names = append(names, name)
+		}
+		pattern = pattern[start+end+1:]
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteWildcards(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{pattern: "", expected: nil},
+		{pattern: "/static/", expected: nil},
+		{pattern: "GET /users/{id}", expected: []string{"id"}},
+		{pattern: "example.com/users/{id}/files/{path...}", expected: []string{"id", "path"}},
+		{pattern: "/{$}", expected: nil},
+		{pattern: "/broken/{id", expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			assert.Equal(t, tt.expected, routeWildcards(tt.pattern))
+		})
+	}
+}
+
+func TestRouteParamsFromEnv(t *testing.T) {
+	t.Setenv(envRouteParams, "")
+	assert.Nil(t, routeParamsFromEnv())
+
+	t.Setenv(envRouteParams, " id,,version ")
+	assert.Equal(t, map[string]bool{"id": true, "version": true}, routeParamsFromEnv())
+}
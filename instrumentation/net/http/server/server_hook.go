@@ -4,12 +4,15 @@
 package server
 
 import (
+	"context"
 	"net/http"
+	"os"
 	"runtime/debug"
 	"sync"
 	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
@@ -24,6 +27,15 @@ const (
 	instrumentationKey  = "NETHTTP"
 	responseWriterIndex = 1
 	requestIndex        = 2
+
+	// envQueryKeysOnly records only the query parameter keys ("url.query.keys")
+	// instead of the full query string, to bound attribute cardinality.
+	envQueryKeysOnly = "OTEL_GO_HTTP_SERVER_QUERY_KEYS_ONLY"
+
+	// envTenantHeader names a request header (e.g. X-Tenant-ID) whose value is
+	// recorded as the "tenant.id" span attribute.
+	envTenantHeader = "OTEL_GO_HTTP_TENANT_HEADER"
+	tenantIDKey     = attribute.Key("tenant.id")
 )
 
 var (
@@ -31,6 +43,12 @@ var (
 	tracer     trace.Tracer
 	propagator propagation.TextMapPropagator
 	initOnce   sync.Once
+
+	queryKeysOnly bool
+	profileLabels bool
+	panicLogs     bool
+	tenantHeader  string
+	routeParams   map[string]bool
 )
 
 // moduleVersion extracts the version from the Go module system.
@@ -62,12 +80,15 @@ func initInstrumentation() {
 			instrumentationName,
 			trace.WithInstrumentationVersion(version),
 		)
-		propagator = otel.GetTextMapPropagator()
-
-		// Start runtime metrics (respects OTEL_GO_ENABLED/DISABLED_INSTRUMENTATIONS)
-		if err := runtime.StartRuntimeMetrics(); err != nil {
-			logger.Error("failed to start runtime metrics", "error", err)
-		}
+		propagator = runtime.Propagator(instrumentationKey)
+		queryKeysOnly = os.Getenv(envQueryKeysOnly) == "true"
+		profileLabels = runtime.ProfileLabelsEnabled()
+		panicLogs = os.Getenv(envPanicLogs) == "true"
+		tenantHeader = os.Getenv(envTenantHeader)
+		routeParams = routeParamsFromEnv()
+		initGoroutineDelta(version)
+		initRequestCount(version)
+		initQueueTime(version)
 
 		logger.Info("HTTP server instrumentation initialized")
 	})
@@ -99,11 +120,25 @@ func BeforeServeHTTP(ictx hook.HookContext, recv interface{}, w http.ResponseWri
 	ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
 
 	// Get trace attributes from semconv
-	attrs := semconv.HTTPServerRequestTraceAttrs("", r)
+	attrs := semconv.HTTPServerRequestTraceAttrsWithOpts("", r, semconv.RequestTraceAttrsOpts{
+		QueryKeysOnly: queryKeysOnly,
+	})
+
+	// Enrich with the tenant identifier, if configured and present
+	if tenantHeader != "" {
+		if tenant := r.Header.Get(tenantHeader); tenant != "" {
+			attrs = append(attrs, tenantIDKey.String(tenant))
+		}
+	}
 
 	// Get HTTP route from r.Pattern (Go 1.22+)
 	route := semconv.HTTPRoute(r.Pattern)
-	spanName := semconv.HTTPServerSpanName(r.Method, route)
+	spanName := runtime.SpanNameWithContext(ctx, ictx, semconv.HTTPServerSpanName(r.Method, route), map[string]string{
+		"method": r.Method,
+		"route":  route,
+		"path":   r.URL.Path,
+		"host":   r.Host,
+	})
 
 	// Start span
 	ctx, span := tracer.Start(ctx,
@@ -117,6 +152,32 @@ func BeforeServeHTTP(ictx hook.HookContext, recv interface{}, w http.ResponseWri
 		span.SetAttributes(semconv.HTTPServerRoute(route))
 	}
 
+	// Attribute CPU time spent in the handler to this span in pprof profiles
+	restoreLabels := func() {}
+	if profileLabels {
+		ctx, restoreLabels = runtime.StartProfileLabels(ctx, spanName)
+	}
+
+	metricAttrs := []attribute.KeyValue{attribute.String("http.request.method", r.Method)}
+	if route != "" {
+		metricAttrs = append(metricAttrs, semconv.HTTPServerRoute(route))
+	}
+
+	// Record how long the request waited for its handler, which grows when
+	// the server is saturated
+	if wait, ok := requestQueueTime(r); ok {
+		span.SetAttributes(queueTimeKey.Float64(wait.Seconds()))
+		if queueTime != nil {
+			recordQueueTime(ctx, wait, metricAttrs...)
+		}
+	}
+
+	// Sample goroutines to flag handlers that leave some behind
+	recordGoroutines := func() {}
+	if goroutineDelta != nil {
+		recordGoroutines = startGoroutineDelta(ctx, metricAttrs...)
+	}
+
 	// Wrap ResponseWriter to capture status code
 	wrapper := &writerWrapper{
 		ResponseWriter: w,
@@ -124,15 +185,21 @@ func BeforeServeHTTP(ictx hook.HookContext, recv interface{}, w http.ResponseWri
 	}
 	ictx.SetParam(responseWriterIndex, wrapper)
 
-	// Update request with new context containing the span
-	newReq := r.WithContext(ctx)
+	// Update request with new context containing the span, marked as served
+	// so that handlers wrapped by WrapHandler do not start another span
+	newReq := r.WithContext(context.WithValue(ctx, servingKey{}, true))
+	requestBody := countBody(newReq)
 	ictx.SetParam(requestIndex, newReq)
 
 	// Store data for after hook
 	ictx.SetData(map[string]interface{}{
-		"ctx":   ctx,
-		"span":  span,
-		"start": time.Now(),
+		"ctx":              ctx,
+		"span":             span,
+		"spanName":         spanName,
+		"start":            time.Now(),
+		"restoreLabels":    restoreLabels,
+		"recordGoroutines": recordGoroutines,
+		"requestBody":      requestBody,
 	})
 }
 
@@ -147,18 +214,71 @@ func AfterServeHTTP(ictx hook.HookContext) {
 		return
 	}
 	defer span.End()
+	defer runtime.RecordOverhead(ictx, span)
+	if restoreLabels, ok := ictx.GetKeyData("restoreLabels").(func()); ok {
+		defer restoreLabels()
+	}
+	if recordGoroutines, ok := ictx.GetKeyData("recordGoroutines").(func()); ok {
+		defer recordGoroutines()
+	}
 
-	// Extract status code from wrapped ResponseWriter
+	// Extract status code and response size from wrapped ResponseWriter
 	statusCode := http.StatusOK
+	wroteHeader := false
+	var written int64
+	var sseAttrs []attribute.KeyValue
 	if p, ok := ictx.GetParam(responseWriterIndex).(http.ResponseWriter); ok {
 		if wrapper, ok := p.(*writerWrapper); ok {
 			statusCode = wrapper.statusCode
+			wroteHeader = wrapper.wroteHeader
+			written = wrapper.written
+			sseAttrs = wrapper.sseAttrs()
+		}
+	}
+	var read int64
+	if r, ok := ictx.GetParam(requestIndex).(*http.Request); ok {
+		requestBody, _ := ictx.GetKeyData("requestBody").(*bodyCounter)
+		read = requestBodySize(r, requestBody)
+		// ServeMux only sets the pattern and path values of the request once
+		// it dispatches it, so the route may first be known now
+		if route := semconv.HTTPRoute(r.Pattern); route != "" {
+			span.SetAttributes(semconv.HTTPServerRoute(route))
+			if routeParams != nil {
+				span.SetAttributes(routeParamAttrs(r, routeParams)...)
+			}
 		}
 	}
 
+	// The handler panicked and the panic is still propagating to the server's
+	// recovery. Unless the handler already sent a response, nothing reaches the
+	// client, so there is no status code to report.
+	ctx, _ := ictx.GetKeyData("ctx").(context.Context)
+	panicked := runtime.Panicking()
+	if panicked && !wroteHeader {
+		statusCode = 0
+	}
+
+	// Count the request per route, method and status code
+	if r, ok := ictx.GetParam(requestIndex).(*http.Request); ok && requestCount != nil {
+		countRequest(ctx, r, statusCode)
+	}
+
+	if panicked {
+		spanName, _ := ictx.GetKeyData("spanName").(string)
+		value, captured := runtime.PanicValue(ictx)
+		recordPanic(ctx, span, spanName, value, captured)
+		if wroteHeader {
+			span.SetAttributes(semconv.HTTPServerResponseTraceAttrs(statusCode, read, written)...)
+			span.SetAttributes(sseAttrs...)
+		}
+		logger.Debug("AfterServeHTTP: handler panicked")
+		return
+	}
+
 	// Add response attributes
-	attrs := semconv.HTTPServerResponseTraceAttrs(statusCode, 0)
+	attrs := semconv.HTTPServerResponseTraceAttrs(statusCode, read, written)
 	span.SetAttributes(attrs...)
+	span.SetAttributes(sseAttrs...)
 
 	// Set span status based on status code
 	code, desc := semconv.HTTPServerStatus(statusCode)
@@ -5,22 +5,34 @@ package server
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"runtime/pprof"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
 )
 
 func setupTestTracer(t *testing.T) (*tracetest.SpanRecorder, *sdktrace.TracerProvider) {
@@ -98,6 +110,82 @@ func TestBeforeServeHTTP(t *testing.T) {
 				assert.True(t, spanCtx.IsValid())
 			},
 		},
+		{
+			name: "query keys only",
+			setupEnv: func(t *testing.T) {
+				t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+				t.Setenv(envQueryKeysOnly, "true")
+			},
+			setupRequest: func() *http.Request {
+				return httptest.NewRequest("GET", "http://example.com/search?q=secret&page=2", nil)
+			},
+			expectSpan: true,
+			validateSpan: func(t *testing.T, span trace.Span) {
+				ro, ok := span.(sdktrace.ReadOnlySpan)
+				require.True(t, ok)
+				attrs := make(map[string]interface{})
+				for _, kv := range ro.Attributes() {
+					attrs[string(kv.Key)] = kv.Value.AsInterface()
+				}
+				assert.Equal(t, []string{"page", "q"}, attrs["url.query.keys"])
+				assert.NotContains(t, attrs, "url.query")
+			},
+		},
+		{
+			name: "tenant header configured and present",
+			setupEnv: func(t *testing.T) {
+				t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+				t.Setenv(envTenantHeader, "X-Tenant-ID")
+			},
+			setupRequest: func() *http.Request {
+				req := httptest.NewRequest("GET", "http://example.com/orders", nil)
+				req.Header.Set("X-Tenant-ID", "acme")
+				return req
+			},
+			expectSpan: true,
+			validateSpan: func(t *testing.T, span trace.Span) {
+				ro, ok := span.(sdktrace.ReadOnlySpan)
+				require.True(t, ok)
+				assert.Contains(t, ro.Attributes(), attribute.String("tenant.id", "acme"))
+			},
+		},
+		{
+			name: "tenant header configured but absent",
+			setupEnv: func(t *testing.T) {
+				t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+				t.Setenv(envTenantHeader, "X-Tenant-ID")
+			},
+			setupRequest: func() *http.Request {
+				return httptest.NewRequest("GET", "http://example.com/orders", nil)
+			},
+			expectSpan: true,
+			validateSpan: func(t *testing.T, span trace.Span) {
+				ro, ok := span.(sdktrace.ReadOnlySpan)
+				require.True(t, ok)
+				for _, kv := range ro.Attributes() {
+					assert.NotEqual(t, "tenant.id", string(kv.Key))
+				}
+			},
+		},
+		{
+			name: "tenant header not configured",
+			setupEnv: func(t *testing.T) {
+				t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+			},
+			setupRequest: func() *http.Request {
+				req := httptest.NewRequest("GET", "http://example.com/orders", nil)
+				req.Header.Set("X-Tenant-ID", "acme")
+				return req
+			},
+			expectSpan: true,
+			validateSpan: func(t *testing.T, span trace.Span) {
+				ro, ok := span.(sdktrace.ReadOnlySpan)
+				require.True(t, ok)
+				for _, kv := range ro.Attributes() {
+					assert.NotEqual(t, "tenant.id", string(kv.Key))
+				}
+			},
+		},
 		{
 			name: "request with route pattern (Go 1.22+)",
 			setupEnv: func(t *testing.T) {
@@ -369,6 +457,40 @@ func TestAfterServeHTTP(t *testing.T) {
 	}
 }
 
+// goroutineLabeled reports whether a goroutine in the current profile carries
+// the given span name as its pprof label.
+func goroutineLabeled(t *testing.T, spanName string) bool {
+	t.Helper()
+	var buf strings.Builder
+	require.NoError(t, pprof.Lookup("goroutine").WriteTo(&buf, 1))
+	return strings.Contains(buf.String(), `"`+runtime.SpanNameProfileLabel+`":"`+spanName+`"`)
+}
+
+func TestServeHTTP_ProfileLabels(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	t.Setenv("OTEL_GO_PPROF_LABELS", "true")
+	setupTestTracer(t)
+
+	req := httptest.NewRequest("GET", "http://example.com/compute", nil)
+	mockCtx := hooktest.NewMockHookContext()
+
+	BeforeServeHTTP(mockCtx, nil, httptest.NewRecorder(), req)
+
+	// The handler runs between the hooks: its goroutine and request context
+	// must both carry the span name label.
+	assert.True(t, goroutineLabeled(t, "GET"), "goroutine should be labeled while the handler runs")
+	updatedReq, ok := mockCtx.GetParam(requestIndex).(*http.Request)
+	require.True(t, ok)
+	name, ok := pprof.Label(updatedReq.Context(), runtime.SpanNameProfileLabel)
+	assert.True(t, ok)
+	assert.Equal(t, "GET", name)
+
+	AfterServeHTTP(mockCtx)
+
+	assert.False(t, goroutineLabeled(t, "GET"), "labels should be restored after the handler returns")
+}
+
 func TestServerEnabler(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -466,3 +588,589 @@ func TestWriterWrapper_IntegrationWithHandler(t *testing.T) {
 		})
 	}
 }
+
+// apiHandler stands for a framework handler with methods beyond ServeHTTP.
+type apiHandler struct{}
+
+func (*apiHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	// Reaches the connection's writer through the instrumentation's wrapper
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(time.Minute)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (*apiHandler) Name() string { return "api" }
+
+// namingMiddleware needs the concrete type of the handler it wraps.
+func namingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api, ok := next.(*apiHandler)
+		if !ok {
+			http.Error(w, "unexpected handler type", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("X-Handler", api.Name())
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestServeHTTP_PreservesMiddlewareChain(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	sr, _ := setupTestTracer(t)
+
+	chain := namingMiddleware(&apiHandler{})
+	// Emulate the instrumented dispatch boundary: the hooks run around the
+	// server's call into the handler chain, which is passed through unchanged.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ictx := hooktest.NewMockHookContext(nil, w, r)
+		BeforeServeHTTP(ictx, nil, w, r)
+		defer AfterServeHTTP(ictx)
+		chain.ServeHTTP(ictx.GetParam(responseWriterIndex).(http.ResponseWriter),
+			ictx.GetParam(requestIndex).(*http.Request))
+	}))
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+
+	assert.Equal(t, http.StatusAccepted, res.StatusCode)
+	assert.Equal(t, "api", res.Header.Get("X-Handler"))
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("http.response.status_code", http.StatusAccepted))
+}
+
+// logRecorder is a log processor that keeps every emitted record.
+type logRecorder struct {
+	records []sdklog.Record
+}
+
+func (r *logRecorder) OnEmit(_ context.Context, record *sdklog.Record) error {
+	r.records = append(r.records, record.Clone())
+	return nil
+}
+
+func (r *logRecorder) Shutdown(context.Context) error   { return nil }
+func (r *logRecorder) ForceFlush(context.Context) error { return nil }
+
+// servePanicking runs the hooks around a handler that panics, the way the
+// trampoline defers the After hook, and recovers the panic afterwards as
+// net/http does. It returns the recovered value.
+func servePanicking(ictx hook.HookContext, req *http.Request) (recovered any) {
+	defer func() { recovered = recover() }()
+	BeforeServeHTTP(ictx, nil, httptest.NewRecorder(), req)
+	defer AfterServeHTTP(ictx)
+	panic("boom")
+}
+
+func TestAfterServeHTTP_Panic(t *testing.T) {
+	tests := []struct {
+		name      string
+		panicLogs string
+	}{
+		{name: "span only", panicLogs: ""},
+		{name: "span and log record", panicLogs: "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initOnce = *new(sync.Once)
+			t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+			t.Setenv(envPanicLogs, tt.panicLogs)
+			sr, _ := setupTestTracer(t)
+			logs := &logRecorder{}
+			lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(logs))
+			global.SetLoggerProvider(lp)
+			t.Cleanup(func() { _ = lp.Shutdown(context.Background()) })
+
+			req := httptest.NewRequest("GET", "http://example.com/panic", nil)
+			recovered := servePanicking(hooktest.NewMockHookContext(), req)
+			assert.Equal(t, "boom", recovered, "the panic must keep propagating")
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			span := spans[0]
+			assert.Equal(t, codes.Error, span.Status().Code)
+			assert.Contains(t, span.Attributes(), attribute.String("error.type", "panic"))
+			for _, kv := range span.Attributes() {
+				assert.NotEqual(t, "http.response.status_code", string(kv.Key),
+					"no response was sent, so no status code should be recorded")
+			}
+			require.Len(t, span.Events(), 1)
+			event := span.Events()[0]
+			assert.Equal(t, "exception", event.Name)
+			var stack string
+			for _, kv := range event.Attributes {
+				if kv.Key == exceptionStackKey {
+					stack = kv.Value.AsString()
+				}
+			}
+			assert.Contains(t, stack, "servePanicking", "stack should include the panicking handler")
+
+			if tt.panicLogs == "" {
+				assert.Empty(t, logs.records)
+				return
+			}
+			require.Len(t, logs.records, 1)
+			record := logs.records[0]
+			assert.Equal(t, otellog.SeverityError, record.Severity())
+			assert.Equal(t, "panic serving GET", record.Body().AsString())
+			assert.Equal(t, span.SpanContext().TraceID(), record.TraceID())
+		})
+	}
+}
+
+// afterCapturingPanic runs AfterServeHTTP as the After trampoline of a rule with
+// capture_panic does: it recovers the panic of the handler, hands its value to
+// the hook and raises it again.
+func afterCapturingPanic(ictx hook.HookContext) {
+	if r := recover(); r != nil {
+		defer panic(r)
+		ictx.SetKeyData(runtime.PanicValueKey, r)
+	}
+	AfterServeHTTP(ictx)
+}
+
+func TestAfterServeHTTP_CapturedPanic(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	sr, _ := setupTestTracer(t)
+
+	ictx := hooktest.NewMockHookContext()
+	req := httptest.NewRequest("GET", "http://example.com/panic", nil)
+	recovered := func() (recovered any) {
+		defer func() { recovered = recover() }()
+		BeforeServeHTTP(ictx, nil, httptest.NewRecorder(), req)
+		defer afterCapturingPanic(ictx)
+		panic(fmt.Errorf("nil cart"))
+	}()
+	require.EqualError(t, recovered.(error), "nil cart", "the panic must keep propagating")
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Equal(t, "nil cart", span.Status().Description)
+	require.Len(t, span.Events(), 1)
+	assert.Contains(t, span.Events()[0].Attributes, exceptionMessageKey.String("nil cart"))
+}
+
+// serveRecovering runs the hooks around a function that recovers its own
+// panic, whose deferred recover thus runs before the After hook.
+func serveRecovering(ictx hook.HookContext, req *http.Request) {
+	BeforeServeHTTP(ictx, nil, httptest.NewRecorder(), req)
+	defer AfterServeHTTP(ictx)
+	defer func() { _ = recover() }()
+	panic("boom")
+}
+
+func TestAfterServeHTTP_RecoveredPanic(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	sr, _ := setupTestTracer(t)
+
+	serveRecovering(hooktest.NewMockHookContext(), httptest.NewRequest("GET", "http://example.com/recovered", nil))
+	recovered := servePanicking(hooktest.NewMockHookContext(), httptest.NewRequest("GET", "http://example.com/panic", nil))
+	assert.Equal(t, "boom", recovered)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	recoveredSpan, panickedSpan := spans[0], spans[1]
+	assert.Equal(t, codes.Unset, recoveredSpan.Status().Code, "a panic the function recovered is not an error")
+	assert.NotContains(t, recoveredSpan.Attributes(), attribute.String("error.type", "panic"))
+	assert.Empty(t, recoveredSpan.Events())
+	assert.Contains(t, recoveredSpan.Attributes(), attribute.Int("http.response.status_code", http.StatusOK))
+
+	assert.Equal(t, codes.Error, panickedSpan.Status().Code, "an unrecovered panic is an error")
+	assert.Contains(t, panickedSpan.Attributes(), attribute.String("error.type", "panic"))
+}
+
+// TestServeHTTP_TraceStatePropagation verifies that an incoming tracestate is
+// kept on the server span and injected again on downstream requests.
+func TestServeHTTP_TraceStatePropagation(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	sr, _ := setupTestTracer(t)
+
+	const incomingTraceState = "vendor=sampled:1,other=abc"
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0bb902b7-01")
+	req.Header.Set("tracestate", incomingTraceState)
+
+	ictx := hooktest.NewMockHookContext(nil, httptest.NewRecorder(), req)
+	BeforeServeHTTP(ictx, nil, httptest.NewRecorder(), req)
+	handlerReq, ok := ictx.GetParam(requestIndex).(*http.Request)
+	require.True(t, ok)
+
+	downstream := http.Header{}
+	otel.GetTextMapPropagator().Inject(handlerReq.Context(), propagation.HeaderCarrier(downstream))
+	AfterServeHTTP(ictx)
+
+	assert.Equal(t, incomingTraceState, downstream.Get("tracestate"))
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, incomingTraceState, spans[0].SpanContext().TraceState().String())
+}
+
+func TestServeHTTP_GoroutineDelta(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	t.Setenv(envGoroutineDelta, "true")
+	setupTestTracer(t)
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { _ = mp.Shutdown(context.Background()) })
+	otel.SetMeterProvider(mp)
+	t.Cleanup(func() { initGoroutineDelta("") })
+
+	leaked := make(chan struct{})
+	defer close(leaked)
+	serve := func(leak bool) {
+		req := httptest.NewRequest(http.MethodGet, "/jobs", nil)
+		req.Pattern = "GET /jobs"
+		ictx := hooktest.NewMockHookContext(nil, httptest.NewRecorder(), req)
+		BeforeServeHTTP(ictx, nil, httptest.NewRecorder(), req)
+		if leak {
+			started := make(chan struct{})
+			go func() {
+				close(started)
+				<-leaked
+			}()
+			<-started
+		}
+		AfterServeHTTP(ictx)
+	}
+	serve(false)
+	serve(true)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	m := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, goroutineDeltaMetric, m.Name)
+	hist, ok := m.Data.(metricdata.Histogram[int64])
+	require.True(t, ok)
+	require.Len(t, hist.DataPoints, 1)
+	dp := hist.DataPoints[0]
+	assert.Equal(t, uint64(2), dp.Count, "one measurement per request")
+	assert.Equal(t, int64(1), dp.Sum, "only the leaking request leaves a goroutine behind")
+	route, ok := dp.Attributes.Value("http.route")
+	require.True(t, ok)
+	assert.Equal(t, "/jobs", route.AsString())
+}
+
+func TestServeHTTP_RouteParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   string
+		expected []attribute.KeyValue
+		absent   []attribute.Key
+	}{
+		{
+			name:     "configured",
+			params:   "id, format",
+			expected: []attribute.KeyValue{attribute.String("http.route.param.id", "123")},
+			absent:   []attribute.Key{"http.route.param.order"},
+		},
+		{
+			name:   "not configured",
+			absent: []attribute.Key{"http.route.param.id", "http.route.param.order"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initOnce = *new(sync.Once)
+			t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+			t.Setenv(envRouteParams, tt.params)
+			sr, _ := setupTestTracer(t)
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /users/{id}/orders/{order}", func(http.ResponseWriter, *http.Request) {})
+			// The hooks run around the server's dispatch to the mux, before it
+			// has routed the request
+			req := httptest.NewRequest(http.MethodGet, "/users/123/orders/9", nil)
+			ictx := hooktest.NewMockHookContext(nil, httptest.NewRecorder(), req)
+			BeforeServeHTTP(ictx, nil, httptest.NewRecorder(), req)
+			w, _ := ictx.GetParam(responseWriterIndex).(http.ResponseWriter)
+			served, _ := ictx.GetParam(requestIndex).(*http.Request)
+			mux.ServeHTTP(w, served)
+			AfterServeHTTP(ictx)
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			attrs := spans[0].Attributes()
+			assert.Contains(t, attrs, attribute.String("http.route", "/users/{id}/orders/{order}"))
+			for _, kv := range tt.expected {
+				assert.Contains(t, attrs, kv)
+			}
+			for _, kv := range attrs {
+				assert.NotContains(t, tt.absent, kv.Key)
+			}
+		})
+	}
+}
+
+func TestServeHTTP_RequestCount(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	t.Setenv(envRequestCount, "true")
+	setupTestTracer(t)
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { _ = mp.Shutdown(context.Background()) })
+	otel.SetMeterProvider(mp)
+	t.Cleanup(func() { initRequestCount("") })
+
+	serve := func(method, pattern string, status int) {
+		req := httptest.NewRequest(method, "/users/42", nil)
+		ictx := hooktest.NewMockHookContext(nil, httptest.NewRecorder(), req)
+		BeforeServeHTTP(ictx, nil, httptest.NewRecorder(), req)
+		// ServeMux sets the pattern on the request it dispatches
+		served, _ := ictx.GetParam(requestIndex).(*http.Request)
+		served.Pattern = pattern
+		w, _ := ictx.GetParam(responseWriterIndex).(http.ResponseWriter)
+		w.WriteHeader(status)
+		AfterServeHTTP(ictx)
+	}
+	serve(http.MethodGet, "GET /users/{id}", http.StatusOK)
+	serve(http.MethodGet, "GET /users/{id}", http.StatusOK)
+	serve(http.MethodGet, "GET /users/{id}", http.StatusNotFound)
+	serve(http.MethodDelete, "DELETE /users/{id}", http.StatusNoContent)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	m := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, requestCountMetric, m.Name)
+	sum, ok := m.Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	assert.True(t, sum.IsMonotonic)
+
+	counts := make(map[string]int64)
+	for _, dp := range sum.DataPoints {
+		method, _ := dp.Attributes.Value("http.request.method")
+		route, _ := dp.Attributes.Value("http.route")
+		status, _ := dp.Attributes.Value("http.response.status_code")
+		counts[fmt.Sprintf("%s %s %d", method.AsString(), route.AsString(), status.AsInt64())] = dp.Value
+	}
+	assert.Equal(t, map[string]int64{
+		"GET /users/{id} 200":    2,
+		"GET /users/{id} 404":    1,
+		"DELETE /users/{id} 204": 1,
+	}, counts)
+}
+
+func TestBeforeServeHTTP_SpanNameTemplate(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	sr, _ := setupTestTracer(t)
+
+	req := httptest.NewRequest("GET", "http://api.example.com/users/123", nil)
+	req.Pattern = "GET /users/{id}"
+	mockCtx := hooktest.NewMockHookContext()
+	// Set by the trampoline of a rule with span_name
+	mockCtx.SetKeyData(runtime.SpanNameTemplateKey, "{method} {host}{route}")
+
+	BeforeServeHTTP(mockCtx, nil, httptest.NewRecorder(), req)
+	AfterServeHTTP(mockCtx)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET api.example.com/users/{id}", spans[0].Name())
+}
+
+func TestBeforeServeHTTP_SpanNameTemplateBaggage(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	sr, _ := setupTestTracer(t)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	req := httptest.NewRequest("GET", "http://api.example.com/users/123", nil)
+	req.Pattern = "GET /users/{id}"
+	req.Header.Set("Baggage", "tenant=acme")
+	mockCtx := hooktest.NewMockHookContext()
+	mockCtx.SetKeyData(runtime.SpanNameTemplateKey, "{baggage.tenant} {method} {route}")
+
+	BeforeServeHTTP(mockCtx, nil, httptest.NewRecorder(), req)
+	AfterServeHTTP(mockCtx)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "acme GET /users/{id}", spans[0].Name())
+}
+
+func TestServeHTTP_QueueTime(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	t.Setenv(envQueueTime, "true")
+	sr, _ := setupTestTracer(t)
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { _ = mp.Shutdown(context.Background()) })
+	otel.SetMeterProvider(mp)
+	t.Cleanup(func() { initQueueTime("") })
+
+	// Set by the server_request_received rule when net/http reads the request
+	req := httptest.NewRequest("GET", "http://example.com/orders", nil)
+	req = req.WithContext(context.WithValue(req.Context(), receivedKey, time.Now().Add(-50*time.Millisecond)))
+	ictx := hooktest.NewMockHookContext()
+	BeforeServeHTTP(ictx, nil, httptest.NewRecorder(), req)
+	AfterServeHTTP(ictx)
+
+	// Without an instrumented net/http, the queue time is unknown
+	ictx = hooktest.NewMockHookContext()
+	BeforeServeHTTP(ictx, nil, httptest.NewRecorder(), httptest.NewRequest("GET", "http://example.com/orders", nil))
+	AfterServeHTTP(ictx)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	var wait attribute.Value
+	for _, attr := range spans[0].Attributes() {
+		if attr.Key == queueTimeKey {
+			wait = attr.Value
+		}
+	}
+	require.Equal(t, attribute.FLOAT64, wait.Type())
+	assert.GreaterOrEqual(t, wait.AsFloat64(), 0.05)
+	for _, attr := range spans[1].Attributes() {
+		assert.NotEqual(t, queueTimeKey, attr.Key)
+	}
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	m := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, queueTimeMetric, m.Name)
+	hist, ok := m.Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, hist.DataPoints, 1)
+	assert.Equal(t, uint64(1), hist.DataPoints[0].Count, "only the request with a known queue time is recorded")
+	assert.GreaterOrEqual(t, hist.DataPoints[0].Sum, 0.05)
+}
+
+func TestRequestQueueTime_ClockSkew(t *testing.T) {
+	req := httptest.NewRequest("GET", "http://example.com/", nil)
+	req = req.WithContext(context.WithValue(req.Context(), receivedKey, time.Now().Add(time.Hour)))
+	wait, ok := requestQueueTime(req)
+	require.True(t, ok)
+	assert.Zero(t, wait, "the queue time is never negative")
+}
+
+func TestServeHTTP_BodySizes(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	sr, _ := setupTestTracer(t)
+
+	const payload = "hello, world"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ictx := hooktest.NewMockHookContext(nil, w, r)
+		BeforeServeHTTP(ictx, nil, w, r)
+		defer AfterServeHTTP(ictx)
+		w, _ = ictx.GetParam(responseWriterIndex).(http.ResponseWriter)
+		r, _ = ictx.GetParam(requestIndex).(*http.Request)
+		_, _ = io.Copy(io.Discard, r.Body)
+		// Flushing between writes sends the response chunked, with no
+		// Content-Length
+		for range 3 {
+			_, _ = w.Write([]byte(payload))
+			http.NewResponseController(w).Flush()
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		body io.Reader
+	}{
+		// strings.Reader bodies are sent with their Content-Length
+		{"known length", strings.NewReader(payload)},
+		// Other readers are sent chunked, with a ContentLength of -1
+		{"chunked", io.MultiReader(strings.NewReader(payload))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr.Reset()
+			res, err := http.Post(server.URL, "text/plain", tt.body)
+			require.NoError(t, err)
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			_ = res.Body.Close()
+			require.Equal(t, strings.Repeat(payload, 3), string(body))
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			attrs := spans[0].Attributes()
+			assert.Contains(t, attrs, attribute.Int("http.request.body.size", len(payload)))
+			assert.Contains(t, attrs, attribute.Int("http.response.body.size", 3*len(payload)))
+		})
+	}
+}
+
+func TestServeHTTP_ServerSentEvents(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	sr, _ := setupTestTracer(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ictx := hooktest.NewMockHookContext(nil, w, r)
+		BeforeServeHTTP(ictx, nil, w, r)
+		defer AfterServeHTTP(ictx)
+		w, _ = ictx.GetParam(responseWriterIndex).(http.ResponseWriter)
+		if r.URL.Path == "/events" {
+			w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+		}
+		rc := http.NewResponseController(w)
+		for i := range 3 {
+			_, _ = fmt.Fprintf(w, "data: %d\n\n", i)
+			_ = rc.Flush()
+			// A flush without new data sends no event
+			_ = rc.Flush()
+		}
+		// The last event is sent when the handler returns
+		_, _ = w.Write([]byte("data: done\n\n"))
+	}))
+	defer server.Close()
+
+	t.Run("event stream", func(t *testing.T) {
+		sr.Reset()
+		res, err := http.Get(server.URL + "/events")
+		require.NoError(t, err)
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+
+		spans := sr.Ended()
+		require.Len(t, spans, 1)
+		attrs := spans[0].Attributes()
+		assert.Contains(t, attrs, sseEventCountKey.Int64(4))
+		var duration float64
+		for _, attr := range attrs {
+			if attr.Key == sseDurationKey {
+				duration = attr.Value.AsFloat64()
+			}
+		}
+		assert.Positive(t, duration)
+	})
+
+	t.Run("other response", func(t *testing.T) {
+		sr.Reset()
+		res, err := http.Get(server.URL + "/plain")
+		require.NoError(t, err)
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+
+		spans := sr.Ended()
+		require.Len(t, spans, 1)
+		for _, attr := range spans[0].Attributes() {
+			assert.NotEqual(t, sseEventCountKey, attr.Key)
+			assert.NotEqual(t, sseDurationKey, attr.Key)
+		}
+	})
+}
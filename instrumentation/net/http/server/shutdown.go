@@ -0,0 +1,56 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+)
+
+// shutdownFlushTimeout bounds the span flush that follows a graceful shutdown
+// when the shutdown context has no usable deadline.
+const shutdownFlushTimeout = 5 * time.Second
+
+// BeforeShutdown keeps the shutdown context so that AfterShutdown can bound the
+// span flush by the caller's grace period.
+func BeforeShutdown(ictx hook.HookContext, _ *http.Server, ctx context.Context) {
+	if !serverEnabler.Enable() {
+		return
+	}
+	ictx.SetData(ctx)
+}
+
+// AfterShutdown flushes the spans of requests that completed while the server
+// was draining. Without it, they may sit in the batch processor until the
+// process exits and be lost.
+func AfterShutdown(ictx hook.HookContext, _ error) {
+	if !serverEnabler.Enable() {
+		return
+	}
+	ctx, ok := ictx.GetData().(context.Context)
+	if !ok {
+		return
+	}
+	if err := runtime.ForceFlush(ctx, flushTimeout(ctx)); err != nil {
+		logger.Warn("failed to flush spans on server shutdown", "error", err)
+	}
+}
+
+// flushTimeout returns what is left of the shutdown grace period, capped at
+// shutdownFlushTimeout. An expired or missing deadline gets the full cap.
+func flushTimeout(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return shutdownFlushTimeout
+	}
+	remaining := time.Until(deadline)
+	if remaining <= 0 || remaining > shutdownFlushTimeout {
+		return shutdownFlushTimeout
+	}
+	return remaining
+}
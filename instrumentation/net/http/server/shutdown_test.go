@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+)
+
+// TestShutdown_FlushesInFlightSpans verifies that the span of a request that
+// completes while the server is shutting down is exported once Shutdown
+// returns, even though the batch processor would otherwise hold it.
+func TestShutdown_FlushesInFlightSpans(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter, sdktrace.WithBatchTimeout(time.Hour)),
+	)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	otel.SetTracerProvider(provider)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+	// Emulate the instrumented dispatch boundary around the handler.
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ictx := hooktest.NewMockHookContext(nil, w, r)
+			BeforeServeHTTP(ictx, nil, w, r)
+			defer AfterServeHTTP(ictx)
+			handler.ServeHTTP(ictx.GetParam(responseWriterIndex).(http.ResponseWriter),
+				ictx.GetParam(requestIndex).(*http.Request))
+		}),
+		ReadHeaderTimeout: time.Second,
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = srv.Serve(ln) }()
+
+	go func() {
+		res, getErr := http.Get("http://" + ln.Addr().String())
+		if getErr == nil {
+			_ = res.Body.Close()
+		}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// Let the request complete only once shutdown is under way.
+	time.AfterFunc(50*time.Millisecond, func() { close(release) })
+	ictx := hooktest.NewMockHookContext(ctx)
+	BeforeShutdown(ictx, srv, ctx)
+	err = srv.Shutdown(ctx)
+	AfterShutdown(ictx, err)
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET", spans[0].Name)
+}
+
+func TestFlushTimeout(t *testing.T) {
+	expired, cancel := context.WithDeadline(t.Context(), time.Now().Add(-time.Second))
+	defer cancel()
+	short, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+	long, cancel := context.WithTimeout(t.Context(), time.Minute)
+	defer cancel()
+
+	assert.Equal(t, shutdownFlushTimeout, flushTimeout(t.Context()))
+	assert.Equal(t, shutdownFlushTimeout, flushTimeout(expired))
+	assert.Equal(t, shutdownFlushTimeout, flushTimeout(long))
+	assert.LessOrEqual(t, flushTimeout(short), time.Second)
+}
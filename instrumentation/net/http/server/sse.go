@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"mime"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// sseContentType is the media type of a Server-Sent Events stream
+	sseContentType = "text/event-stream"
+
+	// sseEventCountKey and sseDurationKey record on the span of a Server-Sent
+	// Events response how many events it sent and how long, in seconds, the
+	// stream stayed open.
+	sseEventCountKey = attribute.Key("http.response.sse.event.count")
+	sseDurationKey   = attribute.Key("http.response.sse.duration")
+)
+
+// isSSE reports whether the handler answered with a Server-Sent Events stream.
+func (w *writerWrapper) isSSE() bool {
+	if !w.wroteHeader {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	return err == nil && mediaType == sseContentType
+}
+
+// sseAttrs returns the event count and duration of the Server-Sent Events
+// stream the handler wrote, or nothing for another response. Handlers flush
+// every event for the client to receive it right away, so the events are
+// counted as the flushes that sent data, plus the data left for net/http to
+// send when the handler returns.
+func (w *writerWrapper) sseAttrs() []attribute.KeyValue {
+	if !w.isSSE() {
+		return nil
+	}
+	events := w.flushes
+	if w.unflushed {
+		events++
+	}
+	return []attribute.KeyValue{
+		sseEventCountKey.Int64(events),
+		sseDurationKey.Float64(time.Since(w.headerTime).Seconds()),
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+)
+
+// servingKey marks the context of a request whose server span is already
+// started, so that nested wrapped handlers do not start another one.
+type servingKey struct{}
+
+// WrapHandler returns h instrumented like the handlers served by net/http.
+// It is meant for call rules on handler constructors (wrap_call with
+// wrap_interface: net/http.Handler), so that handlers dispatched outside of
+// http.Server, by a framework or adapter that cannot be instrumented, still
+// produce server spans. Requests that already have a server span, because
+// http.Server or another wrapper served them, are passed through unchanged.
+func WrapHandler(h http.Handler) http.Handler {
+	if h == nil {
+		return nil
+	}
+	if _, wrapped := h.(*instrumentedHandler); wrapped {
+		return h
+	}
+	return &instrumentedHandler{next: h}
+}
+
+type instrumentedHandler struct {
+	next http.Handler
+}
+
+func (h *instrumentedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Value(servingKey{}) != nil {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	ictx := &dispatchContext{params: []interface{}{h.next, w, r}}
+	BeforeServeHTTP(ictx, h.next, w, r)
+	defer AfterServeHTTP(ictx)
+	w, _ = ictx.GetParam(responseWriterIndex).(http.ResponseWriter)
+	r, _ = ictx.GetParam(requestIndex).(*http.Request)
+	h.next.ServeHTTP(w, r)
+}
+
+// dispatchContext is the hook.HookContext of a wrapped handler dispatch, which
+// lets WrapHandler share the hooks injected into net/http.
+type dispatchContext struct {
+	params []interface{}
+	data   interface{}
+}
+
+var _ hook.HookContext = (*dispatchContext)(nil)
+
+func (c *dispatchContext) SetSkipCall(bool)                  {}
+func (c *dispatchContext) IsSkipCall() bool                  { return false }
+func (c *dispatchContext) SetData(data interface{})          { c.data = data }
+func (c *dispatchContext) GetData() interface{}              { return c.data }
+func (c *dispatchContext) GetParamCount() int                { return len(c.params) }
+func (c *dispatchContext) GetParam(idx int) interface{}      { return c.params[idx] }
+func (c *dispatchContext) SetParam(idx int, val interface{}) { c.params[idx] = val }
+func (c *dispatchContext) GetReturnValCount() int            { return 0 }
+func (c *dispatchContext) GetReturnVal(int) interface{}      { return nil }
+func (c *dispatchContext) SetReturnVal(int, interface{})     {}
+func (c *dispatchContext) GetFuncName() string               { return "ServeHTTP" }
+func (c *dispatchContext) GetPackageName() string            { return "net/http" }
+
+func (c *dispatchContext) GetKeyData(key string) interface{} {
+	m, _ := c.data.(map[string]interface{})
+	return m[key]
+}
+
+func (c *dispatchContext) SetKeyData(key string, val interface{}) {
+	m, ok := c.data.(map[string]interface{})
+	if !ok {
+		m = make(map[string]interface{})
+		c.data = m
+	}
+	m[key] = val
+}
+
+func (c *dispatchContext) HasKeyData(key string) bool {
+	m, _ := c.data.(map[string]interface{})
+	_, ok := m[key]
+	return ok
+}
@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+)
+
+func TestWrapHandler(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	sr, _ := setupTestTracer(t)
+
+	var handlerSpan trace.SpanContext
+	h := WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	// Dispatch through the interface, as a framework would, twice
+	for range 2 {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://example.com/brew", nil))
+	}
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2, "every dispatch produces a span")
+	for _, span := range spans {
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+		assert.Equal(t, "GET", span.Name())
+		assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", http.StatusTeapot))
+		assert.Equal(t, codes.Unset, span.Status().Code)
+	}
+	assert.Equal(t, spans[1].SpanContext(), handlerSpan, "the handler runs within the span")
+}
+
+func TestWrapHandler_AlreadyServed(t *testing.T) {
+	initOnce = *new(sync.Once)
+	t.Setenv("OTEL_GO_ENABLED_INSTRUMENTATIONS", "nethttp")
+	sr, _ := setupTestTracer(t)
+
+	// The request is served by an instrumented http.Server
+	mockCtx := hooktest.NewMockHookContext()
+	BeforeServeHTTP(mockCtx, nil, httptest.NewRecorder(), httptest.NewRequest("GET", "http://example.com/", nil))
+	w, _ := mockCtx.GetParam(responseWriterIndex).(http.ResponseWriter)
+	r, _ := mockCtx.GetParam(requestIndex).(*http.Request)
+
+	called := false
+	WrapHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		called = true
+	})).ServeHTTP(w, r)
+	AfterServeHTTP(mockCtx)
+
+	assert.True(t, called)
+	assert.Len(t, sr.Ended(), 1, "the wrapper must not start a second server span")
+}
+
+func TestWrapHandler_Idempotent(t *testing.T) {
+	assert.Nil(t, WrapHandler(nil))
+
+	h := WrapHandler(http.NotFoundHandler())
+	assert.Same(t, h, WrapHandler(h))
+}
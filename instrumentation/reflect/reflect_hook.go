@@ -0,0 +1,174 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package reflect
+
+import (
+	"context"
+	"os"
+	"reflect"
+	goruntime "runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+)
+
+const (
+	instrumentationName = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/reflect"
+	instrumentationKey  = "reflect"
+
+	// envCallSpans records, when set to "true", a span for every function or
+	// method called through reflect.Value.Call or CallSlice. Reflection is
+	// used all over, so no span is recorded by default.
+	envCallSpans = "OTEL_GO_REFLECT_CALL_SPANS"
+)
+
+var (
+	logger = runtime.Logger()
+	tracer trace.Tracer
+
+	enabledOnce sync.Once
+	enabled     bool
+
+	// initialized is set once the SDK is set up. initializing keeps the calls
+	// the setup itself makes through reflection from waiting on it.
+	initialized  atomic.Bool
+	initializing atomic.Bool
+
+	// methodFunc returns the receiver type and name of the method a method
+	// value is bound to. It is registered by the file added to package
+	// reflect, and is nil when reflect is not instrumented.
+	methodFunc func(reflect.Value) (reflect.Type, string)
+)
+
+// RegisterMethodFunc is called by the file added to package reflect to hand
+// over the lookup of the method a method value is bound to.
+func RegisterMethodFunc(f func(reflect.Value) (reflect.Type, string)) {
+	methodFunc = f
+}
+
+// moduleVersion extracts the version from the Go module system.
+// Falls back to "dev" if version cannot be determined.
+func moduleVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+
+	// Return the main module version
+	if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		return bi.Main.Version
+	}
+
+	return "dev"
+}
+
+// callSpansEnabled reads envCallSpans once, as the hooks run for every
+// reflective call of the process.
+func callSpansEnabled() bool {
+	enabledOnce.Do(func() {
+		enabled = os.Getenv(envCallSpans) == "true" && runtime.Instrumented(instrumentationKey)
+	})
+	return enabled
+}
+
+// initInstrumentation sets up the SDK on the first reflective call and
+// reports whether it is done.
+func initInstrumentation() bool {
+	if initialized.Load() {
+		return true
+	}
+	if !initializing.CompareAndSwap(false, true) {
+		return false
+	}
+	version := moduleVersion()
+	if err := runtime.SetupOTelSDK(
+		"go.opentelemetry.io/compile-instrumentation/reflect",
+		version,
+	); err != nil {
+		logger.Error("failed to setup OTel SDK", "error", err)
+	}
+	tracer = otel.GetTracerProvider().Tracer(
+		instrumentationName,
+		trace.WithInstrumentationVersion(version),
+	)
+	initialized.Store(true)
+	logger.Info("reflect call instrumentation initialized")
+	return true
+}
+
+// funcName returns the name of the function or method v holds, qualified as
+// in stack traces but without the import path, e.g. "plugins.(*Echo).Handle".
+func funcName(v reflect.Value) string {
+	if methodFunc != nil {
+		if recv, method := methodFunc(v); recv != nil {
+			return methodName(recv, method)
+		}
+	}
+	fn := goruntime.FuncForPC(v.Pointer())
+	if fn == nil {
+		return ""
+	}
+	// Method values of a concrete value are wrapped in a "-fm" function
+	name := strings.TrimSuffix(fn.Name(), "-fm")
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// methodName names the method of recv as funcName names functions.
+func methodName(recv reflect.Type, method string) string {
+	if recv.Kind() == reflect.Pointer {
+		elem := recv.Elem()
+		return pkgName(elem) + ".(*" + elem.Name() + ")." + method
+	}
+	return pkgName(recv) + "." + recv.Name() + "." + method
+}
+
+func pkgName(t reflect.Type) string {
+	path := t.PkgPath()
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// BeforeCall starts a span named after the function or method v holds.
+// Value.CallSlice has the same parameters and shares the hook.
+func BeforeCall(ictx hook.HookContext, v reflect.Value, _ []reflect.Value) {
+	if !callSpansEnabled() || v.Kind() != reflect.Func || v.IsNil() {
+		return
+	}
+	if !initInstrumentation() {
+		return
+	}
+	name := funcName(v)
+	if name == "" {
+		return
+	}
+	// The parent is the current span of the goroutine, as kept by the
+	// instrumented SDK
+	_, span := tracer.Start(context.Background(), name,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(semconv.CodeFunctionName(name)),
+	)
+	ictx.SetData(span)
+}
+
+// AfterCall ends the span of the call, failed if the function panicked.
+func AfterCall(ictx hook.HookContext, _ []reflect.Value) {
+	span, ok := ictx.GetData().(trace.Span)
+	if !ok {
+		return
+	}
+	if runtime.Panicking() {
+		span.SetAttributes(attribute.String("error.type", "panic"))
+		span.SetStatus(codes.Error, "panic")
+	}
+	span.End()
+}
@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package reflect
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook/hooktest"
+)
+
+type Plugin struct{ calls int }
+
+func (p *Plugin) Handle(string) { p.calls++ }
+
+func setupCallSpans(t *testing.T, value string) *tracetest.SpanRecorder {
+	t.Helper()
+	t.Setenv(envCallSpans, value)
+	enabledOnce = *new(sync.Once)
+	t.Cleanup(func() { enabledOnce = *new(sync.Once) })
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	tracer = tp.Tracer(instrumentationName)
+	initialized.Store(true)
+	return sr
+}
+
+// call runs v the way the instrumented reflect.Value.Call does.
+func call(v reflect.Value, in ...reflect.Value) {
+	ictx := hooktest.NewMockHookContext(v, in)
+	BeforeCall(ictx, v, in)
+	out := v.Call(in)
+	AfterCall(ictx, out)
+}
+
+func TestBeforeCall_SpanName(t *testing.T) {
+	p := &Plugin{}
+	tests := []struct {
+		name string
+		fn   reflect.Value
+	}{
+		{name: "method expression", fn: reflect.ValueOf((*Plugin).Handle)},
+		{name: "method value", fn: reflect.ValueOf(p.Handle)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := setupCallSpans(t, "true")
+
+			in := []reflect.Value{reflect.ValueOf("event")}
+			if tt.fn.Type().NumIn() == 2 {
+				in = append([]reflect.Value{reflect.ValueOf(p)}, in...)
+			}
+			call(tt.fn, in...)
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "reflect.(*Plugin).Handle", spans[0].Name())
+			assert.Contains(t, spans[0].Attributes(), semconv.CodeFunctionName("reflect.(*Plugin).Handle"))
+		})
+	}
+	assert.Equal(t, 2, p.calls)
+}
+
+func TestBeforeCall_MethodByName(t *testing.T) {
+	sr := setupCallSpans(t, "true")
+	// Stands in for the lookup registered by the file added to package
+	// reflect, which this test does not build with
+	methodFunc = func(reflect.Value) (reflect.Type, string) {
+		return reflect.TypeFor[*Plugin](), "Handle"
+	}
+	t.Cleanup(func() { methodFunc = nil })
+
+	p := &Plugin{}
+	call(reflect.ValueOf(p).MethodByName("Handle"), reflect.ValueOf("event"))
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "reflect.(*Plugin).Handle", spans[0].Name())
+	assert.Equal(t, 1, p.calls)
+}
+
+func TestBeforeCall_Disabled(t *testing.T) {
+	sr := setupCallSpans(t, "")
+
+	call(reflect.ValueOf((&Plugin{}).Handle), reflect.ValueOf("event"))
+
+	assert.Empty(t, sr.Ended(), "no span unless enabled")
+}
+
+func TestMethodName(t *testing.T) {
+	assert.Equal(t, "reflect.(*Plugin).Handle", methodName(reflect.TypeFor[*Plugin](), "Handle"))
+	assert.Equal(t, "time.Duration.String", methodName(reflect.TypeFor[time.Duration](), "String"))
+}
@@ -0,0 +1,26 @@
+//go:build ignore
+
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package reflect
+
+import _ "unsafe"
+
+//go:linkname otelRegisterMethodFunc github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/reflect.RegisterMethodFunc
+func otelRegisterMethodFunc(f func(Value) (Type, string))
+
+func init() {
+	otelRegisterMethodFunc(otelMethod)
+}
+
+// otelMethod returns the receiver type and the name of the method that v, a
+// method value such as the result of Value.MethodByName, is bound to. Such a
+// value only carries the method index, which the public API does not expose.
+func otelMethod(v Value) (Type, string) {
+	if v.flag&flagMethod == 0 {
+		return nil, ""
+	}
+	t := toType(v.typ())
+	return t, t.Method(int(v.flag) >> flagMethodShift).Name
+}
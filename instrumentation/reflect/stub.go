@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package reflect records opt-in spans for the functions and methods called
+// through reflect.Value, for frameworks that dispatch to plugins or handlers
+// by reflection. reflect_method.go, marked with `//go:build ignore`, is added
+// to package reflect by otelc.
+package reflect
@@ -13,9 +13,11 @@ type HookContext interface {
 	SetData(interface{})
 	// Get the data field, can be used to pass information between Before and After hooks
 	GetData() interface{}
-	// Get a value from the data field by key
+	// Get a value from the data field by key, nil unless the data field is a
+	// map[string]interface{}
 	GetKeyData(key string) interface{}
-	// Set a key-value pair in the data field
+	// Set a key-value pair in the data field, replacing any data of another
+	// type set by SetData
 	SetKeyData(key string, val interface{})
 	// Check if a key exists in the data field
 	HasKeyData(key string) bool
@@ -31,24 +31,23 @@ func (m *MockHookContext) SetData(data interface{}) { m.data = data }
 func (m *MockHookContext) GetData() interface{}     { return m.data }
 
 func (m *MockHookContext) SetKeyData(key string, val interface{}) {
-	if m.data == nil {
-		m.data = make(map[string]interface{})
+	data, ok := m.data.(map[string]interface{})
+	if !ok {
+		// Replaces data set by SetData in another form
+		data = make(map[string]interface{})
+		m.data = data
 	}
-	m.data.(map[string]interface{})[key] = val
+	data[key] = val
 }
 
 func (m *MockHookContext) GetKeyData(key string) interface{} {
-	if m.data == nil {
-		return nil
-	}
-	return m.data.(map[string]interface{})[key]
+	data, _ := m.data.(map[string]interface{})
+	return data[key]
 }
 
 func (m *MockHookContext) HasKeyData(key string) bool {
-	if m.data == nil {
-		return false
-	}
-	_, ok := m.data.(map[string]interface{})[key]
+	data, _ := m.data.(map[string]interface{})
+	_, ok := data[key]
 	return ok
 }
 
@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package hooktest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+)
+
+var _ hook.HookContext = (*MockHookContext)(nil)
+
+func TestMockHookContext_KeyData(t *testing.T) {
+	ictx := NewMockHookContext()
+	if ictx.GetKeyData("span") != nil || ictx.HasKeyData("span") {
+		t.Fatal("expected no key data before SetKeyData")
+	}
+
+	ictx.SetKeyData("span", "s")
+	ictx.SetKeyData("start", 42)
+	ictx.SetKeyData("err", nil)
+	ictx.SetKeyData("span", "t")
+	for key, want := range map[string]interface{}{"span": "t", "start": 42, "err": nil} {
+		if got := ictx.GetKeyData(key); got != want {
+			t.Errorf("GetKeyData(%q) = %v, want %v", key, got, want)
+		}
+		// A nil value is still set
+		if !ictx.HasKeyData(key) {
+			t.Errorf("HasKeyData(%q) = false, want true", key)
+		}
+	}
+	if ictx.HasKeyData("ctx") {
+		t.Error(`HasKeyData("ctx") = true, want false`)
+	}
+}
+
+func TestMockHookContext_KeyDataOverSetData(t *testing.T) {
+	ictx := NewMockHookContext()
+	ictx.SetData(map[string]interface{}{"span": "s"})
+	ictx.SetKeyData("start", 42)
+	want := map[string]interface{}{"span": "s", "start": 42}
+	if got := ictx.GetData(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetData() = %v, want %v", got, want)
+	}
+
+	// Data of another type reads as no key, and is replaced by SetKeyData
+	ictx.SetData(map[string]string{"sql": "SELECT 1"})
+	if ictx.GetKeyData("sql") != nil || ictx.HasKeyData("sql") {
+		t.Error("expected no key data over a map[string]string")
+	}
+	ictx.SetKeyData("span", "s")
+	want = map[string]interface{}{"span": "s"}
+	if got := ictx.GetData(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetData() = %v, want %v", got, want)
+	}
+}
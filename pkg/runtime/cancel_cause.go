@@ -0,0 +1,44 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CancelCauseKey records why the context of a failed operation was cancelled,
+// as set through context.WithCancelCause, context.WithDeadlineCause or
+// context.WithTimeoutCause.
+const CancelCauseKey = attribute.Key("context.cancel.cause")
+
+// CancelCause returns the cause ctx was cancelled with, or nil when ctx is not
+// done or was cancelled without a cause of its own, in which case the cause
+// would only repeat the generic context.Canceled or
+// context.DeadlineExceeded.
+func CancelCause(ctx context.Context) error {
+	if ctx == nil || ctx.Err() == nil {
+		return nil
+	}
+	cause := context.Cause(ctx)
+	if cause == nil || cause == ctx.Err() {
+		return nil
+	}
+	return cause
+}
+
+// RecordCancelCause records the cancellation cause of ctx on span, the span of
+// an operation that failed, as CancelCauseKey and returns it. It does nothing
+// and returns nil when ctx has no cause of its own. Callers that flag the span
+// as an error should prefer the cause as the status description.
+func RecordCancelCause(ctx context.Context, span trace.Span) error {
+	cause := CancelCause(ctx)
+	if cause == nil {
+		return nil
+	}
+	span.SetAttributes(CancelCauseKey.String(cause.Error()))
+	return cause
+}
@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestCancelCause(t *testing.T) {
+	errShutdown := errors.New("server shutting down")
+
+	canceled, cancel := context.WithCancel(t.Context())
+	cancel()
+	withCause, cancelCause := context.WithCancelCause(t.Context())
+	cancelCause(errShutdown)
+	child, cancelChild := context.WithCancel(withCause)
+	defer cancelChild()
+	timedOut, cancelTimeout := context.WithTimeoutCause(t.Context(), 0, errShutdown)
+	defer cancelTimeout()
+	<-timedOut.Done()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected error
+	}{
+		{name: "not done", ctx: t.Context(), expected: nil},
+		{name: "cancelled without cause", ctx: canceled, expected: nil},
+		{name: "cancelled with cause", ctx: withCause, expected: errShutdown},
+		{name: "cause of the parent", ctx: child, expected: errShutdown},
+		{name: "timed out with cause", ctx: timedOut, expected: errShutdown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CancelCause(tt.ctx))
+		})
+	}
+}
+
+func TestRecordCancelCause(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	ctx, cancel := context.WithTimeoutCause(t.Context(), time.Nanosecond, errors.New("quota exhausted"))
+	defer cancel()
+	<-ctx.Done()
+	_, span := tracer.Start(ctx, "op")
+	span.SetStatus(codes.Error, context.DeadlineExceeded.Error())
+	cause := RecordCancelCause(ctx, span)
+	require.EqualError(t, cause, "quota exhausted")
+	span.SetStatus(codes.Error, cause.Error())
+	span.End()
+
+	_, plain := tracer.Start(t.Context(), "plain")
+	assert.NoError(t, RecordCancelCause(t.Context(), plain))
+	plain.End()
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 2)
+	assert.Equal(t, "quota exhausted", ended[0].Status().Description)
+	assert.Contains(t, ended[0].Attributes(), CancelCauseKey.String("quota exhausted"))
+	assert.Empty(t, ended[1].Attributes())
+}
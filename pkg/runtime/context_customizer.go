@@ -0,0 +1,40 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ContextCustomizer derives span attributes from the context of an
+// instrumented operation, such as values set by upstream middleware.
+type ContextCustomizer func(ctx context.Context) []attribute.KeyValue
+
+var (
+	customizersMu sync.RWMutex
+	customizers   = []ContextCustomizer{FeatureFlagAttributes}
+)
+
+// RegisterContextCustomizer adds c to the customizers whose attributes
+// ContextAttributes returns. FeatureFlagAttributes is registered by default.
+func RegisterContextCustomizer(c ContextCustomizer) {
+	customizersMu.Lock()
+	defer customizersMu.Unlock()
+	customizers = append(customizers, c)
+}
+
+// ContextAttributes returns the attributes all registered customizers derive
+// from ctx, for the span an instrumentation starts in ctx.
+func ContextAttributes(ctx context.Context) []attribute.KeyValue {
+	customizersMu.RLock()
+	defer customizersMu.RUnlock()
+	var attrs []attribute.KeyValue
+	for _, c := range customizers {
+		attrs = append(attrs, c(ctx)...)
+	}
+	return attrs
+}
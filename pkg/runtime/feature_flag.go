@@ -0,0 +1,45 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Feature flag attributes, following the feature flag semantic conventions.
+const (
+	FeatureFlagKeyKey     = attribute.Key("feature_flag.key")
+	FeatureFlagVariantKey = attribute.Key("feature_flag.variant")
+)
+
+type featureFlagKey struct{}
+
+type featureFlag struct {
+	key     string
+	variant string
+}
+
+// WithFeatureFlag returns a context that carries the feature flag, or
+// experiment, key and the variant evaluated for the current request. Upstream
+// middleware sets it so that the spans instrumentations start in the context
+// are stamped with FeatureFlagKeyKey and FeatureFlagVariantKey.
+func WithFeatureFlag(ctx context.Context, key, variant string) context.Context {
+	return context.WithValue(ctx, featureFlagKey{}, featureFlag{key: key, variant: variant})
+}
+
+// FeatureFlagAttributes is the ContextCustomizer of the feature flag set by
+// WithFeatureFlag. It returns nil when ctx carries none.
+func FeatureFlagAttributes(ctx context.Context) []attribute.KeyValue {
+	flag, ok := ctx.Value(featureFlagKey{}).(featureFlag)
+	if !ok || flag.key == "" {
+		return nil
+	}
+	attrs := []attribute.KeyValue{FeatureFlagKeyKey.String(flag.key)}
+	if flag.variant != "" {
+		attrs = append(attrs, FeatureFlagVariantKey.String(flag.variant))
+	}
+	return attrs
+}
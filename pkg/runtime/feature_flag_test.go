@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestFeatureFlagAttributes(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected []attribute.KeyValue
+	}{
+		{name: "no flag", ctx: context.Background()},
+		{
+			name: "key and variant",
+			ctx:  WithFeatureFlag(context.Background(), "new-checkout", "treatment"),
+			expected: []attribute.KeyValue{
+				FeatureFlagKeyKey.String("new-checkout"),
+				FeatureFlagVariantKey.String("treatment"),
+			},
+		},
+		{
+			name:     "key only",
+			ctx:      WithFeatureFlag(context.Background(), "new-checkout", ""),
+			expected: []attribute.KeyValue{FeatureFlagKeyKey.String("new-checkout")},
+		},
+		{name: "empty key", ctx: WithFeatureFlag(context.Background(), "", "treatment")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FeatureFlagAttributes(tt.ctx))
+		})
+	}
+}
+
+func TestContextAttributes(t *testing.T) {
+	saved := customizers
+	t.Cleanup(func() { customizers = saved })
+
+	type tenantKey struct{}
+	RegisterContextCustomizer(func(ctx context.Context) []attribute.KeyValue {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			return []attribute.KeyValue{attribute.String("tenant.id", tenant)}
+		}
+		return nil
+	})
+
+	ctx := WithFeatureFlag(context.Background(), "new-checkout", "control")
+	ctx = context.WithValue(ctx, tenantKey{}, "acme")
+	assert.Equal(t, []attribute.KeyValue{
+		FeatureFlagKeyKey.String("new-checkout"),
+		FeatureFlagVariantKey.String("control"),
+		attribute.String("tenant.id", "acme"),
+	}, ContextAttributes(ctx))
+	assert.Empty(t, ContextAttributes(context.Background()))
+}
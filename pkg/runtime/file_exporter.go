@@ -0,0 +1,294 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"go.opentelemetry.io/contrib/exporters/autoexport"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	collectormetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// fileExporterName selects the file exporter through OTEL_TRACES_EXPORTER
+	// and OTEL_METRICS_EXPORTER, for hosts that cannot reach a collector.
+	fileExporterName = "file"
+
+	// envExporterFilePath is the file the file exporter appends to. Traces and
+	// metrics may share it: every line holds a single OTLP/JSON export
+	// request, which the collector's otlpjsonfile receiver can replay.
+	envExporterFilePath     = "OTEL_GO_EXPORTER_FILE_PATH"
+	defaultExporterFilePath = "otel-telemetry.jsonl"
+
+	// fileExporterURL is never dialed; requests stop at otlpFileTransport.
+	fileExporterURL = "http://localhost/file"
+)
+
+// registerFileExporters makes the file exporter known to autoexport.
+var registerFileExporters = sync.OnceFunc(func() {
+	autoexport.RegisterSpanExporter(fileExporterName, func(ctx context.Context) (sdktrace.SpanExporter, error) {
+		return newFileSpanExporter(ctx, exporterFilePath())
+	})
+	autoexport.RegisterMetricReader(fileExporterName, func(ctx context.Context) (sdkmetric.Reader, error) {
+		exporter, err := newFileMetricExporter(ctx, exporterFilePath())
+		if err != nil {
+			return nil, err
+		}
+		return sdkmetric.NewPeriodicReader(exporter), nil
+	})
+})
+
+func exporterFilePath() string {
+	if path := os.Getenv(envExporterFilePath); path != "" {
+		return path
+	}
+	return defaultExporterFilePath
+}
+
+// tracesToFile reports whether spans go to the file exporter, which needs no
+// OTLP endpoint.
+func tracesToFile() bool {
+	return os.Getenv("OTEL_TRACES_EXPORTER") == fileExporterName
+}
+
+// The file exporters reuse the OTLP/HTTP exporters, so spans and metrics are
+// converted to OTLP exactly as they would be for a collector, and swap the
+// network for otlpFileTransport.
+
+type fileSpanExporter struct {
+	sdktrace.SpanExporter
+	sink *fileSink
+}
+
+func newFileSpanExporter(ctx context.Context, path string) (*fileSpanExporter, error) {
+	sink, err := openFileSink(path)
+	if err != nil {
+		return nil, err
+	}
+	transport := &otlpFileTransport{
+		sink:       sink,
+		newRequest: func() proto.Message { return &collectortracepb.ExportTraceServiceRequest{} },
+	}
+	exporter, err := otlptracehttp.New(ctx,
+		otlptracehttp.WithEndpointURL(fileExporterURL),
+		otlptracehttp.WithHTTPClient(&http.Client{Transport: transport}),
+		otlptracehttp.WithCompression(otlptracehttp.NoCompression),
+		otlptracehttp.WithRetry(otlptracehttp.RetryConfig{Enabled: false}),
+	)
+	if err != nil {
+		sink.release()
+		return nil, err
+	}
+	return &fileSpanExporter{SpanExporter: exporter, sink: sink}, nil
+}
+
+func (e *fileSpanExporter) Shutdown(ctx context.Context) error {
+	defer e.sink.release()
+	return e.SpanExporter.Shutdown(ctx)
+}
+
+type fileMetricExporter struct {
+	sdkmetric.Exporter
+	sink *fileSink
+}
+
+func newFileMetricExporter(ctx context.Context, path string) (*fileMetricExporter, error) {
+	sink, err := openFileSink(path)
+	if err != nil {
+		return nil, err
+	}
+	transport := &otlpFileTransport{
+		sink:       sink,
+		newRequest: func() proto.Message { return &collectormetricpb.ExportMetricsServiceRequest{} },
+	}
+	exporter, err := otlpmetrichttp.New(ctx,
+		otlpmetrichttp.WithEndpointURL(fileExporterURL),
+		otlpmetrichttp.WithHTTPClient(&http.Client{Transport: transport}),
+		otlpmetrichttp.WithCompression(otlpmetrichttp.NoCompression),
+		otlpmetrichttp.WithRetry(otlpmetrichttp.RetryConfig{Enabled: false}),
+	)
+	if err != nil {
+		sink.release()
+		return nil, err
+	}
+	return &fileMetricExporter{Exporter: exporter, sink: sink}, nil
+}
+
+func (e *fileMetricExporter) Shutdown(ctx context.Context) error {
+	defer e.sink.release()
+	return e.Exporter.Shutdown(ctx)
+}
+
+// otlpFileTransport answers OTLP/HTTP export requests by appending them to a
+// file as OTLP/JSON lines.
+type otlpFileTransport struct {
+	sink       *fileSink
+	newRequest func() proto.Message
+}
+
+func (t *otlpFileTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	line, err := t.encode(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.sink.writeLine(line); err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": []string{"application/x-protobuf"}},
+		Body:       http.NoBody,
+		Request:    req,
+	}, nil
+}
+
+func (t *otlpFileTransport) encode(req *http.Request) ([]byte, error) {
+	defer req.Body.Close()
+	var body io.Reader = req.Body
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		body = gz
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	msg := t.newRequest()
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	return otlpJSON(msg)
+}
+
+// otlpIDKeys are the fields OTLP/JSON encodes as hex rather than the base64
+// that protojson uses for bytes.
+var otlpIDKeys = map[string]bool{
+	"traceId":      true,
+	"spanId":       true,
+	"parentSpanId": true,
+}
+
+// otlpJSON encodes msg following the OTLP/JSON rules, which deviate from the
+// canonical protobuf JSON mapping for trace and span IDs and enums.
+func otlpJSON(msg proto.Message) ([]byte, error) {
+	data, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tree any
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+	if err := hexEncodeIDs(tree); err != nil {
+		return nil, err
+	}
+	return json.Marshal(tree)
+}
+
+func hexEncodeIDs(node any) error {
+	switch v := node.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if s, ok := value.(string); ok && otlpIDKeys[key] {
+				id, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					return fmt.Errorf("invalid %s %q: %w", key, s, err)
+				}
+				v[key] = hex.EncodeToString(id)
+				continue
+			}
+			if err := hexEncodeIDs(value); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, value := range v {
+			if err := hexEncodeIDs(value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fileSink serializes the lines written to one file, which the trace and the
+// metric exporters may share.
+type fileSink struct {
+	path string
+	refs int
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+var (
+	fileSinksMu sync.Mutex
+	fileSinks   = map[string]*fileSink{}
+)
+
+func openFileSink(path string) (*fileSink, error) {
+	fileSinksMu.Lock()
+	defer fileSinksMu.Unlock()
+	if sink, ok := fileSinks[path]; ok {
+		sink.refs++
+		return sink, nil
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export file: %w", err)
+	}
+	sink := &fileSink{path: path, refs: 1, file: file}
+	fileSinks[path] = sink
+	return sink, nil
+}
+
+func (s *fileSink) writeLine(line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.file.Write(append(line, '\n'))
+	return err
+}
+
+// release closes the file once its last exporter is shut down.
+func (s *fileSink) release() {
+	fileSinksMu.Lock()
+	defer fileSinksMu.Unlock()
+	s.refs--
+	if s.refs > 0 {
+		return
+	}
+	delete(fileSinks, s.path)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.file.Close(); err != nil {
+		logger.Warn("failed to close export file", "path", s.path, "error", err)
+	}
+}
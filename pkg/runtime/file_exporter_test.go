@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/exporters/autoexport"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// readJSONLines parses every line of path as a JSON object.
+func readJSONLines(t *testing.T, path string) []map[string]any {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var lines []map[string]any
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line), "line %q", scanner.Text())
+		lines = append(lines, line)
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
+
+func TestFileSpanExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	exporter, err := newFileSpanExporter(t.Context(), path)
+	require.NoError(t, err)
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracer := provider.Tracer("test")
+
+	_, first := tracer.Start(t.Context(), "first")
+	first.End()
+	_, second := tracer.Start(t.Context(), "second")
+	second.End()
+	require.NoError(t, provider.Shutdown(t.Context()))
+
+	lines := readJSONLines(t, path)
+	require.Len(t, lines, 2, "one export request per line")
+	for i, name := range []string{"first", "second"} {
+		resourceSpans := lines[i]["resourceSpans"].([]any)
+		scopeSpans := resourceSpans[0].(map[string]any)["scopeSpans"].([]any)
+		spans := scopeSpans[0].(map[string]any)["spans"].([]any)
+		span := spans[0].(map[string]any)
+		assert.Equal(t, name, span["name"])
+		assert.Regexp(t, "^[0-9a-f]{32}$", span["traceId"], "trace IDs are hex encoded")
+		assert.Regexp(t, "^[0-9a-f]{16}$", span["spanId"], "span IDs are hex encoded")
+	}
+	assert.Empty(t, fileSinks, "the file is closed on shutdown")
+}
+
+func TestFileMetricExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.jsonl")
+	exporter, err := newFileMetricExporter(t.Context(), path)
+	require.NoError(t, err)
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)))
+
+	counter, err := provider.Meter("test").Int64Counter("requests")
+	require.NoError(t, err)
+	counter.Add(t.Context(), 3)
+	require.NoError(t, provider.Shutdown(t.Context()))
+
+	lines := readJSONLines(t, path)
+	require.NotEmpty(t, lines)
+	resourceMetrics := lines[0]["resourceMetrics"].([]any)
+	scopeMetrics := resourceMetrics[0].(map[string]any)["scopeMetrics"].([]any)
+	metric := scopeMetrics[0].(map[string]any)["metrics"].([]any)[0].(map[string]any)
+	assert.Equal(t, "requests", metric["name"])
+}
+
+func TestFileExporters_ShareFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telemetry.jsonl")
+	spans, err := newFileSpanExporter(t.Context(), path)
+	require.NoError(t, err)
+	metrics, err := newFileMetricExporter(t.Context(), path)
+	require.NoError(t, err)
+
+	require.NoError(t, spans.Shutdown(t.Context()))
+	require.Contains(t, fileSinks, path, "still in use by the metric exporter")
+	require.NoError(t, metrics.Shutdown(t.Context()))
+	assert.NotContains(t, fileSinks, path)
+}
+
+func TestRegisterFileExporters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telemetry.jsonl")
+	t.Setenv("OTEL_TRACES_EXPORTER", fileExporterName)
+	t.Setenv("OTEL_METRICS_EXPORTER", fileExporterName)
+	t.Setenv(envExporterFilePath, path)
+	registerFileExporters()
+
+	spans, err := autoexport.NewSpanExporter(t.Context())
+	require.NoError(t, err)
+	assert.IsType(t, &fileSpanExporter{}, spans)
+	reader, err := autoexport.NewMetricReader(t.Context())
+	require.NoError(t, err)
+
+	require.NoError(t, spans.Shutdown(t.Context()))
+	require.NoError(t, reader.Shutdown(t.Context()))
+	assert.FileExists(t, path)
+	assert.True(t, tracesToFile())
+}
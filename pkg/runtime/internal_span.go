@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	internalSpanScopeName = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime"
+
+	// internalSpanKey is the hook data key under which StartInternalSpan
+	// keeps the span for EndInternalSpan.
+	internalSpanKey = "otel.internal_span"
+)
+
+// CodeFunctionNameKey, CodeFilePathKey and CodeLineNumberKey are the hook data
+// keys under which the Before trampoline of a rule with code_location stores
+// the fully qualified name of the hooked function and the file and line
+// declaring it, baked in when it was instrumented.
+const (
+	CodeFunctionNameKey = "otel.code.function.name"
+	CodeFilePathKey     = "otel.code.file.path"
+	CodeLineNumberKey   = "otel.code.line.number"
+)
+
+// funcHookContext is the part of hook.HookContext that internal spans use.
+type funcHookContext interface {
+	keyDataGetter
+	SetKeyData(key string, val interface{})
+	GetPackageName() string
+	GetFuncName() string
+}
+
+// InternalSpanName returns the name of the internal span of the hooked
+// function: "package.Function", e.g. "orders.Checkout" (methods are named
+// after the method alone). A span_name template on the rule takes precedence
+// and may use the {package} and {func} placeholders.
+func InternalSpanName(ictx funcHookContext) string {
+	return internalSpanName(context.Background(), ictx)
+}
+
+// internalSpanName is InternalSpanName with the context of the call, whose
+// baggage the span_name template may use.
+func internalSpanName(ctx context.Context, ictx funcHookContext) string {
+	pkg, fn := ictx.GetPackageName(), ictx.GetFuncName()
+	return SpanNameWithContext(ctx, ictx, pkg+"."+fn, map[string]string{"package": pkg, "func": fn})
+}
+
+// CodeAttributes returns the code.function.name, code.file.path and
+// code.line.number attributes of the hooked function, or nil when the rule
+// that injected the hook does not set code_location.
+func CodeAttributes(ictx funcHookContext) []attribute.KeyValue {
+	file, ok := ictx.GetKeyData(CodeFilePathKey).(string)
+	if !ok {
+		return nil
+	}
+	name, _ := ictx.GetKeyData(CodeFunctionNameKey).(string)
+	attrs := []attribute.KeyValue{
+		semconv.CodeFunctionName(name),
+		semconv.CodeFilePath(file),
+	}
+	if line, ok := ictx.GetKeyData(CodeLineNumberKey).(int); ok {
+		attrs = append(attrs, semconv.CodeLineNumber(line))
+	}
+	return attrs
+}
+
+// StartInternalSpan starts an internal span named InternalSpanName as a child
+// of ctx and returns the context carrying it. The Before hook of any function
+// rule can call it; passing the returned context on with SetParam makes the
+// spans of the hooked functions it calls children of this one. The span
+// carries the CodeAttributes of the function, if any. It is ended by
+// EndInternalSpan, from the After hook.
+func StartInternalSpan(ictx funcHookContext, ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := otel.Tracer(internalSpanScopeName).Start(ctx,
+		internalSpanName(ctx, ictx),
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(CodeAttributes(ictx)...),
+	)
+	ictx.SetKeyData(internalSpanKey, span)
+	return ctx
+}
+
+// EndInternalSpan ends the span StartInternalSpan started for the hooked call,
+// marking it failed when err is not nil or when the call is panicking. A panic
+// the hooked function recovered itself does not fail the span. It does nothing
+// when no span was started.
+func EndInternalSpan(ictx keyDataGetter, err error) {
+	span, ok := ictx.GetKeyData(internalSpanKey).(trace.Span)
+	if !ok {
+		return
+	}
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case Panicking():
+		span.SetAttributes(attribute.String("error.type", "panic"))
+		span.SetStatus(codes.Error, "panic")
+	}
+	span.End()
+}
@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// funcContext stands in for the hook context the trampoline of a hooked
+// function populates.
+type funcContext struct {
+	keyData
+	pkg, fn string
+}
+
+func newFuncContext(pkg, fn string) *funcContext {
+	return &funcContext{keyData: keyData{}, pkg: pkg, fn: fn}
+}
+
+func (c *funcContext) SetKeyData(key string, val interface{}) { c.keyData[key] = val }
+func (c *funcContext) GetPackageName() string                 { return c.pkg }
+func (c *funcContext) GetFuncName() string                    { return c.fn }
+
+func setupInternalSpanTracer(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	original := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(original)
+		_ = tp.Shutdown(context.Background())
+	})
+	return sr
+}
+
+func TestInternalSpanName(t *testing.T) {
+	assert.Equal(t, "orders.Checkout", InternalSpanName(newFuncContext("orders", "Checkout")))
+
+	ictx := newFuncContext("orders", "Checkout")
+	ictx.keyData[SpanNameTemplateKey] = "checkout: {func} in {package}"
+	assert.Equal(t, "checkout: Checkout in orders", InternalSpanName(ictx))
+}
+
+func TestInternalSpan_NamedPackageFunction(t *testing.T) {
+	sr := setupInternalSpanTracer(t)
+
+	outer := newFuncContext("main", "FunctionA")
+	ctx := StartInternalSpan(outer, context.Background())
+	inner := newFuncContext("main", "FunctionB")
+	StartInternalSpan(inner, ctx)
+	EndInternalSpan(inner, nil)
+	EndInternalSpan(outer, nil)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "main.FunctionB", spans[0].Name())
+	assert.Equal(t, "main.FunctionA", spans[1].Name())
+	assert.Equal(t, trace.SpanKindInternal, spans[1].SpanKind())
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID(),
+		"the span of a function called with the returned context is a child")
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
+
+func TestInternalSpan_CodeAttributes(t *testing.T) {
+	sr := setupInternalSpanTracer(t)
+
+	plain := newFuncContext("orders", "Validate")
+	StartInternalSpan(plain, context.Background())
+	EndInternalSpan(plain, nil)
+
+	// The trampoline of a rule with code_location bakes in the declaration
+	// site of the function
+	located := newFuncContext("orders", "Checkout")
+	located.keyData[CodeFunctionNameKey] = "example.com/shop/orders.(*Service).Checkout"
+	located.keyData[CodeFilePathKey] = "example.com/shop/orders/checkout.go"
+	located.keyData[CodeLineNumberKey] = 42
+	StartInternalSpan(located, context.Background())
+	EndInternalSpan(located, nil)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	assert.Empty(t, spans[0].Attributes())
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		attribute.String("code.function.name", "example.com/shop/orders.(*Service).Checkout"),
+		attribute.String("code.file.path", "example.com/shop/orders/checkout.go"),
+		attribute.Int("code.line.number", 42),
+	}, spans[1].Attributes())
+}
+
+func TestEndInternalSpan_RecordsError(t *testing.T) {
+	sr := setupInternalSpanTracer(t)
+
+	ictx := newFuncContext("orders", "Checkout")
+	StartInternalSpan(ictx, nil)
+	EndInternalSpan(ictx, errors.New("out of stock"))
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "out of stock", spans[0].Status().Description)
+	require.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, "exception", spans[0].Events()[0].Name)
+}
+
+func TestEndInternalSpan_Panic(t *testing.T) {
+	sr := setupInternalSpanTracer(t)
+
+	// The trampoline defers the After hook before the function body runs
+	checkout := func(body func()) {
+		defer func() { _ = recover() }()
+		ictx := newFuncContext("orders", "Checkout")
+		StartInternalSpan(ictx, nil)
+		defer EndInternalSpan(ictx, nil)
+		body()
+	}
+	checkout(func() { panic("out of stock") })
+	checkout(func() {
+		defer func() { _ = recover() }()
+		panic("out of stock")
+	})
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[0].Status().Code, "an unrecovered panic fails the span")
+	assert.Contains(t, spans[0].Attributes(), attribute.String("error.type", "panic"))
+	assert.Equal(t, codes.Unset, spans[1].Status().Code, "a panic the function recovered does not")
+	assert.Empty(t, spans[1].Attributes())
+}
+
+func TestEndInternalSpan_WithoutStart(t *testing.T) {
+	sr := setupInternalSpanTracer(t)
+
+	EndInternalSpan(newFuncContext("orders", "Checkout"), nil)
+	assert.Empty(t, sr.Ended())
+}
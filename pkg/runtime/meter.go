@@ -0,0 +1,18 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Meter returns the meter of an instrumentation scope, for instrumentations
+// to obtain their meters from a single source. It comes from the global meter
+// provider, which SetupOTelSDK sets up with the reader OTEL_METRICS_EXPORTER
+// selects, unless the program installed its own. Meters obtained before the
+// provider is set up record to it once it is.
+func Meter(scopeName string, opts ...metric.MeterOption) metric.Meter {
+	return otel.GetMeterProvider().Meter(scopeName, opts...)
+}
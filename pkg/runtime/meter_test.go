@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// TestMeter verifies that meters obtained through Meter, before and after the
+// SDK is set up, record to the reader OTEL_METRICS_EXPORTER selects.
+func TestMeter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.jsonl")
+	t.Setenv("OTEL_METRICS_EXPORTER", fileExporterName)
+	t.Setenv(envExporterFilePath, path)
+	registerFileExporters()
+
+	require.False(t, userMeterProviderInstalled())
+	early, err := Meter("early").Int64Counter("early.requests")
+	require.NoError(t, err)
+
+	require.NoError(t, setupMeterProvider(t.Context(), resource.Default()))
+	t.Cleanup(func() { meterProvider = nil })
+	late, err := Meter("late", metric.WithInstrumentationVersion("v1.2.3")).Int64Counter("late.requests")
+	require.NoError(t, err)
+
+	early.Add(t.Context(), 1)
+	late.Add(t.Context(), 2)
+	require.NoError(t, meterProvider.Shutdown(t.Context()))
+
+	recorded := make(map[string]string)
+	for _, line := range readJSONLines(t, path) {
+		for _, rm := range line["resourceMetrics"].([]any) {
+			for _, sm := range rm.(map[string]any)["scopeMetrics"].([]any) {
+				scope := sm.(map[string]any)["scope"].(map[string]any)
+				for _, m := range sm.(map[string]any)["metrics"].([]any) {
+					recorded[m.(map[string]any)["name"].(string)] = scope["name"].(string)
+				}
+			}
+		}
+	}
+	assert.Equal(t, "early", recorded["early.requests"])
+	assert.Equal(t, "late", recorded["late.requests"])
+}
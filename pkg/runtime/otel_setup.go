@@ -26,20 +26,28 @@ var setupOnce sync.Once
 // following the OpenTelemetry specification:
 //
 // Service Configuration (highest to lowest precedence):
-//   - OTEL_RESOURCE_ATTRIBUTES: Key-value pairs (e.g., "service.name=myapp,service.version=1.2.3")
 //   - OTEL_SERVICE_NAME: Service name for telemetry
+//   - OTEL_RESOURCE_ATTRIBUTES: Key-value pairs (e.g., "service.name=myapp,deployment.environment.name=prod")
+//
+// Without either, service.name is "unknown_service:" followed by the name of
+// the executable, as the SDK defaults it. The resource also carries the
+// detected SDK, process, Go runtime, OS, container and host.
 //
 // Exporter Configuration:
 //   - OTEL_EXPORTER_OTLP_ENDPOINT: OTLP endpoint (e.g., http://localhost:4317)
 //   - OTEL_EXPORTER_OTLP_TRACES_ENDPOINT: Traces-specific endpoint
 //   - OTEL_EXPORTER_OTLP_METRICS_ENDPOINT: Metrics-specific endpoint
 //   - OTEL_EXPORTER_OTLP_PROTOCOL: Protocol (grpc, http/protobuf, http/json)
-//   - OTEL_TRACES_EXPORTER: Trace exporter (otlp, console, none)
-//   - OTEL_METRICS_EXPORTER: Metrics exporter (otlp, console, none)
+//   - OTEL_TRACES_EXPORTER: Trace exporter (otlp, console, file, none)
+//   - OTEL_METRICS_EXPORTER: Metrics exporter (otlp, console, file, none)
+//   - OTEL_GO_EXPORTER_FILE_PATH: File the file exporter appends OTLP/JSON lines to
+//   - OTEL_GO_PRINT_TRACE_TREE: Print the spans as a tree to stdout on shutdown (true/false)
 //
 // Other Configuration:
 //   - OTEL_LOG_LEVEL: Log level (debug, info, warn, error)
 //   - OTEL_SDK_DISABLED: Disable the SDK (true/false)
+//   - OTEL_GO_MAX_SPANS_PER_TRACE: Maximum child spans exported per local root span
+//   - OTEL_GO_INSTRUMENTATION_RUNTIME_ENABLED: Collect Go runtime metrics (true/false, default true)
 //
 // Example usage from an instrumentation:
 //
@@ -51,10 +59,13 @@ func SetupOTelSDK(instrumentationName, instrumentationVersion string) error {
 	setupOnce.Do(func() {
 		// Initialize OpenTelemetry SDK with defensive error handling
 		Initialize(Config{
-			ServiceName:            "otelc-instrumentation",
 			InstrumentationName:    instrumentationName,
 			InstrumentationVersion: instrumentationVersion,
 		})
+		// Started here, once for all instrumentations
+		if err := StartRuntimeMetrics(); err != nil {
+			logger.Error("failed to start runtime metrics", "error", err)
+		}
 	})
 	return nil
 }
@@ -5,10 +5,12 @@ package runtime
 
 import (
 	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric"
 )
 
 func TestGetLogger(t *testing.T) {
@@ -143,3 +145,45 @@ func TestStartRuntimeMetrics_Idempotent(t *testing.T) {
 		assert.Equal(t, err1, err, "concurrent call %d must return the same cached error", i)
 	}
 }
+
+// countRuntimeCollectorStarts resets the cached start of the runtime metrics
+// and counts the starts of their collector.
+func countRuntimeCollectorStarts(t *testing.T) *atomic.Int32 {
+	t.Helper()
+	var starts atomic.Int32
+	origStart, origCollector := startRuntimeMetrics, startRuntimeCollector
+	startRuntimeMetrics = sync.OnceValue(runtimeMetrics)
+	startRuntimeCollector = func(metric.MeterProvider) error {
+		starts.Add(1)
+		return nil
+	}
+	t.Cleanup(func() {
+		startRuntimeMetrics, startRuntimeCollector = origStart, origCollector
+	})
+	return &starts
+}
+
+func TestStartRuntimeMetrics_StartsOnce(t *testing.T) {
+	starts := countRuntimeCollectorStarts(t)
+
+	// As when several instrumentations initialize concurrently
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			assert.NoError(t, StartRuntimeMetrics())
+		})
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), starts.Load(), "the collector starts once")
+}
+
+func TestStartRuntimeMetrics_Disabled(t *testing.T) {
+	t.Setenv(envRuntimeMetricsEnabled, "false")
+	starts := countRuntimeCollectorStarts(t)
+
+	require.NoError(t, StartRuntimeMetrics())
+	require.NoError(t, StartRuntimeMetrics())
+
+	assert.Zero(t, starts.Load(), "the collector never starts when disabled")
+}
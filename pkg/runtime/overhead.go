@@ -0,0 +1,47 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OverheadDataKey is the hook data key under which the trampolines of a build
+// made with `otelc --overhead` store the time spent in the hooks. The Before
+// trampoline stores the nanoseconds spent in the Before hook; the After
+// trampoline replaces them with a func() int64 that adds the time spent in the
+// After hook so far.
+const OverheadDataKey = "otel.overhead_ns"
+
+// InstrumentationOverheadKey records, in nanoseconds, the time spent inside the
+// Before and After hooks of an operation.
+const InstrumentationOverheadKey = attribute.Key("otel.instrumentation.overhead_ns")
+
+// dataGetter is the part of hook.HookContext that RecordOverhead reads.
+type dataGetter interface {
+	GetData() interface{}
+}
+
+// RecordOverhead sets InstrumentationOverheadKey on span when the build
+// measures the overhead. The trampolines only measure hooks that keep their
+// data as a map[string]interface{}, as SetKeyData does. Called from an After
+// hook right before the span ends, it covers the whole Before hook and the
+// After hook up to that point.
+func RecordOverhead(ictx dataGetter, span trace.Span) {
+	data, ok := ictx.GetData().(map[string]interface{})
+	if !ok {
+		return
+	}
+	var ns int64
+	switch overhead := data[OverheadDataKey].(type) {
+	case int64:
+		ns = overhead
+	case func() int64:
+		ns = overhead()
+	default:
+		return
+	}
+	span.SetAttributes(InstrumentationOverheadKey.Int64(ns))
+}
@@ -0,0 +1,61 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type hookData struct{ data interface{} }
+
+func (d hookData) GetData() interface{} { return d.data }
+
+func TestRecordOverhead(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     interface{}
+		expected int64
+		recorded bool
+	}{
+		{name: "not measured", data: map[string]interface{}{"span": nil}},
+		{name: "no hook data", data: nil},
+		{name: "data not a map", data: "custom"},
+		{
+			name:     "before hook only",
+			data:     map[string]interface{}{OverheadDataKey: int64(1200)},
+			expected: 1200,
+			recorded: true,
+		},
+		{
+			name:     "before and after hooks",
+			data:     map[string]interface{}{OverheadDataKey: func() int64 { return 3400 }},
+			expected: 3400,
+			recorded: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			_, span := tp.Tracer("test").Start(context.Background(), "op")
+			RecordOverhead(hookData{tt.data}, span)
+			span.End()
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+			if !tt.recorded {
+				assert.Empty(t, spans[0].Attributes)
+				return
+			}
+			assert.Contains(t, spans[0].Attributes, attribute.Int64(string(InstrumentationOverheadKey), tt.expected))
+		})
+	}
+}
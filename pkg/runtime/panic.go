@@ -0,0 +1,72 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"runtime"
+	"strings"
+)
+
+const (
+	// maxPanicStackFrames bounds how deep Panicking looks for the panic.
+	maxPanicStackFrames = 64
+
+	// afterTrampolineName prefixes the After trampolines the tool generates.
+	afterTrampolineName = ".OtelAfterTrampoline_"
+)
+
+// PanicValueKey is the hook data key under which the After trampoline of a rule
+// with capture_panic stores the value the function panicked with.
+const PanicValueKey = "otel.panic"
+
+// Panicking reports whether the calling goroutine is unwinding a panic. After
+// hooks run from the deferred call of the trampoline, so a panic raised by the
+// instrumented function shows up as runtime.gopanic further down the stack.
+//
+// The trampoline defers the After hook before the function body runs, so the
+// function's own deferred calls run first. A panic the function recovers
+// itself is therefore over by the time the After hook runs, and Panicking
+// reports false: the function returned normally. Only panics still
+// propagating to the caller are reported.
+//
+// A function called from a deferred function while an unrelated panic unwinds
+// also has runtime.gopanic down its stack. When Panicking is called from an
+// After trampoline, only the panic that called the trampoline is therefore
+// reported; one the function returned normally through is not.
+//
+// recover() cannot be used instead: it only works directly in the deferred
+// function, and the panic must keep propagating to whoever recovers it.
+func Panicking() bool {
+	pcs := make([]uintptr, maxPanicStackFrames)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	trampoline := false
+	for {
+		frame, more := frames.Next()
+		switch {
+		case frame.Function == "runtime.gopanic":
+			return true
+		case trampoline && strings.Contains(frame.Function, ".deferwrap"):
+			// The wrapper calling the trampoline with its deferred arguments
+		case trampoline:
+			// The function deferring the trampoline, returning normally
+			return false
+		case strings.Contains(frame.Function, afterTrampolineName):
+			// Called by runtime.gopanic when the function panicked
+			trampoline = true
+		}
+		if !more {
+			return false
+		}
+	}
+}
+
+// PanicValue returns the value the hooked function panicked with, when the
+// rule that injected the After hook sets capture_panic. The trampoline
+// recovered the panic to read it and raises it again once the hook returns,
+// so Panicking still reports it.
+func PanicValue(ictx keyDataGetter) (any, bool) {
+	value := ictx.GetKeyData(PanicValueKey)
+	return value, value != nil
+}
@@ -0,0 +1,122 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// instrumented mimics an instrumented function: the trampoline defers the
+// After hook, which records whether it sees a panic, before the function body
+// runs. It returns what the After hook saw.
+func instrumented(body func()) (panicked bool) {
+	defer func() { _ = recover() }()
+	defer func() { panicked = Panicking() }()
+	body()
+	return false
+}
+
+func TestPanicking(t *testing.T) {
+	assert.False(t, Panicking())
+
+	t.Run("returns normally", func(t *testing.T) {
+		assert.False(t, instrumented(func() {}))
+	})
+
+	t.Run("unrecovered panic", func(t *testing.T) {
+		assert.True(t, instrumented(func() { panic("boom") }))
+	})
+
+	t.Run("panic recovered by the function", func(t *testing.T) {
+		assert.False(t, instrumented(func() {
+			defer func() { _ = recover() }()
+			panic("boom")
+		}))
+	})
+
+	t.Run("panic recovered and raised again", func(t *testing.T) {
+		assert.True(t, instrumented(func() {
+			defer func() {
+				if r := recover(); r != nil {
+					panic(r)
+				}
+			}()
+			panic("boom")
+		}))
+	})
+}
+
+// OtelAfterTrampoline_Test stands for an After trampoline, which the tool
+// names after this prefix.
+func OtelAfterTrampoline_Test(panicked *bool) {
+	*panicked = Panicking()
+}
+
+// trampolined mimics an instrumented function deferring a named trampoline,
+// as the generated code does. It returns what the trampoline saw.
+func trampolined(body func()) (panicked bool) {
+	defer func() { _ = recover() }()
+	defer OtelAfterTrampoline_Test(&panicked)
+	body()
+	return false
+}
+
+func TestPanicking_UnrelatedPanic(t *testing.T) {
+	// calledWhileUnwinding runs f from a deferred function while an unrelated
+	// panic unwinds
+	calledWhileUnwinding := func(f func() bool) (panicked bool) {
+		defer func() { _ = recover() }()
+		defer func() { panicked = f() }()
+		panic("unrelated")
+	}
+
+	assert.False(t, calledWhileUnwinding(func() bool {
+		return trampolined(func() {})
+	}), "the function returned normally")
+	assert.True(t, calledWhileUnwinding(func() bool {
+		return trampolined(func() { panic("boom") })
+	}), "the function panicked itself")
+	assert.True(t, trampolined(func() { panic("boom") }))
+}
+
+// capturing mimics the After trampoline of a rule with capture_panic: it
+// recovers the panic, hands its value to the hook and raises it again.
+func capturing(data map[string]interface{}, hook func()) {
+	if r := recover(); r != nil {
+		defer panic(r)
+		data[PanicValueKey] = r
+	}
+	hook()
+}
+
+func TestPanicValue(t *testing.T) {
+	t.Run("unrecovered panic", func(t *testing.T) {
+		data := map[string]interface{}{}
+		var value any
+		var ok, panicked bool
+		recovered := func() (recovered any) {
+			defer func() { recovered = recover() }()
+			defer capturing(data, func() {
+				value, ok = PanicValue(keyData(data))
+				panicked = Panicking()
+			})
+			panic("boom")
+		}()
+		assert.Equal(t, "boom", recovered, "the panic must keep propagating")
+		assert.True(t, ok)
+		assert.Equal(t, "boom", value)
+		assert.True(t, panicked)
+	})
+
+	t.Run("returns normally", func(t *testing.T) {
+		data := map[string]interface{}{}
+		var ok bool
+		func() {
+			defer capturing(data, func() { _, ok = PanicValue(keyData(data)) })
+		}()
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+	"os"
+	"runtime/pprof"
+)
+
+const (
+	// SpanNameProfileLabel is the pprof label key carrying the name of the span
+	// an instrumented function runs under.
+	SpanNameProfileLabel = "otel.span.name"
+
+	profileLabelsEnv = "OTEL_GO_PPROF_LABELS"
+
+	// profileLabelsKey is the hook data key under which
+	// StartFuncProfileLabels keeps the restore function for
+	// EndFuncProfileLabels.
+	profileLabelsKey = "otel.profile_labels"
+)
+
+// ProfileLabelsEnabled reports whether OTEL_GO_PPROF_LABELS=true, i.e. whether
+// hooks should attach pprof labels to the goroutine running the instrumented
+// function so that CPU profiles attribute samples to the traced operation.
+func ProfileLabelsEnabled() bool {
+	return os.Getenv(profileLabelsEnv) == "true"
+}
+
+// StartProfileLabels is the Before/After hook split of pprof.Do: it labels the
+// current goroutine with the span name, on top of the labels carried by ctx,
+// and returns the labeled context together with a function that puts the
+// labels of ctx back on the goroutine. The restore function must be called
+// from the After hook, which runs on the same goroutine.
+//
+// pprof does not expose the labels of a goroutine, only those of contexts, so
+// labels the goroutine inherited from the one starting it but ctx does not
+// carry are not restored.
+func StartProfileLabels(ctx context.Context, spanName string) (context.Context, func()) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	labeled := pprof.WithLabels(ctx, pprof.Labels(SpanNameProfileLabel, spanName))
+	pprof.SetGoroutineLabels(labeled)
+	return labeled, func() { pprof.SetGoroutineLabels(ctx) }
+}
+
+// StartFuncProfileLabels labels the goroutine running the hooked function with
+// its InternalSpanName, as StartProfileLabels does, when OTEL_GO_PPROF_LABELS
+// is true. The Before hook of any function rule can call it, e.g. next to
+// StartInternalSpan, and pass the returned context on with SetParam so that
+// the goroutines the function starts inherit the label. The labels are removed
+// by EndFuncProfileLabels, from the After hook.
+func StartFuncProfileLabels(ictx funcHookContext, ctx context.Context) context.Context {
+	if !ProfileLabelsEnabled() {
+		return ctx
+	}
+	ctx, restore := StartProfileLabels(ctx, InternalSpanName(ictx))
+	ictx.SetKeyData(profileLabelsKey, restore)
+	return ctx
+}
+
+// EndFuncProfileLabels puts back the labels the goroutine had before
+// StartFuncProfileLabels labeled it for the hooked call. It does nothing when
+// the goroutine was not labeled.
+func EndFuncProfileLabels(ictx keyDataGetter) {
+	if restore, ok := ictx.GetKeyData(profileLabelsKey).(func()); ok {
+		restore()
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileLabelsEnabled(t *testing.T) {
+	t.Setenv(profileLabelsEnv, "")
+	assert.False(t, ProfileLabelsEnabled())
+
+	t.Setenv(profileLabelsEnv, "true")
+	assert.True(t, ProfileLabelsEnabled())
+}
+
+func TestStartProfileLabels(t *testing.T) {
+	parent := pprof.WithLabels(context.Background(), pprof.Labels("user", "label"))
+
+	ctx, restore := StartProfileLabels(parent, "GET /users")
+	defer restore()
+
+	name, ok := pprof.Label(ctx, SpanNameProfileLabel)
+	assert.True(t, ok)
+	assert.Equal(t, "GET /users", name)
+
+	user, ok := pprof.Label(ctx, "user")
+	assert.True(t, ok, "labels from the parent context must be kept")
+	assert.Equal(t, "label", user)
+
+	_, ok = pprof.Label(parent, SpanNameProfileLabel)
+	assert.False(t, ok, "parent context must not be modified")
+}
+
+// goroutineLabels returns the labels of the goroutines, as the goroutine
+// profile lists them.
+func goroutineLabels(t *testing.T) string {
+	t.Helper()
+	var profile strings.Builder
+	require.NoError(t, pprof.Lookup("goroutine").WriteTo(&profile, 1))
+	var labels []string
+	for line := range strings.Lines(profile.String()) {
+		if strings.HasPrefix(line, "# labels: ") {
+			labels = append(labels, line)
+		}
+	}
+	return strings.Join(labels, "")
+}
+
+func TestStartProfileLabels_RestoresGoroutineLabels(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ctx := pprof.WithLabels(context.Background(), pprof.Labels("server", "api"))
+		pprof.SetGoroutineLabels(ctx)
+
+		_, restore := StartProfileLabels(ctx, "GET /users")
+		assert.Contains(t, goroutineLabels(t), `"otel.span.name":"GET /users"`)
+		restore()
+		labels := goroutineLabels(t)
+		assert.NotContains(t, labels, "otel.span.name", "the span name must be removed")
+		assert.Contains(t, labels, `"server":"api"`, "the labels of the context must be restored")
+	}()
+	<-done
+}
+
+func TestFuncProfileLabels(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		t.Setenv(profileLabelsEnv, "")
+		ictx := newFuncContext("orders", "Checkout")
+
+		ctx := StartFuncProfileLabels(ictx, context.Background())
+		_, ok := pprof.Label(ctx, SpanNameProfileLabel)
+		assert.False(t, ok)
+		EndFuncProfileLabels(ictx)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		t.Setenv(profileLabelsEnv, "true")
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			ictx := newFuncContext("orders", "Checkout")
+
+			ctx := StartFuncProfileLabels(ictx, context.Background())
+			name, ok := pprof.Label(ctx, SpanNameProfileLabel)
+			assert.True(t, ok)
+			assert.Equal(t, "orders.Checkout", name)
+			assert.Contains(t, goroutineLabels(t), `"otel.span.name":"orders.Checkout"`)
+
+			EndFuncProfileLabels(ictx)
+			assert.NotContains(t, goroutineLabels(t), "otel.span.name")
+		}()
+		<-done
+	})
+}
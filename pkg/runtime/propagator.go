@@ -0,0 +1,130 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+var (
+	propagatorsMu sync.RWMutex
+	propagators   []propagation.TextMapPropagator
+)
+
+// RegisterPropagator adds p to the propagators of the global propagator, for
+// trace context formats beyond W3C's, such as proprietary headers. Programs
+// register theirs from an init function, e.g.
+//
+//	func init() { runtime.RegisterPropagator(acmePropagator{}) }
+//
+// Registration takes effect at once, for the instrumentations that already
+// looked up the global propagator too.
+func RegisterPropagator(p propagation.TextMapPropagator) {
+	propagatorsMu.Lock()
+	defer propagatorsMu.Unlock()
+	propagators = append(propagators, p)
+}
+
+// TextMapPropagator returns the propagator the SDK installs as global: W3C
+// trace context and baggage, followed by the registered propagators in the
+// order they were registered. TraceContext carries both the traceparent and
+// the tracestate header, so vendor tracestate entries (used for sampling
+// decisions, for instance) reach downstream services alongside the trace and
+// span IDs. On extraction, a span context found by a registered propagator
+// takes precedence over the traceparent header.
+func TextMapPropagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+		registeredPropagators{},
+	)
+}
+
+// registeredPropagators propagates with the propagators registered at the
+// time of each call.
+type registeredPropagators struct{}
+
+func (registeredPropagators) registered() []propagation.TextMapPropagator {
+	propagatorsMu.RLock()
+	defer propagatorsMu.RUnlock()
+	return propagators
+}
+
+func (r registeredPropagators) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	for _, p := range r.registered() {
+		p.Inject(ctx, carrier)
+	}
+}
+
+func (r registeredPropagators) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	for _, p := range r.registered() {
+		ctx = p.Extract(ctx, carrier)
+	}
+	return ctx
+}
+
+func (r registeredPropagators) Fields() []string {
+	var fields []string
+	for _, p := range r.registered() {
+		fields = append(fields, p.Fields()...)
+	}
+	return fields
+}
+
+// instrumentationPropagators holds the propagators set with
+// SetInstrumentationPropagator, by instrumentation name.
+var instrumentationPropagators = map[string]propagation.TextMapPropagator{}
+
+// SetInstrumentationPropagator makes the instrumentation named name, e.g.
+// "nethttp" or "grpc" as in OTEL_GO_ENABLED_INSTRUMENTATIONS, propagate with p
+// instead of the global propagator, e.g. so that a legacy service called over
+// gRPC gets B3 headers only. A nil p restores the global propagator. Like
+// RegisterPropagator, it takes effect at once.
+func SetInstrumentationPropagator(name string, p propagation.TextMapPropagator) {
+	propagatorsMu.Lock()
+	defer propagatorsMu.Unlock()
+	if p == nil {
+		delete(instrumentationPropagators, strings.ToLower(name))
+		return
+	}
+	instrumentationPropagators[strings.ToLower(name)] = p
+}
+
+// Propagator returns the propagator of the instrumentation named name: the one
+// set with SetInstrumentationPropagator, if any, or the global propagator,
+// which carries W3C trace context and baggage along with the registered
+// propagators unless the program installed its own. Both are looked up at each
+// call, so instrumentations may keep the returned propagator.
+func Propagator(name string) propagation.TextMapPropagator {
+	return instrumentationPropagator{name: strings.ToLower(name)}
+}
+
+type instrumentationPropagator struct{ name string }
+
+func (p instrumentationPropagator) current() propagation.TextMapPropagator {
+	propagatorsMu.RLock()
+	override := instrumentationPropagators[p.name]
+	propagatorsMu.RUnlock()
+	if override != nil {
+		return override
+	}
+	return otel.GetTextMapPropagator()
+}
+
+func (p instrumentationPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	p.current().Inject(ctx, carrier)
+}
+
+func (p instrumentationPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return p.current().Extract(ctx, carrier)
+}
+
+func (p instrumentationPropagator) Fields() []string {
+	return p.current().Fields()
+}
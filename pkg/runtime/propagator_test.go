@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// acmePropagator carries the trace context in a proprietary
+// "x-acme-trace: {trace ID}/{span ID}" header.
+type acmePropagator struct{}
+
+func (acmePropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	sc := trace.SpanContextFromContext(ctx)
+	if sc.IsValid() {
+		carrier.Set("x-acme-trace", sc.TraceID().String()+"/"+sc.SpanID().String())
+	}
+}
+
+func (acmePropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	traceIDHex, spanIDHex, ok := strings.Cut(carrier.Get("x-acme-trace"), "/")
+	if !ok {
+		return ctx
+	}
+	traceID, err := trace.TraceIDFromHex(traceIDHex)
+	if err != nil {
+		return ctx
+	}
+	spanID, err := trace.SpanIDFromHex(spanIDHex)
+	if err != nil {
+		return ctx
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+}
+
+func (acmePropagator) Fields() []string { return []string{"x-acme-trace"} }
+
+func TestRegisterPropagator(t *testing.T) {
+	// Looked up before the registration, like the instrumentations do
+	propagator := TextMapPropagator()
+	saved := propagators
+	t.Cleanup(func() { propagators = saved })
+	RegisterPropagator(acmePropagator{})
+
+	assert.Subset(t, propagator.Fields(), []string{"traceparent", "tracestate", "baggage", "x-acme-trace"})
+
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa},
+		TraceFlags: trace.FlagsSampled,
+	})
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(trace.ContextWithSpanContext(t.Context(), parent), carrier)
+	assert.NotEmpty(t, carrier.Get("traceparent"))
+	assert.Equal(t, parent.TraceID().String()+"/"+parent.SpanID().String(), carrier.Get("x-acme-trace"))
+
+	// A request from a service that only speaks the proprietary format
+	extracted := trace.SpanContextFromContext(propagator.Extract(t.Context(), propagation.MapCarrier{
+		"x-acme-trace": carrier.Get("x-acme-trace"),
+	}))
+	require.True(t, extracted.IsValid())
+	assert.True(t, extracted.IsRemote())
+	assert.Equal(t, parent.TraceID(), extracted.TraceID())
+	assert.Equal(t, parent.SpanID(), extracted.SpanID())
+}
+
+func TestPropagator(t *testing.T) {
+	saved := otel.GetTextMapPropagator()
+	t.Cleanup(func() { otel.SetTextMapPropagator(saved) })
+	otel.SetTextMapPropagator(TextMapPropagator())
+
+	// Looked up before the override, like the instrumentations do
+	propagator := Propagator("GRPC")
+	assert.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage"}, propagator.Fields())
+
+	SetInstrumentationPropagator("grpc", acmePropagator{})
+	t.Cleanup(func() { SetInstrumentationPropagator("grpc", nil) })
+	assert.Equal(t, []string{"x-acme-trace"}, propagator.Fields())
+	assert.Contains(t, Propagator("nethttp").Fields(), "traceparent", "other instrumentations keep the global one")
+
+	SetInstrumentationPropagator("grpc", nil)
+	assert.Contains(t, propagator.Fields(), "traceparent")
+}
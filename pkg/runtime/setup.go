@@ -8,13 +8,15 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"go.opentelemetry.io/contrib/exporters/autoexport"
 	"go.opentelemetry.io/contrib/instrumentation/runtime"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -32,13 +34,32 @@ var (
 	meterProvider  *sdkmetric.MeterProvider
 	tracerProvider *sdktrace.TracerProvider
 	initOnce       sync.Once
+
+	// The delegating providers the otel global registry hands out before
+	// anything is registered. Captured at package init, which runs before any
+	// user code, so a different value later means the program installed its
+	// own provider (e.g. a test wiring an in-memory span recorder).
+	defaultTracerProvider = otel.GetTracerProvider()
+	defaultMeterProvider  = otel.GetMeterProvider()
 )
 
+// envRuntimeMetricsEnabled turns the Go runtime metrics off when set to
+// "false"; they are collected by default.
+const envRuntimeMetricsEnabled = "OTEL_GO_INSTRUMENTATION_RUNTIME_ENABLED"
+
+// startRuntimeCollector starts the collector of the Go runtime metrics. It is
+// a variable so tests can count the starts without a meter provider.
+var startRuntimeCollector = func(mp metric.MeterProvider) error {
+	return runtime.Start(runtime.WithMeterProvider(mp))
+}
+
 // startRuntimeMetrics is initialized once and caches the error from the first
 // call. All subsequent calls return the same cached error value.
-var startRuntimeMetrics = sync.OnceValue(func() error {
+var startRuntimeMetrics = sync.OnceValue(runtimeMetrics)
+
+func runtimeMetrics() error {
 	// Check if runtime metrics are enabled
-	if !Instrumented("runtimemetrics") {
+	if os.Getenv(envRuntimeMetricsEnabled) == "false" || !Instrumented("runtimemetrics") {
 		logger.Debug("runtime metrics disabled via environment variable")
 		return nil
 	}
@@ -46,14 +67,14 @@ var startRuntimeMetrics = sync.OnceValue(func() error {
 	// Get the meter provider from the global registry
 	mp := otel.GetMeterProvider()
 
-	if err := runtime.Start(runtime.WithMeterProvider(mp)); err != nil {
+	if err := startRuntimeCollector(mp); err != nil {
 		logger.Warn("failed to start runtime metrics", "error", err)
 		return err
 	}
 
 	logger.Info("runtime metrics enabled")
 	return nil
-})
+}
 
 func init() {
 	// Initialize logger early so hook packages can use it with the correct log level
@@ -135,54 +156,14 @@ func setupOpenTelemetry(cfg Config) (retErr error) {
 
 	ctx := context.Background()
 
-	// Build resource with proper precedence:
-	// 1. OTEL_RESOURCE_ATTRIBUTES (highest precedence - handled by WithFromEnv)
-	// 2. OTEL_SERVICE_NAME (handled by WithFromEnv)
-	// 3. Fallback defaults (lowest precedence)
-	//
-	// Per OTel spec, environment variables should override code configuration.
-	// We achieve this by putting WithFromEnv() AFTER explicit attributes,
-	// which causes env vars to take precedence.
-	var resourceOptions []resource.Option
-
-	// Start with detectors that don't conflict with service.* attributes
-	resourceOptions = append(resourceOptions,
-		resource.WithProcess(),
-		resource.WithOS(),
-		resource.WithContainer(),
-		resource.WithHost(),
-	)
-
-	// Add fallback defaults for service.name and service.version
-	// These will be overridden by environment variables if present
-	serviceName := os.Getenv("OTEL_SERVICE_NAME")
-	if serviceName == "" {
-		serviceName = cfg.ServiceName
-	}
-
-	// Only set service.version if we have a meaningful value
-	// Environment variables (via WithFromEnv) will override this if present
-	if cfg.ServiceVersion != "" {
-		resourceOptions = append(resourceOptions,
-			resource.WithAttributes(
-				semconv.ServiceNameKey.String(serviceName),
-				semconv.ServiceVersionKey.String(cfg.ServiceVersion),
-			),
-		)
-	}
-
-	// Add environment-based configuration LAST so it takes precedence
-	// This will respect OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME
-	resourceOptions = append(resourceOptions, resource.WithFromEnv())
-
-	// Create resource
-	res, err := resource.New(ctx, resourceOptions...)
+	res, err := newResource(ctx, cfg)
 	if err != nil {
-		// Log but don't fail - continue with basic providers
+		// Log but don't fail - continue with what was detected
 		logger.Warn("failed to create resource", "error", err)
-		res = resource.Default()
 	}
 
+	registerFileExporters()
+
 	// Setup trace provider with OTLP exporter
 	if err := setupTraceProvider(ctx, res); err != nil {
 		logger.Warn("failed to setup trace provider", "error", err)
@@ -193,39 +174,132 @@ func setupOpenTelemetry(cfg Config) (retErr error) {
 		logger.Warn("failed to setup meter provider", "error", err)
 	}
 
-	// Set W3C Trace Context as the propagator
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	))
+	// Set W3C Trace Context, and the propagators the program registered, as
+	// the propagator
+	otel.SetTextMapPropagator(TextMapPropagator())
 
+	serviceName, _ := res.Set().Value(semconv.ServiceNameKey)
 	logger.Info("OpenTelemetry initialized",
-		"service_name", serviceName,
+		"service_name", serviceName.AsString(),
 		"instrumentation_name", cfg.InstrumentationName,
 		"instrumentation_version", cfg.InstrumentationVersion)
 
 	return nil
 }
 
+// newResource builds the resource of the telemetry, with proper precedence:
+//  1. OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME, which wins over it
+//  2. The service name and version of cfg, as fallbacks, the service name
+//     defaulting to the one of the SDK, "unknown_service:<executable>"
+//  3. The detected SDK, process, Go runtime, OS, container and host
+//
+// Per OTel spec, environment variables override code configuration, and the
+// attributes they set merge with the detected ones rather than replace them.
+// When some detector fails, the resource holds what the others detected.
+func newResource(ctx context.Context, cfg Config) (*resource.Resource, error) {
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = defaultServiceName()
+	}
+	fallback := []attribute.KeyValue{semconv.ServiceName(serviceName)}
+	if cfg.ServiceVersion != "" {
+		fallback = append(fallback, semconv.ServiceVersion(cfg.ServiceVersion))
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		// The process detectors include the process.runtime.* of Go
+		resource.WithProcess(),
+		resource.WithOS(),
+		resource.WithContainer(),
+		resource.WithHost(),
+		resource.WithAttributes(fallback...),
+		// Last, so that environment variables take precedence
+		resource.WithFromEnv(),
+	)
+	if res == nil {
+		return resource.Default(), err
+	}
+	return res, err
+}
+
+// defaultServiceName returns the service name the SDK defaults to, derived
+// from the name of the executable.
+func defaultServiceName() string {
+	executable, err := os.Executable()
+	if err != nil {
+		return "unknown_service:go"
+	}
+	return "unknown_service:" + filepath.Base(executable)
+}
+
+// userTracerProviderInstalled reports whether the program registered its own
+// tracer provider before the SDK was set up.
+func userTracerProviderInstalled() bool {
+	return otel.GetTracerProvider() != defaultTracerProvider
+}
+
+// userMeterProviderInstalled reports whether the program registered its own
+// meter provider before the SDK was set up.
+func userMeterProviderInstalled() bool {
+	return otel.GetMeterProvider() != defaultMeterProvider
+}
+
 // setupTraceProvider creates and configures the trace provider
 func setupTraceProvider(ctx context.Context, res *resource.Resource) error {
+	// Keep a provider the program installed itself, e.g. a test pointing the
+	// SDK at tracetest.SpanRecorder, so instrumented spans land there.
+	if userTracerProviderInstalled() {
+		logger.Debug("tracer provider already installed, skipping trace provider setup")
+		return nil
+	}
+
 	// Get OTLP endpoint from environment
 	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
 	if endpoint == "" {
 		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
 	}
 
-	// If no endpoint is configured, skip trace provider setup
-	if endpoint == "" {
+	// If no endpoint is configured, skip trace provider setup, unless spans
+	// are written to a file or printed as a tree
+	exported := endpoint != "" || tracesToFile()
+	if !exported && !printTraceTree() {
 		logger.Debug("no OTLP endpoint configured, skipping trace provider setup")
 		return nil
 	}
 
+	tracerOptions := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+	}
+	if exported {
+		spanProcessor, err := newExportSpanProcessor(ctx)
+		if err != nil {
+			return err
+		}
+		tracerOptions = append(tracerOptions, sdktrace.WithSpanProcessor(spanProcessor))
+	}
+	if printTraceTree() {
+		tracerOptions = append(tracerOptions, sdktrace.WithSpanProcessor(newTreeSpanProcessor(os.Stdout)))
+		logger.Debug("printing the trace tree on shutdown", "env", envPrintTraceTree)
+	}
+
+	tracerProvider = sdktrace.NewTracerProvider(tracerOptions...)
+
+	// Set global tracer provider
+	otel.SetTracerProvider(tracerProvider)
+
+	logger.Info("trace provider initialized", "endpoint", endpoint, "exporter", os.Getenv("OTEL_TRACES_EXPORTER"))
+	return nil
+}
+
+// newExportSpanProcessor creates the span processor sending spans to the
+// exporter selected by OTEL_TRACES_EXPORTER.
+func newExportSpanProcessor(ctx context.Context) (sdktrace.SpanProcessor, error) {
 	// Use autoexport to automatically select the right exporter based on
 	// OTEL_EXPORTER_OTLP_PROTOCOL (defaults to http/protobuf)
 	traceExporter, err := autoexport.NewSpanExporter(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	spanProcessor := sdktrace.NewBatchSpanProcessor(traceExporter,
@@ -236,21 +310,21 @@ func setupTraceProvider(ctx context.Context, res *resource.Resource) error {
 		spanProcessor = sdktrace.NewSimpleSpanProcessor(traceExporter)
 		logger.Debug("using SimpleSpanProcessor for immediate span export")
 	}
+	if limit := maxSpansPerTrace(); limit > 0 {
+		spanProcessor = newSpanLimitProcessor(spanProcessor, limit)
+		logger.Debug("limiting child spans per trace", "limit", limit)
+	}
 
-	tracerProvider = sdktrace.NewTracerProvider(
-		sdktrace.WithResource(res),
-		sdktrace.WithSpanProcessor(spanProcessor),
-	)
-
-	// Set global tracer provider
-	otel.SetTracerProvider(tracerProvider)
-
-	logger.Info("trace provider initialized", "endpoint", endpoint)
-	return nil
+	return spanProcessor, nil
 }
 
 // setupMeterProvider creates and configures the meter provider
 func setupMeterProvider(ctx context.Context, res *resource.Resource) error {
+	if userMeterProviderInstalled() {
+		logger.Debug("meter provider already installed, skipping meter provider setup")
+		return nil
+	}
+
 	// Use autoexport to automatically select the right exporter based on
 	// OTEL_EXPORTER_OTLP_PROTOCOL (defaults to http/protobuf)
 	// Supports: otlp, console, and none
@@ -293,17 +367,32 @@ func Shutdown(ctx context.Context) error {
 	return err
 }
 
-// StartRuntimeMetrics enables Go runtime metrics collection.
-// This follows the same enable/disable pattern as other instrumentations via
-// OTEL_GO_ENABLED_INSTRUMENTATIONS and OTEL_GO_DISABLED_INSTRUMENTATIONS.
+// ForceFlush exports the spans buffered by the global tracer provider, waiting
+// at most timeout. Cancellation of ctx is ignored so that a flush following a
+// shutdown whose grace period just ran out still gets its own budget. Providers
+// that do not buffer spans are left alone.
+func ForceFlush(ctx context.Context, timeout time.Duration) error {
+	flusher, ok := otel.GetTracerProvider().(interface {
+		ForceFlush(ctx context.Context) error
+	})
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	return flusher.ForceFlush(ctx)
+}
+
+// StartRuntimeMetrics enables Go runtime metrics collection. SetupOTelSDK
+// calls it once the meter provider is set up, so instrumentations need not.
 //
 // Runtime metrics are enabled by default. To disable:
-//   - Set OTEL_GO_DISABLED_INSTRUMENTATIONS=runtimemetrics
+//   - Set OTEL_GO_INSTRUMENTATION_RUNTIME_ENABLED=false
+//   - Or set OTEL_GO_DISABLED_INSTRUMENTATIONS=runtimemetrics
 //   - Or set OTEL_GO_ENABLED_INSTRUMENTATIONS without including "runtimemetrics"
 //
-// This function is safe to call multiple times - it will only start runtime metrics once.
-// Each instrumentation package calls this during initialization to ensure runtime metrics
-// are available when the application uses any instrumentation.
+// This function is safe to call multiple times, concurrently included - it will
+// only start runtime metrics once.
 //
 // Returns error if runtime metrics fail to start, but this is non-fatal.
 func StartRuntimeMetrics() error {
@@ -0,0 +1,189 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestSetupTraceProvider_KeepsInstalledProvider verifies that a tracer provider
+// registered by the program (as a test under `otelc go test` would do with an
+// in-memory recorder) survives SDK setup, even when an OTLP endpoint is
+// configured, so instrumented spans are delivered to it.
+func TestSetupTraceProvider_KeepsInstalledProvider(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318")
+
+	recorder := tracetest.NewSpanRecorder()
+	installed := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = installed.Shutdown(t.Context()) })
+	otel.SetTracerProvider(installed)
+
+	require.True(t, userTracerProviderInstalled())
+	require.NoError(t, setupTraceProvider(t.Context(), resource.Default()))
+	assert.Same(t, installed, otel.GetTracerProvider(), "installed provider must not be replaced")
+	assert.Nil(t, tracerProvider, "SDK must not create its own tracer provider")
+
+	_, span := otel.Tracer("test").Start(t.Context(), "recorded")
+	span.End()
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 1)
+	assert.Equal(t, "recorded", ended[0].Name())
+}
+
+// TestNewResource verifies that the resource carries the service of the
+// environment over the fallback of the config, merged with the attributes of
+// OTEL_RESOURCE_ATTRIBUTES and the detected ones.
+func TestNewResource(t *testing.T) {
+	cfg := Config{ServiceName: "cart", ServiceVersion: "v1.2.3"}
+	value := func(t *testing.T, res *resource.Resource, key attribute.Key) string {
+		t.Helper()
+		v, ok := res.Set().Value(key)
+		require.True(t, ok, "missing %s", key)
+		return v.AsString()
+	}
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv("OTEL_SERVICE_NAME", "checkout")
+		t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "service.name=ignored,deployment.environment.name=staging")
+
+		res, err := newResource(t.Context(), cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "checkout", value(t, res, semconv.ServiceNameKey))
+		assert.Equal(t, "v1.2.3", value(t, res, semconv.ServiceVersionKey))
+		assert.Equal(t, "staging", value(t, res, semconv.DeploymentEnvironmentNameKey))
+		assert.Equal(t, "go", value(t, res, semconv.ProcessRuntimeNameKey))
+		assert.Equal(t, "go", value(t, res, semconv.TelemetrySDKLanguageKey))
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		t.Setenv("OTEL_SERVICE_NAME", "")
+		t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "")
+
+		res, err := newResource(t.Context(), cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "cart", value(t, res, semconv.ServiceNameKey))
+		assert.Equal(t, "v1.2.3", value(t, res, semconv.ServiceVersionKey))
+	})
+
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("OTEL_SERVICE_NAME", "")
+		t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "")
+
+		executable, err := os.Executable()
+		require.NoError(t, err)
+		res, err := newResource(t.Context(), Config{})
+		require.NoError(t, err)
+		assert.Equal(t, "unknown_service:"+filepath.Base(executable), value(t, res, semconv.ServiceNameKey))
+		_, ok := res.Set().Value(semconv.ServiceVersionKey)
+		assert.False(t, ok)
+	})
+}
+
+// TestSetupTraceProvider_Endpoint verifies that the spans are exported to the
+// endpoint of OTEL_EXPORTER_OTLP_ENDPOINT.
+func TestSetupTraceProvider_Endpoint(t *testing.T) {
+	paths := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case paths <- r.URL.Path:
+		default:
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+
+	// Other tests leave their provider installed, treat it as the default one
+	saved := defaultTracerProvider
+	defaultTracerProvider = otel.GetTracerProvider()
+	t.Cleanup(func() {
+		if tracerProvider != nil {
+			_ = tracerProvider.Shutdown(context.Background())
+		}
+		tracerProvider = nil
+		defaultTracerProvider = saved
+	})
+
+	require.NoError(t, setupTraceProvider(t.Context(), resource.Default()))
+	require.NotNil(t, tracerProvider)
+
+	_, span := otel.Tracer("test").Start(t.Context(), "exported")
+	span.End()
+	require.NoError(t, tracerProvider.ForceFlush(t.Context()))
+
+	select {
+	case path := <-paths:
+		assert.Equal(t, "/v1/traces", path)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no spans exported to the endpoint")
+	}
+}
+
+// TestTextMapPropagator_TraceState verifies that the SDK's propagator carries
+// tracestate across a carrier along with traceparent.
+func TestTextMapPropagator_TraceState(t *testing.T) {
+	traceState, err := trace.ParseTraceState("vendor=sampled:1,other=abc")
+	require.NoError(t, err)
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa},
+		TraceFlags: trace.FlagsSampled,
+		TraceState: traceState,
+	})
+
+	propagator := TextMapPropagator()
+	assert.Contains(t, propagator.Fields(), "tracestate")
+
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(trace.ContextWithSpanContext(t.Context(), parent), carrier)
+	assert.Equal(t, "vendor=sampled:1,other=abc", carrier.Get("tracestate"))
+
+	extracted := trace.SpanContextFromContext(propagator.Extract(t.Context(), carrier))
+	assert.True(t, extracted.IsRemote())
+	assert.Equal(t, parent.TraceID(), extracted.TraceID())
+	assert.Equal(t, traceState, extracted.TraceState())
+}
+
+func TestForceFlush(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter, sdktrace.WithBatchTimeout(time.Hour)),
+	)
+	t.Cleanup(func() { _ = provider.Shutdown(t.Context()) })
+	otel.SetTracerProvider(provider)
+
+	_, span := otel.Tracer("test").Start(t.Context(), "buffered")
+	span.End()
+	require.Empty(t, exporter.GetSpans(), "span should still be buffered")
+
+	// A cancelled context, as left over by an expired shutdown, must not
+	// prevent the flush.
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	require.NoError(t, ForceFlush(ctx, time.Second))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "buffered", spans[0].Name)
+}
@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	sleepEventName = "sleep"
+
+	// SleepDurationKey is the attribute of the sleep event holding how long,
+	// in seconds, the wait took.
+	SleepDurationKey = attribute.Key("sleep.duration")
+)
+
+// currentSpan returns the span of the goroutine. In instrumented programs
+// SpanFromContext falls back to the span kept in goroutine local storage.
+var currentSpan = func() trace.Span {
+	return trace.SpanFromContext(context.Background())
+}
+
+// RecordSleep runs wait, e.g. a time.Sleep call, and records how long it took
+// as a "sleep" event on the current span of the goroutine. It is meant for the
+// replace template of a call rule narrowed to the functions whose delays are
+// being diagnosed, as waiting is too common to trace everywhere:
+//
+//	time_checkout_sleeps:
+//	  target: myapp/orders
+//	  where:
+//	    function_call: time.Sleep
+//	    caller: [Checkout]
+//	  do:
+//	    - wrap_call:
+//	        replace: "otelruntime.RecordSleep(func() { {{ . }} })"
+//	  imports:
+//	    otelruntime: github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/runtime
+func RecordSleep(wait func()) {
+	start := time.Now()
+	wait()
+	span := currentSpan()
+	if !span.IsRecording() {
+		return
+	}
+	span.AddEvent(sleepEventName, trace.WithAttributes(
+		SleepDurationKey.Float64(time.Since(start).Seconds()),
+	))
+}
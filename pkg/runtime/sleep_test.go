@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRecordSleep(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	// checkout stands for a function a call rule narrowed to its sleeps
+	checkout := func() {
+		_, span := tp.Tracer("test").Start(t.Context(), "Checkout")
+		defer span.End()
+		oldCurrentSpan := currentSpan
+		currentSpan = func() trace.Span { return span }
+		defer func() { currentSpan = oldCurrentSpan }()
+
+		RecordSleep(func() { time.Sleep(20 * time.Millisecond) })
+	}
+	checkout()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.Len(t, spans[0].Events, 1)
+	event := spans[0].Events[0]
+	assert.Equal(t, "sleep", event.Name)
+	require.Len(t, event.Attributes, 1)
+	assert.Equal(t, SleepDurationKey, event.Attributes[0].Key)
+	assert.GreaterOrEqual(t, event.Attributes[0].Value.AsFloat64(), 0.02)
+}
+
+func TestRecordSleep_NoSpan(t *testing.T) {
+	slept := false
+	assert.NotPanics(t, func() {
+		RecordSleep(func() { slept = true })
+	})
+	assert.True(t, slept)
+}
@@ -0,0 +1,167 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// envMaxSpansPerTrace caps the number of child spans exported per local
+	// root span, so a buggy instrumented loop cannot flood the backend.
+	envMaxSpansPerTrace = "OTEL_GO_MAX_SPANS_PER_TRACE"
+
+	// spansDroppedKey is set to the number of dropped descendant spans on
+	// the nearest exported ancestor of the dropped spans, or on the local root
+	// when that ancestor has already ended.
+	spansDroppedKey = attribute.Key("spans.dropped")
+)
+
+// maxSpansPerTrace returns the configured child span limit, or 0 when there is
+// none.
+func maxSpansPerTrace() int {
+	value := os.Getenv(envMaxSpansPerTrace)
+	if value == "" {
+		return 0
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		logger.Warn("ignoring invalid span limit", "env", envMaxSpansPerTrace, "value", value)
+		return 0
+	}
+	return limit
+}
+
+// localTrace tracks the spans started under one local root span, that is a
+// span without a parent or with a remote parent.
+type localTrace struct {
+	root     sdktrace.ReadWriteSpan
+	children int
+	open     int
+	// spans holds every span started under root, so that children started
+	// after their parent ended still find their local root.
+	spans map[trace.SpanID]struct{}
+	// dropped maps the dropped spans that have not ended yet to the span
+	// their drop was recorded on.
+	dropped map[trace.SpanID]trace.Span
+	// droppedCounts holds the number of drops recorded on each span.
+	droppedCounts map[trace.SpanID]int
+}
+
+// recorder returns the span the drop of s is recorded on: its nearest
+// exported ancestor while that one is still recording, the local root
+// otherwise.
+func (lt *localTrace) recorder(parent context.Context, s sdktrace.ReadWriteSpan) trace.Span {
+	parentID := s.Parent().SpanID()
+	ancestor, found := lt.dropped[parentID]
+	if !found {
+		if span := trace.SpanFromContext(parent); span.SpanContext().SpanID() == parentID {
+			ancestor = span
+		}
+	}
+	if ancestor == nil || !ancestor.IsRecording() {
+		return lt.root
+	}
+	return ancestor
+}
+
+// spanLimitProcessor forwards to next each local root span and at most limit
+// of the spans started under it. Further spans are still created but never
+// reach next, hence are not exported; their nearest exported ancestor records
+// how many were dropped. Spans of other services sharing the trace in this
+// process, such as the server side of an in-process call, have a remote
+// parent and are limited under their own local root. A local trace is
+// forgotten once all of its spans have ended.
+type spanLimitProcessor struct {
+	next  sdktrace.SpanProcessor
+	limit int
+
+	mu     sync.Mutex
+	owners map[trace.SpanID]*localTrace
+}
+
+func newSpanLimitProcessor(next sdktrace.SpanProcessor, limit int) *spanLimitProcessor {
+	return &spanLimitProcessor{
+		next:   next,
+		limit:  limit,
+		owners: make(map[trace.SpanID]*localTrace),
+	}
+}
+
+// localTraceOf returns the local trace s belongs to, or nil when s is a local
+// root.
+func (p *spanLimitProcessor) localTraceOf(s sdktrace.ReadWriteSpan) *localTrace {
+	parent := s.Parent()
+	if !parent.IsValid() || parent.IsRemote() {
+		return nil
+	}
+	return p.owners[parent.SpanID()]
+}
+
+func (p *spanLimitProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	id := s.SpanContext().SpanID()
+	p.mu.Lock()
+	lt := p.localTraceOf(s)
+	if lt == nil {
+		lt = &localTrace{
+			root:          s,
+			spans:         make(map[trace.SpanID]struct{}),
+			dropped:       make(map[trace.SpanID]trace.Span),
+			droppedCounts: make(map[trace.SpanID]int),
+		}
+	} else {
+		lt.children++
+	}
+	lt.open++
+	lt.spans[id] = struct{}{}
+	p.owners[id] = lt
+	if lt.children <= p.limit {
+		p.mu.Unlock()
+		p.next.OnStart(parent, s)
+		return
+	}
+	recorder := lt.recorder(parent, s)
+	lt.dropped[id] = recorder
+	recorderID := recorder.SpanContext().SpanID()
+	lt.droppedCounts[recorderID]++
+	dropped := lt.droppedCounts[recorderID]
+	p.mu.Unlock()
+	recorder.SetAttributes(spansDroppedKey.Int(dropped))
+}
+
+func (p *spanLimitProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	id := s.SpanContext().SpanID()
+	p.mu.Lock()
+	dropped := false
+	if lt, found := p.owners[id]; found {
+		if _, dropped = lt.dropped[id]; dropped {
+			delete(lt.dropped, id)
+		}
+		lt.open--
+		if lt.open == 0 {
+			for spanID := range lt.spans {
+				delete(p.owners, spanID)
+			}
+		}
+	}
+	p.mu.Unlock()
+	if !dropped {
+		p.next.OnEnd(s)
+	}
+}
+
+func (p *spanLimitProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *spanLimitProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
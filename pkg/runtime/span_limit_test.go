@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMaxSpansPerTrace(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected int
+	}{
+		{name: "unset", value: "", expected: 0},
+		{name: "valid", value: "100", expected: 100},
+		{name: "zero", value: "0", expected: 0},
+		{name: "negative", value: "-5", expected: 0},
+		{name: "not a number", value: "many", expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envMaxSpansPerTrace, tt.value)
+			assert.Equal(t, tt.expected, maxSpansPerTrace())
+		})
+	}
+}
+
+func TestSpanLimitProcessor(t *testing.T) {
+	const limit = 3
+	recorder := tracetest.NewSpanRecorder()
+	processor := newSpanLimitProcessor(recorder, limit)
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor))
+	t.Cleanup(func() { _ = provider.Shutdown(t.Context()) })
+	tracer := provider.Tracer("test")
+
+	ctx, root := tracer.Start(t.Context(), "request")
+	for range limit + 5 {
+		_, child := tracer.Start(ctx, "loop")
+		child.End()
+	}
+	root.End()
+
+	// Spans of another trace are counted separately
+	_, other := tracer.Start(t.Context(), "other request")
+	other.End()
+
+	ended := recorder.Ended()
+	require.Len(t, ended, limit+2, "root, %d children and the other root", limit)
+	var rootSpan sdktrace.ReadOnlySpan
+	for _, s := range ended {
+		if s.Name() == "request" {
+			rootSpan = s
+		}
+	}
+	require.NotNil(t, rootSpan)
+	assert.Contains(t, rootSpan.Attributes(), spansDroppedKey.Int(5))
+	assert.Empty(t, processor.owners, "finished traces must be forgotten")
+}
+
+func endedSpan(t *testing.T, recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for _, s := range recorder.Ended() {
+		if s.Name() == name {
+			return s
+		}
+	}
+	require.Failf(t, "span not exported", "%q", name)
+	return nil
+}
+
+func TestSpanLimitProcessor_RecordsDropsOnRecordingAncestor(t *testing.T) {
+	const limit = 2
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(newSpanLimitProcessor(recorder, limit)))
+	t.Cleanup(func() { _ = provider.Shutdown(t.Context()) })
+	tracer := provider.Tracer("test")
+
+	ctx, root := tracer.Start(t.Context(), "request")
+	handlerCtx, handler := tracer.Start(ctx, "handler")
+	_, query := tracer.Start(handlerCtx, "query")
+	query.End()
+	for range 3 {
+		_, dropped := tracer.Start(handlerCtx, "loop")
+		dropped.End()
+	}
+	handler.End()
+	// The root ends last; the drops are already recorded on their parent
+	root.End()
+
+	assert.Len(t, recorder.Ended(), limit+1)
+	assert.Contains(t, endedSpan(t, recorder, "handler").Attributes(), spansDroppedKey.Int(3))
+	assert.NotContains(t, endedSpan(t, recorder, "request").Attributes(), spansDroppedKey.Int(3))
+}
+
+func TestSpanLimitProcessor_ParentEndedFallsBackToRoot(t *testing.T) {
+	const limit = 1
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(newSpanLimitProcessor(recorder, limit)))
+	t.Cleanup(func() { _ = provider.Shutdown(t.Context()) })
+	tracer := provider.Tracer("test")
+
+	ctx, root := tracer.Start(t.Context(), "request")
+	handlerCtx, handler := tracer.Start(ctx, "handler")
+	handler.End()
+	// Spans started after their parent ended, as by a leaked goroutine
+	for range 2 {
+		_, dropped := tracer.Start(handlerCtx, "async")
+		dropped.End()
+	}
+	root.End()
+
+	assert.Len(t, recorder.Ended(), limit+1)
+	assert.Contains(t, endedSpan(t, recorder, "request").Attributes(), spansDroppedKey.Int(2))
+}
+
+func TestSpanLimitProcessor_RemoteParentIsLocalRoot(t *testing.T) {
+	const limit = 1
+	recorder := tracetest.NewSpanRecorder()
+	processor := newSpanLimitProcessor(recorder, limit)
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor))
+	t.Cleanup(func() { _ = provider.Shutdown(t.Context()) })
+	tracer := provider.Tracer("test")
+
+	ctx, client := tracer.Start(t.Context(), "client")
+	_, call := tracer.Start(ctx, "call")
+	// The in-process server continues the same trace from a remote parent
+	serverCtx, server := tracer.Start(
+		trace.ContextWithRemoteSpanContext(t.Context(), call.SpanContext()), "server")
+	_, handler := tracer.Start(serverCtx, "handler")
+	handler.End()
+	server.End()
+	call.End()
+	client.End()
+
+	assert.Len(t, recorder.Ended(), 4, "each local root has its own limit")
+	for _, s := range recorder.Ended() {
+		assert.NotContains(t, s.Attributes(), spansDroppedKey.Int(1), s.Name())
+	}
+	assert.Empty(t, processor.owners)
+}
@@ -0,0 +1,84 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+	"maps"
+	"strings"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// SpanNameTemplateKey is the hook data key under which the Before trampoline
+// stores the span_name template of the rule that injected the hook.
+const SpanNameTemplateKey = "otel.span_name"
+
+// baggagePlaceholderPrefix starts the placeholders that render a member of
+// the baggage of the operation, e.g. {baggage.tenant}.
+const baggagePlaceholderPrefix = "baggage."
+
+// keyDataGetter is the part of hook.HookContext that SpanName reads.
+type keyDataGetter interface {
+	GetKeyData(key string) interface{}
+}
+
+// SpanName returns the name of the span a Before hook starts: the span_name
+// template of its rule rendered with values, or fallback when the rule sets
+// no template or the rendered name is empty. It must be called before the
+// hook replaces the hook data with SetData.
+func SpanName(ictx keyDataGetter, fallback string, values map[string]string) string {
+	tmpl, _ := ictx.GetKeyData(SpanNameTemplateKey).(string)
+	if tmpl == "" {
+		return fallback
+	}
+	if name := RenderSpanName(tmpl, values); name != "" {
+		return name
+	}
+	return fallback
+}
+
+// SpanNameWithContext is SpanName for hooks that have the context of the
+// operation, which they should prefer: the template may also use
+// {baggage.<key>} placeholders, rendering the value of the baggage member
+// <key> in ctx, e.g. to name the spans of a multi-tenant service after the
+// tenant of the request.
+func SpanNameWithContext(ctx context.Context, ictx keyDataGetter, fallback string, values map[string]string) string {
+	members := baggage.FromContext(ctx).Members()
+	if len(members) == 0 {
+		return SpanName(ictx, fallback, values)
+	}
+	if tmpl, _ := ictx.GetKeyData(SpanNameTemplateKey).(string); tmpl == "" {
+		return fallback
+	}
+	withBaggage := make(map[string]string, len(values)+len(members))
+	maps.Copy(withBaggage, values)
+	for _, m := range members {
+		withBaggage[baggagePlaceholderPrefix+m.Key()] = m.Value()
+	}
+	return SpanName(ictx, fallback, withBaggage)
+}
+
+// RenderSpanName replaces every {name} placeholder in tmpl with values[name].
+// Placeholders without a value render empty, and the whitespace runs they
+// leave behind are collapsed, so "{operation} {table}" renders as "SELECT"
+// when the table is unknown.
+func RenderSpanName(tmpl string, values map[string]string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(tmpl, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(tmpl[start:], '}')
+		if end < 0 {
+			break
+		}
+		b.WriteString(tmpl[:start])
+		b.WriteString(values[strings.TrimSpace(tmpl[start+1:start+end])])
+		tmpl = tmpl[start+end+1:]
+	}
+	b.WriteString(tmpl)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
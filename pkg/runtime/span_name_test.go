@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+type keyData map[string]interface{}
+
+func (d keyData) GetKeyData(key string) interface{} { return d[key] }
+
+func TestRenderSpanName(t *testing.T) {
+	values := map[string]string{"operation": "SELECT", "table": "users", "method": "GET"}
+	tests := []struct {
+		name     string
+		tmpl     string
+		expected string
+	}{
+		{name: "all values", tmpl: "{operation} {table}", expected: "SELECT users"},
+		{name: "literal text", tmpl: "db: {operation} on {table}", expected: "db: SELECT on users"},
+		{name: "spaces in placeholder", tmpl: "{ operation }", expected: "SELECT"},
+		{name: "missing value", tmpl: "{method} {route}", expected: "GET"},
+		{name: "no placeholder", tmpl: "fixed name", expected: "fixed name"},
+		{name: "unterminated placeholder", tmpl: "{operation} {table", expected: "SELECT {table"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RenderSpanName(tt.tmpl, values))
+		})
+	}
+}
+
+func TestSpanName(t *testing.T) {
+	values := map[string]string{"operation": "INSERT", "table": "orders"}
+
+	assert.Equal(t, "INSERT orders",
+		SpanName(keyData{SpanNameTemplateKey: "{operation} {table}"}, "INSERT", values))
+	assert.Equal(t, "fallback", SpanName(keyData{}, "fallback", values), "no template")
+	assert.Equal(t, "fallback",
+		SpanName(keyData{SpanNameTemplateKey: "{route}"}, "fallback", values), "empty rendering")
+}
+
+func TestSpanNameWithContext(t *testing.T) {
+	tenant, err := baggage.NewMember("tenant", "acme")
+	require.NoError(t, err)
+	bag, err := baggage.New(tenant)
+	require.NoError(t, err)
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+	values := map[string]string{"method": "GET", "route": "/orders"}
+
+	tmpl := keyData{SpanNameTemplateKey: "{baggage.tenant} {method} {route}"}
+	assert.Equal(t, "acme GET /orders", SpanNameWithContext(ctx, tmpl, "GET /orders", values))
+	assert.NotContains(t, values, "baggage.tenant", "the values of the hook are left untouched")
+	assert.Equal(t, "GET /orders",
+		SpanNameWithContext(context.Background(), tmpl, "GET /orders", values), "no baggage")
+	assert.Equal(t, "GET /orders", SpanNameWithContext(ctx, keyData{}, "GET /orders", values), "no template")
+}
@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// envPrintTraceTree prints, when set to "true", the spans of the process
+	// as a tree of span names and durations to stdout on shutdown, to check
+	// the parent/child relationships of the spans without a backend.
+	envPrintTraceTree = "OTEL_GO_PRINT_TRACE_TREE"
+
+	// maxTreeSpans bounds the spans the tree printer keeps until shutdown.
+	maxTreeSpans = 10000
+)
+
+// printTraceTree reports whether the trace tree is printed on shutdown.
+func printTraceTree() bool {
+	return os.Getenv(envPrintTraceTree) == "true"
+}
+
+// treeSpanProcessor keeps the spans that end and prints them to out, trace by
+// trace, as a tree when it is shut down.
+type treeSpanProcessor struct {
+	out io.Writer
+
+	mu      sync.Mutex
+	spans   []sdktrace.ReadOnlySpan
+	dropped int
+}
+
+func newTreeSpanProcessor(out io.Writer) *treeSpanProcessor {
+	return &treeSpanProcessor{out: out}
+}
+
+func (p *treeSpanProcessor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (p *treeSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.spans) == maxTreeSpans {
+		p.dropped++
+		return
+	}
+	p.spans = append(p.spans, s)
+}
+
+func (p *treeSpanProcessor) ForceFlush(context.Context) error { return nil }
+
+func (p *treeSpanProcessor) Shutdown(context.Context) error {
+	p.mu.Lock()
+	spans, dropped := p.spans, p.dropped
+	p.spans, p.dropped = nil, 0
+	p.mu.Unlock()
+	if err := writeTraceTree(p.out, spans); err != nil {
+		return err
+	}
+	if dropped > 0 {
+		_, err := fmt.Fprintf(p.out, "(%d more spans not shown)\n", dropped)
+		return err
+	}
+	return nil
+}
+
+// writeTraceTree writes spans to out as one tree per trace, in the order the
+// traces were first seen. Spans whose parent is not among spans, e.g. the
+// server span of a request from another process, are roots. Siblings are
+// sorted by start time.
+func writeTraceTree(out io.Writer, spans []sdktrace.ReadOnlySpan) error {
+	var traces []trace.TraceID
+	seen := make(map[trace.SpanID]bool, len(spans))
+	for _, s := range spans {
+		seen[s.SpanContext().SpanID()] = true
+	}
+	roots := make(map[trace.TraceID][]sdktrace.ReadOnlySpan)
+	children := make(map[trace.SpanID][]sdktrace.ReadOnlySpan)
+	for _, s := range spans {
+		traceID := s.SpanContext().TraceID()
+		if parent := s.Parent(); parent.IsValid() && seen[parent.SpanID()] {
+			children[parent.SpanID()] = append(children[parent.SpanID()], s)
+			continue
+		}
+		if _, ok := roots[traceID]; !ok {
+			traces = append(traces, traceID)
+		}
+		roots[traceID] = append(roots[traceID], s)
+	}
+
+	var write func(s sdktrace.ReadOnlySpan, indent string, last bool) error
+	write = func(s sdktrace.ReadOnlySpan, indent string, last bool) error {
+		branch, next := "├─ ", "│  "
+		if last {
+			branch, next = "└─ ", "   "
+		}
+		duration := s.EndTime().Sub(s.StartTime()).Round(time.Microsecond)
+		if _, err := fmt.Fprintf(out, "%s%s%s (%s)\n", indent, branch, s.Name(), duration); err != nil {
+			return err
+		}
+		kids := sortedByStart(children[s.SpanContext().SpanID()])
+		for i, kid := range kids {
+			if err := write(kid, indent+next, i == len(kids)-1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, traceID := range traces {
+		if _, err := fmt.Fprintf(out, "trace %s\n", traceID); err != nil {
+			return err
+		}
+		tops := sortedByStart(roots[traceID])
+		for i, s := range tops {
+			if err := write(s, "", i == len(tops)-1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func sortedByStart(spans []sdktrace.ReadOnlySpan) []sdktrace.ReadOnlySpan {
+	return slices.SortedStableFunc(slices.Values(spans), func(a, b sdktrace.ReadOnlySpan) int {
+		return a.StartTime().Compare(b.StartTime())
+	})
+}
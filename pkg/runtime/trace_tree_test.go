@@ -0,0 +1,61 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTreeSpanProcessor(t *testing.T) {
+	var out bytes.Buffer
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(newTreeSpanProcessor(&out)))
+	tracer := tp.Tracer("test")
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	ctx, parent := tracer.Start(context.Background(), "GET /users", trace.WithTimestamp(at(0)))
+	// The second child ends first, the tree still lists children by start time
+	_, second := tracer.Start(ctx, "SELECT users", trace.WithTimestamp(at(5)))
+	second.End(trace.WithTimestamp(at(7)))
+	_, first := tracer.Start(ctx, "redis GET", trace.WithTimestamp(at(1)))
+	first.End(trace.WithTimestamp(at(9)))
+	parent.End(trace.WithTimestamp(at(12)))
+
+	assert.Empty(t, out.String(), "the tree is only printed on shutdown")
+	require.NoError(t, tp.Shutdown(context.Background()))
+
+	expected := "trace " + parent.SpanContext().TraceID().String() + "\n" +
+		"└─ GET /users (12ms)\n" +
+		"   ├─ redis GET (8ms)\n" +
+		"   └─ SELECT users (2ms)\n"
+	assert.Equal(t, expected, out.String())
+}
+
+func TestTreeSpanProcessor_RemoteParent(t *testing.T) {
+	var out bytes.Buffer
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(newTreeSpanProcessor(&out)))
+
+	remote := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), remote)
+	_, span := tp.Tracer("test").Start(ctx, "server")
+	span.End()
+	require.NoError(t, tp.Shutdown(context.Background()))
+
+	assert.Contains(t, out.String(), "trace 01000000000000000000000000000000\n└─ server (",
+		"a span whose parent is in another process is a root")
+}
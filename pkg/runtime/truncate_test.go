@@ -0,0 +1,17 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateUTF8(t *testing.T) {
+	assert.Equal(t, "héllo", TruncateUTF8("héllo", 10))
+	assert.Equal(t, "h", TruncateUTF8("héllo", 2), "a rune is never split")
+	assert.Equal(t, "hé", TruncateUTF8("héllo", 3))
+	assert.Empty(t, TruncateUTF8("é", 1))
+}
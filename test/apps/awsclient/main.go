@@ -0,0 +1,49 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package main provides a minimal AWS SQS client for integration testing.
+// This client is designed to be instrumented with the otelc compile-time tool.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"log/slog"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+var (
+	addr     = flag.String("addr", "http://localhost:9324", "The SQS endpoint")
+	queueURL = flag.String("queue-url", "http://localhost:9324/000000000000/orders", "The URL of the queue")
+)
+
+func main() {
+	flag.Parse()
+	ctx := context.Background()
+
+	cfg, err := config.LoadDefaultConfig(ctx,
+		config.WithRegion("us-east-1"),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider("test", "test", "")),
+	)
+	if err != nil {
+		log.Fatalf("failed to load AWS config: %v", err)
+	}
+
+	client := sqs.NewFromConfig(cfg, func(o *sqs.Options) {
+		o.BaseEndpoint = aws.String(*addr)
+	})
+	out, err := client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:    queueURL,
+		MessageBody: aws.String("order created"),
+	})
+	if err != nil {
+		log.Fatalf("failed to send message: %v", err)
+	}
+
+	slog.Info("message sent", "id", aws.ToString(out.MessageId))
+}
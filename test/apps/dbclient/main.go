@@ -10,6 +10,7 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -20,7 +21,7 @@ import (
 var (
 	driverName = flag.String("driver", "testdb", "The database driver name")
 	dsn        = flag.String("dsn", "user:pass@tcp(127.0.0.1:3306)/testdb?charset=utf8", "The data source name")
-	op         = flag.String("op", "all", "The operation to perform: ping, exec, query, tx, prepare, all")
+	op         = flag.String("op", "all", "The operation to perform: ping, exec, query, tx, prepare, cancel, all")
 )
 
 func init() {
@@ -55,6 +56,8 @@ func main() {
 		doTx(ctx, db)
 	case "prepare":
 		doPrepare(ctx, db)
+	case "cancel":
+		doCancelled(ctx, db)
 	case "all":
 		doPing(ctx, db)
 		doExec(ctx, db)
@@ -116,6 +119,15 @@ func doPrepare(ctx context.Context, db *sql.DB) {
 	slog.Info("prepare and stmt query succeeded")
 }
 
+func doCancelled(ctx context.Context, db *sql.DB) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	cancel(errors.New("request abandoned by caller"))
+	if _, err := db.ExecContext(ctx, "DELETE FROM sessions"); !errors.Is(err, context.Canceled) {
+		log.Fatalf("expected a cancelled exec, got: %v", err)
+	}
+	slog.Info("cancelled exec failed as expected")
+}
+
 func doTx(ctx context.Context, db *sql.DB) {
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
@@ -0,0 +1,48 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package main is a minimal echo server used for e2e instrumentation testing.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+var port = flag.String("port", "8080", "port to listen on")
+
+func main() {
+	flag.Parse()
+
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"id": c.Param("id")})
+	})
+
+	e.GET("/status/:code", func(c echo.Context) error {
+		code, err := strconv.Atoi(c.Param("code"))
+		if err != nil || code < 100 || code > 599 {
+			return c.NoContent(http.StatusBadRequest)
+		}
+		return c.NoContent(code)
+	})
+
+	// The error is turned into a 500 response by echo's error handler.
+	e.GET("/error", func(echo.Context) error {
+		return errors.New("echo handler error")
+	})
+
+	addr := fmt.Sprintf(":%s", *port)
+	if err := e.Start(addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("server error: %v", err)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/open-telemetry/opentelemetry-go-compile-instrumentation/test/apps/gotest
+
+go 1.25.0
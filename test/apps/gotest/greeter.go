@@ -0,0 +1,23 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package gotest is a library package whose own tests are run through
+// `otelc go test` to verify that test binaries are instrumented.
+package gotest
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// NewMux returns a handler serving a single greeting endpoint.
+func NewMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		if err := json.NewEncoder(w).Encode("Hello " + name); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	return mux
+}
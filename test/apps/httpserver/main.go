@@ -22,11 +22,22 @@ func greetHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ordersHandler is registered via http.Handle, so it is served through the
+// ServeHTTP method of a user-defined type rather than a HandlerFunc.
+type ordersHandler struct{}
+
+func (ordersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := json.NewEncoder(w).Encode("Order " + r.URL.Query().Get("name")); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func main() {
 	flag.Parse()
 
 	addr := fmt.Sprintf(":%s", *port)
 	http.HandleFunc("/hello", greetHandler)
+	http.Handle("/orders", ordersHandler{})
 	if err := http.ListenAndServe(addr, nil); err != nil {
 		log.Fatalf("failed to serve: %v", err)
 	}
@@ -0,0 +1,10 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package greet is instrumented by the incremental build integration test.
+package greet
+
+// Greet returns the greeting of name.
+func Greet(name string) string {
+	return "Hello, " + name
+}
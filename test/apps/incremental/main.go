@@ -0,0 +1,20 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package main greets through a package the integration test instruments, for
+// testing incremental builds that reuse its instrumented archive.
+package main
+
+import (
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/test/apps/incremental/greet"
+)
+
+// build is changed by the integration test between two builds, so that only
+// the main package is compiled again.
+const build = "first"
+
+func main() {
+	fmt.Println(greet.Greet("world"), build)
+}
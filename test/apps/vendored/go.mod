@@ -0,0 +1,7 @@
+module github.com/open-telemetry/opentelemetry-go-compile-instrumentation/test/apps/vendored
+
+go 1.25.0
+
+require example.com/greet v0.0.0
+
+replace example.com/greet => ./greet
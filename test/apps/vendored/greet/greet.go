@@ -0,0 +1,13 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package greet is a local dependency of the vendored app, vendored into its
+// vendor directory.
+package greet
+
+import "net/url"
+
+// Path returns the path and query that greet name.
+func Path(name string) string {
+	return "/hello?name=" + url.QueryEscape(name)
+}
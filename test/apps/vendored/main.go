@@ -0,0 +1,38 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package main provides a minimal HTTP client whose dependencies are vendored
+// with go mod vendor, for integration testing of vendored projects.
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+
+	"example.com/greet"
+)
+
+var (
+	addr = flag.String("addr", "http://localhost:8080", "The server address")
+	name = flag.String("name", "world", "The name to greet")
+)
+
+func main() {
+	flag.Parse()
+
+	resp, err := http.Get(*addr + greet.Path(*name))
+	if err != nil {
+		log.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatalf("failed to read response: %v", err)
+	}
+
+	slog.Info("response", "body", string(body))
+}
@@ -0,0 +1,42 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/test/testutil"
+)
+
+// TestAllowlist_RefusesUnlistedModule is not parallel: it builds the
+// httpclient app, as TestHTTPClient does, in the same directory.
+func TestAllowlist_RefusesUnlistedModule(t *testing.T) {
+	appDir := filepath.Join("..", "apps", "httpclient")
+	goMod, err := os.ReadFile(filepath.Join(appDir, "go.mod"))
+	require.NoError(t, err)
+
+	allowlist := filepath.Join(t.TempDir(), "allowlist.txt")
+	require.NoError(t, os.WriteFile(allowlist, []byte(
+		"# Only the gRPC client is vetted\n"+
+			"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/google.golang.org/grpc/client\n",
+	), 0o644))
+
+	output := testutil.BuildFails(t, "", "httpclient", "--allowlist", allowlist, "go", "build", "-a")
+	assert.Contains(t, output, "refusing to inject instrumentation modules missing from allowlist")
+	assert.Contains(t, output,
+		"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/instrumentation/net/http/client")
+
+	// Nothing was injected into the project
+	after, err := os.ReadFile(filepath.Join(appDir, "go.mod"))
+	require.NoError(t, err)
+	assert.Equal(t, string(goMod), string(after))
+	assert.NoFileExists(t, filepath.Join(appDir, "otelc.runtime.go"))
+}
@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package test
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/test/testutil"
+)
+
+func TestAWSClient(t *testing.T) {
+	t.Parallel()
+	testutil.Build(t, "", "awsclient", "go", "build", "-a")
+
+	f := testutil.NewTestFixture(t)
+	server, traceparent := startMockSQSServer(t)
+
+	f.Run("awsclient",
+		"-addr="+server.URL,
+		fmt.Sprintf("-queue-url=%s/000000000000/orders", server.URL),
+	)
+
+	span := testutil.RequireSpan(t, f.Traces(),
+		testutil.IsClient,
+		testutil.HasName("SQS.SendMessage"),
+	)
+	testutil.RequireAttribute(t, span, string(semconv.RPCSystemKey), "aws-api")
+	testutil.RequireAttribute(t, span, string(semconv.RPCServiceKey), "SQS")
+	testutil.RequireAttribute(t, span, string(semconv.RPCMethodKey), "SendMessage")
+	testutil.RequireAttribute(t, span, string(semconv.CloudRegionKey), "us-east-1")
+	testutil.RequireAttribute(t, span, string(semconv.AWSRequestIDKey), "b0e2d4f6-0000-4000-8000-000000000001")
+	testutil.RequireAttribute(t, span, string(semconv.HTTPResponseStatusCodeKey), int64(200))
+	testutil.RequireAttribute(t, span, string(semconv.MessagingSystemKey), "aws_sqs")
+	testutil.RequireAttribute(t, span, string(semconv.MessagingDestinationNameKey), "orders")
+
+	// The consumer of the message continues the trace of the operation
+	require.NotEmpty(t, traceparent(), "the message must carry the trace context")
+	assert.Equal(t,
+		fmt.Sprintf("00-%s-%s-01", span.TraceID(), span.SpanID()),
+		traceparent())
+}
+
+// startMockSQSServer creates a mock SQS endpoint answering SendMessage. The
+// returned function reports the traceparent message attribute of the last
+// message sent.
+func startMockSQSServer(t *testing.T) (*httptest.Server, func() string) {
+	t.Helper()
+
+	var (
+		mu          sync.Mutex
+		traceparent string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if target := r.Header.Get("X-Amz-Target"); target != "AmazonSQS.SendMessage" {
+			http.Error(w, "unexpected operation "+target, http.StatusBadRequest)
+			return
+		}
+		var reqBody struct {
+			MessageBody       string `json:"MessageBody"`
+			MessageAttributes map[string]struct {
+				StringValue string `json:"StringValue"`
+			} `json:"MessageAttributes"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		traceparent = reqBody.MessageAttributes["traceparent"].StringValue
+		mu.Unlock()
+
+		// The client checks the body it sent against the digest in the reply
+		sum := md5.Sum([]byte(reqBody.MessageBody))
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.Header().Set("X-Amzn-Requestid", "b0e2d4f6-0000-4000-8000-000000000001")
+		resp := map[string]any{
+			"MessageId":        "5fea7756-0ea4-451a-a703-a558b933e274",
+			"MD5OfMessageBody": hex.EncodeToString(sum[:]),
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return traceparent
+	}
+}
@@ -49,6 +49,13 @@ func TestBasic(t *testing.T) {
 		"Underscore",
 		"AutoDetect: 00000000-0000-0000-0000-000000000000",
 		"UnnamedBefore 42 2.7",
+		// After hooks observe the results of every return path
+		`EarlyReturnAfter "negative" negative input`,
+		`EarlyReturnAfter "zero" <nil>`,
+		`EarlyReturnAfter "small!" <nil>`,
+		`EarlyReturnAfter "large!" <nil>`,
+		`ParityAfter "even"`,
+		`ParityAfter "odd"`,
 	}
 	for _, e := range expect {
 		require.Contains(t, output, e)
@@ -62,6 +69,7 @@ func TestBasic(t *testing.T) {
 type exportedSpan struct {
 	Name                 string               `json:"Name"`
 	SpanContext          spanContext          `json:"SpanContext"`
+	Parent               spanContext          `json:"Parent"`
 	Attributes           []spanAttribute      `json:"Attributes"`
 	InstrumentationScope instrumentationScope `json:"InstrumentationScope"`
 }
@@ -158,6 +166,13 @@ func verifyTracePropagationBetweenFunctionAAndB(t *testing.T, output string) {
 
 	require.Equal(t, traceA, traceB, "expected FunctionA and FunctionB to share the same trace ID")
 	require.NotEqual(t, spanA, spanB, "expected FunctionA and FunctionB to have different span IDs")
+
+	// The internal spans are named after the hooked functions.
+	exportedA := findExportedSpan(t, output, "main.FunctionA")
+	exportedB := findExportedSpan(t, output, "main.FunctionB")
+	require.Equal(t, spanA, exportedA.SpanContext.SpanID)
+	require.Equal(t, spanB, exportedB.SpanContext.SpanID)
+	require.Equal(t, spanA, exportedB.Parent.SpanID, "expected main.FunctionB to be a child of main.FunctionA")
 }
 
 //nolint:revive // just a helper function to extract span info from the output
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/test/testutil"
@@ -39,13 +40,15 @@ func TestDBClient(t *testing.T) {
 		f.Run("dbclient", "-op=exec")
 
 		span := f.RequireSingleSpan()
-		require.Equal(t, "INSERT", span.Name())
+		require.Equal(t, "INSERT users", span.Name())
 		testutil.RequireDBClientSemconv(t, span,
 			"INSERT",
 			"INSERT INTO users (name, email) VALUES (?, ?)",
 			"unknown", 0,
 			"testdb",
 		)
+		testutil.RequireAttribute(t, span, "db.query.summary", "INSERT users")
+		testutil.RequireAttribute(t, span, "db.operation.parameter.count", int64(2))
 	})
 
 	t.Run("Query", func(t *testing.T) {
@@ -54,13 +57,25 @@ func TestDBClient(t *testing.T) {
 		f.Run("dbclient", "-op=query")
 
 		span := f.RequireSingleSpan()
-		require.Equal(t, "SELECT", span.Name())
+		require.Equal(t, "SELECT users", span.Name())
 		testutil.RequireDBClientSemconv(t, span,
 			"SELECT",
 			"SELECT id, name FROM users WHERE name = ?",
 			"unknown", 0,
 			"testdb",
 		)
+		testutil.RequireAttribute(t, span, "db.operation.parameter.count", int64(1))
+	})
+
+	t.Run("CancelCause", func(t *testing.T) {
+		f := testutil.NewTestFixture(t)
+
+		f.Run("dbclient", "-op=cancel")
+
+		span := f.RequireSingleSpan()
+		require.Equal(t, ptrace.StatusCodeError, span.Status().Code())
+		require.Equal(t, "request abandoned by caller", span.Status().Message())
+		testutil.RequireAttribute(t, span, "context.cancel.cause", "request abandoned by caller")
 	})
 
 	t.Run("PrepareAndQuery", func(t *testing.T) {
@@ -74,7 +89,7 @@ func TestDBClient(t *testing.T) {
 
 		// Find the query span from stmt.QueryContext
 		stmtSpan := testutil.RequireSpan(t, f.Traces(), testutil.IsClient)
-		require.Equal(t, "SELECT", stmtSpan.Name())
+		require.Equal(t, "SELECT users", stmtSpan.Name())
 	})
 
 	t.Run("Transaction", func(t *testing.T) {
@@ -90,19 +105,55 @@ func TestDBClient(t *testing.T) {
 			testutil.IsClient,
 			testutil.HasAttribute("db.operation.name", "START"),
 		)
-		require.Equal(t, "START", beginSpan.Name())
+		require.Equal(t, "START TRANSACTION", beginSpan.Name())
 
 		execSpan := testutil.RequireSpan(t, f.Traces(),
 			testutil.IsClient,
 			testutil.HasAttribute("db.operation.name", "INSERT"),
 		)
-		require.Equal(t, "INSERT", execSpan.Name())
+		require.Equal(t, "INSERT orders", execSpan.Name())
 
 		commitSpan := testutil.RequireSpan(t, f.Traces(),
 			testutil.IsClient,
 			testutil.HasAttribute("db.operation.name", "COMMIT"),
 		)
 		require.Equal(t, "COMMIT", commitSpan.Name())
+
+		// The commit is traced within the transaction begun by BeginTx
+		require.Equal(t, beginSpan.TraceID(), commitSpan.TraceID())
+		require.Equal(t, beginSpan.SpanID(), commitSpan.ParentSpanID())
+
+		// Events mark the statements run within the transaction and its end
+		require.Equal(t, 1, execSpan.Events().Len())
+		statement := execSpan.Events().At(0)
+		require.Equal(t, "db.transaction.statement", statement.Name())
+		index, ok := statement.Attributes().Get("db.transaction.statement.index")
+		require.True(t, ok)
+		require.Equal(t, int64(1), index.Int())
+		require.Equal(t, 1, commitSpan.Events().Len())
+		end := commitSpan.Events().At(0)
+		require.Equal(t, "db.transaction.end", end.Name())
+		count, ok := end.Attributes().Get("db.transaction.statement.count")
+		require.True(t, ok)
+		require.Equal(t, int64(1), count.Int())
+	})
+
+	t.Run("DriverSpans", func(t *testing.T) {
+		f := testutil.NewTestFixture(t)
+		f.SetEnv("OTEL_GO_DB_DRIVER_SPANS", "true")
+
+		f.Run("dbclient", "-op=exec")
+
+		// The exec the driver runs is traced below the span of db.ExecContext
+		clientSpan := testutil.RequireSpan(t, f.Traces(), testutil.IsClient)
+		require.Equal(t, "INSERT users", clientSpan.Name())
+		driverSpan := testutil.RequireSpan(t, f.Traces(),
+			testutil.IsInternal,
+			testutil.HasName("sql.conn.exec"),
+		)
+		require.Equal(t, clientSpan.SpanID(), driverSpan.ParentSpanID())
+		testutil.RequireAttribute(t, driverSpan,
+			string(semconv.DBQueryTextKey), "INSERT INTO users (name, email) VALUES (?, ?)")
 	})
 
 	t.Run("All", func(t *testing.T) {
@@ -116,11 +167,11 @@ func TestDBClient(t *testing.T) {
 
 		// "all" operation produces 7 spans:
 		//   PING (PingContext)
-		//   INSERT (ExecContext)
-		//   SELECT (QueryContext)
-		//   SELECT (Stmt.QueryContext via PrepareContext)
-		//   START (BeginTx)
-		//   INSERT (Tx.ExecContext)
+		//   INSERT users (ExecContext)
+		//   SELECT users (QueryContext)
+		//   SELECT users (Stmt.QueryContext via PrepareContext)
+		//   START TRANSACTION (BeginTx)
+		//   INSERT orders (Tx.ExecContext)
 		//   COMMIT (Tx.Commit)
 		spans := testutil.AllSpans(f.Traces())
 		require.GreaterOrEqual(t, len(spans), 7, "Expected at least 7 spans")
@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/test/testutil"
+)
+
+func TestEchoServer(t *testing.T) {
+	t.Parallel()
+	testutil.Build(t, "", "echoserver", "go", "build", "-a")
+
+	testCases := []struct {
+		name       string
+		path       string
+		wantStatus int
+		assertSpan func(t *testing.T, span ptrace.Span)
+	}{
+		{
+			name:       "matched route is enriched with http.route",
+			path:       "/users/42",
+			wantStatus: http.StatusOK,
+			assertSpan: func(t *testing.T, span ptrace.Span) {
+				assert.Equal(t, "GET /users/:id", span.Name(),
+					"span name must be route pattern, not literal URL")
+
+				testutil.RequireAttribute(t, span, string(semconv.HTTPRouteKey), "/users/:id")
+				testutil.RequireAttribute(t, span, string(semconv.HTTPRequestMethodKey), "GET")
+				testutil.RequireAttribute(t, span, string(semconv.HTTPResponseStatusCodeKey), int64(200))
+				testutil.RequireAttribute(t, span, string(semconv.URLPathKey), "/users/42")
+			},
+		},
+		{
+			name:       "status written through echo.Response is captured",
+			path:       fmt.Sprintf("/status/%d", http.StatusServiceUnavailable),
+			wantStatus: http.StatusServiceUnavailable,
+			assertSpan: func(t *testing.T, span ptrace.Span) {
+				assert.Equal(t, "GET /status/:code", span.Name())
+				testutil.RequireAttribute(t, span, string(semconv.HTTPResponseStatusCodeKey), int64(503))
+				testutil.RequireAttributeExists(t, span, string(semconv.ErrorTypeKey))
+			},
+		},
+		{
+			name:       "handler error is answered by the error handler",
+			path:       "/error",
+			wantStatus: http.StatusInternalServerError,
+			assertSpan: func(t *testing.T, span ptrace.Span) {
+				assert.Equal(t, "GET /error", span.Name())
+				testutil.RequireAttribute(t, span, string(semconv.HTTPResponseStatusCodeKey), int64(500))
+				testutil.RequireAttributeExists(t, span, string(semconv.ErrorTypeKey))
+			},
+		},
+		{
+			name:       "unmatched route keeps plain method as span name",
+			path:       "/no-such-route",
+			wantStatus: http.StatusNotFound,
+			assertSpan: func(t *testing.T, span ptrace.Span) {
+				assert.Equal(t, "GET", span.Name(),
+					"span name must remain plain method when no echo route matches")
+
+				_, hasRoute := testutil.Attrs(span)[string(semconv.HTTPRouteKey)]
+				assert.False(t, hasRoute,
+					"http.route must not be set when no echo route matches")
+
+				testutil.RequireAttribute(t, span, string(semconv.HTTPResponseStatusCodeKey), int64(404))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := testutil.NewTestFixture(t)
+			port := testutil.FreePort(t)
+
+			f.Start("echoserver", fmt.Sprintf("-port=%d", port))
+			testutil.WaitForTCP(t, fmt.Sprintf("127.0.0.1:%d", port))
+
+			resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d%s", port, tc.path)) //nolint:noctx
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, tc.wantStatus, resp.StatusCode)
+
+			testutil.WaitForSpanFlush(t)
+
+			f.RequireTraceCount(1)
+			f.RequireSpansPerTrace(1)
+			span := testutil.RequireSpan(t, f.Traces(), testutil.IsServer)
+			tc.assertSpan(t, span)
+		})
+	}
+}
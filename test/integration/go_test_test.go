@@ -0,0 +1,30 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/test/testutil"
+)
+
+// TestGoTest runs a library package's unit tests through `otelc go test` and
+// verifies the test binary is instrumented: the HTTP round trip made by the
+// test must produce both a server and a client span.
+func TestGoTest(t *testing.T) {
+	t.Parallel()
+
+	f := testutil.NewTestFixture(t)
+	output := f.GoTest("gotest", "-count=1", "-v", "./...")
+	require.Contains(t, output, "--- PASS: TestGreeter")
+
+	td := f.Traces()
+	server := testutil.RequireSpan(t, td, testutil.IsServer, testutil.HasAttribute("url.path", "/hello"))
+	client := testutil.RequireSpan(t, td, testutil.IsClient)
+	require.Equal(t, client.TraceID(), server.TraceID(), "server span should continue the client trace")
+}
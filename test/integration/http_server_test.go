@@ -31,6 +31,12 @@ func TestHTTPServer(t *testing.T) {
 			path:   "/hello",
 			method: "GET",
 		},
+		{
+			name:   "handler registered via http.Handle",
+			scheme: "http",
+			path:   "/orders",
+			method: "GET",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -62,6 +68,9 @@ func TestHTTPServer(t *testing.T) {
 				"1.1",
 				"127.0.0.1",
 			)
+			queueTime, ok := testutil.Attrs(span)["http.server.request.queue_time"].(float64)
+			require.True(t, ok, "the queue time should be recorded")
+			require.GreaterOrEqual(t, queueTime, 0.0)
 		})
 	}
 }
@@ -0,0 +1,61 @@
+//go:build integration
+
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/test/testutil"
+)
+
+// incrementalRules injects into the greet package an import nothing else in
+// the app links.
+const incrementalRules = `greet_ascii85:
+  target: github.com/open-telemetry/opentelemetry-go-compile-instrumentation/test/apps/incremental/greet
+  where:
+    func: Greet
+  do:
+    - inject_code:
+        raw: |
+          fmt.Println("ascii85:", ascii85.MaxEncodedLen(len(name)))
+  imports:
+    fmt: "fmt"
+    ascii85: "encoding/ascii85"
+`
+
+// TestIncrementalBuild builds the app twice, changing only its main package in
+// between: the second build reuses the instrumented greet archive from the
+// cache, and must still link the import the instrumentation added to it.
+func TestIncrementalBuild(t *testing.T) {
+	t.Parallel()
+
+	rulesDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(rulesDir, "otelc.yaml"), []byte(incrementalRules), 0o644))
+
+	mainFile := filepath.Join("..", "apps", "incremental", "main.go")
+	source, err := os.ReadFile(mainFile)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.WriteFile(mainFile, source, 0o644) })
+
+	testutil.Build(t, "", "incremental", "--rules-dir", rulesDir, "go", "build")
+	output := testutil.Run(t, "", "incremental", nil)
+	require.Contains(t, output, "ascii85: 10")
+	require.Contains(t, output, "Hello, world first")
+
+	second := strings.Replace(string(source), `const build = "first"`, `const build = "second"`, 1)
+	require.NotEqual(t, string(source), second)
+	require.NoError(t, os.WriteFile(mainFile, []byte(second), 0o644))
+
+	testutil.Build(t, "", "incremental", "--rules-dir", rulesDir, "go", "build")
+	output = testutil.Run(t, "", "incremental", nil)
+	require.Contains(t, output, "ascii85: 10")
+	require.Contains(t, output, "Hello, world second")
+}
@@ -40,19 +40,21 @@ func TestMongoClient(t *testing.T) {
 			spans := testutil.AllSpans(f.Traces())
 			require.GreaterOrEqual(t, len(spans), 1, "expected at least 1 span (insert)")
 
-			// Verify insert span matching the actual attributes from otelmongo
+			// Verify insert span
 			insertSpan := testutil.RequireSpan(t, f.Traces(),
 				testutil.IsClient,
-				testutil.HasAttribute("db.operation", "insert"),
+				testutil.HasAttribute("db.operation.name", "insert"),
 			)
+			require.Equal(t, "insert users", insertSpan.Name())
 
 			// Assert MongoDB specific semantic conventions attributes
-			testutil.RequireAttribute(t, insertSpan, "db.system", "mongodb")
-			testutil.RequireAttribute(t, insertSpan, "db.operation", "insert")
-			testutil.RequireAttribute(t, insertSpan, "db.name", "testdb")
-			testutil.RequireAttribute(t, insertSpan, "db.mongodb.collection", "users")
-			testutil.RequireAttribute(t, insertSpan, "net.peer.name", "127.0.0.1")
-			testutil.RequireAttribute(t, insertSpan, "net.transport", "ip_tcp")
+			testutil.RequireAttribute(t, insertSpan, "db.system.name", "mongodb")
+			testutil.RequireAttribute(t, insertSpan, "db.operation.name", "insert")
+			testutil.RequireAttribute(t, insertSpan, "db.namespace", "testdb")
+			testutil.RequireAttribute(t, insertSpan, "db.collection.name", "users")
+			testutil.RequireAttribute(t, insertSpan, "server.address", "127.0.0.1")
+			testutil.RequireAttribute(t, insertSpan, "network.transport", "tcp")
+			require.NotContains(t, testutil.Attrs(insertSpan), "db.query.text", "documents are not recorded by default")
 		})
 	}
 }
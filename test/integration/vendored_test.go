@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/test/testutil"
+)
+
+func TestVendored(t *testing.T) {
+	t.Parallel()
+	modulesTxt := filepath.Join("..", "apps", "vendored", "vendor", "modules.txt")
+	before, err := os.ReadFile(modulesTxt)
+	require.NoError(t, err)
+
+	// -mod=vendor makes the build load every package from vendor/, whatever
+	// GOFLAGS the environment sets.
+	testutil.Build(t, "", "vendored", "go", "build", "-a", "-mod=vendor")
+
+	// The hook modules were vendored for the build only.
+	after, err := os.ReadFile(modulesTxt)
+	require.NoError(t, err)
+	require.Equal(t, string(before), string(after))
+
+	f := testutil.NewTestFixture(t)
+	server := StartHTTPServerWithResponse(t, 200, `{"message":"Hello"}`)
+
+	f.Run("vendored", "-addr="+server.URL, "-name=world")
+
+	span := f.RequireSingleSpan()
+	testutil.RequireHTTPClientSemconv(
+		t,
+		span,
+		"GET",
+		server.URL+"/hello?name=world",
+		"127.0.0.1",
+		200,
+		server.Port(),
+		"1.1",
+		"http",
+	)
+}
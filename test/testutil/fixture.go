@@ -129,6 +129,12 @@ func (f *TestFixture) Run(appName string, args ...string) string {
 	return Run(f.t, "", appName, f.Env(), args...)
 }
 
+// GoTest runs the named app's tests through `otelc go test` with the fixture
+// environment, so spans emitted by the test binaries reach the collector.
+func (f *TestFixture) GoTest(appName string, args ...string) string {
+	return GoTest(f.t, "", appName, f.Env(), args...)
+}
+
 // BuildAndStart builds the named app and then starts it. Kept for e2e test
 // backward compatibility. Integration tests build applications once and reuse them.
 func (f *TestFixture) BuildAndStart(appName string, args ...string) *Server {
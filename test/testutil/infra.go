@@ -97,6 +97,58 @@ func Build(t *testing.T, appsDir, app string, args ...string) {
 	})
 }
 
+// BuildFails builds the application with the instrumentation tool, expects
+// the build to fail and returns its combined output.
+func BuildFails(t *testing.T, appsDir, app string, args ...string) string {
+	t.Helper()
+	otelc, err := otelcPath()
+	require.NoError(t, err)
+
+	output := appOutputName()
+	args = append(args, "-o", output)
+	args = append([]string{otelc}, args...)
+
+	if appsDir == "" {
+		appsDir, err = appsPath()
+		require.NoError(t, err)
+	}
+	appDir := filepath.Join(appsDir, app)
+
+	cmd := newCmd(t.Context(), appDir, nil, args...)
+	out, err := cmd.CombinedOutput()
+	t.Cleanup(func() {
+		_ = os.Remove(filepath.Join(appDir, output))
+		_ = os.RemoveAll(filepath.Join(appDir, ".otelc-build"))
+	})
+	require.Error(t, err, string(out))
+	return string(out)
+}
+
+// GoTest runs the application's own tests through `otelc go test` and returns
+// the combined output. Unlike Build, no binary is produced, so the test
+// binaries are built and executed by the go command itself. If env is nil,
+// the parent process env is used.
+func GoTest(t *testing.T, appsDir, app string, env []string, args ...string) string {
+	t.Helper()
+	otelc, err := otelcPath()
+	require.NoError(t, err)
+
+	if appsDir == "" {
+		appsDir, err = appsPath()
+		require.NoError(t, err)
+	}
+	appDir := filepath.Join(appsDir, app)
+
+	args = append([]string{otelc, "go", "test"}, args...)
+	cmd := newCmd(t.Context(), appDir, env, args...)
+	out, err := cmd.CombinedOutput()
+	t.Cleanup(func() {
+		_ = os.RemoveAll(filepath.Join(appDir, ".otelc-build"))
+	})
+	require.NoError(t, err, string(out))
+	return string(out)
+}
+
 // Run runs the application and returns the output. It waits for the
 // application to complete. If env is nil, the parent process env is used.
 func Run(t *testing.T, appsDir, app string, env []string, args ...string) string {
@@ -0,0 +1,45 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"github.com/urfave/cli/v3"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/setup"
+)
+
+//nolint:gochecknoglobals // Implementation of a CLI command
+var commandRules = cli.Command{
+	Name:        "rules",
+	Description: "Inspect instrumentation rules",
+	Commands: []*cli.Command{
+		{
+			Name: "cardinality",
+			Description: "Report the attributes the hooks of the func rules matching the dependencies of the " +
+				"module in the current directory may set, flagging the high-cardinality ones",
+			ArgsUsage:       "[--json] [build flags] [packages]",
+			SkipFlagParsing: true,
+			Before:          addLoggerPhaseAttribute,
+			Action:          setup.RulesCardinality,
+		},
+		{
+			Name: "diff",
+			Description: "Report which targets of the module in the current directory gain or " +
+				"lose instrumentation when moving from the old rules to the new rules",
+			ArgsUsage:       "<old-rules> <new-rules> [build flags] [packages]",
+			SkipFlagParsing: true,
+			Before:          addLoggerPhaseAttribute,
+			Action:          setup.RulesDiff,
+		},
+		{
+			Name: "list",
+			Description: "List the func and call rules that match the dependencies of the module in the " +
+				"current directory, along with the hooks they inject",
+			ArgsUsage:       "[--json] [build flags] [packages]",
+			SkipFlagParsing: true,
+			Before:          addLoggerPhaseAttribute,
+			Action:          setup.RulesList,
+		},
+	},
+}
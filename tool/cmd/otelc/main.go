@@ -49,6 +49,18 @@ func main() {
 				TakesFile: true,
 				Value:     "",
 			},
+			&cli.StringSliceFlag{
+				Name:      "rules-dir",
+				Usage:     "A directory of additional rule files, taking precedence over the other rules (repeatable)",
+				TakesFile: true,
+			},
+			&cli.StringFlag{
+				Name:      "allowlist",
+				Sources:   cli.EnvVars(util.EnvOtelcAllowlist),
+				Usage:     "The path to a file listing, one per line, the instrumentation modules that may be injected",
+				TakesFile: true,
+				Value:     "",
+			},
 			&cli.StringFlag{
 				Name:    "profile-path",
 				Sources: cli.EnvVars(profile.EnvProfilePath),
@@ -73,11 +85,40 @@ func main() {
 				Usage:   "Log per-tool wall-clock duration for toolexec commands",
 				Hidden:  true,
 			},
+			&cli.BoolFlag{
+				Name:    "overhead",
+				Sources: cli.EnvVars(util.EnvOtelcOverhead),
+				Usage:   "Record the time spent in hooks on the spans they create",
+				Value:   false,
+			},
+			&cli.BoolFlag{
+				Name:    "force-rebuild",
+				Sources: cli.EnvVars(util.EnvOtelcForceRebuild),
+				Usage:   "Rebuild and instrument every package, as go build -a does, instead of reusing cached ones",
+				Value:   false,
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Report what otelc go would instrument, without building, and leave the project unchanged",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the --dry-run report as JSON",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:    "render-fallback",
+				Sources: cli.EnvVars(util.EnvOtelcRenderFallback),
+				Usage:   "Compile a package uninstrumented, instead of failing, if its instrumented code cannot be rendered",
+				Value:   false,
+			},
 		},
 		Commands: []*cli.Command{
 			&commandSetup,
 			&commandGo,
 			&commandCleanup,
+			&commandRules,
 			&commandToolexec,
 			&commandVersion,
 		},
@@ -90,7 +131,15 @@ func main() {
 			if err != nil {
 				return ctx, err
 			}
-			return initStats(ctx, cmd)
+			ctx, err = initStats(ctx, cmd)
+			if err != nil {
+				return ctx, err
+			}
+			ctx, err = initOverhead(ctx, cmd)
+			if err != nil {
+				return ctx, err
+			}
+			return initRenderFallback(ctx, cmd)
 		},
 		After: func(ctx context.Context, cmd *cli.Command) error {
 			return ex.Join(stopProfiling(ctx, cmd), closeLogger(ctx))
@@ -30,3 +30,39 @@ func initStats(ctx context.Context, cmd *cli.Command) (context.Context, error) {
 
 	return ctx, nil
 }
+
+// initOverhead makes the trampolines measure the time spent in the hooks if
+// --overhead is set. Like initStats, it sets OTELC_OVERHEAD so that the child
+// toolexec processes inherit it.
+func initOverhead(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	if !cmd.Bool("overhead") {
+		return ctx, nil
+	}
+
+	if setErr := os.Setenv(util.EnvOtelcOverhead, "1"); setErr != nil {
+		return ctx, ex.Wrapf(setErr, "set %s", util.EnvOtelcOverhead)
+	}
+
+	logger := util.LoggerFromContext(ctx)
+	logger.InfoContext(ctx, "hook overhead measurement enabled")
+
+	return ctx, nil
+}
+
+// initRenderFallback sets OTELC_RENDER_FALLBACK if --render-fallback is set,
+// so that the child toolexec processes compile the packages whose instrumented
+// code cannot be rendered uninstrumented.
+func initRenderFallback(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	if !cmd.Bool("render-fallback") {
+		return ctx, nil
+	}
+
+	if setErr := os.Setenv(util.EnvOtelcRenderFallback, "1"); setErr != nil {
+		return ctx, ex.Wrapf(setErr, "set %s", util.EnvOtelcRenderFallback)
+	}
+
+	logger := util.LoggerFromContext(ctx)
+	logger.InfoContext(ctx, "fallback to uninstrumented packages enabled")
+
+	return ctx, nil
+}
@@ -4,8 +4,12 @@
 package ast
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/dave/dst"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -13,3 +17,34 @@ func TestParseAst(t *testing.T) {
 	_, err := ParseFile("ast_test.go")
 	require.NoError(t, err)
 }
+
+func TestWriteFile(t *testing.T) {
+	root, err := NewAstParser().ParseSource("package main\n\nfunc main() {}\n")
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "main.go")
+
+	require.NoError(t, WriteFile(path, root))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc main() {}\n", string(content))
+}
+
+func TestWriteFile_RenderError(t *testing.T) {
+	root, err := NewAstParser().ParseSource("package main\n\nfunc main() {}\n\nfunc broken() {}\n")
+	require.NoError(t, err)
+	// A node appearing twice in the tree, which the primitives prevent by
+	// cloning their operands
+	shared := Ident("x")
+	broken := FindFuncDeclWithoutRecv(root, "broken")
+	require.NotNil(t, broken)
+	broken.Body.List = []dst.Stmt{&dst.ExprStmt{X: shared}, &dst.ExprStmt{X: shared}}
+	path := filepath.Join(t.TempDir(), "main.go")
+
+	err = WriteFile(path, root)
+
+	var renderErr *RenderError
+	require.ErrorAs(t, err, &renderErr)
+	assert.Equal(t, path, renderErr.File)
+	assert.Equal(t, []string{"broken"}, renderErr.Funcs)
+	assert.Contains(t, err.Error(), "duplicate node")
+}
@@ -4,10 +4,13 @@
 package ast
 
 import (
+	"fmt"
 	"go/parser"
 	"go/token"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/dave/dst"
 	"github.com/dave/dst/decorator"
@@ -85,17 +88,69 @@ func (ap *AstParser) FindPosition(node dst.Node) token.Position {
 	return ap.fset.Position(astNode.Pos())
 }
 
-// WriteFile writes the AST to a file.
+// RenderError reports that an AST could not be rendered back to source code,
+// i.e. that the instrumentation produced a malformed tree.
+type RenderError struct {
+	// The file being written
+	File string
+	// The functions that fail to render on their own, if any
+	Funcs []string
+	// The error or panic of the printer
+	Err error
+}
+
+func (e *RenderError) Error() string {
+	if len(e.Funcs) == 0 {
+		return fmt.Sprintf("cannot render %s: %v", e.File, e.Err)
+	}
+	return fmt.Sprintf("cannot render %s (%s): %v", e.File, strings.Join(e.Funcs, ", "), e.Err)
+}
+
+func (e *RenderError) Unwrap() error { return e.Err }
+
+// render prints root to w. The printer panics on many malformed trees, e.g.
+// one where a node appears twice, so panics are returned as errors.
+func render(w io.Writer, root *dst.File) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("printer panic: %v", r)
+		}
+	}()
+	return decorator.NewRestorer().Fprint(w, root)
+}
+
+// unrenderableFuncs returns the names of the functions of root that fail to
+// render on their own, to point at what the instrumentation broke.
+func unrenderableFuncs(root *dst.File) []string {
+	var names []string
+	for _, decl := range root.Decls {
+		funcDecl, ok := decl.(*dst.FuncDecl)
+		if !ok {
+			continue
+		}
+		file := &dst.File{Name: dst.NewIdent(root.Name.Name), Decls: []dst.Decl{funcDecl}}
+		if render(io.Discard, file) != nil {
+			name := "<nil>"
+			if funcDecl.Name != nil {
+				name = funcDecl.Name.Name
+			}
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// WriteFile writes the AST to a file. A tree the printer cannot render is
+// reported as a *RenderError.
 func WriteFile(filePath string, root *dst.File) error {
 	file, err := os.Create(filePath)
 	if err != nil {
 		return ex.Wrapf(err, "failed to create file %s", filePath)
 	}
 	defer file.Close()
-	r := decorator.NewRestorer()
-	err = r.Fprint(file, root)
+	err = render(file, root)
 	if err != nil {
-		return ex.Wrapf(err, "failed to write to file %s", filePath)
+		return ex.Wrap(&RenderError{File: filePath, Funcs: unrenderableFuncs(root), Err: err})
 	}
 	return nil
 }
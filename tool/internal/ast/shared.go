@@ -4,11 +4,15 @@
 package ast
 
 import (
+	"bytes"
 	"fmt"
 	"go/token"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
@@ -82,10 +86,13 @@ func stripGenericTypes(recvTypeExpr dst.Expr) string {
 	return ""
 }
 
-func findFuncDecl(root *dst.File, funcName, recv string) *dst.FuncDecl {
-	decls := findFuncDecls(root, func(funcDecl *dst.FuncDecl) bool {
+func findFuncDeclsByName(root *dst.File, funcName, recv string) []*dst.FuncDecl {
+	return findFuncDecls(root, func(funcDecl *dst.FuncDecl) bool {
 		// Receiver type is ignored, match func name only
 		name := funcDecl.Name.Name
+		if funcName == rule.FuncAny {
+			name = rule.FuncAny
+		}
 		if recv == "" {
 			return name == funcName && !HasReceiver(funcDecl)
 		}
@@ -107,19 +114,14 @@ func findFuncDecl(root *dst.File, funcName, recv string) *dst.FuncDecl {
 
 		return baseType == recv && name == funcName
 	})
-
-	if len(decls) == 0 {
-		return nil
-	}
-	return decls[0]
 }
 
 // FindFuncDecl finds the function declaration targeted by r, including
-// name, receiver, and optional signature-filter matching.
+// name, receiver, and optional signature-filter and anchor matching.
 //
 // The returned bool reports whether a matching declaration was found. It is
 // false both when no declaration matches r's function name and receiver, and
-// when a declaration is found but does not satisfy r's signature filters. When
+// when no declaration satisfies r's signature filters and anchor. When
 // the bool is false, the returned function declaration is nil.
 func FindFuncDecl[R rule.InstFuncRule | rule.InstRawRule | rule.FilterDef](
 	root *dst.File,
@@ -143,27 +145,98 @@ func FindFuncDecl[R rule.InstFuncRule | rule.InstRawRule | rule.FilterDef](
 		recv = rr.HasRecv
 	}
 
-	funcDecl := findFuncDecl(root, funcName, recv)
-	if funcDecl == nil {
+	decls := findFuncDeclsByName(root, funcName, recv)
+	if len(decls) == 0 {
 		return nil, false, nil
 	}
 
 	if !matchSignature {
-		return funcDecl, true, nil
+		return decls[0], true, nil
 	}
 
 	rr, ok := any(r).(*rule.InstFuncRule)
 	if !ok {
 		return nil, false, ex.Newf("unexpected %T value", r)
 	}
-	ok, err := funcDeclMatchesFilters(funcDecl, rr)
-	if err != nil {
+	// Same-named functions (e.g. several init functions) are told apart by
+	// the rule's filters and anchor; the first one satisfying them wins.
+	decls, err := filterFuncDecls(decls, rr, true)
+	if err != nil || len(decls) == 0 {
 		return nil, false, err
 	}
-	if !ok {
-		return nil, false, nil
+	return decls[0], true, nil
+}
+
+// FindFuncDecls finds all the function declarations targeted by r. Unless the
+// func of r is [rule.FuncAny], there is at most one.
+func FindFuncDecls(root *dst.File, r *rule.InstFuncRule) ([]*dst.FuncDecl, error) {
+	decls := findFuncDeclsByName(root, r.Func, r.Recv)
+	return filterFuncDecls(decls, r, r.Func != rule.FuncAny)
+}
+
+// filterFuncDecls returns the decls satisfying the filters and anchor of r,
+// stopping at the first one if first is set.
+func filterFuncDecls(decls []*dst.FuncDecl, r *rule.InstFuncRule, first bool) ([]*dst.FuncDecl, error) {
+	var found []*dst.FuncDecl
+	for _, funcDecl := range decls {
+		matched, err := funcDeclMatchesFilters(funcDecl, r)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			continue
+		}
+		matched, err = funcBodyContainsAnchor(funcDecl, r.Anchor)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			continue
+		}
+		found = append(found, funcDecl)
+		if first {
+			break
+		}
+	}
+	return found, nil
+}
+
+// ReceiverType returns the receiver type of the method funcDecl, as written in
+// the recv of a rule, e.g. "*Server", or "" for a function.
+func ReceiverType(funcDecl *dst.FuncDecl) string {
+	if !HasReceiver(funcDecl) {
+		return ""
+	}
+	return stripGenericTypes(funcDecl.Recv.List[0].Type)
+}
+
+// funcBodyContainsAnchor reports whether the formatted body of funcDecl
+// contains anchor. An empty anchor matches any function.
+func funcBodyContainsAnchor(funcDecl *dst.FuncDecl, anchor string) (bool, error) {
+	if anchor == "" {
+		return true, nil
+	}
+	if funcDecl.Body == nil {
+		return false, nil
+	}
+	// Print the function alone in a throwaway file, since restoring requires
+	// a file. The clone keeps the decorations of the original tree intact.
+	clone, ok := dst.Clone(funcDecl).(*dst.FuncDecl)
+	util.Assert(ok, "sanity check")
+	file := &dst.File{Name: dst.NewIdent("anchor"), Decls: []dst.Decl{clone}}
+	var buf bytes.Buffer
+	if err := decorator.Fprint(&buf, file); err != nil {
+		return false, ex.Wrapf(err, "failed to print function %s", funcDecl.Name.Name)
 	}
-	return funcDecl, true, nil
+	return strings.Contains(buf.String(), anchor), nil
+}
+
+// FindFuncDeclsNamed returns the functions and methods of root whose name is
+// one of names.
+func FindFuncDeclsNamed(root *dst.File, names []string) []*dst.FuncDecl {
+	return findFuncDecls(root, func(funcDecl *dst.FuncDecl) bool {
+		return slices.Contains(names, funcDecl.Name.Name)
+	})
 }
 
 func ListFuncDecls(root *dst.File) []*dst.FuncDecl {
@@ -275,6 +348,38 @@ func HasReceiver(fn *dst.FuncDecl) bool {
 	return fn.Recv != nil && len(fn.Recv.List) > 0
 }
 
+// CountStmts returns the number of statements in the body of fn, nested ones
+// included, closures' too. Only statements of statement lists are counted:
+// blocks and case clauses only group statements, and the init and post
+// statements of a for, if or switch belong to their header.
+func CountStmts(fn *dst.FuncDecl) int {
+	if fn.Body == nil {
+		return 0
+	}
+	count := 0
+	countList := func(list []dst.Stmt) {
+		for _, stmt := range list {
+			switch stmt.(type) {
+			case *dst.BlockStmt, *dst.EmptyStmt, *dst.CaseClause, *dst.CommClause:
+			default:
+				count++
+			}
+		}
+	}
+	dst.Inspect(fn.Body, func(node dst.Node) bool {
+		switch n := node.(type) {
+		case *dst.BlockStmt:
+			countList(n.List)
+		case *dst.CaseClause:
+			countList(n.Body)
+		case *dst.CommClause:
+			countList(n.Body)
+		}
+		return true
+	})
+	return count
+}
+
 func MakeUnusedIdent(ident *dst.Ident) *dst.Ident {
 	ident.Name = IdentIgnore
 	return ident
@@ -109,6 +109,150 @@ func TestFindFuncDeclForRule(t *testing.T) {
 	})
 }
 
+func TestFindFuncDecls_FuncAny(t *testing.T) {
+	p := NewAstParser()
+	file, err := p.ParseSource(`package main
+
+import "net/http"
+
+type Server struct{}
+
+func Index(w http.ResponseWriter, r *http.Request) {}
+
+func Health(rw http.ResponseWriter, req *http.Request) {}
+
+func Redirect(w http.ResponseWriter, r *http.Request, url string) {}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {}
+`)
+	require.NoError(t, err)
+
+	names := func(decls []*dst.FuncDecl) []string {
+		var out []string
+		for _, decl := range decls {
+			out = append(out, decl.Name.Name)
+		}
+		return out
+	}
+	sig := rule.FuncSignature{Args: []string{"http.ResponseWriter", "*http.Request"}}
+
+	t.Run("functions", func(t *testing.T) {
+		decls, findErr := FindFuncDecls(file, &rule.InstFuncRule{Func: rule.FuncAny, Signature: &sig})
+		require.NoError(t, findErr)
+		assert.Equal(t, []string{"Index", "Health"}, names(decls))
+	})
+
+	t.Run("methods", func(t *testing.T) {
+		decls, findErr := FindFuncDecls(file, &rule.InstFuncRule{Func: rule.FuncAny, Recv: "*Server", Signature: &sig})
+		require.NoError(t, findErr)
+		assert.Equal(t, []string{"ServeHTTP"}, names(decls))
+		assert.Equal(t, "*Server", ReceiverType(decls[0]))
+	})
+
+	t.Run("named func", func(t *testing.T) {
+		decls, findErr := FindFuncDecls(file, &rule.InstFuncRule{Func: "Health", Signature: &sig})
+		require.NoError(t, findErr)
+		assert.Equal(t, []string{"Health"}, names(decls))
+	})
+}
+
+func TestFindFuncDeclForRule_Anchor(t *testing.T) {
+	p := NewAstParser()
+	file, err := p.ParseSource(`package main
+
+func register(name string) {}
+
+func init() {
+	register("json")
+}
+
+func init() {
+	register("yaml")
+}
+`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		anchor   string
+		found    bool
+		expected string
+	}{
+		{name: "no anchor picks the first", anchor: "", found: true, expected: `"json"`},
+		{name: "anchor picks the second", anchor: `register("yaml")`, found: true, expected: `"yaml"`},
+		{name: "unknown anchor", anchor: `register("xml")`, found: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &rule.InstFuncRule{Func: "init", Anchor: tt.anchor}
+			fn, ok, findErr := FindFuncDecl(file, r)
+			require.NoError(t, findErr)
+			require.Equal(t, tt.found, ok)
+			if !tt.found {
+				assert.Nil(t, fn)
+				return
+			}
+			call := fn.Body.List[0].(*dst.ExprStmt).X.(*dst.CallExpr)
+			assert.Equal(t, tt.expected, call.Args[0].(*dst.BasicLit).Value)
+		})
+	}
+}
+
+func TestCountStmts(t *testing.T) {
+	p := NewAstParser()
+	file, err := p.ParseSource(`package main
+
+func Empty() {}
+
+func Getter() int { return 1 }
+
+func Loop(n int) (total int) {
+	for i := 0; i < n; i++ {
+		if i%2 == 0 {
+			total += i
+		}
+	}
+	go func() {
+		println(total)
+	}()
+	return total
+}
+
+func Switch(v any) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	default:
+		if n, ok := v.(int); ok {
+			return fmt.Sprint(n)
+		}
+	}
+	return ""
+}
+`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		expected int
+	}{
+		{name: "Empty", expected: 0},
+		{name: "Getter", expected: 1},
+		// for, if, assignment, go, println, return; not the for's init and post
+		{name: "Loop", expected: 6},
+		// switch, return, if, its return, final return; not the clauses, the
+		// type switch guard nor the if init
+		{name: "Switch", expected: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := FindFuncDeclWithoutRecv(file, tt.name)
+			require.NotNil(t, fn)
+			assert.Equal(t, tt.expected, CountStmts(fn))
+		})
+	}
+}
+
 func TestFindVarDecl(t *testing.T) {
 	file := parseSharedFixture(t)
 
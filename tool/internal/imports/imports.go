@@ -7,14 +7,13 @@ package imports
 import (
 	"context"
 	"go/token"
+	"maps"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/dave/dst"
-
-	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
-	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/pkgload"
 )
 
 // importMapping holds bidirectional import mappings for a file.
@@ -34,8 +33,8 @@ type Resolution struct {
 // parseFile extracts all imports from a file into bidirectional maps.
 // This avoids multiple AST traversals when checking import conflicts.
 //
-// For imports without explicit aliases, the alias is resolved using pkgload.ResolvePackageName(),
-// which uses the go/packages API to get the actual package name. The ExplicitAlias map tracks
+// For imports without explicit aliases, the alias is resolved using packageName(),
+// which gets the actual package name from the go/packages API once per build. The ExplicitAlias map tracks
 // which imports have user-specified aliases in the source file.
 func parseFile(ctx context.Context, root *dst.File, buildFlags ...string) importMapping {
 	maps := importMapping{
@@ -61,7 +60,7 @@ func parseFile(ctx context.Context, root *dst.File, buildFlags ...string) import
 				alias = importSpec.Name.Name
 				maps.ExplicitAlias[importPath] = true
 			} else {
-				alias = pkgload.ResolvePackageName(ctx, importPath, buildFlags...)
+				alias = packageName(ctx, importPath, buildFlags...)
 			}
 
 			maps.AliasToPath[alias] = importPath
@@ -118,7 +117,7 @@ func createSpec(ctx context.Context, alias, importPath string, buildFlags ...str
 		Path: &dst.BasicLit{Value: strconv.Quote(importPath)},
 	}
 
-	pkgName := pkgload.ResolvePackageName(ctx, importPath, buildFlags...)
+	pkgName := packageName(ctx, importPath, buildFlags...)
 
 	// Set Name only if:
 	// 1. It's a blank import (alias == "_")
@@ -141,14 +140,18 @@ func findFirstDecl(root *dst.File) *dst.GenDecl {
 	return nil
 }
 
-// AddToFile adds import declarations to the AST file.
-// It checks for conflicts and reuses existing import blocks when possible.
-func AddToFile(ctx context.Context, root *dst.File, newImports map[string]string, buildFlags ...string) error {
+// AddToFile adds import declarations to the AST file, reusing existing import
+// blocks when possible. An import whose alias the file already uses for a
+// different path is added under a fresh alias instead, see Renames. It
+// returns the renamed aliases (rule alias -> fresh alias), so that the code
+// injected along with the imports can be rewritten to use them.
+func AddToFile(ctx context.Context, root *dst.File, newImports map[string]string, buildFlags ...string) map[string]string {
 	if len(newImports) == 0 {
 		return nil
 	}
 
 	existingImports := getExisting(ctx, root, buildFlags...)
+	renamed := renames(root, existingImports, newImports)
 
 	// Create reverse lookup: path -> alias
 	existingByPath := make(map[string]string)
@@ -156,16 +159,17 @@ func AddToFile(ctx context.Context, root *dst.File, newImports map[string]string
 		existingByPath[importPath] = alias
 	}
 
-	// Check for conflicts: same alias but different path, or same path with different alias
-	for alias, newPath := range newImports {
+	// Skip the imports the file already has, under the same alias or not, and
+	// move the conflicting ones to their fresh alias
+	for _, alias := range slices.Sorted(maps.Keys(newImports)) {
+		newPath := newImports[alias]
 		if alias == "_" || alias == "." {
 			continue
 		}
-		if existingPath, exists := existingImports[alias]; exists {
-			if existingPath != newPath {
-				return ex.Newf("import conflict: alias %q already imports %q, cannot import %q",
-					alias, existingPath, newPath)
-			}
+		if fresh, ok := renamed[alias]; ok {
+			delete(newImports, alias)
+			newImports[fresh] = newPath
+		} else if _, exists := existingImports[alias]; exists {
 			delete(newImports, alias)
 		} else if _, exists = existingByPath[newPath]; exists {
 			delete(newImports, alias)
@@ -173,15 +177,11 @@ func AddToFile(ctx context.Context, root *dst.File, newImports map[string]string
 	}
 
 	if len(newImports) == 0 {
-		return nil
+		return renamed
 	}
 
 	// Sort aliases for deterministic output
-	aliases := make([]string, 0, len(newImports))
-	for alias := range newImports {
-		aliases = append(aliases, alias)
-	}
-	slices.Sort(aliases)
+	aliases := slices.Sorted(maps.Keys(newImports))
 
 	importDecl := findFirstDecl(root)
 
@@ -205,7 +205,105 @@ func AddToFile(ctx context.Context, root *dst.File, newImports map[string]string
 		root.Decls = append([]dst.Decl{newImportDecl}, root.Decls...)
 	}
 
-	return nil
+	return renamed
+}
+
+// Renames returns the fresh alias AddToFile imports each of newImports under
+// when the file already uses its alias for a different path (rule alias ->
+// fresh alias). The fresh aliases only depend on the file and on newImports,
+// so that repeated builds generate the same code.
+func Renames(ctx context.Context, root *dst.File, newImports map[string]string, buildFlags ...string) map[string]string {
+	if len(newImports) == 0 {
+		return nil
+	}
+	return renames(root, getExisting(ctx, root, buildFlags...), newImports)
+}
+
+func renames(root *dst.File, existingImports, newImports map[string]string) map[string]string {
+	taken := topLevelNames(root)
+	for alias := range existingImports {
+		taken[alias] = true
+	}
+	for alias := range newImports {
+		taken[alias] = true
+	}
+	importedPaths := make(map[string]bool, len(existingImports))
+	for _, importPath := range existingImports {
+		importedPaths[importPath] = true
+	}
+
+	var renamed map[string]string
+	for _, alias := range slices.Sorted(maps.Keys(newImports)) {
+		newPath := newImports[alias]
+		existingPath, exists := existingImports[alias]
+		if alias == "_" || alias == "." || !exists || existingPath == newPath || importedPaths[newPath] {
+			continue
+		}
+		fresh := "otel" + alias
+		for i := 2; taken[fresh]; i++ {
+			fresh = "otel" + alias + strconv.Itoa(i)
+		}
+		taken[fresh] = true
+		if renamed == nil {
+			renamed = make(map[string]string)
+		}
+		renamed[alias] = fresh
+	}
+	return renamed
+}
+
+// topLevelNames returns the names declared at the top level of the file, which
+// a fresh alias must not shadow.
+func topLevelNames(root *dst.File) map[string]bool {
+	names := make(map[string]bool)
+	for _, decl := range root.Decls {
+		switch decl := decl.(type) {
+		case *dst.FuncDecl:
+			if decl.Recv == nil {
+				names[decl.Name.Name] = true
+			}
+		case *dst.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *dst.TypeSpec:
+					names[spec.Name.Name] = true
+				case *dst.ValueSpec:
+					for _, name := range spec.Names {
+						names[name.Name] = true
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// Rename rewrites the references node makes to the packages imported under the
+// aliases renamed by AddToFile, e.g. ctx.Value to otelctx.Value.
+func Rename(node dst.Node, renamed map[string]string) {
+	if len(renamed) == 0 || node == nil {
+		return
+	}
+	dst.Inspect(node, func(n dst.Node) bool {
+		if sel, ok := n.(*dst.SelectorExpr); ok {
+			if pkg, isIdent := sel.X.(*dst.Ident); isIdent && pkg.Path == "" {
+				if fresh, found := renamed[pkg.Name]; found {
+					pkg.Name = fresh
+				}
+			}
+		}
+		return true
+	})
+}
+
+// RenameType is Rename for a type written as a string, such as the type of a
+// struct field added by a rule, e.g. *ctx.Value to *otelctx.Value.
+func RenameType(t string, renamed map[string]string) string {
+	for alias, fresh := range renamed {
+		ref := regexp.MustCompile(`(^|[^\w.])` + regexp.QuoteMeta(alias) + `\.`)
+		t = ref.ReplaceAllString(t, "${1}"+fresh+".")
+	}
+	return t
 }
 
 // CollectPaths returns a map of all unique import paths in the file.
@@ -237,12 +237,11 @@ func TestFindFirstDecl(t *testing.T) {
 
 func TestAddToFile(t *testing.T) {
 	tests := []struct {
-		name        string
-		root        *dst.File
-		newImports  map[string]string
-		expectError bool
-		errorMsg    string
-		checkResult func(*testing.T, *dst.File)
+		name            string
+		root            *dst.File
+		newImports      map[string]string
+		expectedRenames map[string]string
+		checkResult     func(*testing.T, *dst.File)
 	}{
 		{
 			name:       "add to empty file",
@@ -257,7 +256,33 @@ func TestAddToFile(t *testing.T) {
 			},
 		},
 		{
-			name: "import conflict",
+			name: "import conflict renamed",
+			root: &dst.File{
+				Decls: []dst.Decl{
+					&dst.GenDecl{
+						Tok: token.IMPORT,
+						Specs: []dst.Spec{
+							&dst.ImportSpec{
+								Name: dst.NewIdent("ctx"),
+								Path: &dst.BasicLit{Value: `"context"`},
+							},
+						},
+					},
+				},
+			},
+			newImports:      map[string]string{"ctx": "fmt"},
+			expectedRenames: map[string]string{"ctx": "otelctx"},
+			checkResult: func(t *testing.T, root *dst.File) {
+				genDecl := root.Decls[0].(*dst.GenDecl)
+				require.Len(t, genDecl.Specs, 2)
+				spec := genDecl.Specs[1].(*dst.ImportSpec)
+				require.NotNil(t, spec.Name)
+				assert.Equal(t, "otelctx", spec.Name.Name)
+				assert.Equal(t, `"fmt"`, spec.Path.Value)
+			},
+		},
+		{
+			name: "import conflict renamed past taken names",
 			root: &dst.File{
 				Decls: []dst.Decl{
 					&dst.GenDecl{
@@ -267,13 +292,22 @@ func TestAddToFile(t *testing.T) {
 								Name: dst.NewIdent("ctx"),
 								Path: &dst.BasicLit{Value: `"context"`},
 							},
+							&dst.ImportSpec{
+								Name: dst.NewIdent("otelctx"),
+								Path: &dst.BasicLit{Value: `"strings"`},
+							},
 						},
 					},
+					&dst.FuncDecl{Name: dst.NewIdent("otelctx2"), Type: &dst.FuncType{}},
 				},
 			},
-			newImports:  map[string]string{"ctx": "other/context"},
-			expectError: true,
-			errorMsg:    "import conflict",
+			newImports:      map[string]string{"ctx": "fmt"},
+			expectedRenames: map[string]string{"ctx": "otelctx3"},
+			checkResult: func(t *testing.T, root *dst.File) {
+				genDecl := root.Decls[0].(*dst.GenDecl)
+				require.Len(t, genDecl.Specs, 3)
+				assert.Equal(t, "otelctx3", genDecl.Specs[2].(*dst.ImportSpec).Name.Name)
+			},
 		},
 		{
 			name: "duplicate import ignored",
@@ -394,15 +428,10 @@ func TestAddToFile(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := AddToFile(t.Context(), tt.root, tt.newImports)
-			if tt.expectError {
-				require.Error(t, err)
-				assert.Contains(t, err.Error(), tt.errorMsg)
-			} else {
-				require.NoError(t, err)
-				if tt.checkResult != nil {
-					tt.checkResult(t, tt.root)
-				}
+			renamed := AddToFile(t.Context(), tt.root, tt.newImports)
+			assert.Equal(t, tt.expectedRenames, renamed)
+			if tt.checkResult != nil {
+				tt.checkResult(t, tt.root)
 			}
 		})
 	}
@@ -616,3 +645,26 @@ func TestCollectPaths(t *testing.T) {
 		})
 	}
 }
+
+func TestRename(t *testing.T) {
+	// ctx refers to the renamed import, the local variable shadows nothing
+	call := &dst.CallExpr{
+		Fun: &dst.SelectorExpr{X: dst.NewIdent("ctx"), Sel: dst.NewIdent("Wrap")},
+		Args: []dst.Expr{
+			&dst.SelectorExpr{X: dst.NewIdent("other"), Sel: dst.NewIdent("Value")},
+			dst.NewIdent("ctx"),
+		},
+	}
+	Rename(call, map[string]string{"ctx": "otelctx"})
+	assert.Equal(t, "otelctx", call.Fun.(*dst.SelectorExpr).X.(*dst.Ident).Name)
+	assert.Equal(t, "other", call.Args[0].(*dst.SelectorExpr).X.(*dst.Ident).Name)
+	assert.Equal(t, "ctx", call.Args[1].(*dst.Ident).Name, "only package references are renamed")
+}
+
+func TestRenameType(t *testing.T) {
+	renamed := map[string]string{"ctx": "otelctx"}
+	assert.Equal(t, "*otelctx.Value", RenameType("*ctx.Value", renamed))
+	assert.Equal(t, "map[string]otelctx.Value", RenameType("map[string]ctx.Value", renamed))
+	assert.Equal(t, "myctx.Value", RenameType("myctx.Value", renamed))
+	assert.Equal(t, "ctx.Value", RenameType("ctx.Value", nil))
+}
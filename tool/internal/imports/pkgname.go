@@ -0,0 +1,134 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package imports
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/pkgload"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
+)
+
+// goListPackageName resolves a package name with go list. Tests replace it to
+// count the resolutions.
+var goListPackageName = pkgload.ResolvePackageName
+
+// packageNames caches the package names resolved for the whole build. Every
+// compile process resolves the names of the imports of the files it
+// instruments, mostly the same few packages, and go list is slow to start:
+// the names are kept in memory and in per-process files of the build temp
+// directory, which each process loads on first use.
+var packageNames struct {
+	sync.Mutex
+	loaded bool
+	// names maps the cache keys to the names resolved by every process
+	names map[string]string
+	// resolved holds the entries of names the file of this process keeps
+	resolved map[string]string
+}
+
+// packageNameKey keys a package name on the build flags along with the import
+// path, as tags may select another package for it.
+func packageNameKey(importPath string, buildFlags []string) string {
+	if len(buildFlags) == 0 {
+		return importPath
+	}
+	return importPath + " " + util.EncodeBuildFlags(buildFlags)
+}
+
+// packageName returns the declared package name for an import path, running go
+// list at most once per import path and build flags across the build. Only
+// resolved names are cached, a failure exits like pkgload.ResolvePackageName
+// and the next compile tries again.
+func packageName(ctx context.Context, importPath string, buildFlags ...string) string {
+	key := packageNameKey(importPath, buildFlags)
+
+	packageNames.Lock()
+	if !packageNames.loaded {
+		loadPackageNames()
+	}
+	name, found := packageNames.names[key]
+	packageNames.Unlock()
+	if found {
+		return name
+	}
+
+	// go list runs unlocked, a concurrent resolution of the same path merely
+	// stores the same name twice
+	name = goListPackageName(ctx, importPath, buildFlags...)
+
+	packageNames.Lock()
+	defer packageNames.Unlock()
+	packageNames.names[key] = name
+	packageNames.resolved[key] = name
+	if err := savePackageNames(packageNames.resolved); err != nil {
+		// Without the file the next processes resolve the name again
+		util.LoggerFromContext(ctx).DebugContext(ctx, "failed to cache package names", "error", err)
+	}
+	return name
+}
+
+// loadPackageNames merges the package name files of every process. Unreadable
+// files are skipped, their names are resolved again.
+func loadPackageNames() {
+	packageNames.loaded = true
+	packageNames.names = make(map[string]string)
+	packageNames.resolved = make(map[string]string)
+
+	files, _ := filepath.Glob(util.GetPackageNamesPattern())
+	own := util.GetPackageNamesFileForProcess()
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var names map[string]string
+		if json.Unmarshal(data, &names) != nil {
+			continue
+		}
+		maps.Copy(packageNames.names, names)
+		if file == own {
+			// Left by an earlier process that had the same PID, keep its names
+			maps.Copy(packageNames.resolved, names)
+		}
+	}
+}
+
+// savePackageNames replaces the package name file of the process atomically,
+// so that a concurrent loadPackageNames never reads it half written.
+func savePackageNames(names map[string]string) error {
+	data, err := json.MarshalIndent(names, "", "  ")
+	if err != nil {
+		return ex.Wrapf(err, "marshaling package names")
+	}
+	filePath := util.GetPackageNamesFileForProcess()
+	// The temporary name does not match GetPackageNamesPattern
+	tmpPath := filePath + ".tmp"
+	if err = os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return ex.Wrapf(err, "writing package names file")
+	}
+	if err = os.Rename(tmpPath, filePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return ex.Wrapf(err, "replacing package names file")
+	}
+	return nil
+}
+
+// CleanupPackageNames removes the package name files of previous builds, whose
+// dependencies may have changed since. It is called by the setup phase.
+func CleanupPackageNames() {
+	files, err := filepath.Glob(util.GetPackageNamesPattern())
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		_ = os.Remove(file) // Best effort cleanup
+	}
+}
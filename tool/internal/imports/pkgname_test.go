@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package imports
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/pkgload"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
+)
+
+// resetPackageNames starts a build in a fresh work dir, as a new process with
+// nothing cached yet, and returns the import paths resolve is then called for.
+func resetPackageNames(t testing.TB, resolve func(string) string) *[]string {
+	t.Helper()
+	t.Setenv(util.EnvOtelcWorkDir, t.TempDir())
+	require.NoError(t, os.MkdirAll(util.GetBuildTempDir(), 0o755))
+
+	var calls []string
+	packageNames.loaded = false
+	goListPackageName = func(_ context.Context, importPath string, _ ...string) string {
+		calls = append(calls, importPath)
+		return resolve(importPath)
+	}
+	t.Cleanup(func() {
+		packageNames.loaded = false
+		goListPackageName = pkgload.ResolvePackageName
+	})
+	return &calls
+}
+
+func TestPackageName(t *testing.T) {
+	t.Run("resolved once", func(t *testing.T) {
+		calls := resetPackageNames(t, filepath.Base)
+
+		assert.Equal(t, "v5", packageName(t.Context(), "example.com/a/v5"))
+		assert.Equal(t, "v5", packageName(t.Context(), "example.com/a/v5"))
+		assert.Equal(t, "v5", packageName(t.Context(), "example.com/a/v5", "-tags=debug"))
+		assert.Equal(t, []string{"example.com/a/v5", "example.com/a/v5"}, *calls,
+			"once without flags and once with")
+	})
+
+	t.Run("shared with the next processes", func(t *testing.T) {
+		calls := resetPackageNames(t, filepath.Base)
+
+		assert.Equal(t, "v5", packageName(t.Context(), "example.com/a/v5"))
+		// Another process, started after this one
+		packageNames.loaded = false
+		assert.Equal(t, "v5", packageName(t.Context(), "example.com/a/v5"))
+		assert.Len(t, *calls, 1)
+	})
+
+	t.Run("loaded from other processes", func(t *testing.T) {
+		calls := resetPackageNames(t, filepath.Base)
+		require.NoError(t, os.WriteFile(util.GetBuildTemp("package_names.1.json"),
+			[]byte(`{"example.com/a/v5": "a"}`), 0o644))
+		require.NoError(t, os.WriteFile(util.GetBuildTemp("package_names.2.json"),
+			[]byte(`not json`), 0o644))
+
+		assert.Equal(t, "a", packageName(t.Context(), "example.com/a/v5"))
+		assert.Equal(t, "b", packageName(t.Context(), "example.com/b"))
+		assert.Equal(t, []string{"example.com/b"}, *calls)
+	})
+
+	t.Run("failures are not cached", func(t *testing.T) {
+		failing := true
+		calls := resetPackageNames(t, func(importPath string) string {
+			if failing {
+				panic("go list failed")
+			}
+			return filepath.Base(importPath)
+		})
+
+		assert.Panics(t, func() { packageName(t.Context(), "example.com/a") })
+		failing = false
+		assert.Equal(t, "a", packageName(t.Context(), "example.com/a"))
+		assert.Len(t, *calls, 2)
+	})
+}
+
+func TestCleanupPackageNames(t *testing.T) {
+	resetPackageNames(t, filepath.Base)
+	packageName(t.Context(), "example.com/a")
+	other := util.GetBuildTemp("other.json")
+	require.NoError(t, os.WriteFile(other, []byte("{}"), 0o644))
+
+	CleanupPackageNames()
+
+	files, err := filepath.Glob(util.GetPackageNamesPattern())
+	require.NoError(t, err)
+	assert.Empty(t, files)
+	assert.FileExists(t, other)
+}
+
+func BenchmarkPackageName(b *testing.B) {
+	ctx := b.Context()
+	b.Run("go list", func(b *testing.B) {
+		for b.Loop() {
+			pkgload.ResolvePackageName(ctx, "net/http")
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		resetPackageNames(b, func(importPath string) string {
+			return pkgload.ResolvePackageName(ctx, importPath)
+		})
+		for b.Loop() {
+			packageName(ctx, "net/http")
+		}
+	})
+}
@@ -5,11 +5,16 @@ package instrument
 
 import (
 	"context"
+	"fmt"
+	"maps"
+	"strconv"
 
 	"github.com/dave/dst"
 	"github.com/dave/dst/dstutil"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/ast"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/imports"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
 )
@@ -19,20 +24,45 @@ import (
 func (ip *InstrumentPhase) applyCallRule(ctx context.Context, r *rule.InstCallRule, root *dst.File) error {
 	importAliases := collectImportAliases(root)
 
-	appendModified := ip.applyCallAppendArgs(r, root, importAliases)
+	ruleImports := r.Imports
+	// The injected code is generated before the imports are added: it uses the
+	// aliases addRuleImports picks below, known beforehand as they only depend
+	// on the file and the rule
+	renamed := imports.Renames(ctx, root, ruleImports)
+
+	// Likewise the alias of the wrap_interface package is picked before the
+	// injected code adds identifiers to the file
+	ifaceAlias, ifaceImported := "", false
+	if r.WrapInterface != "" {
+		importPath, _, ok := r.InterfaceType()
+		if !ok {
+			return ex.Newf("invalid wrap_interface %q", r.WrapInterface)
+		}
+		ifaceAlias, ifaceImported = packageAlias(root, importPath, importAliases, ruleImports)
+	}
+
+	appendModified := ip.applyCallAppendArgs(r, root, importAliases, renamed)
 
 	replaceModified := false
 	if r.Replace != "" {
 		var err error
-		replaceModified, err = ip.applyCallReplace(r, root, importAliases)
+		replaceModified, err = ip.applyCallReplace(r, root, importAliases, renamed, ifaceAlias)
 		if err != nil {
 			return err
 		}
+		if replaceModified && r.WrapInterface != "" && !ifaceImported {
+			ruleImports = withInterfaceImport(r, ruleImports, ifaceAlias)
+		}
 	}
 
-	util.Assert(appendModified || replaceModified, "call rule did not match any call")
+	if !appendModified && !replaceModified {
+		// Rules are matched per package, and per caller when narrowed to
+		// some, so the file need not make any matching call
+		ip.Debug("Call rule matched no call", "rule", r.Name)
+		return nil
+	}
 
-	if err := ip.addRuleImports(ctx, root, r.Imports, r.Name); err != nil {
+	if _, err := ip.addRuleImports(ctx, root, ruleImports, r.Name); err != nil {
 		return err
 	}
 	ip.Info("Apply call rule", "rule", r)
@@ -41,23 +71,39 @@ func (ip *InstrumentPhase) applyCallRule(ctx context.Context, r *rule.InstCallRu
 }
 
 // applyCallReplace applies replacement wrapping to all matching calls in root using a
-// two-pass approach to avoid re-matching wrapped nodes.
+// two-pass approach to avoid re-matching wrapped nodes. The replacement refers
+// to the renamed rule imports under their fresh alias, and to the wrap_interface
+// type under ifaceAlias.
 // Returns true if any replacement was made.
 func (*InstrumentPhase) applyCallReplace(
 	r *rule.InstCallRule,
 	root *dst.File,
 	importAliases map[string]string,
+	renamed map[string]string,
+	ifaceAlias string,
 ) (bool, error) {
 	tmpl, err := newCallTemplate(r.Replace)
 	if err != nil {
 		return false, ex.Wrapf(err, "rule has no compiled replacement template")
 	}
+	tmpl.renamed = renamed
+	var ifaceType dst.Expr
+	if r.WrapInterface != "" {
+		_, name, ok := r.InterfaceType()
+		if !ok {
+			return false, ex.Newf("invalid wrap_interface %q", r.WrapInterface)
+		}
+		ifaceType = &dst.SelectorExpr{
+			X:   &dst.Ident{Name: ifaceAlias},
+			Sel: &dst.Ident{Name: name},
+		}
+	}
 
 	// Pass 1: collect matching calls and pre-compute replacements to avoid
 	// re-matching the original call pointer inside its own wrapper.
 	replacements := make(map[*dst.CallExpr]dst.Expr)
 	var wrapError error
-	dst.Inspect(root, func(node dst.Node) bool {
+	inspectCallScopes(r, root, func(node dst.Node) bool {
 		if wrapError != nil {
 			return false
 		}
@@ -68,7 +114,16 @@ func (*InstrumentPhase) applyCallReplace(
 		if !matchesCallRule(call, r, importAliases) {
 			return true
 		}
-		wrapped, wrapErr := tmpl.compileExpression(call)
+		var original dst.Expr = call
+		if ifaceType != nil {
+			// Convert to the interface so that the wrapper accepts the
+			// constructed value whatever its concrete type
+			original = &dst.CallExpr{
+				Fun:  util.AssertType[dst.Expr](dst.Clone(ifaceType)),
+				Args: []dst.Expr{call},
+			}
+		}
+		wrapped, wrapErr := tmpl.compileExpression(original)
 		if wrapErr != nil {
 			wrapError = wrapErr
 			return false
@@ -102,17 +157,85 @@ func (*InstrumentPhase) applyCallReplace(
 	return true, nil
 }
 
+// inspectCallScopes walks the parts of root whose calls the rule applies to:
+// the bodies of its callers, when it lists some, or else the whole file.
+func inspectCallScopes(r *rule.InstCallRule, root *dst.File, f func(dst.Node) bool) {
+	if len(r.Caller) == 0 {
+		dst.Inspect(root, f)
+		return
+	}
+	for _, funcDecl := range ast.FindFuncDeclsNamed(root, r.Caller) {
+		if funcDecl.Body != nil {
+			dst.Inspect(funcDecl.Body, f)
+		}
+	}
+}
+
+// packageAlias returns the name under which the file refers to the package at
+// importPath, and whether the file already imports it. If it does not, the
+// returned name is the one to import it under: the package name, or a fresh
+// otel-prefixed one when an identifier of the file or an import, the extra
+// ones included, already uses it.
+func packageAlias(
+	root *dst.File,
+	importPath string,
+	importAliases map[string]string,
+	extraImports map[string]string,
+) (string, bool) {
+	for alias, path := range importAliases {
+		if path == importPath {
+			return alias, true
+		}
+	}
+	taken := make(map[string]bool)
+	dst.Inspect(root, func(node dst.Node) bool {
+		// Qualified identifiers name objects of other packages
+		if ident, ok := node.(*dst.Ident); ok && ident.Path == "" {
+			taken[ident.Name] = true
+		}
+		return true
+	})
+	for alias := range importAliases {
+		taken[alias] = true
+	}
+	for alias := range extraImports {
+		taken[alias] = true
+	}
+	name := defaultImportAlias(importPath)
+	if !taken[name] {
+		return name, false
+	}
+	fresh := "otel" + name
+	for i := 2; taken[fresh]; i++ {
+		fresh = "otel" + name + strconv.Itoa(i)
+	}
+	return fresh, false
+}
+
+// withInterfaceImport returns ruleImports extended with the package declaring
+// the wrap_interface type, imported under alias.
+func withInterfaceImport(r *rule.InstCallRule, ruleImports map[string]string, alias string) map[string]string {
+	importPath, _, _ := r.InterfaceType()
+	extended := maps.Clone(ruleImports)
+	if extended == nil {
+		extended = make(map[string]string)
+	}
+	extended[alias] = importPath
+	return extended
+}
+
 func (ip *InstrumentPhase) applyCallAppendArgs(
 	r *rule.InstCallRule,
 	root *dst.File,
 	importAliases map[string]string,
+	renamed map[string]string,
 ) bool {
 	if len(r.AppendArgs) == 0 {
 		return false
 	}
 
 	var matchingCalls []*dst.CallExpr
-	dst.Inspect(root, func(node dst.Node) bool {
+	inspectCallScopes(r, root, func(node dst.Node) bool {
 		call, ok := node.(*dst.CallExpr)
 		if !ok {
 			return true
@@ -123,18 +246,21 @@ func (ip *InstrumentPhase) applyCallAppendArgs(
 		return true
 	})
 	for _, call := range matchingCalls {
-		if _, err := appendCallArgs(call, r); err != nil {
+		if _, err := appendCallArgs(call, r, renamed); err != nil {
 			ip.Warn("Failed to append args to call", "error", err)
+			util.AnnotateWarning(fmt.Sprintf(
+				"instrumentation rule %q failed to apply to a call: %v", r.Name, err))
 		}
 	}
 
 	return len(matchingCalls) > 0
 }
 
-// appendCallArgs appends the expressions from r.AppendArgs to the call's argument list.
+// appendCallArgs appends the expressions from r.AppendArgs to the call's argument list,
+// their references to the renamed rule imports rewritten.
 // For ellipsis calls, an IIFE wrapper is generated using r.VariadicType.
 // Returns (true, nil) if the call was modified, (false, nil) if AppendArgs is empty.
-func appendCallArgs(call *dst.CallExpr, r *rule.InstCallRule) (bool, error) {
+func appendCallArgs(call *dst.CallExpr, r *rule.InstCallRule, renamed map[string]string) (bool, error) {
 	if len(r.AppendArgs) == 0 {
 		return false, nil
 	}
@@ -146,6 +272,7 @@ func appendCallArgs(call *dst.CallExpr, r *rule.InstCallRule) (bool, error) {
 		if err != nil {
 			return false, ex.Wrapf(err, "failed to parse append_args entry %q", argStr)
 		}
+		imports.Rename(argExpr, renamed)
 		newArgs = append(newArgs, argExpr)
 	}
 
@@ -169,6 +296,7 @@ func appendCallArgs(call *dst.CallExpr, r *rule.InstCallRule) (bool, error) {
 	if err != nil {
 		return false, ex.Wrapf(err, "failed to parse variadic_type %q", r.VariadicType)
 	}
+	imports.Rename(varTypeExpr, renamed)
 
 	// Replace the spread arg with an IIFE that appends the new args before spreading.
 	// call.Ellipsis remains true — the outer call is still a spread call.
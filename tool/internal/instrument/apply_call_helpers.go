@@ -22,6 +22,7 @@ import (
 //   - http.Get() after "import 'net/http'" matches "net/http.Get"
 //   - redis.Get() after "import redis 'github.com/redis/go-redis/v9'" matches "github.com/redis/go-redis/v9.Get"
 //   - sql.Open() after "import 'database/sql'" matches "database/sql.Open"
+//   - slices.Index[[]int, int]() after "import 'slices'" matches "slices.Index"
 //
 // What does NOT match:
 //   - Get() without package qualifier (unqualified calls not supported)
@@ -31,8 +32,16 @@ func matchesCallRule(call *dst.CallExpr, r *rule.InstCallRule, importAliases map
 	importPath := r.ImportPath
 	funcName := r.FuncName
 
-	// Only match qualified calls: pkg.Function()
-	sel, ok := call.Fun.(*dst.SelectorExpr)
+	// Only match qualified calls: pkg.Function(), or pkg.Function[T]() for an
+	// instantiation of a generic function
+	fun := call.Fun
+	switch index := fun.(type) {
+	case *dst.IndexExpr:
+		fun = index.X
+	case *dst.IndexListExpr:
+		fun = index.X
+	}
+	sel, ok := fun.(*dst.SelectorExpr)
 	if !ok {
 		return false
 	}
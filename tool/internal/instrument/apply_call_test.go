@@ -6,6 +6,7 @@ package instrument
 import (
 	"context"
 	"go/token"
+	"strings"
 	"testing"
 
 	"github.com/dave/dst"
@@ -13,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
 )
 
 // makeCallFile builds a minimal *dst.File containing a single function whose
@@ -195,6 +197,40 @@ func TestMatchesCallRule_NonSelectorExpression(t *testing.T) {
 	assert.False(t, matches)
 }
 
+func TestMatchesCallRule_GenericInstantiation(t *testing.T) {
+	r := &rule.InstCallRule{
+		ImportPath: "slices",
+		FuncName:   "Index",
+	}
+	sel := func(name string) *dst.SelectorExpr {
+		return &dst.SelectorExpr{
+			X:   &dst.Ident{Name: "slices", Path: "slices"},
+			Sel: &dst.Ident{Name: name},
+		}
+	}
+
+	// slices.Index[[]int](...)
+	single := &dst.CallExpr{
+		Fun: &dst.IndexExpr{X: sel("Index"), Index: &dst.ArrayType{Elt: &dst.Ident{Name: "int"}}},
+	}
+	assert.True(t, matchesCallRule(single, r, nil))
+
+	// slices.Index[[]int, int](...)
+	list := &dst.CallExpr{
+		Fun: &dst.IndexListExpr{X: sel("Index"), Indices: []dst.Expr{
+			&dst.ArrayType{Elt: &dst.Ident{Name: "int"}},
+			&dst.Ident{Name: "int"},
+		}},
+	}
+	assert.True(t, matchesCallRule(list, r, nil))
+
+	// slices.Contains[[]int, int](...)
+	other := &dst.CallExpr{
+		Fun: &dst.IndexListExpr{X: sel("Contains"), Indices: list.Fun.(*dst.IndexListExpr).Indices},
+	}
+	assert.False(t, matchesCallRule(other, r, nil))
+}
+
 func TestMatchesCallRule_ImportAliasFromVersionSuffix(t *testing.T) {
 	r := &rule.InstCallRule{
 		ImportPath: "example.com/foo/v2",
@@ -231,7 +267,7 @@ func TestAppendCallArgs_Empty(t *testing.T) {
 	r := &rule.InstCallRule{}
 	call := &dst.CallExpr{Fun: &dst.Ident{Name: "f"}}
 
-	modified, err := appendCallArgs(call, r)
+	modified, err := appendCallArgs(call, r, nil)
 
 	require.NoError(t, err)
 	assert.False(t, modified)
@@ -247,7 +283,7 @@ func TestAppendCallArgs_SimpleAppend(t *testing.T) {
 		Args: []dst.Expr{&dst.Ident{Name: "a"}},
 	}
 
-	modified, err := appendCallArgs(call, r)
+	modified, err := appendCallArgs(call, r, nil)
 
 	require.NoError(t, err)
 	assert.True(t, modified)
@@ -264,7 +300,7 @@ func TestAppendCallArgs_EllipsisNoVariadicType(t *testing.T) {
 		Ellipsis: true,
 	}
 
-	modified, err := appendCallArgs(call, r)
+	modified, err := appendCallArgs(call, r, nil)
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "variadic_type")
@@ -282,7 +318,7 @@ func TestAppendCallArgs_EllipsisWithVariadicType(t *testing.T) {
 		Ellipsis: true,
 	}
 
-	modified, err := appendCallArgs(call, r)
+	modified, err := appendCallArgs(call, r, nil)
 
 	require.NoError(t, err)
 	assert.True(t, modified)
@@ -308,7 +344,7 @@ func TestAppendCallArgs_EllipsisNoArgs(t *testing.T) {
 		Ellipsis: true,
 	}
 
-	modified, err := appendCallArgs(call, r)
+	modified, err := appendCallArgs(call, r, nil)
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "no arguments")
@@ -326,7 +362,7 @@ func TestAppendCallArgs_InvalidVariadicType(t *testing.T) {
 		Ellipsis: true,
 	}
 
-	modified, err := appendCallArgs(call, r)
+	modified, err := appendCallArgs(call, r, nil)
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to parse variadic_type")
@@ -339,7 +375,7 @@ func TestAppendCallArgs_InvalidExpr(t *testing.T) {
 	}
 	call := &dst.CallExpr{Fun: &dst.Ident{Name: "f"}}
 
-	modified, err := appendCallArgs(call, r)
+	modified, err := appendCallArgs(call, r, nil)
 
 	require.Error(t, err)
 	assert.False(t, modified)
@@ -467,11 +503,30 @@ func TestApplyCallAppendArgs_NoMatchReturnsFalse(t *testing.T) {
 
 	ip := newTestPhase()
 	importAliases := collectImportAliases(file)
-	result := ip.applyCallAppendArgs(r, file, importAliases)
+	result := ip.applyCallAppendArgs(r, file, importAliases, nil)
 
 	assert.False(t, result, "applyCallAppendArgs must return false when no calls match")
 }
 
+func TestApplyCallAppendArgs_FailureAnnotated(t *testing.T) {
+	t.Setenv(util.EnvGitHubActions, "true")
+	var out strings.Builder
+	original := util.AnnotationOutput
+	util.AnnotationOutput = &out
+	t.Cleanup(func() { util.AnnotationOutput = original })
+
+	file := makeCallFile(httpGetCall())
+	r := httpGetRule("")
+	r.AppendArgs = []string{"func {{{"}
+
+	ip := newTestPhase()
+	result := ip.applyCallAppendArgs(r, file, collectImportAliases(file), nil)
+
+	assert.True(t, result, "the call matched even though appending failed")
+	assert.True(t, strings.HasPrefix(out.String(),
+		`::warning title=otelc::instrumentation rule "wrap_get" failed to apply to a call: `), out.String())
+}
+
 func TestApplyCallRule_WrapFailureReturnsError(t *testing.T) {
 	// Template parses but generates invalid Go when applied to the matched call.
 	file := makeCallFile(httpGetCall())
@@ -482,3 +537,150 @@ func TestApplyCallRule_WrapFailureReturnsError(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to wrap")
 }
+
+func TestApplyCallRule_WrapInterface(t *testing.T) {
+	// The file imports net/http under an alias, which the conversion reuses.
+	file := makeCallFile(httpGetCall())
+	file.Decls = append([]dst.Decl{&dst.GenDecl{
+		Tok: token.IMPORT,
+		Specs: []dst.Spec{
+			&dst.ImportSpec{
+				Name: &dst.Ident{Name: "nethttp"},
+				Path: &dst.BasicLit{Kind: token.STRING, Value: `"net/http"`},
+			},
+		},
+	}}, file.Decls...)
+	r := httpGetRule("traced({{ . }})")
+	r.WrapInterface = "net/http.Handler"
+
+	err := newTestPhase().applyCallRule(context.Background(), r, file)
+
+	require.NoError(t, err)
+	stmt := file.Decls[1].(*dst.FuncDecl).Body.List[0].(*dst.ExprStmt)
+	outerCall, ok := stmt.X.(*dst.CallExpr)
+	require.True(t, ok, "expected *dst.CallExpr after wrap, got %T", stmt.X)
+	require.Len(t, outerCall.Args, 1)
+	conversion, ok := outerCall.Args[0].(*dst.CallExpr)
+	require.True(t, ok, "expected the wrapper argument to be a conversion")
+	iface, ok := conversion.Fun.(*dst.SelectorExpr)
+	require.True(t, ok)
+	assert.Equal(t, "nethttp", iface.X.(*dst.Ident).Name)
+	assert.Equal(t, "Handler", iface.Sel.Name)
+	require.Len(t, conversion.Args, 1)
+	_, ok = conversion.Args[0].(*dst.CallExpr)
+	require.True(t, ok, "expected the original call inside the conversion")
+}
+
+func TestPackageAlias(t *testing.T) {
+	// localVar declares name in the body of f, as in `http := "local"`.
+	localVar := func(name string) *dst.File {
+		file := makeCallFile(httpGetCall())
+		body := file.Decls[0].(*dst.FuncDecl).Body
+		body.List = append([]dst.Stmt{&dst.AssignStmt{
+			Lhs: []dst.Expr{&dst.Ident{Name: name}},
+			Tok: token.DEFINE,
+			Rhs: []dst.Expr{&dst.BasicLit{Kind: token.STRING, Value: `"local"`}},
+		}}, body.List...)
+		return file
+	}
+
+	tests := []struct {
+		name          string
+		file          *dst.File
+		importAliases map[string]string
+		ruleImports   map[string]string
+		alias         string
+		imported      bool
+	}{
+		{
+			name:          "already imported",
+			file:          makeCallFile(httpGetCall()),
+			importAliases: map[string]string{"nethttp": "net/http"},
+			alias:         "nethttp",
+			imported:      true,
+		},
+		{
+			name:  "not imported",
+			file:  makeCallFile(httpGetCall()),
+			alias: "http",
+		},
+		{
+			name:  "package name used by a local variable",
+			file:  localVar("http"),
+			alias: "otelhttp",
+		},
+		{
+			name:  "fresh name used too",
+			file:  localVar("otelhttp"),
+			alias: "http",
+		},
+		{
+			name:          "package name used by another import",
+			file:          makeCallFile(httpGetCall()),
+			importAliases: map[string]string{"http": "example.com/http"},
+			ruleImports:   map[string]string{"otelhttp": "example.com/otelhttp"},
+			alias:         "otelhttp2",
+		},
+		{
+			name:        "package name used by a rule import",
+			file:        makeCallFile(httpGetCall()),
+			ruleImports: map[string]string{"http": "example.com/http"},
+			alias:       "otelhttp",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alias, imported := packageAlias(tt.file, "net/http", tt.importAliases, tt.ruleImports)
+			assert.Equal(t, tt.alias, alias)
+			assert.Equal(t, tt.imported, imported)
+		})
+	}
+}
+
+func TestApplyCallRule_WrapInterfaceAliasClash(t *testing.T) {
+	// The file does not import net/http and declares a local named http, so
+	// the conversion must not refer to the package as http.
+	file := makeCallFile(httpGetCall())
+	body := file.Decls[0].(*dst.FuncDecl).Body
+	body.List = append([]dst.Stmt{&dst.AssignStmt{
+		Lhs: []dst.Expr{&dst.Ident{Name: "http"}},
+		Tok: token.DEFINE,
+		Rhs: []dst.Expr{&dst.BasicLit{Kind: token.STRING, Value: `"local"`}},
+	}}, body.List...)
+	r := httpGetRule("traced({{ . }})")
+	r.WrapInterface = "net/http.Handler"
+
+	err := newTestPhase().applyCallRule(context.Background(), r, file)
+
+	require.NoError(t, err)
+	var fn *dst.FuncDecl
+	for _, decl := range file.Decls {
+		if f, ok := decl.(*dst.FuncDecl); ok {
+			fn = f
+		}
+	}
+	require.NotNil(t, fn)
+	outerCall := fn.Body.List[1].(*dst.ExprStmt).X.(*dst.CallExpr)
+	conversion := outerCall.Args[0].(*dst.CallExpr)
+	assert.Equal(t, "otelhttp", conversion.Fun.(*dst.SelectorExpr).X.(*dst.Ident).Name)
+	assert.Equal(t, map[string]string{"otelhttp": "net/http"}, collectImportAliases(file))
+}
+
+func TestWithInterfaceImport(t *testing.T) {
+	r := &rule.InstCallRule{WrapInterface: "net/http.Handler"}
+	ruleImports := map[string]string{"tracing": "example.com/tracing"}
+
+	t.Run("with rule imports", func(t *testing.T) {
+		got := withInterfaceImport(r, ruleImports, "otelhttp")
+		assert.Equal(t, map[string]string{
+			"tracing":  "example.com/tracing",
+			"otelhttp": "net/http",
+		}, got)
+		assert.Len(t, ruleImports, 1, "the rule imports must not be modified")
+	})
+
+	t.Run("no rule imports", func(t *testing.T) {
+		got := withInterfaceImport(r, nil, "http")
+		assert.Equal(t, map[string]string{"http": "net/http"}, got)
+	})
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/ast"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/imports"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
 )
@@ -41,14 +42,15 @@ func (ip *InstrumentPhase) applyDeclRule(ctx context.Context, r *rule.InstDeclRu
 	}
 
 	// Handle imports if specified in the rule
-	if err := ip.addRuleImports(ctx, root, r.Imports, r.Name); err != nil {
+	renamed, err := ip.addRuleImports(ctx, root, r.Imports, r.Name)
+	if err != nil {
 		return err
 	}
 
 	spec := util.AssertType[*dst.ValueSpec](node)
 
 	if r.Wrap != "" {
-		if err := wrapDeclValues(spec, r.Wrap); err != nil {
+		if err = wrapDeclValues(spec, r.Wrap, renamed); err != nil {
 			return err
 		}
 		ip.Info("Apply decl rule", "rule", r)
@@ -59,6 +61,7 @@ func (ip *InstrumentPhase) applyDeclRule(ctx context.Context, r *rule.InstDeclRu
 	if err != nil {
 		return err
 	}
+	imports.Rename(expr, renamed)
 	// Assign the expression to all names in the spec.
 	spec.Values = make([]dst.Expr, len(spec.Names))
 	for i := range spec.Values {
@@ -69,10 +72,11 @@ func (ip *InstrumentPhase) applyDeclRule(ctx context.Context, r *rule.InstDeclRu
 	return nil
 }
 
-// wrapDeclValues wraps each initializer in spec using the given template.
-// Returns an error if spec has no initializers, since wrap requires
-// an existing value to substitute into {{ . }}.
-func wrapDeclValues(spec *dst.ValueSpec, templateStr string) error {
+// wrapDeclValues wraps each initializer in spec using the given template,
+// whose references to the renamed rule imports are rewritten. Returns an error
+// if spec has no initializers, since wrap requires an existing value to
+// substitute into {{ . }}.
+func wrapDeclValues(spec *dst.ValueSpec, templateStr string, renamed map[string]string) error {
 	if len(spec.Values) == 0 {
 		return ex.Newf(
 			"wrap requires an existing initializer but the declaration has none",
@@ -83,6 +87,7 @@ func wrapDeclValues(spec *dst.ValueSpec, templateStr string) error {
 	if err != nil {
 		return ex.Wrapf(err, "failed to compile wrap template")
 	}
+	tmpl.renamed = renamed
 
 	var wrapped dst.Expr
 	for i, val := range spec.Values {
@@ -36,7 +36,7 @@ func TestWrapDeclValues_Success(t *testing.T) {
 		},
 	}
 
-	err := wrapDeclValues(spec, "wrapper({{ . }})")
+	err := wrapDeclValues(spec, "wrapper({{ . }})", nil)
 
 	require.NoError(t, err)
 	require.Len(t, spec.Values, 1)
@@ -62,7 +62,7 @@ func TestWrapDeclValues_MultipleValues(t *testing.T) {
 		},
 	}
 
-	err := wrapDeclValues(spec, "inc({{ . }})")
+	err := wrapDeclValues(spec, "inc({{ . }})", nil)
 
 	require.NoError(t, err)
 	require.Len(t, spec.Values, 2)
@@ -82,7 +82,7 @@ func TestWrapDeclValues_NoInitializer(t *testing.T) {
 		Values: nil,
 	}
 
-	err := wrapDeclValues(spec, "wrapper({{ . }})")
+	err := wrapDeclValues(spec, "wrapper({{ . }})", nil)
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "wrap requires an existing initializer")
@@ -94,7 +94,7 @@ func TestWrapDeclValues_InvalidTemplate(t *testing.T) {
 		Values: []dst.Expr{&dst.Ident{Name: "x"}},
 	}
 
-	err := wrapDeclValues(spec, "func {{ . }}")
+	err := wrapDeclValues(spec, "func {{ . }}", nil)
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to wrap expression")
@@ -10,6 +10,7 @@ import (
 	"github.com/dave/dst"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/ast"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/imports"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
 	"github.com/valyala/fasttemplate"
 )
@@ -22,7 +23,8 @@ type directiveTemplateData struct {
 // the template for each, and prepends the resulting Go statements into the
 // function body.
 func (ip *InstrumentPhase) applyDirectiveRule(ctx context.Context, r *rule.InstDirectiveRule, root *dst.File) error {
-	if err := ip.addRuleImports(ctx, root, r.Imports, r.Name); err != nil {
+	renamed, err := ip.addRuleImports(ctx, root, r.Imports, r.Name)
+	if err != nil {
 		return err
 	}
 	tmpl, err := fasttemplate.NewTemplate(r.Template, "{{", "}}")
@@ -44,6 +46,9 @@ func (ip *InstrumentPhase) applyDirectiveRule(ctx context.Context, r *rule.InstD
 		if err != nil {
 			return ex.Wrapf(err, "parsing rendered template for func %s", funcDecl.Name.Name)
 		}
+		for _, stmt := range stmts {
+			imports.Rename(stmt, renamed)
+		}
 		renameReturnValues(funcDecl)
 		funcDecl.Body.List = append(stmts, funcDecl.Body.List...)
 		ip.Info("Apply directive rule", "rule", r, "func", funcDecl.Name.Name)
@@ -63,7 +63,7 @@ func (ip *InstrumentPhase) applyFileRule(ctx context.Context, rule *rule.InstFil
 	ext := filepath.Ext(base)
 	newName := strings.TrimSuffix(base, ext)
 	newFile := filepath.Join(ip.workDir, fmt.Sprintf("otelc.%s.go", newName))
-	err = ast.WriteFile(newFile, root)
+	err = writeFile(newFile, root)
 	if err != nil {
 		return ex.Wrapf(err, "writing instrumented file %s", newFile)
 	}
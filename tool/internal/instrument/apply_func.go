@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"go/parser"
 	"path/filepath"
+	"strings"
 
 	"github.com/dave/dst"
 
@@ -140,8 +141,11 @@ func createTJumpIf(t *rule.InstFuncRule, funcDecl *dst.FuncDecl,
 	argsToAfter = append([]dst.Expr{argHookContext}, argsToAfter...)
 	beforeCallName := makeName(t, funcDecl, true)
 	afterCallName := makeName(t, funcDecl, false)
-	beforeCall := ast.CallTo(beforeCallName, funcDecl.Type.TypeParams, argsToBefore)
-	afterCall := ast.CallTo(afterCallName, funcDecl.Type.TypeParams, argsToAfter)
+	// Instantiate the trampolines explicitly: the after trampoline cannot infer
+	// type parameters that only appear in the receiver or the parameters
+	typeArgs := findTargetGenericType(funcDecl, nil)
+	beforeCall := ast.CallTo(beforeCallName, typeArgs, argsToBefore)
+	afterCall := ast.CallTo(afterCallName, typeArgs, argsToAfter)
 	tjumpInit := ast.DefineStmts(
 		ast.Exprs(
 			ast.Ident(trampolineHookContextName+funcSuffix),
@@ -214,6 +218,7 @@ func (ip *InstrumentPhase) insertTJump(t *rule.InstFuncRule, funcDecl *dst.FuncD
 
 	// Record the target function for the whole trampoline creation process
 	ip.targetFunc = funcDecl
+	ip.recvTypeParams = ip.findRecvTypeParams(funcDecl)
 
 	// Collect return values from target function
 	retVals := collectReturnValues(funcDecl)
@@ -276,7 +281,12 @@ var templateAPI string
 func (ip *InstrumentPhase) writeGlobals(pkgName string) error {
 	// Prepare trampoline code header
 	p := ast.NewAstParser()
-	trampoline, err := p.ParseSource("package " + pkgName)
+	header := "package " + pkgName
+	if ip.measureOverhead {
+		// Declare the clock of the trampolines
+		header += "\n" + overheadGlobalsSource
+	}
+	trampoline, err := p.ParseSource(header)
 	if err != nil {
 		return ex.Wrapf(err, "parsing globals header for package %s", pkgName)
 	}
@@ -292,7 +302,7 @@ func (ip *InstrumentPhase) writeGlobals(pkgName string) error {
 
 	// Write trampoline code to file
 	path := filepath.Join(ip.workDir, otelcGlobalsFile)
-	err = ast.WriteFile(path, trampoline)
+	err = writeFile(path, trampoline)
 	if err != nil {
 		return ex.Wrapf(err, "writing globals file %s", path)
 	}
@@ -304,35 +314,43 @@ func (ip *InstrumentPhase) writeGlobals(pkgName string) error {
 func (ip *InstrumentPhase) writeInstrumented(root *dst.File, oldFile string) error {
 	// Write the instrumented AST to the new file in the working directory
 	newFile := filepath.Join(ip.workDir, filepath.Base(oldFile))
-	err := ast.WriteFile(newFile, root)
+	err := writeFile(newFile, root)
 	if err != nil {
 		return ex.Wrapf(err, "writing instrumented file %s", newFile)
 	}
 	ip.keepForDebug(newFile)
 
 	// Replace the original file with the new file in the compile command
-	replace := false
-	for i, arg := range ip.compileArgs {
-		// Files in the compile command maybe relative or absolute, we need to
-		// consolidate them to absolute path
-		abs, err1 := filepath.Abs(arg)
-		if err1 != nil {
-			return ex.Wrap(err1)
-		}
-		if abs == oldFile {
-			ip.compileArgs[i] = newFile
-			replace = true
-			break
-		}
+	i, err := ip.compileArgIndex(oldFile)
+	if err != nil {
+		return err
 	}
-	if !replace {
+	if i < 0 {
 		return ex.Newf("cannot replace %s with %s during %v",
 			oldFile, newFile, ip.compileArgs)
 	}
+	ip.compileArgs[i] = newFile
 	ip.Info("Write instrumented AST", "old", oldFile, "new", newFile)
 	return nil
 }
 
+// compileArgIndex returns the index of the file in the compile command, or -1
+// if the compiler is not given the file.
+func (ip *InstrumentPhase) compileArgIndex(file string) (int, error) {
+	for i, arg := range ip.compileArgs {
+		// Files in the compile command maybe relative or absolute, we need to
+		// consolidate them to absolute path
+		abs, err := filepath.Abs(arg)
+		if err != nil {
+			return -1, ex.Wrap(err)
+		}
+		if abs == file {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
 func (ip *InstrumentPhase) parseFile(file string) (*dst.File, error) {
 	ip.parser = ast.NewAstParser()
 	root, err := ip.parser.Parse(file, parser.ParseComments)
@@ -340,25 +358,64 @@ func (ip *InstrumentPhase) parseFile(file string) (*dst.File, error) {
 		return nil, ex.Wrapf(err, "parsing source file %s", file)
 	}
 	ip.target = root
+	ip.targetFile = file
 	// Every time we parse a file, we need to reset the trampoline jumps
 	// because they are associated with one certain file
 	ip.tjumps = make([]*TJump, 0)
 	return root, nil
 }
 
-func (ip *InstrumentPhase) applyFuncRule(ctx context.Context, rule *rule.InstFuncRule, root *dst.File) error {
-	funcDecl, ok, err := ast.FindFuncDecl(root, rule)
+func (ip *InstrumentPhase) applyFuncRule(ctx context.Context, t *rule.InstFuncRule, root *dst.File) error {
+	funcDecls, err := ast.FindFuncDecls(root, t)
 	if err != nil {
 		return err
 	}
-	if !ok {
-		return ex.Newf("can not find function %s", rule.Func)
+	if len(funcDecls) == 0 {
+		return ex.Newf("can not find function %s", t.Func)
+	}
+	if t.Func != rule.FuncAny {
+		return ip.applyFuncRuleTo(ctx, t, root, funcDecls[0])
+	}
+	// A wildcard rule is applied to every matching function as if it named the
+	// function, so that each of them gets its own trampolines
+	for _, funcDecl := range funcDecls {
+		if funcDecl.Body == nil || isTrampoline(funcDecl) {
+			continue
+		}
+		concrete := *t
+		concrete.Func = funcDecl.Name.Name
+		concrete.Recv = ast.ReceiverType(funcDecl)
+		if err = ip.applyFuncRuleTo(ctx, &concrete, root, funcDecl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isTrampoline reports whether funcDecl was generated by an earlier func rule
+// applied to the same file.
+func isTrampoline(funcDecl *dst.FuncDecl) bool {
+	name := funcDecl.Name.Name
+	return strings.HasPrefix(name, trampolineBeforeName) ||
+		strings.HasPrefix(name, trampolineAfterName)
+}
+
+func (ip *InstrumentPhase) applyFuncRuleTo(ctx context.Context, t *rule.InstFuncRule,
+	root *dst.File, funcDecl *dst.FuncDecl,
+) error {
+	if t.MinStatements > 0 {
+		if n := ast.CountStmts(funcDecl); n < t.MinStatements {
+			ip.Debug("Skipping func rule below the statement threshold",
+				"rule", t.Name, "func", t.Func, "statements", n, "min", t.MinStatements)
+			return nil
+		}
 	}
 
 	// Apply imports for every matching rule, including ones de-duplicated below:
 	// two rules with the same content identity may still declare different
 	// imports, and skipping them could drop an import the hook code needs.
-	if err = ip.addRuleImports(ctx, root, rule.Imports, rule.Name); err != nil {
+	// The trampolines do not refer to the rule imports, they need no renaming
+	if _, err := ip.addRuleImports(ctx, root, t.Imports, t.Name); err != nil {
 		return err
 	}
 
@@ -366,20 +423,20 @@ func (ip *InstrumentPhase) applyFuncRule(ctx context.Context, rule *rule.InstFun
 	// same content identity: emitting again would redeclare byte-identical
 	// declarations. Distinct do-sequence modifiers differ by content or by
 	// application index, so this only collapses genuinely duplicate rules.
-	id := rule.Identity()
+	id := t.Identity()
 	if _, seen := ip.appliedFuncIdentities[id]; seen {
 		ip.Debug("Skipping duplicate func rule trampoline (imports already applied)",
-			"rule", rule.Name, "func", rule.Func)
+			"rule", t.Name, "func", t.Func)
 		return nil
 	}
 
-	if err = ip.insertTJump(rule, funcDecl); err != nil {
+	if err := ip.insertTJump(t, funcDecl); err != nil {
 		return err
 	}
 	if ip.appliedFuncIdentities == nil {
 		ip.appliedFuncIdentities = make(map[string]struct{})
 	}
 	ip.appliedFuncIdentities[id] = struct{}{}
-	ip.Info("Apply func rule", "rule", rule)
+	ip.Info("Apply func rule", "rule", t)
 	return nil
 }
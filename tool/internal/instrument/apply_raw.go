@@ -16,6 +16,7 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/ast"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/imports"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
 )
@@ -101,7 +102,15 @@ func insertRawAtPattern(
 	return inserted
 }
 
-func insertRaw(ctx context.Context, r *rule.InstRawRule, decl *dst.FuncDecl, root *dst.File) error {
+// insertRaw inserts the raw code of r into decl, its references to the rule
+// imports renamed in root rewritten.
+func insertRaw(
+	ctx context.Context,
+	r *rule.InstRawRule,
+	decl *dst.FuncDecl,
+	root *dst.File,
+	renamed map[string]string,
+) error {
 	util.Assert(decl.Name.Name == r.Func, "sanity check")
 
 	// Rename the unnamed return values so that the raw code can reference them
@@ -112,6 +121,9 @@ func insertRaw(ctx context.Context, r *rule.InstRawRule, decl *dst.FuncDecl, roo
 	if err != nil {
 		return err
 	}
+	for _, stmt := range stmts {
+		imports.Rename(stmt, renamed)
+	}
 
 	// if specified, insert raw code at the position matched by the regex
 	if r.Pattern != "" {
@@ -152,12 +164,13 @@ func (ip *InstrumentPhase) applyRawRule(ctx context.Context, rule *rule.InstRawR
 	}
 
 	// Handle imports if specified in the rule
-	if err = ip.addRuleImports(ctx, root, rule.Imports, rule.Name); err != nil {
+	renamed, err := ip.addRuleImports(ctx, root, rule.Imports, rule.Name)
+	if err != nil {
 		return err
 	}
 
 	// Insert the raw code into the target function
-	err = insertRaw(ctx, rule, funcDecl, root)
+	err = insertRaw(ctx, rule, funcDecl, root, renamed)
 	if err != nil {
 		return err
 	}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/ast"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/imports"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
 )
 
@@ -20,12 +21,13 @@ func (ip *InstrumentPhase) applyStructRule(ctx context.Context, rule *rule.InstS
 	}
 
 	// Handle imports if specified in the rule
-	if err := ip.addRuleImports(ctx, root, rule.Imports, rule.Name); err != nil {
+	renamed, err := ip.addRuleImports(ctx, root, rule.Imports, rule.Name)
+	if err != nil {
 		return err
 	}
 
 	for _, field := range rule.NewField {
-		ast.AddStructField(structDecl, field.Name, field.Type)
+		ast.AddStructField(structDecl, field.Name, imports.RenameType(field.Type, renamed))
 	}
 	ip.Info("Apply struct rule", "rule", rule)
 	return nil
@@ -17,6 +17,7 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
 	toolast "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/ast"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/imports"
 )
 
 // callTemplate represents a code template that can be used to wrap or transform
@@ -25,6 +26,8 @@ import (
 type callTemplate struct {
 	template *fasttemplate.Template
 	source   string
+	// The rule imports renamed in the file, see imports.AddToFile
+	renamed map[string]string
 }
 
 // newCallTemplate creates a new callTemplate from the provided template text.
@@ -118,6 +121,9 @@ func (t *callTemplate) compileExpression(node dst.Expr) (dst.Expr, error) {
 		return nil, ex.Newf("expected expression statement, got %T", funcDecl.Body.List[0])
 	}
 
+	// Use the renamed imports, before the placeholder brings in the file's code
+	imports.Rename(exprStmt.X, t.renamed)
+
 	// Replace placeholder with the actual node
 	result, replaced := replacePlaceholder(exprStmt.X, node)
 	if !replaced {
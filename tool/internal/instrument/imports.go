@@ -30,6 +30,9 @@ func (ip *InstrumentPhase) updateImportConfigForFile(ctx context.Context, root *
 }
 
 // addRuleImports processes imports for a rule and updates the import config.
+// It returns the aliases renamed because the file already uses them for a
+// different package (rule alias -> fresh alias), which the code injected by
+// the rule must be rewritten to use, see imports.Rename.
 //
 // This function validates that if a rule expects to use an import with a specific alias,
 // and the file already imports the same package with a different alias (whether explicit or
@@ -40,9 +43,9 @@ func (ip *InstrumentPhase) addRuleImports(
 	root *dst.File,
 	ruleImports map[string]string,
 	ruleName string,
-) error {
+) (map[string]string, error) {
 	if len(ruleImports) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	resolution := imports.FindNew(ctx, root, ruleImports)
@@ -53,7 +56,7 @@ func (ip *InstrumentPhase) addRuleImports(
 			// Dot-import conflict check
 			if existingAlias, pathExists := resolution.ExistingAliases[importPath]; pathExists {
 				if existingAlias != "." {
-					return ex.Newf(
+					return nil, ex.Newf(
 						"%s: dot-import conflict for %q - "+
 							"file imports the path with alias %q but rule requires dot-import; "+
 							"injected unqualified identifiers will not resolve; "+
@@ -72,7 +75,7 @@ func (ip *InstrumentPhase) addRuleImports(
 		// must use the alias that actually exists in the file.
 		if existingAlias, pathExists := resolution.ExistingAliases[importPath]; pathExists {
 			if existingAlias != ruleAlias {
-				return ex.Newf(
+				return nil, ex.Newf(
 					"%s: import alias mismatch for %q - "+
 						"file uses alias %q but rule expects %q; "+
 						"injected code will fail to compile; "+
@@ -83,18 +86,19 @@ func (ip *InstrumentPhase) addRuleImports(
 	}
 
 	if len(resolution.NewImports) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// Add import declarations to the AST
-	if err := imports.AddToFile(ctx, root, resolution.NewImports); err != nil {
-		return ex.Wrapf(err, "adding imports for %s", ruleName)
+	renamed := imports.AddToFile(ctx, root, resolution.NewImports)
+	for alias, fresh := range renamed {
+		ip.Info("Renamed conflicting rule import", "rule", ruleName, "alias", alias, "renamed", fresh)
 	}
 
 	// Update importcfg for the build
 	if err := ip.updateImportConfig(ctx, resolution.NewImports); err != nil {
-		return ex.Wrapf(err, "updating import config for %s", ruleName)
+		return nil, ex.Wrapf(err, "updating import config for %s", ruleName)
 	}
 
-	return nil
+	return renamed, nil
 }
@@ -213,7 +213,7 @@ func TestHandleRuleImports_AliasMismatch(t *testing.T) {
 			// Create a mock InstrumentPhase with no importcfg (to avoid actual file operations)
 			ip := &InstrumentPhase{}
 
-			err := ip.addRuleImports(t.Context(), tt.root, tt.imports, "test-rule")
+			_, err := ip.addRuleImports(t.Context(), tt.root, tt.imports, "test-rule")
 			if tt.expectError {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), tt.errorMsg)
@@ -75,6 +75,18 @@ func (ip *InstrumentPhase) instrument(ctx context.Context, rset *rule.InstRuleSe
 		}
 	}
 	for file, rules := range groupRules(ip.workDir, rset) {
+		// Only the files the compiler is given are instrumented. Of files that
+		// define the same function behind build constraints, e.g. foo_linux.go
+		// and foo_windows.go, that is the one active for the target platform.
+		i, err := ip.compileArgIndex(file)
+		if err != nil {
+			return err
+		}
+		if i < 0 {
+			ip.Debug("Skip file not in compile command", "file", file)
+			continue
+		}
+
 		// Group rules by file, then parse the target file once
 		root, err := ip.parseFile(file)
 		if err != nil {
@@ -23,6 +23,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"slices"
 	"strings"
 	"testing"
@@ -59,6 +60,11 @@ const (
 	goldenExt          = ".golden"
 	invalidReceiver    = "invalid-receiver"
 	invalidReceiverMsg = "can not find function"
+	// overheadMeasurement is built as with --overhead
+	overheadMeasurement = "overhead-measurement"
+	// funcCodeLocation is compiled with -trimpath, so that the location it
+	// bakes in does not depend on the temporary directory
+	funcCodeLocation = "func-code-location"
 )
 
 func TestInstrumentation_Integration(t *testing.T) {
@@ -77,6 +83,9 @@ func TestInstrumentation_Integration(t *testing.T) {
 func runTest(t *testing.T, testName string) {
 	tempDir := t.TempDir()
 	t.Setenv(util.EnvOtelcWorkDir, tempDir)
+	if testName == overheadMeasurement {
+		t.Setenv(util.EnvOtelcOverhead, "1")
+	}
 	ctx := util.ContextWithLogger(
 		t.Context(),
 		slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
@@ -107,6 +116,9 @@ func runTest(t *testing.T, testName string) {
 	helpers := buildTestcaseHelpers(ctx, t, testcaseDir)
 
 	args := compileArgs(tempDir, sourceFile, helpers, importPath)
+	if testName == funcCodeLocation {
+		args = slices.Insert(args, len(args)-1, "-trimpath", tempDir+"=>example.com/app")
+	}
 	err := Toolexec(ctx, args)
 
 	if testName == invalidReceiver {
@@ -672,3 +684,52 @@ func TestGroupRules(t *testing.T) {
 		})
 	}
 }
+
+// TestInstrument_BuildTagGatedFiles verifies that, of two files defining the
+// target function for different platforms, only the one the compile command
+// passes is instrumented, even if rules were matched against both.
+func TestInstrument_BuildTagGatedFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv(util.EnvOtelcWorkDir, tempDir)
+	ctx := util.ContextWithLogger(t.Context(), slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	// The sources live apart from the working directory the instrumented
+	// files are written to
+	srcDir := filepath.Join(tempDir, "src")
+	require.NoError(t, os.MkdirAll(srcDir, 0o755))
+	inactiveGOOS := "windows"
+	if runtime.GOOS == inactiveGOOS {
+		inactiveGOOS = "linux"
+	}
+	activeFile := filepath.Join(srcDir, "func_"+runtime.GOOS+".go")
+	inactiveFile := filepath.Join(srcDir, "func_"+inactiveGOOS+".go")
+	for file, body := range map[string]string{
+		activeFile:   `println("active")`,
+		inactiveFile: `println("inactive")`,
+	} {
+		source := "package main\n\nfunc Func1(p1 string, p2 int) {\n\t" + body + "\n}\n"
+		require.NoError(t, os.WriteFile(file, []byte(source), 0o644))
+	}
+
+	hookPath := filepath.ToSlash(filepath.Join("testdata", "golden", "func-rule-only"))
+	ruleData := []byte("func: Func1\nbefore: H1Before\npath: " + hookPath + "\n")
+	ruleSet := &rule.InstRuleSet{
+		PackageName: mainPackage,
+		ModulePath:  mainPackage,
+		FuncRules:   make(map[string][]*rule.InstFuncRule),
+	}
+	for _, file := range []string{activeFile, inactiveFile} {
+		r, err := rule.NewInstFuncRule(ruleData, "hook_func1")
+		require.NoError(t, err)
+		ruleSet.FuncRules[file] = []*rule.InstFuncRule{r}
+	}
+	writeMatchedJSON(ruleSet)
+
+	args := compileArgs(tempDir, activeFile, nil, mainPackage)
+	require.NoError(t, Toolexec(ctx, args))
+
+	instrumented, err := os.ReadFile(filepath.Join(tempDir, filepath.Base(activeFile)))
+	require.NoError(t, err)
+	assert.Contains(t, string(instrumented), "OtelBeforeTrampoline_Func1")
+	assert.NoFileExists(t, filepath.Join(tempDir, filepath.Base(inactiveFile)))
+}
@@ -54,6 +54,9 @@ import (
 //	    ...
 //	}
 //
+// The HookContext only captures the addresses of the arguments if the After
+// hook may read them, since taking them forces the arguments to the heap.
+//
 // The If skeleton should be kept as is, otherwise inlining of trampoline-jump-if
 // will not work. During compiling, the DCE and SCCP passes will remove the whole
 // then block. That's not the whole story. We can further optimize the tjump iff
@@ -120,15 +123,20 @@ func removeAfterTrampolineCall(tjump *TJump) error {
 
 // newHookContextImpl constructs a new HookContextImpl structure literal and
 // populates its Params && ReturnValues field with addresses of all arguments.
-// The HookContextImpl structure is used to pass arguments to the exit trampoline
-func newHookContextImpl(tjump *TJump) dst.Expr {
+// The HookContextImpl structure is used to pass arguments to the exit trampoline.
+// Params is left empty unless withParams is set, taking the address of every
+// argument would otherwise force them to the heap for nothing.
+func newHookContextImpl(tjump *TJump, withParams bool) dst.Expr {
 	targetFunc := tjump.target
 	structName := trampolineHookContextImplType + tjump.rule.Identity()
 
 	// Build params slice: []interface{}{&param1, &param2, ...}
 	// Use createHookArgs to handle underscore parameters correctly
-	paramNames := collectArguments(targetFunc)
-	paramExprs := createTrampArgs(paramNames)
+	paramExprs := make([]dst.Expr, 0)
+	if withParams {
+		paramNames := collectArguments(targetFunc)
+		paramExprs = createTrampArgs(paramNames)
+	}
 	paramsSlice := ast.CompositeLit(
 		ast.ArrayType(ast.InterfaceType()),
 		paramExprs,
@@ -153,9 +161,9 @@ func newHookContextImpl(tjump *TJump) dst.Expr {
 	)
 }
 
-func removeBeforeTrampolineCall(targetFile *dst.File, tjump *TJump) error {
+func removeBeforeTrampolineCall(targetFile *dst.File, tjump *TJump, withParams bool) error {
 	// Construct HookContext on the fly and pass to After trampoline defer call
-	hookContextExpr := newHookContextImpl(tjump)
+	hookContextExpr := newHookContextImpl(tjump, withParams)
 	// Find defer call to After and replace its call context with new one
 	found := false
 	block := util.AssertType[*dst.BlockStmt](tjump.ifStmt.Else)
@@ -224,12 +232,18 @@ func canFlattenTJump(hookFunc *dst.FuncDecl) bool {
 		return false
 	}
 
-	// Check if the hook context parameter escapes (used for non-method calls)
+	return !hookContextEscapes(hookFunc)
+}
+
+// hookContextEscapes checks if the HookContext parameter of the hook function
+// is used for anything but as a receiver for method calls, e.g. passed as an
+// argument or assigned to a variable.
+func hookContextEscapes(hookFunc *dst.FuncDecl) bool {
 	escape := false
 	hookContextParam := hookFunc.Type.Params.List[0].Names[0].Name
 	if hookContextParam == ast.IdentIgnore {
 		// If the parameter is ignored, it doesn't escape because it is not used
-		return true
+		return false
 	}
 	dst.Inspect(hookFunc.Body, func(n dst.Node) bool {
 		if escape {
@@ -255,7 +269,37 @@ func canFlattenTJump(hookFunc *dst.FuncDecl) bool {
 		}
 		return true
 	})
-	return !escape
+	return escape
+}
+
+// hookReadsParams checks if the hook function may access the parameters of
+// the target function through its HookContext. It conservatively returns true
+// when the HookContext escapes, as the parameters may be read elsewhere.
+func hookReadsParams(hookFunc *dst.FuncDecl) bool {
+	if hookContextEscapes(hookFunc) {
+		return true
+	}
+	hookContextParam := hookFunc.Type.Params.List[0].Names[0].Name
+	if hookContextParam == ast.IdentIgnore {
+		return false
+	}
+	reads := false
+	dst.Inspect(hookFunc.Body, func(n dst.Node) bool {
+		if reads {
+			return false
+		}
+		if sel, ok := n.(*dst.SelectorExpr); ok {
+			if id, ok1 := sel.X.(*dst.Ident); ok1 && id.Name == hookContextParam {
+				switch sel.Sel.Name {
+				case trampolineGetParamName, trampolineSetParamName, trampolineGetParamCountName:
+					reads = true
+				}
+				return false
+			}
+		}
+		return true
+	})
+	return reads
 }
 
 // flattenTJump transforms the trampoline-jump-if AST to a flattened form.
@@ -365,9 +409,15 @@ func (ip *InstrumentPhase) optimizeTJumps() error {
 
 		// No Before hook present? Construct HookContext on the fly and pass it
 		// to After trampoline defer call and rewrite the whole condition to
-		// always false, then null out its initialization statement.
+		// always false, then null out its initialization statement. Arguments
+		// are only captured if the After hook may read them, as an after-only
+		// hook typically deals with return values alone.
 		if rule.Before == "" {
-			err := removeBeforeTrampolineCall(ip.target, tjump)
+			hookFunc, err := getHookFunc(tjump.rule, false)
+			if err != nil {
+				return err
+			}
+			err = removeBeforeTrampolineCall(ip.target, tjump, hookReadsParams(hookFunc))
 			if err != nil {
 				return err
 			}
@@ -5,12 +5,14 @@ package instrument
 
 import (
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/dave/dst"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/ast"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -266,7 +268,7 @@ func TestNewHookContextImpl(t *testing.T) {
 				},
 			}
 
-			expr := newHookContextImpl(tjump)
+			expr := newHookContextImpl(tjump, true)
 			assert.NotNil(t, expr)
 			if tt.validate != nil {
 				tt.validate(t, expr)
@@ -275,6 +277,75 @@ func TestNewHookContextImpl(t *testing.T) {
 	}
 }
 
+func TestNewHookContextImpl_WithoutParams(t *testing.T) {
+	targetFunc := parseFunc(t, `package main
+	func testFunc(param1 string, param2 int) (result1 string) { return "" }`)
+	tjump := &TJump{
+		target: targetFunc,
+		rule:   &rule.InstFuncRule{Func: targetFunc.Name.Name},
+	}
+
+	expr := newHookContextImpl(tjump, false)
+	compositeLit := expr.(*dst.UnaryExpr).X.(*dst.CompositeLit)
+	paramsLit := compositeLit.Elts[0].(*dst.KeyValueExpr).Value.(*dst.CompositeLit)
+	assert.Empty(t, paramsLit.Elts, "no parameter address should be taken")
+	returnsLit := compositeLit.Elts[1].(*dst.KeyValueExpr).Value.(*dst.CompositeLit)
+	assert.Len(t, returnsLit.Elts, 1, "return values are still captured")
+}
+
+func TestHookReadsParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		hookSrc string
+		reads   bool
+	}{
+		{
+			name: "return values only",
+			hookSrc: `package main
+			func hookFunc(ctx HookContext, ret string) {
+				ctx.SetReturnVal(0, ret+"!")
+			}`,
+			reads: false,
+		},
+		{
+			name: "ignored hook context",
+			hookSrc: `package main
+			func hookFunc(_ HookContext, ret string) {
+			}`,
+			reads: false,
+		},
+		{
+			name: "get param",
+			hookSrc: `package main
+			func hookFunc(ctx HookContext, ret string) {
+				println(ctx.GetParam(0))
+			}`,
+			reads: true,
+		},
+		{
+			name: "param count",
+			hookSrc: `package main
+			func hookFunc(ctx HookContext, ret string) {
+				println(ctx.GetParamCount())
+			}`,
+			reads: true,
+		},
+		{
+			name: "escaping hook context",
+			hookSrc: `package main
+			func hookFunc(ctx HookContext, ret string) {
+				passTo(ctx)
+			}`,
+			reads: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.reads, hookReadsParams(parseFunc(t, tt.hookSrc)))
+		})
+	}
+}
+
 func TestStripTJumpLabel(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -371,7 +442,7 @@ func TestRemoveBeforeTrampolineCall(t *testing.T) {
 	targetFile, err := ast.NewAstParser().ParseSource(fileSrc)
 	require.NoError(t, err)
 
-	err = removeBeforeTrampolineCall(targetFile, tjump)
+	err = removeBeforeTrampolineCall(targetFile, tjump, true)
 	require.NoError(t, err)
 
 	// Verify condition was set to false
@@ -535,3 +606,64 @@ func TestFlattenTJump(t *testing.T) {
 		})
 	}
 }
+
+func TestOptimizeTJumps_MultipleTJumps(t *testing.T) {
+	// Two hooked points in the same function: the tjump of the second rule is
+	// nested in the else block of the first, as findJumpPoint places it
+	hookDir := filepath.Join(testdataDir, goldenDir, "multiple-hooks-single-func")
+	beforeOnly := &rule.InstFuncRule{Func: "Func1", Before: "H1Before", Path: hookDir, ResolvedPath: hookDir}
+	afterOnly := &rule.InstFuncRule{Func: "Func1", After: "H2After", Path: hookDir, ResolvedPath: hookDir}
+
+	targetFile, err := ast.NewAstParser().ParseSource(`package main
+	func Func1(p1 string) {}`)
+	require.NoError(t, err)
+	targetFunc := ast.FindFuncDeclWithoutRecv(targetFile, "Func1")
+	for _, r := range []*rule.InstFuncRule{beforeOnly, afterOnly} {
+		for _, before := range []bool{true, false} {
+			targetFile.Decls = append(targetFile.Decls, parseFunc(t,
+				fmt.Sprintf("package main\nfunc %s() {}", makeName(r, targetFunc, before))))
+		}
+	}
+
+	outer := parseIfStmt(t, `if ctx1, skip1 := before1(&p1); skip1 {
+		after1(ctx1)
+		return
+	} else {
+		defer after1(ctx1)
+	}`)
+	inner := parseIfStmt(t, `if ctx2, skip2 := before2(&p1); skip2 {
+		after2(ctx2)
+		return
+	} else {
+		defer after2(ctx2)
+	}`)
+	outerElse := util.AssertType[*dst.BlockStmt](outer.Else)
+	outerElse.List = append(outerElse.List, inner)
+	targetFunc.Body.List = []dst.Stmt{outer}
+
+	ip := &InstrumentPhase{
+		target: targetFile,
+		tjumps: []*TJump{
+			{target: targetFunc, ifStmt: outer, rule: beforeOnly},
+			{target: targetFunc, ifStmt: inner, rule: afterOnly},
+		},
+	}
+	require.NoError(t, ip.optimizeTJumps())
+
+	// The before-only tjump lost its After call and was flattened
+	assert.Empty(t, outer.Decs.If, "label should be stripped")
+	assert.Equal(t, "false", util.AssertType[*dst.BasicLit](outer.Cond).Value)
+	for _, stmt := range outerElse.List {
+		_, isDefer := stmt.(*dst.DeferStmt)
+		assert.False(t, isDefer, "After call of the before-only tjump should be removed")
+	}
+	assert.Nil(t, ast.FindFuncDeclWithoutRecv(targetFile, makeName(beforeOnly, targetFunc, false)))
+
+	// The after-only tjump, nested in it, lost its Before call
+	assert.Empty(t, inner.Decs.If, "label should be stripped")
+	assert.Nil(t, inner.Init)
+	assert.Equal(t, "false", util.AssertType[*dst.BasicLit](inner.Cond).Value)
+	assert.Nil(t, ast.FindFuncDeclWithoutRecv(targetFile, makeName(afterOnly, targetFunc, true)))
+	_, isDefer := util.AssertType[*dst.BlockStmt](inner.Else).List[0].(*dst.DeferStmt)
+	assert.True(t, isDefer, "After call of the after-only tjump should be kept")
+}
@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package instrument
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/ast"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
+)
+
+// breakRendering makes the instrumented Func1 of the func-rule-only fixture
+// unrenderable, as a trampoline generated with a node appearing twice would.
+func breakRendering(t *testing.T) {
+	t.Helper()
+	original := writeFile
+	t.Cleanup(func() { writeFile = original })
+	writeFile = func(path string, root *dst.File) error {
+		if funcDecl := ast.FindFuncDeclWithoutRecv(root, "Func1"); funcDecl != nil {
+			funcDecl.Body.List = append(funcDecl.Body.List, funcDecl.Body.List[0])
+		}
+		return original(path, root)
+	}
+}
+
+func TestToolexec_RenderFailure(t *testing.T) {
+	const testName = "func-rule-only"
+	tests := []struct {
+		name     string
+		fallback bool
+	}{
+		{name: "fails the build", fallback: false},
+		{name: "falls back to the uninstrumented package", fallback: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			t.Setenv(util.EnvOtelcWorkDir, tempDir)
+			if tt.fallback {
+				t.Setenv(util.EnvOtelcRenderFallback, "1")
+			}
+			breakRendering(t)
+			ctx := util.ContextWithLogger(t.Context(), slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+			// Keep the source out of the working directory the instrumented
+			// files are written to, as the go command does
+			sourceFile := filepath.Join(tempDir, "src", mainGoFileName)
+			require.NoError(t, os.MkdirAll(filepath.Dir(sourceFile), 0o755))
+			require.NoError(t, util.CopyFile(filepath.Join(testdataDir, goldenDir, testName, sourceFileName), sourceFile))
+			writeMatchedJSON(loadRulesYAML(t, testName, sourceFile, mainPackage, false))
+			args := compileArgs(tempDir, sourceFile, nil, mainPackage)
+
+			err := Toolexec(ctx, args)
+
+			if !tt.fallback {
+				var renderErr *ast.RenderError
+				require.ErrorAs(t, err, &renderErr)
+				assert.Equal(t, []string{"Func1"}, renderErr.Funcs)
+				assert.Contains(t, err.Error(), "instrumenting package main")
+				return
+			}
+			require.NoError(t, err)
+			assert.FileExists(t, filepath.Join(tempDir, compiledOutput),
+				"the package is compiled from its original source")
+		})
+	}
+}
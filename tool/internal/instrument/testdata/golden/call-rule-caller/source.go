@@ -0,0 +1,26 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import "fmt"
+
+func Timed(f func()) {
+	f()
+}
+
+type Cart struct{}
+
+func (c *Cart) Pay() {
+	fmt.Println("paying")
+}
+
+func Checkout(c *Cart) {
+	fmt.Println("checking out")
+	c.Pay()
+}
+
+func main() {
+	fmt.Println("starting")
+	Checkout(&Cart{})
+}
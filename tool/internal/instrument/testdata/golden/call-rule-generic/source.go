@@ -0,0 +1,18 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/instrument/testdata/golden/call-rule-generic/helpers/generic"
+
+func IndexInferred(s []int) int {
+	return generic.Index(s, 1)
+}
+
+func IndexExplicit(s []int) int {
+	return generic.Index[[]int, int](s, 2)
+}
+
+func IndexPartial(s []string) int {
+	return generic.Index[[]string](s, "x")
+}
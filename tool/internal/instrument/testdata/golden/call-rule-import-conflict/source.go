@@ -0,0 +1,13 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	fmt "strings"
+	"unsafe"
+)
+
+func main() {
+	_ = unsafe.Sizeof(fmt.ToUpper("sizing"))
+}
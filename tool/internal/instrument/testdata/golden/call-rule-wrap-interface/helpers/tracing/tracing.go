@@ -0,0 +1,14 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tracing
+
+import "io"
+
+type reader struct{ io.Reader }
+
+// Reader wraps an io.Reader so that every Read goes through the wrapper,
+// used by call rules with wrap_interface.
+func Reader(r io.Reader) io.Reader {
+	return reader{r}
+}
@@ -0,0 +1,13 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import "strings"
+
+func main() {
+	var r interface{ Read([]byte) (int, error) } = strings.NewReader("hello")
+	buf := make([]byte, 5)
+	_, _ = r.Read(buf)
+	println(string(buf))
+}
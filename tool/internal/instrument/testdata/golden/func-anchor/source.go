@@ -0,0 +1,18 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+var codecs []string
+
+func registerCodec(name string) { codecs = append(codecs, name) }
+
+func init() {
+	registerCodec("json")
+}
+
+func init() {
+	registerCodec("yaml")
+}
+
+func main() { println(len(codecs)) }
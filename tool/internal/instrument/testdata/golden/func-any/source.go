@@ -0,0 +1,30 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+type ResponseWriter interface {
+	Write(b []byte) (int, error)
+}
+
+type Request struct {
+	Path string
+}
+
+func Index(w ResponseWriter, r *Request) {
+	println("index")
+}
+
+func Health(w ResponseWriter, req *Request) {
+	println("ok")
+}
+
+func Redirect(w ResponseWriter, r *Request, url string) {
+	println("redirect")
+}
+
+func main() {
+	Index(nil, &Request{})
+	Health(nil, &Request{})
+	Redirect(nil, &Request{}, "/")
+}
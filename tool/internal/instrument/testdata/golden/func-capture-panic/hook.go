@@ -0,0 +1,18 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	_ "unsafe"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+)
+
+func H1Before(ctx hook.HookContext, cart string) {
+	ctx.SetData(map[string]interface{}{"cart": cart})
+}
+
+func H1After(ctx hook.HookContext, err error) {
+	println("H1After", ctx.GetKeyData("cart"), ctx.GetKeyData("otel.panic"))
+}
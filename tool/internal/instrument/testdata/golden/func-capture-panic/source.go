@@ -0,0 +1,13 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+func Checkout(cart string) error {
+	if cart == "" {
+		panic("empty cart")
+	}
+	return nil
+}
+
+func main() { _ = Checkout("books") }
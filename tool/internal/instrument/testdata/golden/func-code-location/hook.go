@@ -0,0 +1,14 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	_ "unsafe"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+)
+
+func H1Before(ctx hook.HookContext, order string) {
+	println("H1Before", ctx.GetKeyData("otel.code.file.path"), ctx.GetKeyData("otel.code.line.number"))
+}
@@ -0,0 +1,11 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+// Checkout is declared on line 7, the line its code.line.number reports.
+func Checkout(order string) {
+	println(order)
+}
+
+func main() { Checkout("order-1") }
@@ -0,0 +1,18 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	_ "unsafe"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+)
+
+func H1Before(ctx hook.HookContext) {
+	println("H1Before")
+}
+
+func H2Before(ctx hook.HookContext, n int) {
+	println("H2Before")
+}
@@ -0,0 +1,11 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+func Query(query string) error {
+	println(query)
+	return nil
+}
+
+func main() { _ = Query("SELECT * FROM users") }
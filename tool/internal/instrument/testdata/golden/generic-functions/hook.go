@@ -16,3 +16,7 @@ func GenericFuncAfter(ctx hook.HookContext, r1 interface{}, r2 error) {}
 func GenericMethodBefore(ctx hook.HookContext, recv interface{}, p1 interface{}, p2 string) {}
 
 func GenericMethodAfter(ctx hook.HookContext, r1 interface{}, r2 error) {}
+
+func MapBefore(ctx hook.HookContext, s interface{}) {}
+
+func MapAfter(ctx hook.HookContext, r interface{}) {}
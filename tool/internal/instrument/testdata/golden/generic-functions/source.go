@@ -14,3 +14,7 @@ type GenStruct[T any] struct {
 func (g *GenStruct[T]) GenericMethod(p1 T, p2 string) (T, error) {
 	return p1, nil
 }
+
+func Map[T any](s []T) []T {
+	return s
+}
@@ -0,0 +1,16 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	_ "unsafe"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+)
+
+func CacheGetBefore(ctx hook.HookContext, recv interface{}, k interface{}) {}
+
+func CacheGetAfter(ctx hook.HookContext, v interface{}, ok bool) {}
+
+func TreeRootBefore(ctx hook.HookContext, recv interface{}) {}
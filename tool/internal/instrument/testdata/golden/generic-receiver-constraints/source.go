@@ -0,0 +1,21 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+type Cache[K comparable, V any] struct {
+	m map[K]V
+}
+
+func (c *Cache[K, V]) Get(k K) (V, bool) {
+	v, ok := c.m[k]
+	return v, ok
+}
+
+type Tree[T any, P interface{ *T }] struct {
+	root P
+}
+
+func (t Tree[A, B]) Root() B {
+	return t.root
+}
@@ -0,0 +1,18 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	_ "unsafe"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/hook"
+)
+
+func H1Before(ctx hook.HookContext, p1 string, p2 int) {
+	ctx.SetKeyData("p1", p1)
+}
+
+func H1After(ctx hook.HookContext, r1 float32, r2 error) {
+	println(ctx.GetKeyData("p1"))
+}
@@ -6,10 +6,14 @@ package instrument
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dave/dst"
@@ -31,12 +35,17 @@ type InstrumentPhase struct {
 	importConfigPath string
 	// The target file to be instrumented
 	target *dst.File
+	// The path of the target file
+	targetFile string
 	// The parser for the target file
 	parser *ast.AstParser
 	// The compiling arguments for the target file
 	compileArgs []string
 	// The target function to be instrumented
 	targetFunc *dst.FuncDecl
+	// The type parameters declared by the generic type the target function is a
+	// method of, if any
+	recvTypeParams *dst.FieldList
 	// The before trampoline function
 	beforeTrampFunc *dst.FuncDecl
 	// The after trampoline function
@@ -56,8 +65,18 @@ type InstrumentPhase struct {
 	// whole package because HookContext declarations accumulate into one globals
 	// file across all instrumented source files.
 	appliedFuncIdentities map[string]struct{}
+	// Whether the trampolines measure the time spent in the hooks, see
+	// util.EnvOtelcOverhead
+	measureOverhead bool
+	// Whether a package whose instrumented code cannot be rendered is compiled
+	// uninstrumented, see util.EnvOtelcRenderFallback
+	renderFallback bool
 }
 
+// writeFile writes the instrumented files; tests replace it to simulate
+// printer failures.
+var writeFile = ast.WriteFile
+
 func (ip *InstrumentPhase) Info(msg string, args ...any)  { ip.logger.Info(msg, args...) }
 func (ip *InstrumentPhase) Error(msg string, args ...any) { ip.logger.Error(msg, args...) }
 func (ip *InstrumentPhase) Warn(msg string, args ...any)  { ip.logger.Warn(msg, args...) }
@@ -100,7 +119,12 @@ func interceptCompile(ctx context.Context, args []string) ([]string, error) {
 		workDir:          filepath.Dir(target),
 		compileArgs:      args,
 		importConfigPath: importCfgPath,
+		measureOverhead:  os.Getenv(util.EnvOtelcOverhead) == "1",
+		renderFallback:   os.Getenv(util.EnvOtelcRenderFallback) == "1",
 	}
+	// The instrumentation edits the arguments in place; keep the original
+	// command to fall back on
+	originalArgs := slices.Clone(args)
 
 	// Parse existing importcfg if present
 	if importCfgPath != "" {
@@ -123,6 +147,15 @@ func interceptCompile(ctx context.Context, args []string) ([]string, error) {
 		ip.Info("Instrument package", "rules", matched, "args", args)
 		// Okay, this package should be instrumented.
 		err = ip.instrument(ctx, matched)
+		var renderErr *ast.RenderError
+		if err != nil && ip.renderFallback && errors.As(err, &renderErr) {
+			ip.Warn("Leave package uninstrumented: the instrumented code cannot be rendered",
+				"package", matched.ModulePath,
+				"file", renderErr.File,
+				"funcs", renderErr.Funcs,
+				"error", renderErr.Err)
+			return originalArgs, nil
+		}
 		if err != nil {
 			return nil, ex.Wrapf(err, "instrumenting package %s", matched.ModulePath)
 		}
@@ -195,48 +228,89 @@ func (ip *InstrumentPhase) updateImportConfig(ctx context.Context, newImports ma
 	return nil
 }
 
+// trackAddedImportsMu serializes the tracking file updates of this process.
+var trackAddedImportsMu sync.Mutex
+
 // trackAddedImports saves the resolved package files to a per-process tracking file.
 // During the link phase, all per-process files will be merged.
 // Each compile process writes to its own file to avoid inter-process race conditions.
+// Entries already in the file, left by an earlier call or by an earlier process
+// that had the same PID, are kept. The file is replaced atomically so that a
+// concurrent loadAddedImports never reads it half written.
+//
+// The files are kept under the instrumentation key, which the build cache
+// entries of the instrumented packages are keyed on too: a package reused
+// from the cache is not compiled again, so the link phase of a later build
+// still needs the imports tracked when it was.
 func trackAddedImports(packages map[string]string) error {
 	if len(packages) == 0 {
 		return nil
 	}
 
-	// Write to process-specific file (no locking needed)
-	filePath := util.GetAddedImportsFileForProcess()
+	key, err := currentInstrumentationKey()
+	if err != nil {
+		return err
+	}
+
+	trackAddedImportsMu.Lock()
+	defer trackAddedImportsMu.Unlock()
 
-	data, err := json.MarshalIndent(packages, "", "  ")
+	filePath := util.GetAddedImportsFileForProcess(key)
+	if err = os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return ex.Wrapf(err, "creating imports directory")
+	}
+
+	merged := make(map[string]string, len(packages))
+	if data, readErr := os.ReadFile(filePath); readErr == nil {
+		// An unreadable file is simply replaced
+		_ = json.Unmarshal(data, &merged)
+	}
+	maps.Copy(merged, packages)
+
+	data, err := json.MarshalIndent(merged, "", "  ")
 	if err != nil {
 		return ex.Wrapf(err, "marshaling added imports")
 	}
 
-	if err = os.WriteFile(filePath, data, 0o600); err != nil {
+	// The temporary name does not match GetAddedImportsPattern
+	tmpPath := filePath + ".tmp"
+	if err = os.WriteFile(tmpPath, data, 0o600); err != nil {
 		return ex.Wrapf(err, "writing imports file")
 	}
+	if err = os.Rename(tmpPath, filePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return ex.Wrapf(err, "replacing imports file")
+	}
 
 	return nil
 }
 
-// CleanupImportTrackingFiles removes import tracking files from previous builds.
-// Should be called at the start of a new build to clean up stale files from prior runs.
-// This is exported for use by the setup phase.
-func CleanupImportTrackingFiles() {
-	pattern := util.GetAddedImportsPattern()
-	files, err := filepath.Glob(pattern)
+// pruneImportTrackingFiles removes the import tracking files of the other
+// instrumentation keys than key, which no build links against anymore once
+// the instrumentation changed.
+func pruneImportTrackingFiles(key string) {
+	entries, err := os.ReadDir(util.GetAddedImportsRootDir())
 	if err != nil {
 		return
 	}
 
-	for _, file := range files {
-		_ = os.Remove(file) // Best effort cleanup
+	for _, entry := range entries {
+		if entry.Name() != key {
+			_ = os.RemoveAll(filepath.Join(util.GetAddedImportsRootDir(), entry.Name())) // Best effort cleanup
+		}
 	}
 }
 
-// loadAddedImports discovers and merges all per-process import tracking files.
+// loadAddedImports discovers and merges all per-process import tracking files
+// of the instrumentation key of the build. The files written last, by the
+// packages compiled most recently, take precedence.
 func loadAddedImports(ctx context.Context) (map[string]string, error) {
 	logger := util.LoggerFromContext(ctx)
-	pattern := util.GetAddedImportsPattern()
+	key, err := currentInstrumentationKey()
+	if err != nil {
+		return nil, err
+	}
+	pattern := util.GetAddedImportsPattern(key)
 
 	// Find all per-process import files
 	files, err := filepath.Glob(pattern)
@@ -251,7 +325,7 @@ func loadAddedImports(ctx context.Context) (map[string]string, error) {
 
 	// Merge all files
 	merged := make(map[string]string)
-	for _, filePath := range files {
+	for _, filePath := range byModTime(files) {
 		data, readErr := os.ReadFile(filePath)
 		if readErr != nil {
 			// Log warning but continue with other files
@@ -288,6 +362,21 @@ func loadAddedImports(ctx context.Context) (map[string]string, error) {
 	return merged, nil
 }
 
+// byModTime sorts files from the least to the most recently modified, keeping
+// the order of the files modified at the same time.
+func byModTime(files []string) []string {
+	modTimes := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			modTimes[file] = info.ModTime()
+		}
+	}
+	slices.SortStableFunc(files, func(a, b string) int {
+		return modTimes[a].Compare(modTimes[b])
+	})
+	return files
+}
+
 // interceptLink updates the link-time importcfg with packages added during compilation.
 func interceptLink(ctx context.Context, args []string) ([]string, error) {
 	logger := util.LoggerFromContext(ctx)
@@ -299,12 +388,17 @@ func interceptLink(ctx context.Context, args []string) ([]string, error) {
 		return args, nil
 	}
 
-	// Load imports that were added during compilation
+	// Load imports that were added during compilation, in this build or in
+	// the earlier ones whose instrumented archives it reuses
 	addedImports, err := loadAddedImports(ctx)
 	if err != nil {
 		logger.WarnContext(ctx, "failed to load added imports for link phase", "error", err)
 		return args, nil // Non-fatal, proceed with original args
 	}
+	// The files tracked for earlier instrumentations are never linked again
+	if key, keyErr := currentInstrumentationKey(); keyErr == nil {
+		pruneImportTrackingFiles(key)
+	}
 
 	if len(addedImports) == 0 {
 		// No imports were added during compilation
@@ -341,9 +435,10 @@ func interceptLink(ctx context.Context, args []string) ([]string, error) {
 
 	logger.InfoContext(ctx, "Updated link importcfg", "path", importCfgPath, "added", len(addedImports))
 
-	// Note: We don't clean up tracking files here because multi-link builds
-	// (e.g., go build ./cmd/...) need the files available for all link steps.
-	// Cleanup happens at the start of the next build via CleanupImportTrackingFiles.
+	// Note: We don't clean up the tracking files of the build here because
+	// multi-link builds (e.g., go build ./cmd/...) need the files available
+	// for all link steps, and later builds need them for the packages they
+	// reuse from the cache.
 
 	return args, nil
 }
@@ -356,6 +451,11 @@ func Toolexec(ctx context.Context, args []string) error {
 	// Use slice-based detection to correctly handle tool paths with spaces
 	// (common on Windows, e.g., "C:\Program Files\Go\pkg\tool\...")
 
+	// Key the build cache entries of the go command on the instrumentation
+	if util.IsToolIDCommandWithArgs(args) {
+		return printToolID(ctx, args)
+	}
+
 	// Intercept compile commands for instrumentation
 	if util.IsCompileCommandWithArgs(args) {
 		var err error
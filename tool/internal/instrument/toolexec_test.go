@@ -5,10 +5,15 @@ package instrument
 
 import (
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/imports"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
@@ -160,6 +165,36 @@ func TestUpdateImportConfig(t *testing.T) {
 		assert.Equal(t, "packagefile fmt=/path/to/fmt.a\n", string(content))
 	})
 
+	t.Run("transitive dependencies are added", func(t *testing.T) {
+		tempDir := t.TempDir()
+		t.Setenv(util.EnvOtelcWorkDir, tempDir)
+		require.NoError(t, os.MkdirAll(util.GetBuildTempDir(), 0o755))
+		cfgPath := filepath.Join(tempDir, "importcfg")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(""), 0o644))
+
+		ip := &InstrumentPhase{
+			logger:           slog.Default(),
+			importConfigPath: cfgPath,
+		}
+		// net/http is what the injected net/http hooks import
+		err := ip.updateImportConfig(t.Context(), map[string]string{"http": "net/http"})
+		require.NoError(t, err)
+
+		// Every package net/http depends on, directly or not, must be there
+		// for the compile and link to find it
+		out, err := exec.CommandContext(t.Context(), "go", "list", "-deps", "net/http").Output()
+		require.NoError(t, err)
+		written, err := imports.ParseImportCfg(cfgPath)
+		require.NoError(t, err)
+		for dep := range strings.FieldsSeq(string(out)) {
+			if dep == "unsafe" {
+				continue
+			}
+			assert.Contains(t, written.PackageFile, dep)
+			assert.FileExists(t, written.PackageFile[dep])
+		}
+	})
+
 	t.Run("nil PackageFile map", func(t *testing.T) {
 		tempDir := t.TempDir()
 		cfgPath := filepath.Join(tempDir, "importcfg")
@@ -189,7 +224,7 @@ func TestTrackAddedImports(t *testing.T) {
 		require.NoError(t, err)
 
 		// No file should be created
-		pattern := util.GetAddedImportsPattern()
+		pattern := util.GetAddedImportsPattern(trackingKey(t))
 		files, _ := filepath.Glob(pattern)
 		assert.Empty(t, files)
 	})
@@ -211,7 +246,7 @@ func TestTrackAddedImports(t *testing.T) {
 		require.NoError(t, err)
 
 		// Verify file was created with correct name pattern
-		expectedPath := util.GetAddedImportsFileForProcess()
+		expectedPath := util.GetAddedImportsFileForProcess(trackingKey(t))
 		_, err = os.Stat(expectedPath)
 		require.NoError(t, err, "per-process file should exist")
 
@@ -225,6 +260,87 @@ func TestTrackAddedImports(t *testing.T) {
 
 		assert.Equal(t, packages, result)
 	})
+
+	t.Run("keeps entries of earlier calls", func(t *testing.T) {
+		tempDir := t.TempDir()
+		t.Setenv(util.EnvOtelcWorkDir, tempDir)
+		require.NoError(t, os.MkdirAll(util.GetBuildTempDir(), 0o755))
+
+		require.NoError(t, trackAddedImports(map[string]string{"fmt": "/path/to/fmt.a"}))
+		require.NoError(t, trackAddedImports(map[string]string{"context": "/path/to/context.a"}))
+
+		result, err := loadAddedImports(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"fmt":     "/path/to/fmt.a",
+			"context": "/path/to/context.a",
+		}, result)
+	})
+}
+
+// TestTrackAddedImports_Concurrent simulates parallel instrument phases
+// updating their tracking files while the link phase reads them. Run it with
+// -race to catch unsynchronized access.
+func TestTrackAddedImports_Concurrent(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv(util.EnvOtelcWorkDir, tempDir)
+	buildDir := trackingDir(t)
+
+	const writers = 16
+	expected := make(map[string]string)
+	for i := range writers {
+		expected[fmt.Sprintf("example.com/pkg%d", i)] = fmt.Sprintf("/path/to/pkg%d.a", i)
+		// Files of the other compile processes
+		other := filepath.Join(buildDir, fmt.Sprintf("added_imports.%d.json", 100000+i))
+		data, err := json.Marshal(map[string]string{fmt.Sprintf("example.com/other%d", i): "/path/to/other.a"})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(other, data, 0o600))
+		expected[fmt.Sprintf("example.com/other%d", i)] = "/path/to/other.a"
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	readerErrs := make(chan error, 1)
+	go func() {
+		defer close(readerErrs)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			data, err := os.ReadFile(util.GetAddedImportsFileForProcess(trackingKey(t)))
+			if os.IsNotExist(err) {
+				continue
+			}
+			var parsed map[string]string
+			if err == nil {
+				err = json.Unmarshal(data, &parsed)
+			}
+			if err != nil {
+				readerErrs <- err
+				return
+			}
+		}
+	}()
+
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pkg := fmt.Sprintf("example.com/pkg%d", i)
+			assert.NoError(t, trackAddedImports(map[string]string{pkg: expected[pkg]}))
+			_, err := loadAddedImports(t.Context())
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	close(done)
+	require.NoError(t, <-readerErrs, "tracking file was read half written")
+
+	result, err := loadAddedImports(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
 }
 
 func TestLoadAddedImports(t *testing.T) {
@@ -246,10 +362,7 @@ func TestLoadAddedImports(t *testing.T) {
 		tempDir := t.TempDir()
 		t.Setenv(util.EnvOtelcWorkDir, tempDir)
 
-		// Create build temp directory
-		buildDir := util.GetBuildTempDir()
-		err := os.MkdirAll(buildDir, 0o755)
-		require.NoError(t, err)
+		buildDir := trackingDir(t)
 
 		// Simulate files from different processes
 		file1 := filepath.Join(buildDir, "added_imports.1234.json")
@@ -279,10 +392,7 @@ func TestLoadAddedImports(t *testing.T) {
 		tempDir := t.TempDir()
 		t.Setenv(util.EnvOtelcWorkDir, tempDir)
 
-		// Create build temp directory
-		buildDir := util.GetBuildTempDir()
-		err := os.MkdirAll(buildDir, 0o755)
-		require.NoError(t, err)
+		buildDir := trackingDir(t)
 
 		// One valid file, one corrupted
 		validFile := filepath.Join(buildDir, "added_imports.1111.json")
@@ -303,73 +413,89 @@ func TestLoadAddedImports(t *testing.T) {
 	t.Run("later file overrides earlier for same package", func(t *testing.T) {
 		tempDir := t.TempDir()
 		t.Setenv(util.EnvOtelcWorkDir, tempDir)
+		buildDir := trackingDir(t)
 
-		// Create build temp directory
-		buildDir := util.GetBuildTempDir()
-		err := os.MkdirAll(buildDir, 0o755)
-		require.NoError(t, err)
-
-		// Two files with same package but different archives
-		// Note: filepath.Glob returns files in lexical order
-		file1 := filepath.Join(buildDir, "added_imports.1111.json")
-		file2 := filepath.Join(buildDir, "added_imports.2222.json")
+		// Two files with same package but different archives, the one written
+		// last by a process with a lower PID
+		file1 := filepath.Join(buildDir, "added_imports.2222.json")
+		file2 := filepath.Join(buildDir, "added_imports.1111.json")
 
 		data1, _ := json.Marshal(map[string]string{"fmt": "/old/path/fmt.a"})
 		data2, _ := json.Marshal(map[string]string{"fmt": "/new/path/fmt.a"})
 
 		require.NoError(t, os.WriteFile(file1, data1, 0o644))
 		require.NoError(t, os.WriteFile(file2, data2, 0o644))
+		earlier := time.Now().Add(-time.Hour)
+		require.NoError(t, os.Chtimes(file1, earlier, earlier))
 
 		result, err := loadAddedImports(t.Context())
 		require.NoError(t, err)
 
-		// The second file (lexically) should win
+		// The file written last should win
 		assert.Equal(t, "/new/path/fmt.a", result["fmt"])
 	})
-}
 
-func TestCleanupImportTrackingFiles(t *testing.T) {
-	t.Run("removes all tracking files", func(t *testing.T) {
+	t.Run("ignores the files of other instrumentations", func(t *testing.T) {
 		tempDir := t.TempDir()
 		t.Setenv(util.EnvOtelcWorkDir, tempDir)
+		trackingDir(t)
 
-		// Create build temp directory
-		buildDir := util.GetBuildTempDir()
-		err := os.MkdirAll(buildDir, 0o755)
+		other := util.GetAddedImportsDir("other")
+		require.NoError(t, os.MkdirAll(other, 0o755))
+		data, _ := json.Marshal(map[string]string{"fmt": "/other/path/fmt.a"})
+		require.NoError(t, os.WriteFile(filepath.Join(other, "added_imports.1111.json"), data, 0o644))
+
+		result, err := loadAddedImports(t.Context())
 		require.NoError(t, err)
+		assert.Empty(t, result)
+	})
+}
 
-		// Create some tracking files
-		file1 := filepath.Join(buildDir, "added_imports.1234.json")
-		file2 := filepath.Join(buildDir, "added_imports.5678.json")
-		require.NoError(t, os.WriteFile(file1, []byte("{}"), 0o644))
-		require.NoError(t, os.WriteFile(file2, []byte("{}"), 0o644))
+func TestPruneImportTrackingFiles(t *testing.T) {
+	t.Run("removes the files of other instrumentations", func(t *testing.T) {
+		tempDir := t.TempDir()
+		t.Setenv(util.EnvOtelcWorkDir, tempDir)
+
+		key := trackingKey(t)
+		require.NoError(t, trackAddedImports(map[string]string{"fmt": "/path/to/fmt.a"}))
+		other := util.GetAddedImportsDir("other")
+		require.NoError(t, os.MkdirAll(other, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(other, "added_imports.1234.json"), []byte("{}"), 0o644))
 
 		// Also create a non-tracking file that should NOT be removed
-		otherFile := filepath.Join(buildDir, "other.json")
+		otherFile := filepath.Join(util.GetBuildTempDir(), "other.json")
 		require.NoError(t, os.WriteFile(otherFile, []byte("{}"), 0o644))
 
-		CleanupImportTrackingFiles()
+		pruneImportTrackingFiles(key)
 
-		// Tracking files should be removed
-		_, err = os.Stat(file1)
-		assert.True(t, os.IsNotExist(err), "tracking file 1 should be removed")
-		_, err = os.Stat(file2)
-		assert.True(t, os.IsNotExist(err), "tracking file 2 should be removed")
-
-		// Other file should still exist
-		_, err = os.Stat(otherFile)
-		assert.NoError(t, err, "non-tracking file should still exist")
+		assert.NoDirExists(t, other, "the files of other instrumentations should be removed")
+		assert.FileExists(t, util.GetAddedImportsFileForProcess(key), "the files of the build should be kept")
+		assert.FileExists(t, otherFile, "non-tracking file should still exist")
 	})
 
-	t.Run("handles empty directory gracefully", func(t *testing.T) {
+	t.Run("handles missing directory gracefully", func(t *testing.T) {
 		tempDir := t.TempDir()
 		t.Setenv(util.EnvOtelcWorkDir, tempDir)
 
-		// Create build temp directory (empty)
-		err := os.MkdirAll(util.GetBuildTempDir(), 0o755)
-		require.NoError(t, err)
-
 		// Should not panic
-		CleanupImportTrackingFiles()
+		pruneImportTrackingFiles(trackingKey(t))
 	})
 }
+
+// trackingKey returns the instrumentation key the import tracking files of
+// the test process are kept under.
+func trackingKey(t *testing.T) string {
+	t.Helper()
+	key, err := currentInstrumentationKey()
+	require.NoError(t, err)
+	return key
+}
+
+// trackingDir returns the created directory of the import tracking files of
+// the test process.
+func trackingDir(t *testing.T) string {
+	t.Helper()
+	dir := util.GetAddedImportsDir(trackingKey(t))
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	return dir
+}
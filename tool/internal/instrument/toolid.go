@@ -0,0 +1,193 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrument
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
+)
+
+// toolIDEnvs are the environment variables that change the code generated for
+// the instrumented packages.
+//
+//nolint:gochecknoglobals // private lookup table
+var toolIDEnvs = []string{
+	util.EnvOtelcOverhead,
+	util.EnvOtelcRenderFallback,
+}
+
+// printToolID answers the "-V=full" query of the go command with the ID of the
+// tool, extended with the instrumentation key.
+//
+// The go command keys the build cache entry of every package on the ID of the
+// compiler that builds it, along with its sources and dependencies. Extending
+// the ID therefore makes the cache tell instrumented archives apart from plain
+// ones, and from those instrumented with other rules or hooks, while packages
+// whose sources and instrumentation did not change since the last build are
+// reused instead of being instrumented again.
+func printToolID(ctx context.Context, args []string) error {
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return ex.Wrapf(err, "failed to run %v", args)
+	}
+	key, err := currentInstrumentationKey()
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintln(os.Stdout, extendToolID(string(out), key)); err != nil {
+		return ex.Wrap(err)
+	}
+	return nil
+}
+
+// extendToolID appends key to the "-V=full" output of a tool. Release
+// toolchains print "compile version go1.25.0 ...", the whole of which is the
+// ID, while development ones print "compile version devel ... buildID=...",
+// of which only the content part of the build ID is.
+func extendToolID(output, key string) string {
+	line := strings.TrimSpace(output)
+	fields := strings.Fields(line)
+	if len(fields) >= 3 && fields[2] == "devel" && strings.HasPrefix(fields[len(fields)-1], "buildID=") {
+		return line + "+otelc-" + key
+	}
+	return line + " otelc:" + key
+}
+
+// currentInstrumentationKey returns the instrumentationKey of the build,
+// computed once per process.
+//
+//nolint:gochecknoglobals // per-process cache
+var currentInstrumentationKey = sync.OnceValues(func() (string, error) {
+	return instrumentationKey(util.GetMatchedRuleFile())
+})
+
+// instrumentationKey returns a digest of everything the instrumented code
+// depends on besides the sources of the package: the tool itself, the rules
+// matched in the setup phase, the sources of the hooks they inject and the
+// settings of the code generation.
+func instrumentationKey(matchedRuleFile string) (string, error) {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "otelc %s\n", util.Version)
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", ex.Wrapf(err, "failed to get executable path")
+	}
+	if err = hashFile(h, execPath); err != nil {
+		return "", err
+	}
+	for _, env := range toolIDEnvs {
+		_, _ = fmt.Fprintf(h, "%s=%s\n", env, os.Getenv(env))
+	}
+
+	// No rules were matched, e.g. for the tool ID queries of the setup phase
+	content, err := os.ReadFile(matchedRuleFile)
+	if os.IsNotExist(err) {
+		return hex.EncodeToString(h.Sum(nil))[:32], nil
+	}
+	if err != nil {
+		return "", ex.Wrapf(err, "failed to read file %s", matchedRuleFile)
+	}
+	var sets []*rule.InstRuleSet
+	if err = json.Unmarshal(content, &sets); err != nil {
+		return "", ex.Wrapf(err, "failed to unmarshal JSON")
+	}
+	// The rules are loaded from maps, so their order changes from one setup
+	// to the next without changing what they do
+	var tree any
+	if err = json.Unmarshal(content, &tree); err != nil {
+		return "", ex.Wrapf(err, "failed to unmarshal JSON")
+	}
+	canonical, err := json.Marshal(sortArrays(tree))
+	if err != nil {
+		return "", ex.Wrap(err)
+	}
+	_, _ = h.Write(canonical)
+
+	for _, dir := range hookDirs(sets) {
+		files, err1 := util.ListFiles(dir)
+		if err1 != nil {
+			return "", err1
+		}
+		for _, file := range files {
+			if !util.IsGoFile(file) {
+				continue
+			}
+			_, _ = fmt.Fprintf(h, "%s\n", file)
+			if err = hashFile(h, file); err != nil {
+				return "", err
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32], nil
+}
+
+// hookDirs returns the sorted directories holding the hooks and the files the
+// rules of sets inject.
+func hookDirs(sets []*rule.InstRuleSet) []string {
+	var dirs []string
+	for _, set := range sets {
+		for _, r := range set.AllFuncRules() {
+			dirs = append(dirs, r.ResolvedPath)
+		}
+		for _, r := range set.FileRules {
+			dirs = append(dirs, r.ResolvedPath)
+		}
+	}
+	dirs = slices.DeleteFunc(dirs, func(dir string) bool { return dir == "" })
+	slices.Sort(dirs)
+	return slices.Compact(dirs)
+}
+
+// sortArrays sorts the arrays of the decoded JSON value v, recursively, by
+// their encoded elements. Objects need no sorting: their keys are encoded in
+// order.
+func sortArrays(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			v[key] = sortArrays(value)
+		}
+	case []any:
+		encoded := make([]string, len(v))
+		for i, elem := range v {
+			b, _ := json.Marshal(sortArrays(elem))
+			encoded[i] = string(b)
+		}
+		slices.Sort(encoded)
+		sorted := make([]any, len(encoded))
+		for i, elem := range encoded {
+			sorted[i] = json.RawMessage(elem)
+		}
+		return sorted
+	}
+	return v
+}
+
+func hashFile(w io.Writer, path string) error {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return ex.Wrapf(err, "failed to open %s", path)
+	}
+	defer f.Close()
+	if _, err = io.Copy(w, f); err != nil {
+		return ex.Wrapf(err, "failed to read %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrument
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
+)
+
+func TestExtendToolID(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{
+			name:     "release",
+			output:   "compile version go1.25.0\n",
+			expected: "compile version go1.25.0 otelc:abc",
+		},
+		{
+			name:     "release with experiments",
+			output:   "compile version go1.25.0 X:nocoverageredesign\n",
+			expected: "compile version go1.25.0 X:nocoverageredesign otelc:abc",
+		},
+		{
+			name:     "development",
+			output:   "compile version devel go1.26-4e7ea6b Tue Oct 7 10:00:00 2025 +0000 buildID=a1b2/c3d4\n",
+			expected: "compile version devel go1.26-4e7ea6b Tue Oct 7 10:00:00 2025 +0000 buildID=a1b2/c3d4+otelc-abc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, extendToolID(tt.output, "abc"))
+		})
+	}
+}
+
+// writeRuleSets writes a matched rule file made of one rule set per hook
+// directory, and returns its path.
+func writeRuleSets(t *testing.T, dir string, hookDirs ...string) string {
+	t.Helper()
+	sets := make([]*rule.InstRuleSet, 0, len(hookDirs))
+	for i, hookDir := range hookDirs {
+		set := rule.NewInstRuleSet("example.com/pkg" + string(rune('a'+i)))
+		set.AddFuncRule(filepath.Join(dir, "source.go"), &rule.InstFuncRule{
+			InstBaseRule: rule.InstBaseRule{Name: "rule", Target: set.ModulePath},
+			Func:         "Serve",
+			Before:       "BeforeServe",
+			Path:         "example.com/hooks",
+			ResolvedPath: hookDir,
+		})
+		sets = append(sets, set)
+	}
+	content, err := json.Marshal(sets)
+	require.NoError(t, err)
+	file := filepath.Join(dir, "matched.json")
+	require.NoError(t, os.WriteFile(file, content, 0o644))
+	return file
+}
+
+func TestInstrumentationKey(t *testing.T) {
+	dir := t.TempDir()
+	hooksA := filepath.Join(dir, "hooks_a")
+	hooksB := filepath.Join(dir, "hooks_b")
+	for _, hookDir := range []string{hooksA, hooksB} {
+		require.NoError(t, os.MkdirAll(hookDir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(hookDir, "hook.go"), []byte("package hooks\n"), 0o644))
+	}
+	key := func(t *testing.T, matchedRuleFile string) string {
+		t.Helper()
+		k, err := instrumentationKey(matchedRuleFile)
+		require.NoError(t, err)
+		return k
+	}
+	base := key(t, writeRuleSets(t, dir, hooksA, hooksB))
+
+	t.Run("stable", func(t *testing.T) {
+		assert.Equal(t, base, key(t, writeRuleSets(t, dir, hooksA, hooksB)))
+	})
+
+	t.Run("independent of the order of the rules", func(t *testing.T) {
+		sets := writeRuleSets(t, dir, hooksB, hooksA)
+		// Swap the import paths back, so that only the order differs
+		content, err := os.ReadFile(sets)
+		require.NoError(t, err)
+		var decoded []*rule.InstRuleSet
+		require.NoError(t, json.Unmarshal(content, &decoded))
+		decoded[0].ModulePath, decoded[1].ModulePath = decoded[1].ModulePath, decoded[0].ModulePath
+		for _, set := range decoded {
+			for _, r := range set.AllFuncRules() {
+				r.Target = set.ModulePath
+			}
+		}
+		content, err = json.Marshal(decoded)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(sets, content, 0o644))
+
+		assert.Equal(t, base, key(t, sets))
+	})
+
+	t.Run("changes with the rules", func(t *testing.T) {
+		assert.NotEqual(t, base, key(t, writeRuleSets(t, dir, hooksA)))
+	})
+
+	t.Run("changes with the hooks", func(t *testing.T) {
+		hookFile := filepath.Join(hooksB, "hook.go")
+		require.NoError(t, os.WriteFile(hookFile, []byte("package hooks\n\nfunc BeforeServe() {}\n"), 0o644))
+		t.Cleanup(func() { _ = os.WriteFile(hookFile, []byte("package hooks\n"), 0o644) })
+
+		assert.NotEqual(t, base, key(t, writeRuleSets(t, dir, hooksA, hooksB)))
+	})
+
+	t.Run("changes with the code generation settings", func(t *testing.T) {
+		t.Setenv(util.EnvOtelcOverhead, "1")
+		assert.NotEqual(t, base, key(t, writeRuleSets(t, dir, hooksA, hooksB)))
+	})
+
+	t.Run("no matched rules", func(t *testing.T) {
+		none := key(t, filepath.Join(dir, "missing.json"))
+		assert.NotEmpty(t, none)
+		assert.NotEqual(t, base, none)
+	})
+}
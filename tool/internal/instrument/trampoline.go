@@ -6,8 +6,13 @@ package instrument
 import (
 	_ "embed"
 	"fmt"
+	"go/parser"
 	"go/token"
+	"maps"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/dave/dst"
 
@@ -38,6 +43,7 @@ const (
 	trampolineSkipName              = "skip"
 	trampolineSetParamName          = "SetParam"
 	trampolineGetParamName          = "GetParam"
+	trampolineGetParamCountName     = "GetParamCount"
 	trampolineSetReturnValName      = "SetReturnVal"
 	trampolineGetReturnValName      = "GetReturnVal"
 	trampolineSetSkipCallName       = "SetSkipCall"
@@ -53,8 +59,83 @@ const (
 	trampolineBefore                = true
 	trampolineAfter                 = false
 	unsafePackageName               = "unsafe"
+	trampolineSetKeyDataName        = "SetKeyData"
+	// trampolineSpanNameKey is the hook data key holding the span_name
+	// template; it must match runtime.SpanNameTemplateKey in pkg/runtime.
+	trampolineSpanNameKey = "otel.span_name"
+	// trampolineCodeFunctionNameKey, trampolineCodeFilePathKey and
+	// trampolineCodeLineNumberKey are the hook data keys holding the fully
+	// qualified name and the declaration site of the target function; they
+	// must match runtime.CodeFunctionNameKey, runtime.CodeFilePathKey and
+	// runtime.CodeLineNumberKey.
+	trampolineCodeFunctionNameKey = "otel.code.function.name"
+	trampolineCodeFilePathKey     = "otel.code.file.path"
+	trampolineCodeLineNumberKey   = "otel.code.line.number"
+	// trampolinePanicKey is the hook data key holding the value the target
+	// function panicked with; it must match runtime.PanicValueKey.
+	trampolinePanicKey = "otel.panic"
+	// trampolineNanotimeName is the clock the trampolines read to measure the
+	// time spent in the hooks, linked to runtime.nanotime.
+	trampolineNanotimeName = "OtelNanotime"
 )
 
+// Statements measuring the time spent in the hooks when the build is made with
+// --overhead. The key must match runtime.OverheadDataKey in pkg/runtime. The
+// time is kept in the hook data only if the hook keeps a map there, so that
+// hooks storing data of another type, or none, are left alone.
+const (
+	overheadStartSource = `overheadStart := OtelNanotime()`
+
+	// The time spent in the Before hook
+	overheadBeforeSource = `
+if data, ok := hookContext.GetData().(map[string]interface{}); ok {
+	data["otel.overhead_ns"] = OtelNanotime() - overheadStart
+}`
+
+	// The After hook reads the time spent in both hooks so far when its span ends
+	overheadAfterSource = `
+if data, ok := hookContext.GetData().(map[string]interface{}); ok {
+	overheadBefore, _ := data["otel.overhead_ns"].(int64)
+	data["otel.overhead_ns"] = func() int64 { return overheadBefore + OtelNanotime() - overheadStart }
+}`
+
+	// The clock declaration of the globals file
+	overheadGlobalsSource = `
+import _ "unsafe"
+
+//go:linkname OtelNanotime runtime.nanotime
+func OtelNanotime() int64
+`
+)
+
+// Statements capturing the panic of the target function for the After hook of
+// a rule with capture_panic. The After trampoline is the function deferred by
+// the target function, so recover() stops the panic there; raising it again
+// is deferred first so that it runs last, once the hook returned and past the
+// recovery of panics raised by the hook itself. The value is kept in the hook
+// data as long as the hook keeps a map there, or nothing.
+const (
+	capturePanicSource = `
+otelPanic := recover()
+if otelPanic != nil {
+	defer panic(otelPanic)
+}`
+
+	capturePanicDataSource = `
+if otelPanic != nil {
+	if _, ok := hookContext.GetData().(map[string]interface{}); ok || hookContext.GetData() == nil {
+		hookContext.SetKeyData("` + trampolinePanicKey + `", otelPanic)
+	}
+}`
+)
+
+// parseTrampolineSnippet parses one of the constant overhead or panic snippets.
+func parseTrampolineSnippet(source string) []dst.Stmt {
+	stmts, err := ast.NewAstParser().ParseSnippet(source)
+	util.Assert(err == nil, "invalid trampoline snippet")
+	return stmts
+}
+
 // @@ Modification on this trampoline template should be cautious, as it imposes
 // many implicit constraints on generated code, known constraints are as follows:
 // - It's performance critical, so it should be as simple as possible
@@ -327,7 +408,114 @@ func (ip *InstrumentPhase) callBeforeHook(t *rule.InstFuncRule) {
 		ast.Block(call),
 		nil,
 	)
-	insertAt(ip.beforeTrampFunc, iff, len(ip.beforeTrampFunc.Body.List)-1)
+	// Hand the span name template to the hook
+	// hookContext.SetKeyData("otel.span_name", "...")
+	if t.SpanName != "" {
+		setSpanName := setKeyDataStmt(trampolineSpanNameKey, ast.StringLit(t.SpanName))
+		insertAt(ip.beforeTrampFunc, setSpanName, len(ip.beforeTrampFunc.Body.List)-1)
+	}
+	// Hand the name and declaration site of the target function to the hook
+	// hookContext.SetKeyData("otel.code.function.name", "...")
+	// hookContext.SetKeyData("otel.code.file.path", "...")
+	// hookContext.SetKeyData("otel.code.line.number", ...)
+	if t.CodeLocation {
+		name := qualifiedFuncName(util.FindFlagValue(ip.compileArgs, "-p"), ip.targetFunc)
+		setName := setKeyDataStmt(trampolineCodeFunctionNameKey, ast.StringLit(name))
+		insertAt(ip.beforeTrampFunc, setName, len(ip.beforeTrampFunc.Body.List)-1)
+		file, line := ip.targetFuncLocation()
+		setFile := setKeyDataStmt(trampolineCodeFilePathKey, ast.StringLit(file))
+		insertAt(ip.beforeTrampFunc, setFile, len(ip.beforeTrampFunc.Body.List)-1)
+		if line > 0 {
+			setLine := setKeyDataStmt(trampolineCodeLineNumberKey, ast.IntLit(line))
+			insertAt(ip.beforeTrampFunc, setLine, len(ip.beforeTrampFunc.Body.List)-1)
+		}
+	}
+	if !ip.measureOverhead {
+		insertAt(ip.beforeTrampFunc, iff, len(ip.beforeTrampFunc.Body.List)-1)
+		return
+	}
+	stmts := parseTrampolineSnippet(overheadStartSource)
+	stmts = append(stmts, iff)
+	stmts = append(stmts, parseTrampolineSnippet(overheadBeforeSource)...)
+	for _, stmt := range stmts {
+		insertAt(ip.beforeTrampFunc, stmt, len(ip.beforeTrampFunc.Body.List)-1)
+	}
+}
+
+// setKeyDataStmt returns hookContext.SetKeyData(key, value).
+func setKeyDataStmt(key string, value dst.Expr) dst.Stmt {
+	return ast.ExprStmt(&dst.CallExpr{
+		Fun:  ast.SelectorExpr(ast.Ident(trampolineHookContextName), trampolineSetKeyDataName),
+		Args: []dst.Expr{ast.StringLit(key), value},
+	})
+}
+
+// targetFuncLocation returns the file and line declaring the target function.
+// The file is rewritten by the -trimpath of the compile command, as the
+// compiler rewrites the file names it records, so that it matches what
+// runtime.Caller would report for the function.
+func (ip *InstrumentPhase) targetFuncLocation() (string, int) {
+	file := trimPath(ip.targetFile, util.FindFlagValue(ip.compileArgs, "-trimpath"))
+	pos := ip.parser.FindPosition(ip.targetFunc)
+	if !pos.IsValid() {
+		return file, 0
+	}
+	return file, pos.Line
+}
+
+// qualifiedFuncName returns the fully qualified name of decl, declared in the
+// package importPath, as the runtime reports it for the function, e.g.
+// "example.com/shop/orders.(*Service).Checkout".
+func qualifiedFuncName(importPath string, decl *dst.FuncDecl) string {
+	name := decl.Name.Name
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return importPath + "." + name
+	}
+	recv := decl.Recv.List[0].Type
+	pointer := false
+	if star, ok := recv.(*dst.StarExpr); ok {
+		recv, pointer = star.X, true
+	}
+	// The runtime elides the type parameters of generic receivers
+	typeParams := ""
+	switch r := recv.(type) {
+	case *dst.IndexExpr:
+		recv, typeParams = r.X, "[...]"
+	case *dst.IndexListExpr:
+		recv, typeParams = r.X, "[...]"
+	}
+	typeName := ""
+	if ident, ok := recv.(*dst.Ident); ok {
+		typeName = ident.Name + typeParams
+	}
+	if pointer {
+		typeName = "(*" + typeName + ")"
+	}
+	return importPath + "." + typeName + "." + name
+}
+
+// trimPath applies the first of the ";"-separated "prefix=>replacement"
+// rewrites of a -trimpath flag value whose prefix is a directory of path. A
+// rewrite without "=>" removes the prefix.
+func trimPath(path, rewrites string) string {
+	for rewrite := range strings.SplitSeq(rewrites, ";") {
+		prefix, replace, _ := strings.Cut(rewrite, "=>")
+		if prefix == "" || !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		rest := path[len(prefix):]
+		switch {
+		case rest == "":
+			return replace
+		case rest[0] != '/' && rest[0] != filepath.Separator:
+			continue
+		case replace == "":
+			return rest[1:]
+		default:
+			return replace + rest
+		}
+	}
+	return path
 }
 
 func (ip *InstrumentPhase) callAfterHook(t *rule.InstFuncRule) {
@@ -352,6 +540,22 @@ func (ip *InstrumentPhase) callAfterHook(t *rule.InstFuncRule) {
 		ast.Block(call),
 		nil,
 	)
+	// Recover the panic of the target function ahead of the deferred recovery
+	// of hook panics, and hand it to the hook after it
+	if t.CapturePanic {
+		recoverStmts := parseTrampolineSnippet(capturePanicSource)
+		for i, stmt := range recoverStmts {
+			insertAt(ip.afterTrampFunc, stmt, i)
+		}
+		for _, stmt := range parseTrampolineSnippet(capturePanicDataSource) {
+			insertAt(ip.afterTrampFunc, stmt, len(recoverStmts)+1)
+		}
+	}
+	if ip.measureOverhead {
+		for _, stmt := range parseTrampolineSnippet(overheadStartSource + overheadAfterSource) {
+			insertAtEnd(ip.afterTrampFunc, stmt)
+		}
+	}
 	insertAtEnd(ip.afterTrampFunc, iff)
 }
 
@@ -472,15 +676,17 @@ func findTargetResultType(targetFunc *dst.FuncDecl) *dst.FieldList {
 	return paramTypes
 }
 
-// findTargetGenericType finds the type parameter list of the target function
+// findTargetGenericType finds the type parameter list of the target function.
+// recvTypeParams are the type parameters declared by the receiver's type, if
+// known, and supply the constraints of the receiver's type parameters.
 //
 // func (c *Type1[K]) Target[V any]() V
 // ->
 // [K, V]
-func findTargetGenericType(targetFunc *dst.FuncDecl) *dst.FieldList {
+func findTargetGenericType(targetFunc *dst.FuncDecl, recvTypeParams *dst.FieldList) *dst.FieldList {
 	var trampolineTypeParams *dst.FieldList
 	if ast.HasReceiver(targetFunc) {
-		receiverTypeParams := extractReceiverTypeParams(targetFunc.Recv.List[0].Type)
+		receiverTypeParams := extractReceiverTypeParams(targetFunc.Recv.List[0].Type, recvTypeParams)
 		if receiverTypeParams != nil {
 			trampolineTypeParams = receiverTypeParams
 		}
@@ -508,12 +714,12 @@ func (ip *InstrumentPhase) buildTrampSignature(before bool) {
 	if before {
 		beforeTramp := ip.beforeTrampFunc
 		beforeTramp.Type.Params = findTargetParamType(ip.targetFunc)
-		beforeTramp.Type.TypeParams = findTargetGenericType(ip.targetFunc)
+		beforeTramp.Type.TypeParams = findTargetGenericType(ip.targetFunc, ip.recvTypeParams)
 		fields = beforeTramp.Type.Params
 	} else {
 		afterTramp := ip.afterTrampFunc
 		afterTramp.Type.Params = findTargetResultType(ip.targetFunc)
-		afterTramp.Type.TypeParams = findTargetGenericType(ip.targetFunc)
+		afterTramp.Type.TypeParams = findTargetGenericType(ip.targetFunc, ip.recvTypeParams)
 		fields = afterTramp.Type.Params
 	}
 	// All types should be replaced with dereferenced types, so that the trampoline
@@ -543,7 +749,7 @@ func (ip *InstrumentPhase) buildHookSignature(t *rule.InstFuncRule, before bool)
 	addHookContext(paramTypes)
 
 	// Replace type parameters with interface{}
-	genericTypes = findTargetGenericType(ip.targetFunc)
+	genericTypes = findTargetGenericType(ip.targetFunc, ip.recvTypeParams)
 	for _, field := range paramTypes.List {
 		field.Type = replaceTypeParamsWithAny(field.Type, genericTypes)
 	}
@@ -738,39 +944,192 @@ func setReturnValClause(idx int, t dst.Expr) *dst.CaseClause {
 
 // extractReceiverTypeParams extracts type parameters from a receiver type expression
 // For example: *GenStruct[T] or GenStruct[T, U] -> FieldList with T and U as type parameters
-func extractReceiverTypeParams(recvType dst.Expr) *dst.FieldList {
+// The constraints are taken from declared, the type parameters of the receiver's
+// type declaration, renamed after the receiver. Without them, or if they do not
+// line up with the receiver, every type parameter is constrained by any.
+func extractReceiverTypeParams(recvType dst.Expr, declared *dst.FieldList) *dst.FieldList {
+	var indices []dst.Expr
 	switch t := recvType.(type) {
 	case *dst.StarExpr:
 		// *GenStruct[T] - recurse into X
-		return extractReceiverTypeParams(t.X)
+		return extractReceiverTypeParams(t.X, declared)
 	case *dst.IndexExpr:
 		// GenStruct[T] - single type parameter
-		if ident, ok := t.Index.(*dst.Ident); ok {
-			return &dst.FieldList{
-				List: []*dst.Field{{
-					Names: []*dst.Ident{ident},
-					Type:  ast.Ident("any"), // Type constraint for the parameter
-				}},
-			}
-		}
+		indices = []dst.Expr{t.Index}
 	case *dst.IndexListExpr:
 		// GenStruct[T, U, ...] - multiple type parameters
-		fields := make([]*dst.Field, 0, len(t.Indices))
-		for _, idx := range t.Indices {
-			if ident, ok := idx.(*dst.Ident); ok {
-				fields = append(fields, &dst.Field{
-					Names: []*dst.Ident{ident},
-					Type:  ast.Ident("any"), // Type constraint for the parameter
-				})
+		indices = t.Indices
+	}
+
+	names := make([]*dst.Ident, 0, len(indices))
+	for _, idx := range indices {
+		if ident, ok := idx.(*dst.Ident); ok {
+			names = append(names, ident)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	constraints := receiverConstraints(names, declared)
+	fields := make([]*dst.Field, 0, len(names))
+	for i, ident := range names {
+		fields = append(fields, &dst.Field{
+			Names: []*dst.Ident{ident},
+			Type:  constraints[i],
+		})
+	}
+	return &dst.FieldList{List: fields}
+}
+
+// receiverConstraints returns the constraint of every receiver type parameter.
+// Declared constraints may refer to the other type parameters, so their names
+// are rewritten to the ones the receiver uses.
+//
+// type Tree[T any, P interface{ *T }] ... func (t *Tree[A, B]) ...
+// ->
+// [any, interface{ *A }]
+func receiverConstraints(names []*dst.Ident, declared *dst.FieldList) []dst.Expr {
+	var declNames []string
+	var declConstraints []dst.Expr
+	if declared != nil {
+		for _, field := range declared.List {
+			for _, name := range field.Names {
+				declNames = append(declNames, name.Name)
+				declConstraints = append(declConstraints, field.Type)
 			}
 		}
-		if len(fields) > 0 {
-			return &dst.FieldList{List: fields}
+	}
+
+	constraints := make([]dst.Expr, len(names))
+	if len(declNames) != len(names) {
+		for i := range constraints {
+			constraints[i] = ast.Ident("any") // Type constraint for the parameter
+		}
+		return constraints
+	}
+	rename := make(map[string]string, len(names))
+	for i, name := range declNames {
+		rename[name] = names[i].Name
+	}
+	for i, constraint := range declConstraints {
+		clone := util.AssertType[dst.Expr](dst.Clone(constraint))
+		dst.Inspect(clone, func(n dst.Node) bool {
+			if ident, ok := n.(*dst.Ident); ok && ident.Path == "" {
+				if to, found := rename[ident.Name]; found {
+					ident.Name = to
+				}
+			}
+			return true
+		})
+		constraints[i] = clone
+	}
+	return constraints
+}
+
+// receiverTypeName returns the name of the receiver's type, e.g. Cache for
+// *Cache[K, V], or "" if the receiver is not a generic type.
+func receiverTypeName(recvType dst.Expr) string {
+	switch t := recvType.(type) {
+	case *dst.StarExpr:
+		return receiverTypeName(t.X)
+	case *dst.IndexExpr:
+		if ident, ok := t.X.(*dst.Ident); ok {
+			return ident.Name
+		}
+	case *dst.IndexListExpr:
+		if ident, ok := t.X.(*dst.Ident); ok {
+			return ident.Name
+		}
+	}
+	return ""
+}
+
+// findTypeParams returns the type parameters of the named type declared in root.
+func findTypeParams(root *dst.File, name string) *dst.FieldList {
+	decl := ast.FindTypeDecl(root, name)
+	if decl == nil {
+		return nil
+	}
+	for _, spec := range decl.Specs {
+		if typeSpec, ok := spec.(*dst.TypeSpec); ok && typeSpec.Name.Name == name {
+			return typeSpec.TypeParams
+		}
+	}
+	return nil
+}
+
+// findRecvTypeParams returns the type parameters declared by the generic type
+// the target function is a method of. The type is looked up in the target file
+// first and then in the other source files of the package, in which case the
+// packages its constraints refer to are imported into the target file.
+func (ip *InstrumentPhase) findRecvTypeParams(funcDecl *dst.FuncDecl) *dst.FieldList {
+	if !ast.HasReceiver(funcDecl) {
+		return nil
+	}
+	name := receiverTypeName(funcDecl.Recv.List[0].Type)
+	if name == "" {
+		return nil
+	}
+	if typeParams := findTypeParams(ip.target, name); typeParams != nil {
+		return typeParams
+	}
+	for _, arg := range ip.compileArgs {
+		if !strings.HasSuffix(arg, ".go") {
+			continue
+		}
+		root, err := ast.NewAstParser().Parse(arg, parser.SkipObjectResolution)
+		if err != nil {
+			ip.Debug("Skipping unparsable file while resolving receiver type", "file", arg, "error", err)
+			continue
+		}
+		if typeParams := findTypeParams(root, name); typeParams != nil {
+			return ip.importConstraintPackages(root, typeParams)
 		}
 	}
 	return nil
 }
 
+// importConstraintPackages returns a copy of typeParams, declared in the other
+// file of the package, whose constraints refer to the packages under the
+// names of the target file. The packages the target file does not import yet
+// are imported under a name that does not clash with its identifiers.
+//
+// type Set[T constraints.Ordered] ... (file importing golang.org/x/exp/constraints)
+// ->
+// [T constraints.Ordered] and import "golang.org/x/exp/constraints" in the target
+func (ip *InstrumentPhase) importConstraintPackages(other *dst.File, typeParams *dst.FieldList) *dst.FieldList {
+	otherImports := collectImportAliases(other)
+	targetImports := collectImportAliases(ip.target)
+	// The names picked so far, until the imports are added
+	added := make(map[string]string)
+	qualified := util.AssertType[*dst.FieldList](dst.Clone(typeParams))
+	dst.Inspect(qualified, func(n dst.Node) bool {
+		sel, ok := n.(*dst.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, ok := sel.X.(*dst.Ident)
+		if !ok || pkg.Path != "" {
+			return true
+		}
+		importPath, found := otherImports[pkg.Name]
+		if !found {
+			return true
+		}
+		alias, imported := packageAlias(ip.target, importPath, targetImports, added)
+		if !imported {
+			added[alias] = importPath
+			targetImports[alias] = importPath
+		}
+		pkg.Name = alias
+		return false
+	})
+	for _, alias := range slices.Sorted(maps.Keys(added)) {
+		ip.target.Decls = append([]dst.Decl{ast.ImportDecl(alias, added[alias])}, ip.target.Decls...)
+	}
+	return qualified
+}
+
 // desugarType desugars parameter type to its original type, if parameter
 // is type of ...T, it will be converted to []T
 func desugarType(param *dst.Field) dst.Expr {
@@ -833,7 +1192,7 @@ func (ip *InstrumentPhase) rewriteHookContextMethods() {
 	}
 
 	// For generic functions, we need to panic the methods that are not supported
-	if findTargetGenericType(ip.targetFunc) != nil {
+	if findTargetGenericType(ip.targetFunc, ip.recvTypeParams) != nil {
 		makeMethodPanic(methodGetParam, "GetParam is unsupported for generic functions")
 		makeMethodPanic(methodGetRetVal, "GetReturnVal is unsupported for generic functions")
 		makeMethodPanic(methodSetParam, "SetParam is unsupported for generic functions")
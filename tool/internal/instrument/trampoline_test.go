@@ -4,10 +4,14 @@
 package instrument
 
 import (
+	"log/slog"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/dave/dst"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/ast"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -188,3 +192,135 @@ func H1Before(ctx hook.HookContext, p1 string, p2 string) {}`,
 		})
 	}
 }
+
+func TestFindRecvTypeParams(t *testing.T) {
+	dir := t.TempDir()
+	typesFile := filepath.Join(dir, "types.go")
+	require.NoError(t, os.WriteFile(typesFile, []byte(`package main
+type Cache[K comparable, V any] struct{ m map[K]V }
+`), 0o644))
+
+	root, err := ast.NewAstParser().ParseSource(`package main
+func (c *Cache[Key, Val]) Get(k Key) Val { return c.m[k] }
+func (o *Orphan[T]) Get() {}
+`)
+	require.NoError(t, err)
+	methods := ast.ListFuncDecls(root)
+	require.Len(t, methods, 2)
+
+	ip := &InstrumentPhase{
+		logger:      slog.Default(),
+		target:      root,
+		compileArgs: []string{"compile", "-p", "main", "main.go", typesFile},
+	}
+
+	constraints := func(fn *dst.FuncDecl) map[string]string {
+		result := make(map[string]string)
+		for _, field := range findTargetGenericType(fn, ip.findRecvTypeParams(fn)).List {
+			result[field.Names[0].Name] = util.AssertType[*dst.Ident](field.Type).Name
+		}
+		return result
+	}
+
+	// Declared in another file of the package, renamed after the receiver
+	assert.Equal(t, map[string]string{"Key": "comparable", "Val": "any"}, constraints(methods[0]))
+	// Unknown declaration falls back to any
+	assert.Equal(t, map[string]string{"T": "any"}, constraints(methods[1]))
+}
+
+func TestFindRecvTypeParams_ImportsConstraintPackages(t *testing.T) {
+	dir := t.TempDir()
+	typesFile := filepath.Join(dir, "types.go")
+	require.NoError(t, os.WriteFile(typesFile, []byte(`package main
+import (
+	"cmp"
+	ordered "golang.org/x/exp/constraints"
+)
+type Index[K cmp.Ordered, V ordered.Integer] struct{ m map[K]V }
+`), 0o644))
+
+	// The target file imports one of the packages under another name, and
+	// uses the name of the other for a parameter
+	root, err := ast.NewAstParser().ParseSource(`package main
+import "golang.org/x/exp/constraints"
+func (i *Index[Key, Val]) Get(cmp Key) Val { return i.m[cmp] }
+var _ constraints.Signed
+`)
+	require.NoError(t, err)
+	method := ast.ListFuncDecls(root)[0]
+
+	ip := &InstrumentPhase{
+		logger:      slog.Default(),
+		target:      root,
+		compileArgs: []string{"compile", "-p", "main", "main.go", typesFile},
+	}
+	constraints := make(map[string]string)
+	for _, field := range findTargetGenericType(method, ip.findRecvTypeParams(method)).List {
+		sel := util.AssertType[*dst.SelectorExpr](field.Type)
+		constraints[field.Names[0].Name] = util.AssertType[*dst.Ident](sel.X).Name + "." + sel.Sel.Name
+	}
+
+	assert.Equal(t, map[string]string{"Key": "otelcmp.Ordered", "Val": "constraints.Integer"}, constraints)
+	assert.Equal(t, map[string]string{
+		"otelcmp":     "cmp",
+		"constraints": "golang.org/x/exp/constraints",
+	}, collectImportAliases(root))
+}
+
+func TestReceiverConstraints_Renamed(t *testing.T) {
+	root, err := ast.NewAstParser().ParseSource(`package main
+type Tree[T any, P interface{ *T }] struct{ root P }
+func (t Tree[A, B]) Root() B { return t.root }
+`)
+	require.NoError(t, err)
+	method := ast.ListFuncDecls(root)[0]
+
+	typeParams := extractReceiverTypeParams(method.Recv.List[0].Type, findTypeParams(root, "Tree"))
+	require.Len(t, typeParams.List, 2)
+	assert.Equal(t, "A", typeParams.List[0].Names[0].Name)
+	iface := util.AssertType[*dst.InterfaceType](typeParams.List[1].Type)
+	star := util.AssertType[*dst.StarExpr](iface.Methods.List[0].Type)
+	assert.Equal(t, "A", util.AssertType[*dst.Ident](star.X).Name, "constraint refers to the receiver's name")
+}
+
+func TestQualifiedFuncName(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{"function", "func Checkout() {}", "example.com/shop/orders.Checkout"},
+		{"value receiver", "func (s Service) Checkout() {}", "example.com/shop/orders.Service.Checkout"},
+		{"pointer receiver", "func (s *Service) Checkout() {}", "example.com/shop/orders.(*Service).Checkout"},
+		{"generic receiver", "func (s *Cache[K, V]) Get() {}", "example.com/shop/orders.(*Cache[...]).Get"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := ast.NewAstParser().ParseSource("package orders\n\n" + tt.source)
+			require.NoError(t, err)
+			decl, ok := file.Decls[0].(*dst.FuncDecl)
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, qualifiedFuncName("example.com/shop/orders", decl))
+		})
+	}
+}
+
+func TestTrimPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		rewrites string
+		expected string
+	}{
+		{"no rewrites", "/src/app/main.go", "", "/src/app/main.go"},
+		{"strip prefix", "/src/app/main.go", "/src/app", "main.go"},
+		{"rewrite prefix", "/src/app/main.go", "/src/app=>example.com/app", "example.com/app/main.go"},
+		{"first match wins", "/src/app/main.go", "/other;/src=>src;/src/app=>app", "src/app/main.go"},
+		{"partial element", "/src/application/main.go", "/src/app=>app", "/src/application/main.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, trimPath(tt.path, tt.rewrites))
+		})
+	}
+}
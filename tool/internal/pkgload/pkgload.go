@@ -6,6 +6,8 @@ package pkgload
 
 import (
 	"context"
+	"slices"
+	"strings"
 
 	"golang.org/x/tools/go/packages"
 
@@ -55,28 +57,73 @@ func ResolvePackageName(ctx context.Context, importPath string, buildFlags ...st
 	return pkg.Name
 }
 
+// cgoRuntime is the package that every package using cgo depends on. go list
+// drops the "C" import of those packages, and with it the edge to runtime/cgo.
+const cgoRuntime = "runtime/cgo"
+
 // ResolveExportFiles returns importPath -> exportFile for a package and all
-// transitive dependencies.
+// transitive dependencies. Dependencies reported without an export file are
+// loaded again on their own, and an error names those that still lack one.
+// runtime/cgo is resolved along, when cgo is enabled, as the dependency graph
+// go list reports leaves it out.
 func ResolveExportFiles(ctx context.Context, importPath string, buildFlags ...string) (map[string]string, error) {
 	mode := packages.NeedName | packages.NeedImports | packages.NeedDeps | packages.NeedExportFile
-	pkgs, err := LoadPackages(ctx, mode, buildFlags, importPath)
+	pkgs, err := LoadPackages(ctx, mode, buildFlags, importPath, cgoRuntime)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(pkgs) == 0 {
-		return nil, ex.Newf("no packages found for %q", importPath)
-	}
-
 	// Check for package-level errors
+	roots := make([]*packages.Package, 0, len(pkgs))
 	for _, pkg := range pkgs {
+		if pkg.PkgPath == cgoRuntime && importPath != cgoRuntime {
+			// runtime/cgo does not build without cgo, nor is it needed then
+			if len(pkg.Errors) == 0 {
+				roots = append(roots, pkg)
+			}
+			continue
+		}
 		if len(pkg.Errors) > 0 {
 			return nil, ex.Newf("loading package %q: %v", importPath, pkg.Errors[0])
 		}
+		roots = append(roots, pkg)
+	}
+	if len(roots) == 0 {
+		return nil, ex.Newf("no packages found for %q", importPath)
+	}
+
+	result, missing := collectExportFiles(roots)
+
+	// Verify we found the requested package
+	if _, found := result[importPath]; !found {
+		return nil, ex.Newf("package %q not found or has no export file", importPath)
 	}
 
+	if len(missing) > 0 {
+		// go list occasionally reports a dependency without its archive, load
+		// the missing packages on their own so that they get built
+		retried, err := LoadPackages(ctx, packages.NeedName|packages.NeedExportFile, buildFlags, missing...)
+		if err != nil {
+			return nil, ex.Wrapf(err, "resolving export files of %v", missing)
+		}
+		fillExportFiles(result, retried)
+	}
+	if err := checkExportFiles(importPath, result, missing); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// collectExportFiles walks pkgs and their transitive imports and returns
+// importPath -> exportFile for every package that has an export file, along
+// with the sorted import paths of the packages that lack one.
+//
+//nolint:revive // if we add named returns then nonamedreturns will complain
+func collectExportFiles(pkgs []*packages.Package) (map[string]string, []string) {
 	result := make(map[string]string)
 	visited := make(map[string]bool)
+	var missing []string
 
 	var walk func(pkg *packages.Package)
 	walk = func(pkg *packages.Package) {
@@ -85,8 +132,13 @@ func ResolveExportFiles(ctx context.Context, importPath string, buildFlags ...st
 		}
 		visited[pkg.PkgPath] = true
 
-		if pkg.ExportFile != "" {
+		switch {
+		case pkg.ExportFile != "":
 			result[pkg.PkgPath] = pkg.ExportFile
+		case pkg.PkgPath != "unsafe" && pkg.PkgPath != "C":
+			// unsafe is built-in, C is the cgo pseudo-package; neither has an
+			// archive file
+			missing = append(missing, pkg.PkgPath)
 		}
 
 		for _, dep := range pkg.Imports {
@@ -97,13 +149,35 @@ func ResolveExportFiles(ctx context.Context, importPath string, buildFlags ...st
 	for _, pkg := range pkgs {
 		walk(pkg)
 	}
+	slices.Sort(missing)
 
-	// Verify we found the requested package
-	if _, found := result[importPath]; !found {
-		return nil, ex.Newf("package %q not found or has no export file", importPath)
+	return result, missing
+}
+
+// fillExportFiles adds the export files of pkgs that are absent from result.
+func fillExportFiles(result map[string]string, pkgs []*packages.Package) {
+	for _, pkg := range pkgs {
+		if _, found := result[pkg.PkgPath]; !found && pkg.ExportFile != "" {
+			result[pkg.PkgPath] = pkg.ExportFile
+		}
 	}
+}
 
-	return result, nil
+// checkExportFiles reports the packages of missing that still have no export
+// file in result. Leaving them out of the importcfg would fail the compile
+// with a bare "missing package" error, so name them instead.
+func checkExportFiles(importPath string, result map[string]string, missing []string) error {
+	var unresolved []string
+	for _, pkg := range missing {
+		if _, found := result[pkg]; !found {
+			unresolved = append(unresolved, pkg)
+		}
+	}
+	if len(unresolved) == 0 {
+		return nil
+	}
+	return ex.Newf("no export file for %s, needed by %q; make sure they build with the current flags",
+		strings.Join(unresolved, ", "), importPath)
 }
 
 // ResolveModuleDir returns the module directory for a given package directory.
@@ -5,7 +5,9 @@ package pkgload
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -96,6 +98,22 @@ func TestResolveExportFiles_MultiplePackages(t *testing.T) {
 	t.Logf("Resolved %d packages for net/http", len(archives))
 }
 
+func TestResolveExportFiles_Cgo(t *testing.T) {
+	ctx := t.Context()
+	out, err := exec.CommandContext(ctx, "go", "env", "CGO_ENABLED").Output()
+	require.NoError(t, err)
+	if strings.TrimSpace(string(out)) != "1" {
+		t.Skip("cgo is disabled")
+	}
+
+	// net uses cgo, so it depends on runtime/cgo although it does not
+	// import it
+	archives, err := ResolveExportFiles(ctx, "net")
+	require.NoError(t, err)
+	assert.Contains(t, archives, "runtime/cgo")
+	assert.FileExists(t, archives["runtime/cgo"], "runtime/cgo export file should exist")
+}
+
 func TestResolveExportFiles_NoExportFile(t *testing.T) {
 	ctx := t.Context()
 
@@ -106,6 +124,40 @@ func TestResolveExportFiles_NoExportFile(t *testing.T) {
 	assert.Nil(t, archives)
 }
 
+func TestCollectExportFiles_EmptyExport(t *testing.T) {
+	unsafePkg := &packages.Package{PkgPath: "unsafe"}
+	noArchive := &packages.Package{PkgPath: "example.com/noarchive"}
+	dep := &packages.Package{
+		PkgPath:    "example.com/dep",
+		ExportFile: "/cache/dep.a",
+		Imports:    map[string]*packages.Package{"example.com/noarchive": noArchive},
+	}
+	root := &packages.Package{
+		PkgPath:    "example.com/root",
+		ExportFile: "/cache/root.a",
+		Imports: map[string]*packages.Package{
+			"example.com/dep": dep,
+			"unsafe":          unsafePkg,
+		},
+	}
+
+	result, missing := collectExportFiles([]*packages.Package{root})
+	assert.Equal(t, map[string]string{
+		"example.com/root": "/cache/root.a",
+		"example.com/dep":  "/cache/dep.a",
+	}, result)
+	assert.Equal(t, []string{"example.com/noarchive"}, missing, "unsafe has no archive by design")
+
+	err := checkExportFiles("example.com/root", result, missing)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `no export file for example.com/noarchive, needed by "example.com/root"`)
+
+	// The targeted reload found the archive
+	fillExportFiles(result, []*packages.Package{{PkgPath: "example.com/noarchive", ExportFile: "/cache/noarchive.a"}})
+	assert.Equal(t, "/cache/noarchive.a", result["example.com/noarchive"])
+	require.NoError(t, checkExportFiles("example.com/root", result, missing))
+}
+
 func TestResolveModuleDir(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -19,11 +19,12 @@ import (
 // bound is exclusive. For example, "v1.0.0,v2.0.0" means the rule is applicable
 // to the target module version range [v1.0.0, v2.0.0).
 type InstRule interface {
-	String() string      // The string representation of the rule
-	GetName() string     // The unique name of the rule
-	GetTarget() string   // The target module path where the rule is applied
-	GetVersion() string  // The version range of target module if available, e.g "v1.0.0,v2.0.0"
-	GetWhere() *WhereDef // Optional non-package selectors that remain after normalization
+	String() string       // The string representation of the rule
+	GetName() string      // The unique name of the rule
+	GetTarget() string    // The target module path where the rule is applied
+	GetVersion() string   // The version range of target module if available, e.g "v1.0.0,v2.0.0"
+	GetWhere() *WhereDef  // Optional non-package selectors that remain after normalization
+	GetEnabledIf() string // Optional condition on the build environment, e.g. "APP_ENV=prod"
 }
 
 // FilterDef describes file predicates nested under where.file.
@@ -87,13 +88,17 @@ type InstBaseRule struct {
 	Version string            `json:"version,omitempty" yaml:"version,omitempty"`
 	Imports map[string]string `json:"imports,omitempty" yaml:"imports,omitempty"` // map[alias]path
 	Where   *WhereDef         `json:"where,omitempty"   yaml:"where,omitempty"`
+	// EnabledIf applies the rule only when the build environment meets the
+	// condition, see EnabledIfMet.
+	EnabledIf string `json:"enabled_if,omitempty" yaml:"enabled_if,omitempty"`
 }
 
-func (ibr *InstBaseRule) String() string      { return ibr.Name }
-func (ibr *InstBaseRule) GetName() string     { return ibr.Name }
-func (ibr *InstBaseRule) GetTarget() string   { return ibr.Target }
-func (ibr *InstBaseRule) GetVersion() string  { return ibr.Version }
-func (ibr *InstBaseRule) GetWhere() *WhereDef { return ibr.Where }
+func (ibr *InstBaseRule) String() string       { return ibr.Name }
+func (ibr *InstBaseRule) GetName() string      { return ibr.Name }
+func (ibr *InstBaseRule) GetTarget() string    { return ibr.Target }
+func (ibr *InstBaseRule) GetVersion() string   { return ibr.Version }
+func (ibr *InstBaseRule) GetWhere() *WhereDef  { return ibr.Where }
+func (ibr *InstBaseRule) GetEnabledIf() string { return ibr.EnabledIf }
 
 // InstRuleSet represents a collection of instrumentation rules that apply to a
 // single Go package within a specific module. It acts as a container for rules,
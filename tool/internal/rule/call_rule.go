@@ -47,6 +47,11 @@ type InstCallRule struct {
 	// This field is populated during rule creation from FunctionCall.
 	FuncName string `json:"func-name" yaml:"-"`
 
+	// Caller restricts the rule to the calls made in the bodies of the listed
+	// functions of the target package; methods are listed by their name
+	// alone. Every matching call of the package is wrapped when it is empty.
+	Caller []string `json:"caller" yaml:"caller"`
+
 	// Replace is the wrapper code with {{ . }} as placeholder for the original call.
 	// The replacement must be a valid Go expression. The output may be any
 	// expression type; it is not required to be a call expression.
@@ -66,6 +71,14 @@ type InstCallRule struct {
 	// When set and the call is ellipsis, an IIFE wrapper is generated.
 	// When unset and the call is ellipsis, the call is skipped with a warning.
 	VariadicType string `json:"variadic_type" yaml:"variadic_type"`
+
+	// WrapInterface is the qualified interface type (e.g. "net/http.Handler")
+	// the matched call's result is converted to before Replace wraps it. It
+	// lets a call rule on a constructor hand the constructed value to a
+	// wrapper that takes the interface, whatever its concrete type, so that
+	// every method dispatched through the interface reaches the wrapper. The
+	// matched call must return a single value implementing the interface.
+	WrapInterface string `json:"wrap_interface" yaml:"wrap_interface"`
 }
 
 // funcNamePattern matches qualified function names like "net/http.Get".
@@ -137,14 +150,40 @@ func (r *InstCallRule) validate() error {
 	if strings.TrimSpace(r.Replace) != "" && !replacePlaceholderPattern.MatchString(r.Replace) {
 		return ex.Newf("replace must contain {{ . }} placeholder (also accepts {{.}}, {{- . -}}, etc.)")
 	}
+	for i, caller := range r.Caller {
+		if strings.TrimSpace(caller) == "" {
+			return ex.Newf("caller[%d] must be a non-empty function name", i)
+		}
+	}
 	for i, arg := range r.AppendArgs {
 		if strings.TrimSpace(arg) == "" {
 			return ex.Newf("append_args[%d] must be a non-empty string", i)
 		}
 	}
+	if r.WrapInterface != "" {
+		if strings.TrimSpace(r.Replace) == "" {
+			return ex.Newf("wrap_interface requires replace to name the wrapper")
+		}
+		if _, _, ok := r.InterfaceType(); !ok {
+			return ex.Newf("invalid wrap_interface format: %q (expected 'package/path.InterfaceName')",
+				r.WrapInterface)
+		}
+	}
 	return nil
 }
 
+// InterfaceType splits WrapInterface into the import path and name of the
+// interface. It reports false if WrapInterface is not a qualified type name.
+//
+//nolint:revive // if we add named returns then nonamedreturns will complain
+func (r *InstCallRule) InterfaceType() (string, string, bool) {
+	matches := funcNamePattern.FindStringSubmatch(r.WrapInterface)
+	if matches == nil {
+		return "", "", false
+	}
+	return matches[1], matches[2], true
+}
+
 // UnmarshalJSON implements json.Unmarshaler to ensure derived fields are populated
 // after JSON deserialization.
 func (r *InstCallRule) UnmarshalJSON(data []byte) error {
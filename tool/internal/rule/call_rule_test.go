@@ -75,6 +75,65 @@ append_args: ["ctx"]
 				assert.NotEmpty(t, r.AppendArgs)
 			},
 		},
+		{
+			name: "wrap_interface",
+			yaml: `
+function_call: net/http.HandlerFunc
+replace: "wrapHandler({{ . }})"
+wrap_interface: net/http.Handler
+`,
+			ruleName: "wrap_handler",
+			check: func(t *testing.T, r *InstCallRule) {
+				importPath, name, ok := r.InterfaceType()
+				require.True(t, ok)
+				assert.Equal(t, "net/http", importPath)
+				assert.Equal(t, "Handler", name)
+			},
+		},
+		{
+			name: "wrap_interface without replace",
+			yaml: `
+function_call: net/http.HandlerFunc
+append_args: ["ctx"]
+wrap_interface: net/http.Handler
+`,
+			ruleName:    "bad",
+			wantErr:     true,
+			errContains: "wrap_interface requires replace",
+		},
+		{
+			name: "invalid wrap_interface format",
+			yaml: `
+function_call: net/http.HandlerFunc
+replace: "wrapHandler({{ . }})"
+wrap_interface: Handler
+`,
+			ruleName:    "bad",
+			wantErr:     true,
+			errContains: "invalid wrap_interface format",
+		},
+		{
+			name: "caller",
+			yaml: `
+function_call: time.Sleep
+caller: [Checkout, Retry]
+replace: "timed(func() { {{ . }} })"
+`,
+			ruleName: "time_sleeps",
+			check: func(t *testing.T, r *InstCallRule) {
+				assert.Equal(t, []string{"Checkout", "Retry"}, r.Caller)
+			},
+		},
+		{
+			name: "empty caller entry",
+			yaml: `
+function_call: time.Sleep
+caller: [""]
+replace: "timed(func() { {{ . }} })"
+`,
+			wantErr:     true,
+			errContains: "caller[0] must be a non-empty function name",
+		},
 		{
 			name: "name from YAML overrides argument",
 			yaml: `
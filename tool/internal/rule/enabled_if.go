@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package rule
+
+import (
+	"os"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
+)
+
+// An enabled_if condition compares an environment variable of the build with
+// a value, so that one rule file can enable expensive rules only in some
+// environments:
+//
+//	APP_ENV=prod    the variable is set to prod
+//	APP_ENV!=dev    the variable is not set to dev, or is unset
+//	APP_ENV         the variable is set to a non-empty value
+const enabledIfNotEqual = "!="
+
+// parseEnabledIf splits cond into the name of the variable, the value it is
+// compared with and whether the comparison is negated. hasValue is false for
+// a bare variable name.
+func parseEnabledIf(cond string) (name, value string, negate, hasValue bool, err error) {
+	cond = strings.TrimSpace(cond)
+	if i := strings.Index(cond, enabledIfNotEqual); i >= 0 {
+		name, value, negate, hasValue = cond[:i], cond[i+len(enabledIfNotEqual):], true, true
+	} else {
+		name, value, hasValue = strings.Cut(cond, "=")
+	}
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if name == "" || strings.ContainsAny(name, " \t=!") {
+		return "", "", false, false, ex.Newf("enabled_if %q must be NAME=value, NAME!=value or NAME", cond)
+	}
+	return name, value, negate, hasValue, nil
+}
+
+// ValidateEnabledIf rejects malformed enabled_if conditions at load time, so
+// that a typo does not silently disable a rule. An empty condition is valid
+// and always met.
+func ValidateEnabledIf(cond string) error {
+	if cond == "" {
+		return nil
+	}
+	_, _, _, _, err := parseEnabledIf(cond)
+	return err
+}
+
+// EnabledIfMet reports whether the enabled_if condition cond holds for the
+// environment of the build. An empty condition is always met; a malformed one,
+// which ValidateEnabledIf rejects at load time, never is.
+func EnabledIfMet(cond string) bool {
+	if cond == "" {
+		return true
+	}
+	name, value, negate, hasValue, err := parseEnabledIf(cond)
+	if err != nil {
+		return false
+	}
+	actual := os.Getenv(name)
+	if !hasValue {
+		return actual != ""
+	}
+	return (actual == value) != negate
+}
@@ -0,0 +1,47 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package rule_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
+)
+
+func TestEnabledIfMet(t *testing.T) {
+	t.Setenv("APP_ENV", "prod")
+	t.Setenv("EMPTY_VAR", "")
+	tests := []struct {
+		cond string
+		want bool
+	}{
+		{"", true},
+		{"APP_ENV=prod", true},
+		{"APP_ENV = prod", true},
+		{"APP_ENV=dev", false},
+		{"APP_ENV!=dev", true},
+		{"APP_ENV!=prod", false},
+		{"APP_ENV", true},
+		{"EMPTY_VAR", false},
+		{"UNSET_VAR!=prod", true},
+		{"UNSET_VAR=", true},
+		{"=prod", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cond, func(t *testing.T) {
+			assert.Equal(t, tt.want, rule.EnabledIfMet(tt.cond))
+		})
+	}
+}
+
+func TestValidateEnabledIf(t *testing.T) {
+	for _, cond := range []string{"", "APP_ENV=prod", "APP_ENV!=dev", "APP_ENV"} {
+		assert.NoError(t, rule.ValidateEnabledIf(cond), cond)
+	}
+	for _, cond := range []string{"=prod", "!=dev", "APP ENV=prod", "  "} {
+		assert.Error(t, rule.ValidateEnabledIf(cond), cond)
+	}
+}
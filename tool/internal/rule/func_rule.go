@@ -24,6 +24,14 @@ type FuncSignature struct {
 	Returns []string `json:"returns,omitempty" yaml:"returns"`
 }
 
+// FuncAny is the func of a rule targeting every function that satisfies its
+// signature filters, whatever its name, e.g. all the HTTP handlers:
+//
+//	func: "*"
+//	signature:
+//	  args: [http.ResponseWriter, "*http.Request"]
+const FuncAny = "*"
+
 // InstFuncRule represents a rule that guides hook function injection into
 // appropriate target function locations. For example, if we want to inject
 // custom Foo function at the entry of target function Bar, we can define a rule:
@@ -46,6 +54,33 @@ type FuncSignature struct {
 //	result: error
 //	last_result: error
 //	param: context.Context
+//
+// When several functions share the name and receiver, e.g. multiple init
+// functions, anchor selects the one whose body contains the given substring:
+//
+//	anchor: "registerDriver(\"mysql\""
+//
+// min_statements leaves out functions too small to be worth the overhead,
+// such as trivial getters:
+//
+//	min_statements: 3
+//
+// span_name overrides the name of the span started by the Before hook, with
+// {placeholders} for the values the hook captures:
+//
+//	span_name: "{operation} {table}"
+//
+// code_location hands the file and line declaring the target function, known
+// when it is instrumented, to the Before hook, which records them as the
+// code.* attributes of its span:
+//
+//	code_location: true
+//
+// capture_panic recovers a panic the target function raises before its After
+// hook runs, hands the panic value to the hook and raises it again once the
+// hook returned, so that the hook can describe the failure:
+//
+//	capture_panic: true
 type InstFuncRule struct {
 	InstBaseRule `yaml:",inline"`
 
@@ -65,6 +100,26 @@ type InstFuncRule struct {
 	Result            string         `json:"result,omitempty"             yaml:"result"`
 	LastResult        string         `json:"last_result,omitempty"        yaml:"last_result"`
 	Param             string         `json:"param,omitempty"              yaml:"param"`
+
+	// Optional source anchor: a substring of a line in the body of the target
+	// function, used to pick one among same-named functions.
+	Anchor string `json:"anchor,omitempty" yaml:"anchor"`
+
+	// Optional size threshold: functions whose body holds fewer statements,
+	// nested ones included, are left uninstrumented.
+	MinStatements int `json:"min_statements,omitempty" yaml:"min_statements"`
+
+	// Optional span name template, passed to the Before hook through its
+	// HookContext, which renders it with the values it captured.
+	SpanName string `json:"span_name,omitempty" yaml:"span_name"`
+
+	// Optional: pass the declaration site of the target function to the Before
+	// hook through its HookContext, instead of looking it up at run time.
+	CodeLocation bool `json:"code_location,omitempty" yaml:"code_location"`
+
+	// Optional: pass the value the target function panicked with to the After
+	// hook through its HookContext, then keep panicking.
+	CapturePanic bool `json:"capture_panic,omitempty" yaml:"capture_panic"`
 }
 
 // NewInstFuncRule loads and validates an InstFuncRule from YAML data.
@@ -89,6 +144,10 @@ func (r *InstFuncRule) validate() error {
 	if strings.TrimSpace(r.Func) == "" {
 		return ex.Newf("func cannot be empty")
 	}
+	if r.Func == FuncAny && !r.hasSignatureFilter() {
+		return ex.Newf("func %q requires a signature, signature_contains, result, last_result or param filter",
+			FuncAny)
+	}
 	if strings.TrimSpace(r.Before) == "" && strings.TrimSpace(r.After) == "" {
 		return ex.Newf("before or after must be set")
 	}
@@ -98,9 +157,30 @@ func (r *InstFuncRule) validate() error {
 	if r.Path != r.ModulePath && !strings.HasPrefix(r.Path, r.ModulePath+"/") {
 		return ex.Newf("import path %q is not part of module path %q", r.Path, r.ModulePath)
 	}
+	if r.MinStatements < 0 {
+		return ex.Newf("min_statements cannot be negative, got %d", r.MinStatements)
+	}
+	if r.SpanName != "" && strings.TrimSpace(r.Before) == "" {
+		return ex.Newf("span_name requires a before hook, which starts the span")
+	}
+	if r.CodeLocation && strings.TrimSpace(r.Before) == "" {
+		return ex.Newf("code_location requires a before hook, which starts the span")
+	}
+	if r.CapturePanic && strings.TrimSpace(r.After) == "" {
+		return ex.Newf("capture_panic requires an after hook, which reads the panic")
+	}
+	if strings.Count(r.SpanName, "{") != strings.Count(r.SpanName, "}") {
+		return ex.Newf("span_name %q has unbalanced braces", r.SpanName)
+	}
 	return nil
 }
 
+// hasSignatureFilter reports whether any of the signature sub-filters is set.
+func (r *InstFuncRule) hasSignatureFilter() bool {
+	return r.Signature != nil || r.SignatureContains != nil ||
+		r.Result != "" || r.LastResult != "" || r.Param != ""
+}
+
 // Identity returns a content-derived key used to generate trampoline and
 // HookContext names. It is a function purely of what the rule does — its
 // target, function/receiver, before/after hooks, hook path, and signature
@@ -146,5 +226,21 @@ func (r *InstFuncRule) Identity() string {
 		enc(r.Result), enc(r.LastResult), enc(r.Param),
 		encSig(r.Signature), encSig(r.SignatureContains),
 	}
+	// Appended only when set, so rules without an anchor keep their identity.
+	if r.Anchor != "" {
+		parts = append(parts, enc(r.Anchor))
+	}
+	if r.MinStatements > 0 {
+		parts = append(parts, "min_statements"+strconv.Itoa(r.MinStatements))
+	}
+	if r.SpanName != "" {
+		parts = append(parts, "span_name"+enc(r.SpanName))
+	}
+	if r.CodeLocation {
+		parts = append(parts, "code_location")
+	}
+	if r.CapturePanic {
+		parts = append(parts, "capture_panic")
+	}
 	return util.CRC32(strings.Join(parts, ""))
 }
@@ -167,6 +167,137 @@ target: example.com/pkg
 before: MyBefore
 path: github.com/example/instrumentation/net/http/client
 module: github.com/example/pkg
+`,
+			wantErr: true,
+		},
+		{
+			name: "rule with min_statements",
+			yaml: `
+func: MyFunc
+target: example.com/pkg
+before: MyBefore
+path: example.com/pkg
+min_statements: 3
+`,
+			check: func(t *testing.T, r *InstFuncRule) {
+				assert.Equal(t, 3, r.MinStatements)
+			},
+		},
+		{
+			name: "negative min_statements",
+			yaml: `
+func: MyFunc
+target: example.com/pkg
+before: MyBefore
+path: example.com/pkg
+min_statements: -1
+`,
+			wantErr: true,
+		},
+		{
+			name: "rule with span_name",
+			yaml: `
+func: Query
+target: example.com/pkg
+before: BeforeQuery
+path: example.com/pkg
+span_name: "{operation} {table}"
+`,
+			check: func(t *testing.T, r *InstFuncRule) {
+				assert.Equal(t, "{operation} {table}", r.SpanName)
+			},
+		},
+		{
+			name: "span_name without before hook",
+			yaml: `
+func: Query
+target: example.com/pkg
+after: AfterQuery
+path: example.com/pkg
+span_name: "{operation}"
+`,
+			wantErr: true,
+		},
+		{
+			name: "span_name with unbalanced braces",
+			yaml: `
+func: Query
+target: example.com/pkg
+before: BeforeQuery
+path: example.com/pkg
+span_name: "{operation"
+`,
+			wantErr: true,
+		},
+		{
+			name: "rule with code_location",
+			yaml: `
+func: Checkout
+target: example.com/pkg
+before: BeforeCheckout
+path: example.com/pkg
+code_location: true
+`,
+			check: func(t *testing.T, r *InstFuncRule) {
+				assert.True(t, r.CodeLocation)
+			},
+		},
+		{
+			name: "code_location without before hook",
+			yaml: `
+func: Checkout
+target: example.com/pkg
+after: AfterCheckout
+path: example.com/pkg
+code_location: true
+`,
+			wantErr: true,
+		},
+		{
+			name: "rule with capture_panic",
+			yaml: `
+func: ServeHTTP
+target: example.com/pkg
+after: AfterServeHTTP
+path: example.com/pkg
+capture_panic: true
+`,
+			check: func(t *testing.T, r *InstFuncRule) {
+				assert.True(t, r.CapturePanic)
+			},
+		},
+		{
+			name: "capture_panic without after hook",
+			yaml: `
+func: ServeHTTP
+target: example.com/pkg
+before: BeforeServeHTTP
+path: example.com/pkg
+capture_panic: true
+`,
+			wantErr: true,
+		},
+		{
+			name: "any func with signature",
+			yaml: `
+func: "*"
+target: example.com/pkg
+before: BeforeHandler
+path: example.com/pkg
+signature:
+  args: [http.ResponseWriter, "*http.Request"]
+`,
+			check: func(t *testing.T, r *InstFuncRule) {
+				assert.Equal(t, FuncAny, r.Func)
+			},
+		},
+		{
+			name: "any func without signature filter",
+			yaml: `
+func: "*"
+target: example.com/pkg
+before: BeforeHandler
+path: example.com/pkg
 `,
 			wantErr: true,
 		},
@@ -259,4 +390,38 @@ func TestInstFuncRule_Identity(t *testing.T) {
 	sigC["signature"] = map[string]any{"args": []any{"context.Context"}, "returns": []any{"error"}}
 	assert.Equal(t, ruleIdentity(t, "sig", sigA), ruleIdentity(t, "sig", sigC),
 		"identical signature filters must yield identical identity")
+
+	// (e) Anchors tell apart hooks on same-named functions.
+	anchorA := base()
+	anchorA["before"] = "H1"
+	anchorA["anchor"] = `register("json")`
+	anchorB := base()
+	anchorB["before"] = "H1"
+	anchorB["anchor"] = `register("yaml")`
+	assert.NotEqual(t, ruleIdentity(t, "init", anchorA), ruleIdentity(t, "init", anchorB),
+		"rules differing only in anchor must have distinct identities")
+
+	// (f) Size thresholds tell apart otherwise identical hooks.
+	small := base()
+	small["before"] = "H1"
+	small["min_statements"] = 3
+	large := base()
+	large["before"] = "H1"
+	large["min_statements"] = 10
+	assert.NotEqual(t, ruleIdentity(t, "f", small), ruleIdentity(t, "f", large),
+		"rules differing only in min_statements must have distinct identities")
+	plain := base()
+	plain["before"] = "H1"
+	assert.NotEqual(t, ruleIdentity(t, "f", plain), ruleIdentity(t, "f", small),
+		"a threshold must change the identity")
+
+	// (g) Span name templates are rendered into the trampoline.
+	spanA := base()
+	spanA["before"] = "H1"
+	spanA["span_name"] = "{operation}"
+	spanB := base()
+	spanB["before"] = "H1"
+	spanB["span_name"] = "{operation} {table}"
+	assert.NotEqual(t, ruleIdentity(t, "span", spanA), ruleIdentity(t, "span", spanB),
+		"rules differing only in span_name must have distinct identities")
 }
@@ -34,6 +34,11 @@ const (
 	SelResult            = "result"
 	SelLastResult        = "last_result"
 	SelParam             = "param"
+	SelAnchor            = "anchor"
+	SelMinStatements     = "min_statements"
+
+	// Call-site narrowing selector for call rules (see InstCallRule).
+	SelCaller = "caller"
 
 	// Raw match-narrowing selector for raw rules (see InstRawRule).
 	SelPattern   = "pattern"
@@ -162,7 +167,7 @@ func normalizeWhere(common, where map[string]any) (map[string]any, error) {
 		switch key {
 		case SelFunc, SelRecv, SelStruct, SelFunctionCall, SelDirective, SelKind, SelIdentifier,
 			SelSignature, SelSignatureContains, SelResult, SelLastResult, SelParam,
-			SelPattern, SelPlacement:
+			SelAnchor, SelMinStatements, SelPattern, SelPlacement, SelCaller:
 			common[key] = value
 		case WhereFile:
 			if _, ok := value.(map[string]any); !ok {
@@ -42,13 +42,24 @@ func NewInstRawRule(data []byte, name string) (*InstRawRule, error) {
 	if r.Name == "" {
 		r.Name = name
 	}
-	if err := r.validate(); err != nil {
+	// Raw rules have no anchor field, decode it separately so that it is
+	// rejected rather than silently ignored.
+	var unsupported struct {
+		Anchor string `yaml:"anchor"`
+	}
+	if err := yaml.Unmarshal(data, &unsupported); err != nil {
+		return nil, ex.Wrap(err)
+	}
+	if err := r.validate(unsupported.Anchor); err != nil {
 		return nil, ex.Wrapf(err, "invalid raw rule %q", name)
 	}
 	return &r, nil
 }
 
-func (r *InstRawRule) validate() error {
+func (r *InstRawRule) validate(anchor string) error {
+	if anchor != "" {
+		return ex.Newf("anchor is not supported by raw rules, use pattern to locate the code instead")
+	}
 	if strings.TrimSpace(r.Raw) == "" {
 		return ex.Newf("raw cannot be empty")
 	}
@@ -0,0 +1,61 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInstRawRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "valid rule",
+			yaml: `
+target: main
+func: Bar
+raw: println("hello")
+pattern: '^name := getName\(\)$'
+placement: after
+`,
+		},
+		{
+			name: "empty raw",
+			yaml: `
+target: main
+func: Bar
+raw: "  "
+`,
+			wantErr: "raw cannot be empty",
+		},
+		{
+			name: "anchor is rejected",
+			yaml: `
+target: main
+func: init
+anchor: register("yaml")
+raw: println("hello")
+`,
+			wantErr: "anchor is not supported by raw rules",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewInstRawRule([]byte(tt.yaml), "raw")
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "raw", r.Name)
+		})
+	}
+}
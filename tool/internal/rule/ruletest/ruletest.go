@@ -0,0 +1,131 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ruletest checks instrumentation rules against the real packages
+// they target. A rule whose target declaration was renamed, removed or had
+// its signature changed upstream only produces a warning at build time, and
+// the instrumentation is silently lost; checking the rules in a test of the
+// instrumentation module catches that when its dependency is upgraded.
+package ruletest
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/ast"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/setup"
+)
+
+// Unmatched returns the rules that match no declaration of the package they
+// target, resolved from the module in dir, i.e. at the version that module
+// requires. Declarations are matched as in the setup phase, signature filters
+// and anchors included.
+//
+// Call and file rules, which match no particular declaration, and glob
+// targets, which are expected to miss in most packages, are not checked.
+func Unmatched(ctx context.Context, dir string, rules []rule.InstRule) ([]rule.InstRule, error) {
+	sources := make(map[string][]string)
+	var unmatched []rule.InstRule
+	for _, r := range rules {
+		switch r.(type) {
+		case *rule.InstCallRule, *rule.InstFileRule:
+			continue
+		}
+		target := r.GetTarget()
+		if rule.IsGlobTarget(target) {
+			continue
+		}
+		files, ok := sources[target]
+		if !ok {
+			var err error
+			files, err = loadSources(ctx, dir, target)
+			if err != nil {
+				return nil, err
+			}
+			sources[target] = files
+		}
+		matched, err := matchAny(files, r)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			unmatched = append(unmatched, r)
+		}
+	}
+	return unmatched, nil
+}
+
+// RequireTargets fails the test if a rule of the rule files and directories
+// in ruleConfig matches nothing in the package it targets, resolved from the
+// module in dir.
+func RequireTargets(t testing.TB, dir, ruleConfig string) {
+	t.Helper()
+	rules, err := setup.LoadRules(ruleConfig)
+	if err != nil {
+		t.Fatalf("loading rules from %s: %v", ruleConfig, err)
+	}
+	unmatched, err := Unmatched(t.Context(), dir, rules)
+	if err != nil {
+		t.Fatalf("checking rules from %s: %v", ruleConfig, err)
+	}
+	for _, r := range unmatched {
+		t.Errorf("rule %q matches nothing in %s; the target may have changed", r.GetName(), r.GetTarget())
+	}
+}
+
+// loadSources returns the Go files of the package importPath, resolved from
+// the module in dir.
+func loadSources(ctx context.Context, dir, importPath string) ([]string, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode:    packages.NeedName | packages.NeedFiles,
+		Context: ctx,
+		Dir:     dir,
+	}, importPath)
+	if err != nil {
+		return nil, ex.Wrapf(err, "failed to load package %s", importPath)
+	}
+	if len(pkgs) != 1 {
+		return nil, ex.Newf("import path %q resolved to %d packages", importPath, len(pkgs))
+	}
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		return nil, ex.Newf("failed to load package %s: %v", importPath, pkg.Errors[0])
+	}
+	return pkg.GoFiles, nil
+}
+
+// matchAny reports whether r matches a declaration in one of files.
+func matchAny(files []string, r rule.InstRule) (bool, error) {
+	for _, file := range files {
+		tree, err := ast.ParseFileFast(file)
+		if err != nil {
+			return false, err
+		}
+		var matched bool
+		switch rt := r.(type) {
+		case *rule.InstFuncRule:
+			_, matched, err = ast.FindFuncDecl(tree, rt)
+		case *rule.InstRawRule:
+			_, matched, err = ast.FindFuncDecl(tree, rt)
+		case *rule.InstStructRule:
+			matched = ast.FindStructDecl(tree, rt.Struct) != nil
+		case *rule.InstDirectiveRule:
+			matched = ast.FileHasDirective(tree, rt.Directive)
+		case *rule.InstDeclRule:
+			matched = ast.FindNamedDecl(tree, rt.Identifier, rt.Kind) != nil
+		default:
+			return false, ex.Newf("unexpected rule type %T", r)
+		}
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
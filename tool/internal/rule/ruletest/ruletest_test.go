@@ -0,0 +1,84 @@
+//go:build integration
+
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// The tests load the instrumented libraries, downloading them when they are
+// not in the module cache, so they run with the integration tests.
+
+package ruletest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
+)
+
+const instrumentationDir = "../../../../instrumentation"
+
+func TestRequireTargets(t *testing.T) {
+	tests := []struct {
+		name string
+		dir  string
+	}{
+		{name: "redis", dir: "github.com/redis/go-redis/v9"},
+		{name: "nethttp client", dir: "net/http/client"},
+		{name: "nethttp server", dir: "net/http/server"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(instrumentationDir, tt.dir)
+			RequireTargets(t, dir, filepath.Join(dir, "otelc.yaml"))
+		})
+	}
+}
+
+func TestUnmatched(t *testing.T) {
+	redisDir := filepath.Join(instrumentationDir, "github.com/redis/go-redis/v9")
+	funcRule := func(name, fn, recv string, signature *rule.FuncSignature) *rule.InstFuncRule {
+		return &rule.InstFuncRule{
+			InstBaseRule: rule.InstBaseRule{Name: name, Target: "github.com/redis/go-redis/v9"},
+			Func:         fn,
+			Recv:         recv,
+			After:        "afterNewRedisClientV9",
+			Signature:    signature,
+		}
+	}
+	rules := []rule.InstRule{
+		funcRule("exists", "NewClient", "", &rule.FuncSignature{Args: []string{"*Options"}, Returns: []string{"*Client"}}),
+		funcRule("method", "Conn", "*Client", nil),
+		funcRule("renamed", "NewRedisClient", "", nil),
+		funcRule("signature changed", "NewClient", "", &rule.FuncSignature{Args: []string{"context.Context", "*Options"}}),
+		funcRule("receiver changed", "Conn", "*Ring", nil),
+		&rule.InstStructRule{
+			InstBaseRule: rule.InstBaseRule{Name: "struct", Target: "github.com/redis/go-redis/v9"},
+			Struct:       "Options",
+		},
+		&rule.InstCallRule{
+			InstBaseRule: rule.InstBaseRule{Name: "call", Target: "github.com/redis/go-redis/v9"},
+		},
+	}
+
+	unmatched, err := Unmatched(t.Context(), redisDir, rules)
+	require.NoError(t, err)
+
+	var names []string
+	for _, r := range unmatched {
+		names = append(names, r.GetName())
+	}
+	assert.Equal(t, []string{"renamed", "signature changed", "receiver changed"}, names)
+}
+
+func TestUnmatched_UnknownPackage(t *testing.T) {
+	rules := []rule.InstRule{&rule.InstFuncRule{
+		InstBaseRule: rule.InstBaseRule{Name: "missing", Target: "github.com/redis/go-redis/v10"},
+		Func:         "NewClient",
+	}}
+
+	_, err := Unmatched(t.Context(), filepath.Join(instrumentationDir, "net/http/client"), rules)
+	assert.ErrorContains(t, err, "github.com/redis/go-redis/v10")
+}
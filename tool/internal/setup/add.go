@@ -19,6 +19,7 @@ import (
 
 const (
 	OtelcRuntimeFile = "otelc.runtime.go"
+	mainPackageName  = "main"
 )
 
 //nolint:gochecknoglobals // This is a constant
@@ -28,16 +29,16 @@ var requiredImports = map[string]string{
 	"unsafe":        "_",           // The golinkname tag depends on unsafe
 }
 
-func genImportDecl(funcRules []*rule.InstFuncRule, fileRules []*rule.InstFileRule) []dst.Decl {
+func genImportDecl(funcRules []*rule.InstFuncRule, fileRules []*rule.InstFileRule, withStack bool) []dst.Decl {
 	var imports map[string]string
-	if len(funcRules) > 0 {
+	if len(funcRules) > 0 && withStack {
 		imports = maps.Clone(requiredImports) // clone required imports to avoid mutating the global map
-		for _, m := range funcRules {
-			imports[m.Path] = ast.IdentIgnore
-		}
 	} else {
 		imports = make(map[string]string)
 	}
+	for _, m := range funcRules {
+		imports[m.Path] = ast.IdentIgnore
+	}
 	for _, m := range fileRules {
 		imports[m.Path] = ast.IdentIgnore
 	}
@@ -49,6 +50,18 @@ func genImportDecl(funcRules []*rule.InstFuncRule, fileRules []*rule.InstFileRul
 	return importDecls
 }
 
+// hookImports returns the sorted hook packages imported by otelc.runtime.go.
+func hookImports(funcRules []*rule.InstFuncRule, fileRules []*rule.InstFileRule) []string {
+	paths := make(map[string]bool)
+	for _, m := range funcRules {
+		paths[m.Path] = true
+	}
+	for _, m := range fileRules {
+		paths[m.Path] = true
+	}
+	return slices.Sorted(maps.Keys(paths))
+}
+
 func genVarDecl(matched []*rule.InstFuncRule) []dst.Decl {
 	decls := make([]dst.Decl, 0, len(matched))
 	uniquePath := map[string]bool{}
@@ -68,18 +81,20 @@ func genVarDecl(matched []*rule.InstFuncRule) []dst.Decl {
 		}
 		// Second variable declaration
 		// //go:linkname _printstack%d %s.OtelPrintStackImpl
-		// var _printstack%d = func (bt []byte){ _otel_log.Printf(string(bt)) }
+		// var _printstack%d = func (bt []byte){ _otel_log.Print(string(bt)) }
+		// Print rather than Printf: the stack is not a format string, and the
+		// vet pass that `go test` runs rejects a non-constant format.
 		// Build: string(bt)
 		stringCall := &dst.CallExpr{
 			Fun:  ast.Ident("string"),
 			Args: []dst.Expr{ast.Ident("bt")},
 		}
-		// Build: _otel_log.Printf(string(bt))
+		// Build: _otel_log.Print(string(bt))
 		printfCall := &dst.CallExpr{
-			Fun:  ast.SelectorExpr(ast.Ident("_otel_log"), "Printf"),
+			Fun:  ast.SelectorExpr(ast.Ident("_otel_log"), "Print"),
 			Args: []dst.Expr{stringCall},
 		}
-		// Build: func (bt []byte) { _otel_log.Printf(string(bt)) }
+		// Build: func (bt []byte) { _otel_log.Print(string(bt)) }
 		printStackFunc := &dst.FuncLit{
 			Type: &dst.FuncType{
 				Params: &dst.FieldList{
@@ -100,10 +115,10 @@ func genVarDecl(matched []*rule.InstFuncRule) []dst.Decl {
 	return decls
 }
 
-func buildOtelcRuntimeAst(decls []dst.Decl) *dst.File {
+func buildOtelcRuntimeAst(pkgName string, decls []dst.Decl) *dst.File {
 	const comment = "// This file is generated by the opentelemetry-go-compile-instrumentation tool. DO NOT EDIT."
 	return &dst.File{
-		Name: ast.Ident("main"),
+		Name: ast.Ident(pkgName),
 		Decs: dst.FileDecorations{
 			NodeDecs: ast.LineComments(comment),
 		},
@@ -113,7 +128,14 @@ func buildOtelcRuntimeAst(decls []dst.Decl) *dst.File {
 
 // addDeps generates and writes otelc.runtime.go with required imports and variable
 // declarations for OpenTelemetry instrumentation based on matched rules.
-func (sp *SetupPhase) addDeps(ctx context.Context, matched []*rule.InstRuleSet, packagePath string) error {
+// The file joins the package named pkgName: `go build` targets are main
+// packages, but `go test` also builds library packages into test binaries.
+func (sp *SetupPhase) addDeps(
+	ctx context.Context,
+	matched []*rule.InstRuleSet,
+	packagePath string,
+	pkgName string,
+) error {
 	funcRules := []*rule.InstFuncRule{}
 	fileRules := []*rule.InstFileRule{}
 	for _, m := range matched {
@@ -124,12 +146,20 @@ func (sp *SetupPhase) addDeps(ctx context.Context, matched []*rule.InstRuleSet,
 		return nil
 	}
 
+	// The stack helpers are linknamed into the hook packages, so only one
+	// package per binary may define them. That is the main package; a test
+	// binary links several instrumented library packages, and defining them
+	// in each would fail the link with duplicated symbols.
+	withStack := pkgName == mainPackageName
 	// Add required imports
-	importDecls := genImportDecl(funcRules, fileRules)
+	importDecls := genImportDecl(funcRules, fileRules, withStack)
 	// Generate the variable declarations that used by otel runtime
-	varDecls := genVarDecl(funcRules)
+	var varDecls []dst.Decl
+	if withStack {
+		varDecls = genVarDecl(funcRules)
+	}
 	// Build the ast
-	root := buildOtelcRuntimeAst(append(importDecls, varDecls...))
+	root := buildOtelcRuntimeAst(pkgName, append(importDecls, varDecls...))
 	otelcRuntimeFilePath := filepath.Join(packagePath, OtelcRuntimeFile)
 	// Track file in state manager
 	stateManager, _ := StateManagerFromContext(ctx)
@@ -142,5 +172,8 @@ func (sp *SetupPhase) addDeps(ctx context.Context, matched []*rule.InstRuleSet,
 	}
 	sp.keepForDebug(otelcRuntimeFilePath)
 	sp.Info("Created otelc.runtime.go", "path", otelcRuntimeFilePath)
+	for _, path := range hookImports(funcRules, fileRules) {
+		sp.audit(auditEntry{Kind: auditKindImport, Path: path, Target: otelcRuntimeFilePath})
+	}
 	return nil
 }
@@ -24,6 +24,7 @@ import (
 func TestAddDeps(t *testing.T) {
 	tests := []struct {
 		name       string
+		pkgName    string // Defaults to "main"
 		matched    []*rule.InstRuleSet
 		goldenFile string // Empty means no file should be generated
 	}{
@@ -77,6 +78,21 @@ func TestAddDeps(t *testing.T) {
 			},
 			goldenFile: "multiple_rule_sets.otelc.runtime.go.golden",
 		},
+		{
+			// Library packages only reach the build through `go test`. They get
+			// the hook imports but not the linknamed stack helpers, which the
+			// test binary would otherwise define once per instrumented package.
+			name:    "library_package",
+			pkgName: "handler",
+			matched: []*rule.InstRuleSet{
+				newTestRuleSet(
+					"github.com/example/pkg",
+					[]*rule.InstFuncRule{newTestFuncRule("github.com/example/pkg", "github.com/example/pkg")},
+					[]*rule.InstFileRule{newTestFileRule("github.com/example/pkg2", "github.com/example/pkg2")},
+				),
+			},
+			goldenFile: "library_package.otelc.runtime.go.golden",
+		},
 	}
 
 	for _, tt := range tests {
@@ -87,7 +103,11 @@ func TestAddDeps(t *testing.T) {
 			stateManager := NewStateManager()
 			ctx := ContextWithStateManager(t.Context(), stateManager)
 
-			err := sp.addDeps(ctx, tt.matched, tmpDir)
+			pkgName := tt.pkgName
+			if pkgName == "" {
+				pkgName = "main"
+			}
+			err := sp.addDeps(ctx, tt.matched, tmpDir, pkgName)
 			require.NoError(t, err)
 
 			runtimeFilePath := filepath.Join(tmpDir, OtelcRuntimeFile)
@@ -121,6 +141,6 @@ func TestAddDeps_FileWriteError(t *testing.T) {
 	invalidPath := filepath.Join(t.TempDir(), "nonexistent", "subdir")
 	sp := newTestSetupPhase()
 
-	err := sp.addDeps(t.Context(), matched, invalidPath)
+	err := sp.addDeps(t.Context(), matched, invalidPath, "main")
 	assert.Error(t, err)
 }
@@ -0,0 +1,78 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package setup
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
+)
+
+// loadAllowlist reads the instrumentation modules listed in the allowlist
+// file, one module path per line. Blank lines and lines starting with "#" are
+// ignored.
+func loadAllowlist(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, ex.Wrapf(err, "failed to open allowlist %s", path)
+	}
+	defer f.Close()
+
+	allowed := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		allowed[line] = true
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, ex.Wrapf(err, "failed to read allowlist %s", path)
+	}
+	return allowed, nil
+}
+
+// checkAllowlist fails when a matched rule would inject a hook module that the
+// allowlist does not list. It runs before anything is written to the project,
+// so a refused build leaves it untouched. The pkg and pkg/runtime modules of
+// the tool itself are always injected and need not be listed.
+func (sp *SetupPhase) checkAllowlist(matched []*rule.InstRuleSet) error {
+	if sp.allowlist == "" {
+		return nil
+	}
+	allowed, err := loadAllowlist(sp.allowlist)
+	if err != nil {
+		return err
+	}
+
+	var disallowed []string
+	check := func(modulePath, ruleName, target string) {
+		if !allowed[modulePath] {
+			disallowed = append(disallowed,
+				fmt.Sprintf("%s (rule %q on %s)", modulePath, ruleName, target))
+		}
+	}
+	for _, rs := range matched {
+		for _, r := range rs.AllFuncRules() {
+			check(r.ModulePath, r.GetName(), rs.ModulePath)
+		}
+		for _, r := range rs.FileRules {
+			check(r.ModulePath, r.GetName(), rs.ModulePath)
+		}
+	}
+	if len(disallowed) > 0 {
+		slices.Sort(disallowed)
+		disallowed = slices.Compact(disallowed)
+		return ex.Newf("refusing to inject instrumentation modules missing from allowlist %s:\n  %s",
+			sp.allowlist, strings.Join(disallowed, "\n  "))
+	}
+	sp.Info("All injected instrumentation modules are allowlisted", "allowlist", sp.allowlist)
+	return nil
+}
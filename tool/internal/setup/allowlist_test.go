@@ -0,0 +1,153 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package setup
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
+)
+
+func writeAllowlist(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "allowlist.txt")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadAllowlist(t *testing.T) {
+	path := writeAllowlist(t, `# Vetted by the security team
+`+util.OtelcInstRoot+`/net/http/client
+
+  `+util.OtelcInstRoot+`/database/sql
+`)
+	allowed, err := loadAllowlist(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{
+		util.OtelcInstRoot + "/net/http/client": true,
+		util.OtelcInstRoot + "/database/sql":    true,
+	}, allowed)
+
+	_, err = loadAllowlist(filepath.Join(t.TempDir(), "missing.txt"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to open allowlist")
+}
+
+func TestCheckAllowlist(t *testing.T) {
+	httpClient := util.OtelcInstRoot + "/net/http/client"
+	sqlClient := util.OtelcInstRoot + "/database/sql"
+	runtimeFiles := util.OtelcInstRoot + "/runtime"
+
+	httpRules := &rule.InstRuleSet{
+		ModulePath: "net/http",
+		FuncRules: map[string][]*rule.InstFuncRule{
+			"client.go": {{
+				InstBaseRule: rule.InstBaseRule{Name: "client_do", Target: "net/http"},
+				ModulePath:   httpClient,
+			}},
+		},
+	}
+	sqlRules := &rule.InstRuleSet{
+		ModulePath: "database/sql",
+		FuncRules: map[string][]*rule.InstFuncRule{
+			"sql.go": {{
+				InstBaseRule: rule.InstBaseRule{Name: "sql_open", Target: "database/sql"},
+				ModulePath:   sqlClient,
+			}},
+		},
+	}
+	runtimeRules := &rule.InstRuleSet{
+		ModulePath: "runtime",
+		FileRules: []*rule.InstFileRule{{
+			InstBaseRule: rule.InstBaseRule{Name: "runtime_file", Target: "runtime"},
+			ModulePath:   runtimeFiles,
+		}},
+	}
+	matched := []*rule.InstRuleSet{httpRules, sqlRules, runtimeRules}
+
+	tests := []struct {
+		name       string
+		disallowed []string
+		allowed    []string
+	}{
+		{
+			name:    "every module listed",
+			allowed: []string{httpClient, sqlClient, runtimeFiles},
+		},
+		{
+			name:       "func and file rules checked",
+			allowed:    []string{httpClient},
+			disallowed: []string{sqlClient, runtimeFiles},
+		},
+		{
+			name:       "empty allowlist",
+			disallowed: []string{httpClient, sqlClient, runtimeFiles},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "# allowlist\n"
+			for _, m := range tt.allowed {
+				content += m + "\n"
+			}
+			sp := &SetupPhase{logger: slog.Default(), allowlist: writeAllowlist(t, content)}
+
+			err := sp.checkAllowlist(matched)
+			if len(tt.disallowed) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "refusing to inject instrumentation modules missing from allowlist "+sp.allowlist)
+			for _, m := range tt.disallowed {
+				assert.Contains(t, err.Error(), m)
+			}
+			for _, m := range tt.allowed {
+				assert.NotContains(t, err.Error(), m+" ")
+			}
+		})
+	}
+}
+
+func TestCheckAllowlist_ReportsEachInjectionOnce(t *testing.T) {
+	sqlClient := util.OtelcInstRoot + "/database/sql"
+	sqlRules := &rule.InstRuleSet{
+		ModulePath: "database/sql",
+		FuncRules:  map[string][]*rule.InstFuncRule{},
+	}
+	for _, file := range []string{"sql.go", "ctxutil.go"} {
+		sqlRules.FuncRules[file] = []*rule.InstFuncRule{{
+			InstBaseRule: rule.InstBaseRule{Name: "sql_open", Target: "database/sql"},
+			ModulePath:   sqlClient,
+		}}
+	}
+	sp := &SetupPhase{logger: slog.Default(), allowlist: writeAllowlist(t, "")}
+
+	err := sp.checkAllowlist([]*rule.InstRuleSet{sqlRules})
+	require.Error(t, err)
+	assert.Equal(t, 1, strings.Count(err.Error(), sqlClient), err.Error())
+	assert.Contains(t, err.Error(), sqlClient+` (rule "sql_open" on database/sql)`)
+}
+
+func TestCheckAllowlist_Disabled(t *testing.T) {
+	rs := &rule.InstRuleSet{
+		ModulePath: "net/http",
+		FuncRules: map[string][]*rule.InstFuncRule{
+			"client.go": {{
+				InstBaseRule: rule.InstBaseRule{Name: "client_do"},
+				ModulePath:   util.OtelcInstRoot + "/net/http/client",
+			}},
+		},
+	}
+	sp := &SetupPhase{logger: slog.Default()}
+	require.NoError(t, sp.checkAllowlist([]*rule.InstRuleSet{rs}))
+}
@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package setup
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
+)
+
+const (
+	auditKindImport = "import"
+	auditKindModule = "module"
+)
+
+// auditEntry records one dependency that the setup phase introduced into the
+// user's project, either an import in otelc.runtime.go or a requirement in
+// go.mod. The entries let users review what instrumentation brought into
+// their build.
+type auditEntry struct {
+	Kind    string `json:"kind"`
+	Path    string `json:"path"`
+	Version string `json:"version,omitempty"`
+	// Target is the otelc.runtime.go or go.mod file that gained the entry
+	Target string `json:"target"`
+}
+
+// audit logs the added dependency and keeps it for the audit report.
+func (sp *SetupPhase) audit(entry auditEntry) {
+	sp.audited = append(sp.audited, entry)
+	sp.Info("Audit: added dependency",
+		"kind", entry.Kind,
+		"path", entry.Path,
+		"version", entry.Version,
+		"target", entry.Target)
+}
+
+// storeAudit writes the audited dependencies to the audit report file.
+func (sp *SetupPhase) storeAudit() error {
+	f := util.GetDependencyAuditFile()
+	entries := sp.audited
+	if entries == nil {
+		entries = []auditEntry{}
+	}
+	bs, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return ex.Wrapf(err, "failed to marshal dependency audit to JSON")
+	}
+	err = os.WriteFile(f, bs, 0o644) //nolint:gosec // 0644 is ok
+	if err != nil {
+		return ex.Wrapf(err, "failed to write dependency audit to %s", f)
+	}
+	sp.Info("Stored dependency audit", "path", f, "entries", len(entries))
+	return nil
+}
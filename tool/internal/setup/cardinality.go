@@ -0,0 +1,381 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package setup
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dave/dst"
+	"github.com/urfave/cli/v3"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/ast"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
+)
+
+const (
+	attributeImportPath = "go.opentelemetry.io/otel/attribute"
+	semconvImportPrefix = "go.opentelemetry.io/otel/semconv/"
+)
+
+// RuleCardinality lists the attributes the hooks of a func rule may set.
+type RuleCardinality struct {
+	Rule       string                 `json:"rule"`
+	Target     string                 `json:"target"`
+	Attributes []AttributeCardinality `json:"attributes"`
+}
+
+// AttributeCardinality is an attribute key found in the hooks of a rule. High
+// is set, with the reason, for keys whose values are usually unbounded.
+type AttributeCardinality struct {
+	Key    string `json:"key"`
+	High   bool   `json:"high_cardinality,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// highCardinalityKeys are the attribute keys known to carry unbounded values.
+//
+//nolint:gochecknoglobals // private lookup table
+var highCardinalityKeys = map[string]string{
+	"url.full":             "raw URL",
+	"url.original":         "raw URL",
+	"http.url":             "raw URL",
+	"url.path":             "raw path",
+	"http.target":          "raw path",
+	"url.query":            "raw query",
+	"db.query.text":        "SQL text",
+	"db.statement":         "SQL text",
+	"db.query.parameters":  "query parameters",
+	"client.address":       "client address",
+	"network.peer.address": "peer address",
+	"user.email":           "user identity",
+	"user.name":            "user identity",
+	"enduser.id":           "user id",
+	"session.id":           "session id",
+}
+
+// idKey matches identifier keys, such as user.id or messaging.message.id.
+//
+//nolint:gochecknoglobals // This is a constant
+var idKey = regexp.MustCompile(`[._](id|uid)$`)
+
+func classifyAttribute(key string) AttributeCardinality {
+	if reason, ok := highCardinalityKeys[key]; ok {
+		return AttributeCardinality{Key: key, High: true, Reason: reason}
+	}
+	if idKey.MatchString(key) {
+		return AttributeCardinality{Key: key, High: true, Reason: "identifier"}
+	}
+	return AttributeCardinality{Key: key}
+}
+
+// RulesCardinality implements `otelc rules cardinality [--json] [build
+// args...]`. For every func rule matching the dependencies of the module in
+// the current directory, it reports the attribute keys its hooks may set and
+// flags the high-cardinality ones. The report is advisory: the keys are found
+// by reading the source of the hook packages, not by running them.
+func RulesCardinality(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args().Slice()
+	asJSON := len(args) > 0 && (args[0] == "--json" || args[0] == "-json")
+	if asJSON {
+		args = args[1:]
+	}
+	sp := &SetupPhase{
+		logger:     util.LoggerFromContext(ctx),
+		ruleConfig: cmd.String("rules"),
+		ruleDirs:   cmd.StringSlice("rules-dir"),
+	}
+	report, err := sp.rulesCardinality(ctx, args)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return writeRulesCardinalityJSON(cmd.Writer, report)
+	}
+	return writeRulesCardinality(cmd.Writer, report)
+}
+
+func (sp *SetupPhase) rulesCardinality(ctx context.Context, buildArgs []string) ([]RuleCardinality, error) {
+	deps, err := sp.findDeps(ctx, subcmdBuild, buildArgs)
+	if err != nil {
+		return nil, err
+	}
+	if err = sp.extract(); err != nil {
+		return nil, ex.Wrapf(err, "extracting embedded instrumentation pkg")
+	}
+	matched, err := sp.matchDeps(ctx, deps)
+	if err != nil {
+		return nil, ex.Wrapf(err, "matching dependencies to hook rules")
+	}
+	scanner := newAttrScanner()
+	var report []RuleCardinality
+	for _, set := range matched {
+		for _, r := range set.AllFuncRules() {
+			keys := scanner.keys(ctx, r.Path, r.ModulePath)
+			attrs := make([]AttributeCardinality, 0, len(keys))
+			for _, key := range keys {
+				attrs = append(attrs, classifyAttribute(key))
+			}
+			report = append(report, RuleCardinality{
+				Rule:       r.GetName(),
+				Target:     funcTarget(set.ModulePath, r.Recv, r.Func),
+				Attributes: attrs,
+			})
+		}
+	}
+	slices.SortFunc(report, func(a, b RuleCardinality) int {
+		return cmp.Or(strings.Compare(a.Target, b.Target), strings.Compare(a.Rule, b.Rule))
+	})
+	return slices.CompactFunc(report, func(a, b RuleCardinality) bool {
+		return a.Target == b.Target && a.Rule == b.Rule
+	}), nil
+}
+
+// attrScanner finds the attribute keys set by the code of hook packages:
+// attribute.String("key", v) and the like, attribute.Key("key") and the
+// semconv constants and functions, in the hook package and in the packages it
+// imports from its module or from the bundled instrumentation.
+type attrScanner struct {
+	resolver *hookResolver
+	pkgs     map[string][]string          // import path -> keys
+	semconv  map[string]map[string]string // semconv import path -> identifier -> key
+}
+
+func newAttrScanner() *attrScanner {
+	return &attrScanner{
+		resolver: &hookResolver{dirs: make(map[string]string)},
+		pkgs:     make(map[string][]string),
+		semconv:  make(map[string]map[string]string),
+	}
+}
+
+// keys returns the sorted attribute keys set by the package importPath, of the
+// module modulePath, and by the packages it imports from it.
+func (s *attrScanner) keys(ctx context.Context, importPath, modulePath string) []string {
+	if keys, ok := s.pkgs[importPath]; ok {
+		return keys
+	}
+	s.pkgs[importPath] = nil // breaks import cycles, should the sources have any
+	dir := s.resolver.dir(ctx, importPath)
+	if dir == "" {
+		return nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil
+	}
+	var keys []string
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		tree, err1 := ast.ParseFileFast(file)
+		if err1 != nil {
+			continue
+		}
+		imports := fileImports(tree)
+		keys = append(keys, s.fileKeys(ctx, tree, imports, dir)...)
+		for _, imported := range imports {
+			if imported == modulePath || strings.HasPrefix(imported, modulePath+"/") ||
+				strings.HasPrefix(imported, util.OtelcInstRoot+"/") {
+				keys = append(keys, s.keys(ctx, imported, modulePath)...)
+			}
+		}
+	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+	s.pkgs[importPath] = keys
+	return keys
+}
+
+// fileKeys returns the attribute keys set in tree, whose imports are given by
+// local name. dir is the directory of the file, semconv packages are resolved
+// from.
+func (s *attrScanner) fileKeys(ctx context.Context, tree *dst.File, imports map[string]string, dir string) []string {
+	var keys []string
+	dst.Inspect(tree, func(n dst.Node) bool {
+		switch n := n.(type) {
+		case *dst.CallExpr:
+			sel, ok := n.Fun.(*dst.SelectorExpr)
+			if !ok || len(n.Args) == 0 {
+				return true
+			}
+			if pkg, ok1 := sel.X.(*dst.Ident); ok1 && imports[pkg.Name] == attributeImportPath {
+				if key, ok2 := stringLit(n.Args[0]); ok2 {
+					keys = append(keys, key)
+				}
+			}
+		case *dst.SelectorExpr:
+			pkg, ok := n.X.(*dst.Ident)
+			if !ok || !strings.HasPrefix(imports[pkg.Name], semconvImportPrefix) {
+				return true
+			}
+			if key, ok1 := s.semconvKeys(ctx, imports[pkg.Name], dir)[n.Sel.Name]; ok1 {
+				keys = append(keys, key)
+			}
+		}
+		return true
+	})
+	return keys
+}
+
+// semconvKeys maps the identifiers of the semconv package importPath to the
+// attribute key they stand for: the Key constants, the functions building an
+// attribute from their constant and the variables holding one of its values.
+func (s *attrScanner) semconvKeys(ctx context.Context, importPath, fromDir string) map[string]string {
+	if keys, ok := s.semconv[importPath]; ok {
+		return keys
+	}
+	keys := make(map[string]string)
+	s.semconv[importPath] = keys
+	resolver := &hookResolver{base: fromDir, dirs: make(map[string]string)}
+	dir := resolver.dir(ctx, importPath)
+	if dir == "" {
+		return keys
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return keys
+	}
+	var trees []*dst.File
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		if tree, err1 := ast.ParseFileFast(file); err1 == nil {
+			trees = append(trees, tree)
+		}
+	}
+	// Constants first, functions and variables refer to them
+	for _, tree := range trees {
+		forEachValueSpec(tree, token.CONST, func(name string, value dst.Expr) {
+			if call, ok := value.(*dst.CallExpr); ok && len(call.Args) == 1 {
+				if key, ok1 := stringLit(call.Args[0]); ok1 {
+					keys[name] = key
+				}
+			}
+		})
+	}
+	for _, tree := range trees {
+		for _, decl := range tree.Decls {
+			if fn, ok := decl.(*dst.FuncDecl); ok && fn.Recv == nil {
+				if key, ok1 := keys[fn.Name.Name+"Key"]; ok1 {
+					keys[fn.Name.Name] = key
+				}
+			}
+		}
+		forEachValueSpec(tree, token.VAR, func(name string, value dst.Expr) {
+			call, ok := value.(*dst.CallExpr)
+			if !ok {
+				return
+			}
+			if sel, ok1 := call.Fun.(*dst.SelectorExpr); ok1 {
+				if id, ok2 := sel.X.(*dst.Ident); ok2 && keys[id.Name] != "" {
+					keys[name] = keys[id.Name]
+				}
+			}
+		})
+	}
+	return keys
+}
+
+// forEachValueSpec calls fn with every name declared by the const or var
+// declarations of tree, along with its value.
+func forEachValueSpec(tree *dst.File, tok token.Token, fn func(name string, value dst.Expr)) {
+	for _, decl := range tree.Decls {
+		gen, ok := decl.(*dst.GenDecl)
+		if !ok || gen.Tok != tok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs, ok1 := spec.(*dst.ValueSpec)
+			if !ok1 || len(vs.Values) != len(vs.Names) {
+				continue
+			}
+			for i, name := range vs.Names {
+				fn(name.Name, vs.Values[i])
+			}
+		}
+	}
+}
+
+// fileImports maps the names tree refers to its imports by to their path.
+func fileImports(tree *dst.File) map[string]string {
+	imports := make(map[string]string)
+	for _, spec := range tree.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(importPath)
+		// The semconv packages are versioned, e.g. semconv/v1.37.0
+		if strings.HasPrefix(name, "v") && strings.Contains(name, ".") {
+			name = path.Base(path.Dir(importPath))
+		}
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+	return imports
+}
+
+func stringLit(expr dst.Expr) (string, bool) {
+	lit, ok := expr.(*dst.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+func writeRulesCardinalityJSON(w io.Writer, report []RuleCardinality) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return ex.Wrapf(err, "failed to print cardinality report")
+	}
+	return nil
+}
+
+// writeRulesCardinality prints a table with a row per attribute of every rule.
+func writeRulesCardinality(w io.Writer, report []RuleCardinality) error {
+	if len(report) == 0 {
+		if _, err := fmt.Fprintln(w, "No rules matched"); err != nil {
+			return ex.Wrapf(err, "failed to print cardinality report")
+		}
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "RULE\tTARGET\tATTRIBUTE\tCARDINALITY")
+	for _, r := range report {
+		if len(r.Attributes) == 0 {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t-\t-\n", r.Rule, r.Target)
+			continue
+		}
+		for _, a := range r.Attributes {
+			cardinality := "-"
+			if a.High {
+				cardinality = "high (" + a.Reason + ")"
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Rule, r.Target, a.Key, cardinality)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return ex.Wrapf(err, "failed to print cardinality report")
+	}
+	return nil
+}
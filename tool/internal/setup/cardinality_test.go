@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package setup
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
+)
+
+func TestRulesCardinality(t *testing.T) {
+	moduleDir := t.TempDir()
+	writeFixtureFiles(t, moduleDir, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.21\n",
+		"main.go": `package main
+
+type Server struct{}
+
+func (*Server) Serve(path string) {}
+
+func (*Server) Close() {}
+
+func main() {
+	(&Server{}).Serve("/")
+	(&Server{}).Close()
+}
+`,
+		"hooks/hooks.go": `package hooks
+
+import (
+	"example.com/app/hooks/attrs"
+	otelattr "go.opentelemetry.io/otel/attribute"
+)
+
+func BeforeServe(path string) {
+	_ = otelattr.String("url.path", path)
+	_ = attrs.Method("GET")
+}
+`,
+		"hooks/attrs/attrs.go": `package attrs
+
+import "go.opentelemetry.io/otel/attribute"
+
+const methodKey = attribute.Key("http.request.method")
+
+func Method(m string) attribute.KeyValue { return methodKey.String(m) }
+`,
+		"closehooks/hooks.go": "package closehooks\n\nfunc BeforeClose() {}\n",
+		"rules.yaml": `
+serve_hook:
+  target: main
+  where:
+    func: Serve
+    recv: "*Server"
+  do:
+    - inject_hooks:
+        before: BeforeServe
+        path: example.com/app/hooks
+close_hook:
+  target: main
+  where:
+    func: Close
+    recv: "*Server"
+  do:
+    - inject_hooks:
+        before: BeforeClose
+        path: example.com/app/closehooks
+`,
+	})
+	t.Chdir(moduleDir)
+	workDir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(workDir, util.BuildTempDir), 0o755))
+	t.Setenv(util.EnvOtelcWorkDir, workDir)
+
+	sp := newTestSetupPhase()
+	sp.ruleConfig = "rules.yaml"
+	report, err := sp.rulesCardinality(t.Context(), []string{"."})
+	require.NoError(t, err)
+
+	assert.Equal(t, []RuleCardinality{
+		{
+			Rule:       "close_hook",
+			Target:     "main.(*Server).Close",
+			Attributes: []AttributeCardinality{},
+		},
+		{
+			Rule:   "serve_hook",
+			Target: "main.(*Server).Serve",
+			Attributes: []AttributeCardinality{
+				{Key: "http.request.method"},
+				{Key: "url.path", High: true, Reason: "raw path"},
+			},
+		},
+	}, report)
+
+	var out strings.Builder
+	require.NoError(t, writeRulesCardinality(&out, report))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, []string{"RULE", "TARGET", "ATTRIBUTE", "CARDINALITY"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"close_hook", "main.(*Server).Close", "-", "-"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"serve_hook", "main.(*Server).Serve", "url.path", "high", "(raw", "path)"},
+		strings.Fields(lines[3]))
+
+	out.Reset()
+	require.NoError(t, writeRulesCardinalityJSON(&out, report))
+	var decoded []RuleCardinality
+	require.NoError(t, json.Unmarshal([]byte(out.String()), &decoded))
+	assert.Equal(t, report, decoded)
+}
+
+func TestClassifyAttribute(t *testing.T) {
+	tests := []struct {
+		key  string
+		want AttributeCardinality
+	}{
+		{"http.route", AttributeCardinality{Key: "http.route"}},
+		{"db.query.text", AttributeCardinality{Key: "db.query.text", High: true, Reason: "SQL text"}},
+		{"user.id", AttributeCardinality{Key: "user.id", High: true, Reason: "identifier"}},
+		{"rpc.jsonrpc.request_id", AttributeCardinality{Key: "rpc.jsonrpc.request_id", High: true, Reason: "identifier"}},
+		{"db.system.name", AttributeCardinality{Key: "db.system.name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyAttribute(tt.key))
+		})
+	}
+}
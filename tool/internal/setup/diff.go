@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package setup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
+)
+
+// CoverageDiff lists the instrumentation targets that a new rule set covers
+// but an old one does not (Gained), and the reverse (Lost).
+type CoverageDiff struct {
+	Gained []string
+	Lost   []string
+}
+
+// RulesDiff implements `otelc rules diff <old> <new> [build args...]`. It dry-run
+// matches both rule sets against the dependencies of the module in the current
+// directory, without modifying it, and reports the coverage difference.
+func RulesDiff(ctx context.Context, cmd *cli.Command) error {
+	const ruleArgs = 2
+	args := cmd.Args().Slice()
+	if len(args) < ruleArgs {
+		return ex.New("usage: otelc rules diff <old-rules> <new-rules> [build flags] [packages]")
+	}
+	// Rules that match nothing, or fail to, are warnings the user should see
+	// next to the diff
+	sp := &SetupPhase{logger: util.WithWarningsTo(util.LoggerFromContext(ctx), cmd.Root().ErrWriter)}
+	diff, err := sp.diffRules(ctx, args[0], args[1], args[ruleArgs:])
+	if err != nil {
+		return err
+	}
+	return writeCoverageDiff(cmd.Writer, diff)
+}
+
+func (sp *SetupPhase) diffRules(ctx context.Context, oldRules, newRules string, buildArgs []string) (*CoverageDiff, error) {
+	deps, err := sp.findDeps(ctx, subcmdBuild, buildArgs)
+	if err != nil {
+		return nil, err
+	}
+	oldCoverage, err := sp.coverage(ctx, deps, oldRules)
+	if err != nil {
+		return nil, err
+	}
+	newCoverage, err := sp.coverage(ctx, deps, newRules)
+	if err != nil {
+		return nil, err
+	}
+	return &CoverageDiff{
+		Gained: subtractSorted(newCoverage, oldCoverage),
+		Lost:   subtractSorted(oldCoverage, newCoverage),
+	}, nil
+}
+
+// coverage returns the sorted, de-duplicated targets that the rules in
+// ruleConfig instrument among deps.
+func (sp *SetupPhase) coverage(ctx context.Context, deps []*Dependency, ruleConfig string) ([]string, error) {
+	rules, err := loadCustomRules(ruleConfig)
+	if err != nil {
+		return nil, ex.Wrapf(err, "loading rules %s", ruleConfig)
+	}
+	matched, err := sp.matchRules(ctx, deps, rules)
+	if err != nil {
+		return nil, err
+	}
+	var targets []string
+	for _, set := range matched {
+		pkg := set.ModulePath
+		for _, r := range set.AllFuncRules() {
+			targets = append(targets, funcTarget(pkg, r.Recv, r.Func))
+		}
+		for _, rs := range set.RawRules {
+			for _, r := range rs {
+				targets = append(targets, funcTarget(pkg, r.Recv, r.Func))
+			}
+		}
+		for _, r := range set.AllStructRules() {
+			targets = append(targets, fmt.Sprintf("%s.%s (struct)", pkg, r.Struct))
+		}
+		for _, rs := range set.DeclRules {
+			for _, r := range rs {
+				targets = append(targets, fmt.Sprintf("%s.%s (decl)", pkg, r.Identifier))
+			}
+		}
+		for _, rs := range set.CallRules {
+			for _, r := range rs {
+				targets = append(targets, fmt.Sprintf("%s calls %s", pkg, r.FunctionCall))
+			}
+		}
+		for _, rs := range set.DirectiveRules {
+			for _, r := range rs {
+				targets = append(targets, fmt.Sprintf("%s //%s", pkg, r.Directive))
+			}
+		}
+		for _, r := range set.FileRules {
+			targets = append(targets, fmt.Sprintf("%s +%s", pkg, r.File))
+		}
+	}
+	slices.Sort(targets)
+	return slices.Compact(targets), nil
+}
+
+func funcTarget(pkg, recv, fn string) string {
+	if recv == "" {
+		return fmt.Sprintf("%s.%s", pkg, fn)
+	}
+	return fmt.Sprintf("%s.(%s).%s", pkg, recv, fn)
+}
+
+// subtractSorted returns the elements of the sorted slice a missing from the
+// sorted slice b.
+func subtractSorted(a, b []string) []string {
+	var out []string
+	for _, s := range a {
+		if _, found := slices.BinarySearch(b, s); !found {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func writeCoverageDiff(w io.Writer, diff *CoverageDiff) error {
+	if len(diff.Gained) == 0 && len(diff.Lost) == 0 {
+		if _, err := fmt.Fprintln(w, "No instrumentation coverage changes"); err != nil {
+			return ex.Wrapf(err, "failed to print coverage diff")
+		}
+		return nil
+	}
+	for _, t := range diff.Gained {
+		if _, err := fmt.Fprintf(w, "+ %s\n", t); err != nil {
+			return ex.Wrapf(err, "failed to print coverage diff")
+		}
+	}
+	for _, t := range diff.Lost {
+		if _, err := fmt.Fprintf(w, "- %s\n", t); err != nil {
+			return ex.Wrapf(err, "failed to print coverage diff")
+		}
+	}
+	return nil
+}
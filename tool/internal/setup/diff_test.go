@@ -0,0 +1,110 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package setup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
+)
+
+func TestDiffRules(t *testing.T) {
+	moduleDir := t.TempDir()
+	writeFixtureFiles(t, moduleDir, map[string]string{
+		"go.mod":  "module example.com/app\n\ngo 1.21\n",
+		"main.go": "package main\n\ntype Server struct{}\n\nfunc Handle() {}\n\nfunc Serve() {}\n\nfunc main() { Handle(); Serve() }\n",
+		"old.yaml": `
+handle_hook:
+  target: main
+  where:
+    func: Handle
+  do:
+    - inject_hooks:
+        before: BeforeHandle
+        path: example.com/hooks
+serve_hook:
+  target: main
+  where:
+    func: Serve
+  do:
+    - inject_hooks:
+        before: BeforeServe
+        path: example.com/hooks
+`,
+		"new.yaml": `
+serve_hook:
+  target: main
+  where:
+    func: Serve
+  do:
+    - inject_hooks:
+        before: BeforeServe
+        path: example.com/hooks
+server_fields:
+  target: main
+  where:
+    struct: Server
+  do:
+    - add_struct_fields:
+        new_field:
+          - name: Extra
+            type: string
+`,
+	})
+	t.Chdir(moduleDir)
+	workDir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(workDir, util.BuildTempDir), 0o755))
+	t.Setenv(util.EnvOtelcWorkDir, workDir)
+
+	sp := newTestSetupPhase()
+	diff, err := sp.diffRules(t.Context(), "old.yaml", "new.yaml", []string{"."})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.Server (struct)"}, diff.Gained)
+	assert.Equal(t, []string{"main.Handle"}, diff.Lost)
+
+	var out strings.Builder
+	require.NoError(t, writeCoverageDiff(&out, diff))
+	assert.Equal(t, "+ main.Server (struct)\n- main.Handle\n", out.String())
+
+	same, err := sp.diffRules(t.Context(), "new.yaml", "new.yaml", []string{"."})
+	require.NoError(t, err)
+	out.Reset()
+	require.NoError(t, writeCoverageDiff(&out, same))
+	assert.Equal(t, "No instrumentation coverage changes\n", out.String())
+}
+
+func TestDiffRules_PrintsWarnings(t *testing.T) {
+	moduleDir := t.TempDir()
+	writeFixtureFiles(t, moduleDir, map[string]string{
+		"go.mod":  "module example.com/app\n\ngo 1.21\n",
+		"main.go": "package main\n\nfunc Serve() {}\n\nfunc main() { Serve() }\n",
+		"rules.yaml": `
+renamed_hook:
+  target: main
+  where:
+    func: Handle
+  do:
+    - inject_hooks:
+        before: BeforeHandle
+        path: example.com/hooks
+`,
+	})
+	t.Chdir(moduleDir)
+	workDir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(workDir, util.BuildTempDir), 0o755))
+	t.Setenv(util.EnvOtelcWorkDir, workDir)
+
+	var stderr strings.Builder
+	sp := newTestSetupPhase()
+	sp.logger = util.WithWarningsTo(sp.logger, &stderr)
+	_, err := sp.diffRules(t.Context(), "rules.yaml", "rules.yaml", []string{"."})
+	require.NoError(t, err)
+	assert.Contains(t, stderr.String(), `msg="rule matched nothing in its target package" rule=renamed_hook`)
+}
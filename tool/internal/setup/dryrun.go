@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package setup
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
+)
+
+// DryRunReport describes what `otelc --dry-run go build` would instrument: the
+// rules rewriting the packages of the build and the imports added for their
+// hooks.
+type DryRunReport struct {
+	Rules   []MatchedRule `json:"rules"`
+	Imports []AddedImport `json:"imports"`
+}
+
+// AddedImport is a hook package imported by the otelc.runtime.go generated
+// into File.
+type AddedImport struct {
+	Path string `json:"path"`
+	File string `json:"file"`
+}
+
+// dryRunReport reports the rules the setup phase matched and the imports it
+// added, which the build would otherwise go on to apply.
+func (sp *SetupPhase) dryRunReport(listed []MatchedRule) *DryRunReport {
+	report := &DryRunReport{Rules: listed, Imports: []AddedImport{}}
+	if report.Rules == nil {
+		report.Rules = []MatchedRule{}
+	}
+	for _, entry := range sp.audited {
+		if entry.Kind == auditKindImport {
+			report.Imports = append(report.Imports, AddedImport{Path: entry.Path, File: entry.Target})
+		}
+	}
+	slices.SortFunc(report.Imports, func(a, b AddedImport) int {
+		return cmp.Or(strings.Compare(a.File, b.File), strings.Compare(a.Path, b.Path))
+	})
+	return report
+}
+
+func writeDryRunReportJSON(w io.Writer, report *DryRunReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return ex.Wrapf(err, "failed to print dry run report")
+	}
+	return nil
+}
+
+// writeDryRunReport prints the matched rules as `otelc rules list` does,
+// followed by a table of the added imports.
+func writeDryRunReport(w io.Writer, report *DryRunReport) error {
+	if err := writeMatchedRules(w, report.Rules); err != nil {
+		return err
+	}
+	if len(report.Imports) == 0 {
+		return nil
+	}
+	_, _ = fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "IMPORT\tFILE")
+	for _, imp := range report.Imports {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\n", imp.Path, imp.File)
+	}
+	if err := tw.Flush(); err != nil {
+		return ex.Wrapf(err, "failed to print dry run report")
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package setup
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
+)
+
+func TestGoBuild_DryRun(t *testing.T) {
+	moduleDir := t.TempDir()
+	writeFixtureFiles(t, moduleDir, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.21\n",
+		"main.go": `package main
+
+type Server struct{}
+
+func (*Server) Serve() {}
+
+func main() {
+	(&Server{}).Serve()
+}
+`,
+		"hooks/hooks.go": "package hooks\n\nfunc BeforeServe() {}\n",
+		"rules.yaml": `
+serve_hook:
+  target: main
+  where:
+    func: Serve
+    recv: "*Server"
+  do:
+    - inject_hooks:
+        before: BeforeServe
+        path: example.com/app/hooks
+`,
+	})
+	t.Chdir(moduleDir)
+	workDir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(workDir, util.BuildTempDir), 0o755))
+	t.Setenv(util.EnvOtelcWorkDir, workDir)
+	goMod, err := os.ReadFile("go.mod")
+	require.NoError(t, err)
+
+	var out strings.Builder
+	app := &cli.Command{
+		Name: "otelc",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "rules"},
+			&cli.BoolFlag{Name: "dry-run"},
+			&cli.BoolFlag{Name: "json"},
+		},
+		Commands: []*cli.Command{{Name: "go", SkipFlagParsing: true, Writer: &out, Action: GoBuild}},
+	}
+	err = app.Run(t.Context(), []string{"otelc", "--rules", "rules.yaml", "--dry-run", "--json", "go", "build", "."})
+	require.NoError(t, err)
+
+	var report DryRunReport
+	require.NoError(t, json.Unmarshal([]byte(out.String()), &report))
+	assert.Equal(t, []MatchedRule{{
+		Rule:   "serve_hook",
+		Target: "main.(*Server).Serve",
+		Hooks: []MatchedHook{
+			{Func: "BeforeServe", File: filepath.Join(moduleDir, "hooks", "hooks.go")},
+		},
+	}}, report.Rules)
+	assert.Equal(t, []AddedImport{{
+		Path: "example.com/app/hooks",
+		File: filepath.Join(moduleDir, OtelcRuntimeFile),
+	}}, report.Imports)
+
+	// Nothing setup changed survives the dry run, and nothing was built
+	assert.NoFileExists(t, filepath.Join(moduleDir, OtelcRuntimeFile))
+	assert.NoFileExists(t, filepath.Join(moduleDir, "go.sum"))
+	assert.NoFileExists(t, filepath.Join(moduleDir, "app"))
+	after, err := os.ReadFile("go.mod")
+	require.NoError(t, err)
+	assert.Equal(t, string(goMod), string(after))
+}
+
+func TestWriteDryRunReport(t *testing.T) {
+	report := &DryRunReport{
+		Rules: []MatchedRule{{
+			Rule:   "serve_hook",
+			Target: "main.(*Server).Serve",
+			Hooks:  []MatchedHook{{Func: "BeforeServe"}},
+		}},
+		Imports: []AddedImport{{Path: "example.com/app/hooks", File: "/app/otelc.runtime.go"}},
+	}
+
+	var out strings.Builder
+	require.NoError(t, writeDryRunReport(&out, report))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, []string{"RULE", "TARGET", "HOOK", "FILE"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"serve_hook", "main.(*Server).Serve", "BeforeServe", "-"}, strings.Fields(lines[1]))
+	assert.Empty(t, lines[2])
+	assert.Equal(t, []string{"IMPORT", "FILE"}, strings.Fields(lines[3]))
+	assert.Equal(t, []string{"example.com/app/hooks", "/app/otelc.runtime.go"}, strings.Fields(lines[4]))
+}
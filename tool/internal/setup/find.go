@@ -91,11 +91,13 @@ func (sp *SetupPhase) listBuildPlan(ctx context.Context, subcommand string, cmdA
 	if subcommand == subcmdTest {
 		planVerb = subcmdTest
 	}
-	// The full command is: "go build/test -a -x -n {...}"
+	// The full command is: "go build/test -a -x -n {...}". It inherits the
+	// environment, so a cross-compile (GOOS/GOARCH, CGO_ENABLED) lists the
+	// packages and files of the target platform rather than the host.
 	prefix := []string{planVerb, "-a", "-x", "-n"}
 	args := make([]string, 0, len(prefix)+len(cmdArgs))
 	args = append(args, prefix...)
-	args = append(args, cmdArgs...) // args from original build/install or setup command
+	args = append(args, stripPlanOutputFlags(cmdArgs)...) // args from original build/install or setup command
 	sp.Info("go build command", "args", args)
 
 	cmd := execCommandContext(ctx, "go", args...)
@@ -123,6 +125,24 @@ func (sp *SetupPhase) listBuildPlan(ctx context.Context, subcommand string, cmdA
 	return compileCmds, nil
 }
 
+// stripPlanOutputFlags drops flags that change where the dry run prints its
+// commands. `go test -json` reports the -x output as JSON build-output events on
+// stdout instead of plain text on stderr, which would leave the build plan log
+// empty. Arguments after `-args` belong to the test binary and are kept as-is.
+func stripPlanOutputFlags(args []string) []string {
+	stripped := make([]string, 0, len(args))
+	for i, arg := range args {
+		if arg == "-args" {
+			return append(stripped, args[i:]...)
+		}
+		if arg == "-json" || strings.HasPrefix(arg, "-json=") {
+			continue
+		}
+		stripped = append(stripped, arg)
+	}
+	return stripped
+}
+
 const (
 	cgoSuffix = ".cgo1.go"
 	goSuffix  = ".go"
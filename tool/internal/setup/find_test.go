@@ -369,6 +369,21 @@ echo nothing useful
 			},
 			expectedGoCmd: []string{"test", "-a", "-x", "-n", "./..."},
 		},
+		{
+			// `go test -json` moves the -x output to stdout as JSON events, so
+			// the flag must not reach the dry run. Arguments after -args go to
+			// the test binary and are left untouched.
+			name:       "test subcommand drops -json from the plan",
+			subcommand: "test",
+			buildPlan: `
+.../compile -o /tmp/out.a -buildid abc -p main main.go
+`,
+			args: []string{"-json", "-v", "./...", "-args", "-json"},
+			expected: []string{
+				".../compile -o /tmp/out.a -buildid abc -p main main.go",
+			},
+			expectedGoCmd: []string{"test", "-a", "-x", "-n", "-v", "./...", "-args", "-json"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -414,3 +429,58 @@ echo nothing useful
 		})
 	}
 }
+
+// writeFixtureFiles writes files (path relative to dir -> content) under dir.
+func writeFixtureFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+func TestFindDeps_TargetPlatform(t *testing.T) {
+	moduleDir := t.TempDir()
+	writeFixtureFiles(t, moduleDir, map[string]string{
+		"go.mod":                     "module example.com/xplat\n\ngo 1.21\n",
+		"main.go":                    "package main\n\nfunc main() { platform() }\n",
+		"platform_linux.go":          "package main\n\nimport \"example.com/xplat/linuxonly\"\n\nfunc platform() { linuxonly.Run() }\n",
+		"platform_windows.go":        "package main\n\nimport \"example.com/xplat/windowsonly\"\n\nfunc platform() { windowsonly.Run() }\n",
+		"linuxonly/linuxonly.go":     "package linuxonly\n\nfunc Run() {}\n",
+		"windowsonly/windowsonly.go": "package windowsonly\n\nfunc Run() {}\n",
+	})
+	t.Chdir(moduleDir)
+
+	tests := []struct {
+		goos       string
+		expected   string
+		unexpected string
+	}{
+		{goos: "linux", expected: "example.com/xplat/linuxonly", unexpected: "example.com/xplat/windowsonly"},
+		{goos: "windows", expected: "example.com/xplat/windowsonly", unexpected: "example.com/xplat/linuxonly"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			workDir := t.TempDir()
+			require.NoError(t, os.Mkdir(filepath.Join(workDir, util.BuildTempDir), 0o755))
+			t.Setenv(util.EnvOtelcWorkDir, workDir)
+			// Cross-compile: whichever of the two is not the host platform must
+			// still resolve the target's files, not the host's.
+			t.Setenv("GOOS", tt.goos)
+			t.Setenv("GOARCH", "amd64")
+			t.Setenv("CGO_ENABLED", "0")
+
+			sp := newTestSetupPhase()
+			deps, err := sp.findDeps(t.Context(), subcmdBuild, []string{"."})
+			require.NoError(t, err)
+
+			importPaths := make([]string, 0, len(deps))
+			for _, dep := range deps {
+				importPaths = append(importPaths, dep.ImportPath)
+			}
+			assert.Contains(t, importPaths, tt.expected)
+			assert.NotContains(t, importPaths, tt.unexpected)
+		})
+	}
+}
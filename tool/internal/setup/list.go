@@ -0,0 +1,207 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package setup
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v3"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/ast"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
+)
+
+// MatchedRule describes a rule that instruments a dependency of the module.
+type MatchedRule struct {
+	Rule   string `json:"rule"`
+	Target string `json:"target"`
+	// The hooks a func rule injects
+	Hooks []MatchedHook `json:"hooks,omitempty"`
+	// The expression a call rule wraps the matched calls in
+	Replace string `json:"replace,omitempty"`
+}
+
+// MatchedHook is a hook function a func rule injects, along with the file
+// declaring it, if it could be resolved.
+type MatchedHook struct {
+	Func string `json:"func"`
+	File string `json:"file,omitempty"`
+}
+
+// RulesList implements `otelc rules list [--json] [build args...]`. It
+// dry-run matches the configured rules against the dependencies of the module
+// in the current directory, without modifying it, and prints the func and call
+// rules that matched.
+func RulesList(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args().Slice()
+	asJSON := len(args) > 0 && (args[0] == "--json" || args[0] == "-json")
+	if asJSON {
+		args = args[1:]
+	}
+	sp := &SetupPhase{
+		logger:     util.LoggerFromContext(ctx),
+		ruleConfig: cmd.String("rules"),
+		ruleDirs:   cmd.StringSlice("rules-dir"),
+	}
+	listed, err := sp.listRules(ctx, args)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return writeMatchedRulesJSON(cmd.Writer, listed)
+	}
+	return writeMatchedRules(cmd.Writer, listed)
+}
+
+func (sp *SetupPhase) listRules(ctx context.Context, buildArgs []string) ([]MatchedRule, error) {
+	deps, err := sp.findDeps(ctx, subcmdBuild, buildArgs)
+	if err != nil {
+		return nil, err
+	}
+	// The default rules and their hooks are read from the extracted bundle
+	if err = sp.extract(); err != nil {
+		return nil, ex.Wrapf(err, "extracting embedded instrumentation pkg")
+	}
+	matched, err := sp.matchDeps(ctx, deps)
+	if err != nil {
+		return nil, ex.Wrapf(err, "matching dependencies to hook rules")
+	}
+	return listMatchedRules(ctx, matched), nil
+}
+
+// listMatchedRules describes the func and call rules of the matched rule sets,
+// sorted by target.
+func listMatchedRules(ctx context.Context, matched []*rule.InstRuleSet) []MatchedRule {
+	resolver := &hookResolver{dirs: make(map[string]string)}
+	var listed []MatchedRule
+	for _, set := range matched {
+		pkg := set.ModulePath
+		for _, r := range set.AllFuncRules() {
+			var hooks []MatchedHook
+			for _, fn := range []string{r.Before, r.After} {
+				if fn != "" {
+					hooks = append(hooks, MatchedHook{Func: fn, File: resolver.file(ctx, r.Path, fn)})
+				}
+			}
+			listed = append(listed, MatchedRule{
+				Rule:   r.GetName(),
+				Target: funcTarget(pkg, r.Recv, r.Func),
+				Hooks:  hooks,
+			})
+		}
+		for _, rs := range set.CallRules {
+			for _, r := range rs {
+				listed = append(listed, MatchedRule{
+					Rule:    r.GetName(),
+					Target:  fmt.Sprintf("%s calls %s", pkg, r.FunctionCall),
+					Replace: r.Replace,
+				})
+			}
+		}
+	}
+	// Call rules are matched once per source file of their target package
+	slices.SortFunc(listed, func(a, b MatchedRule) int {
+		return cmp.Or(strings.Compare(a.Target, b.Target), strings.Compare(a.Rule, b.Rule))
+	})
+	return slices.CompactFunc(listed, func(a, b MatchedRule) bool {
+		return a.Target == b.Target && a.Rule == b.Rule
+	})
+}
+
+// hookResolver finds the files declaring the hooks of func rules. The hooks of
+// the bundled instrumentation are found in the extracted bundle, those of
+// other modules in the module cache, if the module in the current directory
+// depends on them.
+type hookResolver struct {
+	base string            // directory import paths are resolved from, cwd if empty
+	dirs map[string]string // import path -> directory, "" if unresolved
+}
+
+func (hr *hookResolver) dir(ctx context.Context, importPath string) string {
+	if dir, ok := hr.dirs[importPath]; ok {
+		return dir
+	}
+	var dir string
+	if rel, isEmbedded := strings.CutPrefix(importPath, util.OtelcInstRoot+"/"); isEmbedded {
+		dir = filepath.Join(util.GetBuildTempDir(), unzippedInstDir, rel)
+	} else {
+		pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedFiles, Context: ctx, Dir: hr.base}, importPath)
+		if err == nil && len(pkgs) == 1 && len(pkgs[0].Errors) == 0 {
+			dir = pkgs[0].Dir
+		}
+	}
+	hr.dirs[importPath] = dir
+	return dir
+}
+
+// file returns the file of the package importPath declaring the function fn,
+// or "" if it cannot be found.
+func (hr *hookResolver) file(ctx context.Context, importPath, fn string) string {
+	dir := hr.dir(ctx, importPath)
+	if dir == "" {
+		return ""
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return ""
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		tree, err1 := ast.ParseFileFast(file)
+		if err1 != nil {
+			continue
+		}
+		if ast.FindFuncDeclWithoutRecv(tree, fn) != nil {
+			return file
+		}
+	}
+	return ""
+}
+
+func writeMatchedRulesJSON(w io.Writer, listed []MatchedRule) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(listed); err != nil {
+		return ex.Wrapf(err, "failed to print matched rules")
+	}
+	return nil
+}
+
+// writeMatchedRules prints a table with a row per hook of every rule.
+func writeMatchedRules(w io.Writer, listed []MatchedRule) error {
+	if len(listed) == 0 {
+		if _, err := fmt.Fprintln(w, "No rules matched"); err != nil {
+			return ex.Wrapf(err, "failed to print matched rules")
+		}
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "RULE\tTARGET\tHOOK\tFILE")
+	for _, r := range listed {
+		if len(r.Hooks) == 0 {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Rule, r.Target, r.Replace, "-")
+			continue
+		}
+		for _, h := range r.Hooks {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Rule, r.Target, h.Func, cmp.Or(h.File, "-"))
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return ex.Wrapf(err, "failed to print matched rules")
+	}
+	return nil
+}
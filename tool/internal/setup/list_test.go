@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package setup
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
+)
+
+func TestListRules(t *testing.T) {
+	moduleDir := t.TempDir()
+	writeFixtureFiles(t, moduleDir, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.21\n",
+		"main.go": `package main
+
+import "net/http"
+
+type Server struct{}
+
+func (*Server) Serve() {}
+
+func main() {
+	(&Server{}).Serve()
+	_, _ = http.Get("http://example.com")
+}
+`,
+		"hooks/hooks.go": "package hooks\n\nfunc BeforeServe() {}\n",
+		"hooks/after.go": "package hooks\n\nfunc AfterServe() {}\n",
+		"rules.yaml": `
+serve_hook:
+  target: main
+  where:
+    func: Serve
+    recv: "*Server"
+  do:
+    - inject_hooks:
+        before: BeforeServe
+        after: AfterServe
+        path: example.com/app/hooks
+unknown_hook:
+  target: main
+  where:
+    func: main
+  do:
+    - inject_hooks:
+        before: BeforeMain
+        path: example.com/missing
+get_wrapper:
+  target: main
+  function_call: net/http.Get
+  replace: "traced({{ . }})"
+unmatched_hook:
+  target: main
+  where:
+    func: Handle
+  do:
+    - inject_hooks:
+        before: BeforeHandle
+        path: example.com/app/hooks
+`,
+	})
+	t.Chdir(moduleDir)
+	workDir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(workDir, util.BuildTempDir), 0o755))
+	t.Setenv(util.EnvOtelcWorkDir, workDir)
+	goMod, err := os.ReadFile("go.mod")
+	require.NoError(t, err)
+
+	sp := newTestSetupPhase()
+	sp.ruleConfig = "rules.yaml"
+	listed, err := sp.listRules(t.Context(), []string{"."})
+	require.NoError(t, err)
+
+	hooksDir := filepath.Join(moduleDir, "hooks")
+	assert.Equal(t, []MatchedRule{
+		{
+			Rule:    "get_wrapper",
+			Target:  "main calls net/http.Get",
+			Replace: "traced({{ . }})",
+		},
+		{
+			Rule:   "serve_hook",
+			Target: "main.(*Server).Serve",
+			Hooks: []MatchedHook{
+				{Func: "BeforeServe", File: filepath.Join(hooksDir, "hooks.go")},
+				{Func: "AfterServe", File: filepath.Join(hooksDir, "after.go")},
+			},
+		},
+		{
+			Rule:   "unknown_hook",
+			Target: "main.main",
+			Hooks:  []MatchedHook{{Func: "BeforeMain"}},
+		},
+	}, listed)
+
+	after, err := os.ReadFile("go.mod")
+	require.NoError(t, err)
+	assert.Equal(t, string(goMod), string(after), "listing must leave the module untouched")
+
+	var out strings.Builder
+	require.NoError(t, writeMatchedRules(&out, listed))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, []string{"RULE", "TARGET", "HOOK", "FILE"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"serve_hook", "main.(*Server).Serve", "BeforeServe", filepath.Join(hooksDir, "hooks.go")},
+		strings.Fields(lines[2]))
+	assert.Equal(t, []string{"unknown_hook", "main.main", "BeforeMain", "-"}, strings.Fields(lines[4]))
+
+	out.Reset()
+	require.NoError(t, writeMatchedRulesJSON(&out, listed))
+	var decoded []MatchedRule
+	require.NoError(t, json.Unmarshal([]byte(out.String()), &decoded))
+	assert.Equal(t, listed, decoded)
+}
+
+func TestWriteMatchedRules_None(t *testing.T) {
+	var out strings.Builder
+	require.NoError(t, writeMatchedRules(&out, nil))
+	assert.Equal(t, "No rules matched\n", out.String())
+}
@@ -90,6 +90,9 @@ func parseRuleFromYaml(content []byte) ([]rule.InstRule, error) {
 			if err3 := rule.ValidateTarget(r.GetTarget()); err3 != nil {
 				return nil, ex.Wrapf(err3, "rule %q", name)
 			}
+			if err4 := rule.ValidateEnabledIf(r.GetEnabledIf()); err4 != nil {
+				return nil, ex.Wrapf(err4, "rule %q", name)
+			}
 			rules = append(rules, r)
 		}
 	}
@@ -269,6 +272,10 @@ func (sp *SetupPhase) preciseMatching(
 	// shares it), so compute it once and reuse it across each file's context.
 	isTest := isTestBuild(dep.Sources)
 
+	// matched records, for every rule evaluated against at least one source
+	// file (i.e. not excluded by its where filter), whether it matched.
+	matched := make(map[rule.InstRule]bool, len(ruleFilters))
+
 	for _, source := range dep.Sources {
 		if err := ctx.Err(); err != nil {
 			return nil, err
@@ -303,14 +310,34 @@ func (sp *SetupPhase) preciseMatching(
 			if rf.where != nil && !rf.where.Match(&mctx) {
 				continue
 			}
-			if err = sp.matchOneRule(tree, source, rf.rule, set, dep); err != nil {
-				return nil, err
+			ok, err1 := sp.matchOneRule(tree, source, rf.rule, set, dep)
+			if err1 != nil {
+				return nil, err1
 			}
+			matched[rf.rule] = matched[rf.rule] || ok
+		}
+	}
+	for _, rf := range ruleFilters {
+		if ok, evaluated := matched[rf.rule]; evaluated && !ok {
+			sp.reportUnmatchedRule(rf.rule, dep)
 		}
 	}
 	return set, nil
 }
 
+// reportUnmatchedRule warns about a rule whose target package is part of the
+// build but that found nothing to instrument in it, which usually means the
+// target declaration was renamed or removed upstream. Glob-target rules are
+// expected to miss in most packages they cover, so they are not reported.
+func (sp *SetupPhase) reportUnmatchedRule(r rule.InstRule, dep *Dependency) {
+	if rule.IsGlobTarget(r.GetTarget()) {
+		return
+	}
+	sp.Warn("rule matched nothing in its target package", "rule", r.GetName(), "dep", dep.ImportPath)
+	util.AnnotateWarning(fmt.Sprintf(
+		"instrumentation rule %q matched nothing in %s; the target may have changed", r.GetName(), dep.ImportPath))
+}
+
 // isTestBuild reports whether a compile invocation is part of a `go test` run.
 // The Go toolchain only ever feeds these inputs to the compiler while building
 // a test binary: a package augmented with its in-package _test.go files, the
@@ -337,63 +364,77 @@ func isTestBuild(sources []string) bool {
 }
 
 // matchOneRule performs precise AST matching for a single rule against a parsed
-// source file, adding the rule to the set if it matches.
+// source file, adding the rule to the set if it matches. It reports whether the
+// rule matched.
 func (sp *SetupPhase) matchOneRule(
 	tree *dst.File,
 	source string,
 	r rule.InstRule,
 	set *rule.InstRuleSet,
 	dep *Dependency,
-) error {
+) (bool, error) {
 	switch rt := r.(type) {
 	case *rule.InstFuncRule:
 		_, ok, err := ast.FindFuncDecl(tree, rt)
 		if err != nil {
-			return err
+			return false, err
 		}
 		if ok {
 			set.AddFuncRule(source, rt)
 			sp.Info("Match func rule", "rule", rt, "dep", dep)
 		}
+		return ok, nil
 	case *rule.InstStructRule:
 		structDecl := ast.FindStructDecl(tree, rt.Struct)
 		if structDecl != nil {
 			set.AddStructRule(source, rt)
 			sp.Info("Match struct rule", "rule", rt, "dep", dep)
 		}
+		return structDecl != nil, nil
 	case *rule.InstRawRule:
 		_, ok, err := ast.FindFuncDecl(tree, rt)
 		if err != nil {
-			return err
+			return false, err
 		}
 		if ok {
 			set.AddRawRule(source, rt)
 			sp.Info("Match raw rule", "rule", rt, "dep", dep)
 		}
+		return ok, nil
 	case *rule.InstCallRule:
 		// Call rules are added unconditionally to all source files in the
 		// target package. Unlike func/struct/raw rules, there is no cheap
 		// AST predicate to pre-filter files (the matching requires import
 		// alias resolution which happens during the instrument phase).
-		// Files without matching calls are a no-op in applyCallRule.
+		// Files without matching calls are a no-op in applyCallRule. Rules
+		// narrowed to some callers only need the files declaring them.
+		if len(rt.Caller) > 0 && len(ast.FindFuncDeclsNamed(tree, rt.Caller)) == 0 {
+			return false, nil
+		}
 		set.AddCallRule(source, rt)
 		sp.Info("Match call rule", "rule", rt, "dep", dep)
+		return true, nil
 	case *rule.InstDirectiveRule:
-		if ast.FileHasDirective(tree, rt.Directive) {
+		ok := ast.FileHasDirective(tree, rt.Directive)
+		if ok {
 			set.AddDirectiveRule(source, rt)
 			sp.Info("Match directive rule", "rule", rt, "dep", dep)
 		}
+		return ok, nil
 	case *rule.InstDeclRule:
-		if ast.FindNamedDecl(tree, rt.Identifier, rt.Kind) != nil {
+		ok := ast.FindNamedDecl(tree, rt.Identifier, rt.Kind) != nil
+		if ok {
 			set.AddDeclRule(source, rt)
 			sp.Info("Match decl rule", "rule", rt, "dep", dep)
 		}
+		return ok, nil
 	case *rule.InstFileRule:
 		// Skip as it's already processed
+		return true, nil
 	default:
 		util.ShouldNotReachHere()
+		return false, nil
 	}
-	return nil
 }
 
 func rulesFromDir(path string) ([]string, error) {
@@ -418,16 +459,17 @@ func rulesFromDir(path string) ([]string, error) {
 	return filesToProcess, nil
 }
 
+// LoadRules loads the rules of the comma-separated rule files and directories
+// in ruleConfig, as the --rules flag does.
+func LoadRules(ruleConfig string) ([]rule.InstRule, error) {
+	return loadCustomRules(ruleConfig)
+}
+
 func loadCustomRules(ruleConfig string) ([]rule.InstRule, error) {
-	// Deduplicate by YAML-entry name. A single entry can expand into several
-	// rules (e.g. a do: sequence with multiple modifiers), all sharing that
-	// name, so each name maps to the full slice of rules it produced. Re-reading
-	// the same entry replaces the whole group as a unit, preserving the
-	// "same rule file passed twice should dedupe" behavior.
+	// Deduplicate by YAML-entry name, so that the same rule file passed twice
+	// is only loaded once
 	ruleSet := make(map[string][]rule.InstRule)
-	ruleFiles := strings.SplitSeq(ruleConfig, ",")
-	var content []byte
-	for path := range ruleFiles {
+	for path := range strings.SplitSeq(ruleConfig, ",") {
 		path = strings.TrimSpace(path)
 
 		// Get all rule files from path (file or directory)
@@ -436,41 +478,36 @@ func loadCustomRules(ruleConfig string) ([]rule.InstRule, error) {
 			return nil, ex.Wrapf(err, "failed to stat %s", path)
 		}
 
-		var files []string
+		files := []string{path}
 		if info.IsDir() {
 			files, err = rulesFromDir(path)
 			if err != nil {
 				return nil, err
 			}
-		} else {
-			files = []string{path}
 		}
-		for _, file := range files {
-			content, err = os.ReadFile(file)
-			if err != nil {
-				return nil, ex.Wrapf(err, "failed to read %s from -rules flag", file)
-			}
-
-			var rules []rule.InstRule
-			rules, err = parseRuleFromYaml(content)
-			if err != nil {
-				return nil, err
-			}
-
-			// Group this file's rules by entry name, then replace any
-			// previously-seen entry of the same name as a single unit.
-			grouped := make(map[string][]rule.InstRule)
-			for _, r := range rules {
-				grouped[r.GetName()] = append(grouped[r.GetName()], r)
-			}
-			maps.Copy(ruleSet, grouped)
+		if err = addRuleFiles(ruleSet, files, "-rules", nil); err != nil {
+			return nil, err
 		}
 	}
 
 	return slices.Concat(slices.Collect(maps.Values(ruleSet))...), nil
 }
 
-func (sp *SetupPhase) loadRules() ([]rule.InstRule, error) {
+func (sp *SetupPhase) loadRules(ctx context.Context) ([]rule.InstRule, error) {
+	rules, err := sp.loadBaseRules()
+	if err != nil || len(sp.ruleDirs) == 0 {
+		return rules, err
+	}
+	// Add the user rules, which take precedence over the base rules
+	sp.Debug("rules source: rules dirs", "dirs", sp.ruleDirs)
+	user, err := loadRuleDirs(ctx, sp.ruleDirs)
+	if err != nil {
+		return nil, err
+	}
+	return mergeRules(rules, user), nil
+}
+
+func (sp *SetupPhase) loadBaseRules() ([]rule.InstRule, error) {
 	// Load rules from environment variable OTELC_RULES if specified. It has the
 	// highest priority.
 	rulePath := os.Getenv(util.EnvOtelcRules)
@@ -492,10 +529,20 @@ func (sp *SetupPhase) loadRules() ([]rule.InstRule, error) {
 
 func (sp *SetupPhase) matchDeps(ctx context.Context, deps []*Dependency) ([]*rule.InstRuleSet, error) {
 	// Construct the set of default allRules by parsing embedded data
-	allRules, err := sp.loadRules()
+	allRules, err := sp.loadRules(ctx)
 	if err != nil {
 		return nil, err
 	}
+	return sp.matchRules(ctx, deps, allRules)
+}
+
+// matchRules matches the given rules against the dependencies and returns a
+// rule set for every dependency that at least one rule applies to.
+func (sp *SetupPhase) matchRules(
+	ctx context.Context,
+	deps []*Dependency,
+	allRules []rule.InstRule,
+) ([]*rule.InstRuleSet, error) {
 	sp.Info("Found available rules", "rules", allRules)
 	if len(allRules) == 0 {
 		return nil, nil
@@ -508,6 +555,11 @@ func (sp *SetupPhase) matchDeps(ctx context.Context, deps []*Dependency) ([]*rul
 	exactRules := make(map[string][]rule.InstRule)
 	globRules := make([]rule.InstRule, 0)
 	for _, r := range allRules {
+		// Rules enabled only in other build environments never match
+		if !rule.EnabledIfMet(r.GetEnabledIf()) {
+			sp.Info("Skip rule disabled by enabled_if", "rule", r.GetName(), "enabled_if", r.GetEnabledIf())
+			continue
+		}
 		target := r.GetTarget()
 		if rule.IsGlobTarget(target) {
 			globRules = append(globRules, r)
@@ -537,11 +589,12 @@ func (sp *SetupPhase) matchDeps(ctx context.Context, deps []*Dependency) ([]*rul
 		})
 	}
 
-	if err = g.Wait(); err != nil {
+	if err := g.Wait(); err != nil {
 		return nil, err
 	}
 	if len(matched) == 0 {
 		_, _ = fmt.Fprintf(os.Stderr, "Warning: no instrumentation will be applied\n")
+		util.AnnotateWarning("no instrumentation rules matched any dependencies")
 		sp.Warn("no instrumentation rules matched any dependencies")
 	}
 	return matched, nil
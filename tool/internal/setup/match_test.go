@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
@@ -488,7 +489,7 @@ func TestRuleFilesFromDir(t *testing.T) {
 
 	sp.ruleConfig = dir
 
-	rules, err := sp.loadRules()
+	rules, err := sp.loadRules(t.Context())
 	require.NoError(t, err)
 	require.Len(t, rules, 2)
 }
@@ -514,7 +515,7 @@ func TestMultipleRuleFiles(t *testing.T) {
 
 	sp.ruleConfig = p1 + "," + p2
 
-	rules, err := sp.loadRules()
+	rules, err := sp.loadRules(t.Context())
 	require.NoError(t, err)
 	require.Len(t, rules, 2)
 	names := []string{
@@ -531,7 +532,7 @@ func TestMultipleRuleFiles(t *testing.T) {
 
 	sp.ruleConfig = p1 + "," + p1
 
-	rules, err = sp.loadRules()
+	rules, err = sp.loadRules(t.Context())
 	require.NoError(t, err)
 	require.Len(t, rules, 1)
 	require.Equal(t, "h1", rules[0].GetName())
@@ -559,7 +560,7 @@ func TestDoSequenceLoadsAllExpandedRules(t *testing.T) {
 	require.NoError(t, sp.extract())
 	sp.ruleConfig = p
 
-	rules, err := sp.loadRules()
+	rules, err := sp.loadRules(t.Context())
 	require.NoError(t, err)
 	require.Len(t, rules, 2)
 	for _, r := range rules {
@@ -586,7 +587,7 @@ func TestDoSequenceLoadsAllExpandedRules(t *testing.T) {
 	require.NoError(t, sp.extract())
 	sp.ruleConfig = p + "," + p
 
-	rules, err = sp.loadRules()
+	rules, err = sp.loadRules(t.Context())
 	require.NoError(t, err)
 	require.Len(t, rules, 2)
 }
@@ -636,7 +637,7 @@ func TestLoadDefaultRules(t *testing.T) {
 
 	// Verify that the custom rule specified by environment variable has
 	// higher priority than the custom rule specified by flag
-	rules, err := sp.loadRules()
+	rules, err := sp.loadRules(t.Context())
 	require.NoError(t, err)
 	require.NotEmpty(t, rules)
 	require.Len(t, rules, 1)
@@ -645,7 +646,7 @@ func TestLoadDefaultRules(t *testing.T) {
 	// Verify that the custom rule specified by flag has higher priority than
 	// default rules
 	t.Setenv(util.EnvOtelcRules, "")
-	rules, err = sp.loadRules()
+	rules, err = sp.loadRules(t.Context())
 	require.NoError(t, err)
 	require.NotEmpty(t, rules)
 	require.Len(t, rules, 1)
@@ -655,7 +656,7 @@ func TestLoadDefaultRules(t *testing.T) {
 	t.Setenv(util.EnvOtelcRules, "")
 	sp.ruleConfig = ""
 
-	rules, err = sp.loadRules()
+	rules, err = sp.loadRules(t.Context())
 	require.NoError(t, err)
 	require.NotEmpty(t, rules)
 	require.Greater(t, len(rules), 1, "default rules should be more than 1")
@@ -1298,6 +1299,73 @@ func TestMatchDeps_EmptyTargetRejected(t *testing.T) {
 	require.ErrorContains(t, err, "empty target")
 }
 
+func TestMatchDeps_EnabledIf(t *testing.T) {
+	// One rule file serves every environment: the rule only applies to builds
+	// whose APP_ENV meets its enabled_if condition.
+	dir := t.TempDir()
+	ruleFile := filepath.Join(dir, "env.yaml")
+	err := os.WriteFile(ruleFile, []byte(`prod_hook:
+  target: example.com/svc
+  enabled_if: APP_ENV=prod
+  where:
+    func: Handler
+  do:
+    - inject_hooks:
+        before: BeforeHandler
+        path: "example.com/hooks"
+`), 0o644)
+	require.NoError(t, err)
+	src := writeGoSource(t, "svc.go", "package svc\n\nfunc Handler() {}\n")
+
+	tests := []struct {
+		name    string
+		appEnv  string
+		matched bool
+	}{
+		{name: "condition met", appEnv: "prod", matched: true},
+		{name: "other environment", appEnv: "dev", matched: false},
+		{name: "unset", appEnv: "", matched: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_ENV", tt.appEnv)
+			sp := newTestSetupPhase()
+			sp.ruleConfig = ruleFile
+
+			deps := []*Dependency{
+				{ImportPath: "example.com/svc", Sources: []string{src}, CgoFiles: map[string]string{}},
+			}
+			matched, err := sp.matchDeps(context.Background(), deps)
+			require.NoError(t, err)
+			if tt.matched {
+				require.Len(t, matched, 1)
+				assert.Len(t, matched[0].FuncRules[src], 1)
+			} else {
+				assert.Empty(t, matched)
+			}
+		})
+	}
+}
+
+func TestMatchDeps_InvalidEnabledIfRejected(t *testing.T) {
+	dir := t.TempDir()
+	ruleFile := filepath.Join(dir, "bad.yaml")
+	err := os.WriteFile(ruleFile, []byte(`bad_hook:
+  target: example.com/svc
+  enabled_if: "=prod"
+  func: Handler
+  before: BeforeHandler
+  path: "example.com/hooks"
+`), 0o644)
+	require.NoError(t, err)
+
+	sp := newTestSetupPhase()
+	sp.ruleConfig = ruleFile
+
+	_, err = sp.matchDeps(context.Background(), nil)
+	require.ErrorContains(t, err, "enabled_if")
+}
+
 func TestMatchDeps_NoMatchesWarning(t *testing.T) {
 	// Create a rule file that won't match any dependencies
 	dir := t.TempDir()
@@ -1312,6 +1380,12 @@ func TestMatchDeps_NoMatchesWarning(t *testing.T) {
 `), 0o644)
 	require.NoError(t, err)
 
+	t.Setenv(util.EnvGitHubActions, "true")
+	var out strings.Builder
+	original := util.AnnotationOutput
+	util.AnnotationOutput = &out
+	t.Cleanup(func() { util.AnnotationOutput = original })
+
 	sp := newTestSetupPhase()
 	sp.ruleConfig = ruleFile
 
@@ -1326,4 +1400,56 @@ func TestMatchDeps_NoMatchesWarning(t *testing.T) {
 	matched, err := sp.matchDeps(context.Background(), deps)
 	require.NoError(t, err)
 	assert.Empty(t, matched)
+	assert.Equal(t, "::warning title=otelc::no instrumentation rules matched any dependencies\n", out.String())
+}
+
+func TestPreciseMatching_UnmatchedRuleAnnotation(t *testing.T) {
+	source := writeGoSource(t, "svc.go", "package main\n\nfunc Renamed() {}\n")
+	dep := &Dependency{
+		ImportPath: "example.com/svc",
+		Sources:    []string{source},
+	}
+	funcRule := &rule.InstFuncRule{
+		InstBaseRule: rule.InstBaseRule{
+			Name:   "test-drift",
+			Target: "example.com/svc",
+		},
+		Func:   "Handler",
+		Before: "BeforeHandler",
+		Path:   "example.com/hooks",
+	}
+
+	tests := []struct {
+		name     string
+		ciEnv    string
+		expected string
+	}{
+		{
+			name:  "in GitHub Actions",
+			ciEnv: "true",
+			expected: "::warning title=otelc::instrumentation rule \"test-drift\" matched nothing in " +
+				"example.com/svc; the target may have changed\n",
+		},
+		{
+			name:     "outside GitHub Actions",
+			ciEnv:    "",
+			expected: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(util.EnvGitHubActions, tt.ciEnv)
+			var out strings.Builder
+			original := util.AnnotationOutput
+			util.AnnotationOutput = &out
+			t.Cleanup(func() { util.AnnotationOutput = original })
+
+			sp := newTestSetupPhase()
+			set := rule.NewInstRuleSet(dep.ImportPath)
+			result, err := sp.preciseMatching(t.Context(), dep, []rule.InstRule{funcRule}, set)
+			require.NoError(t, err)
+			assert.Empty(t, result.FuncRules)
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
 }
@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package setup
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
+)
+
+// loadRuleDirs loads the rule files found under the directories given with
+// --rules-dir. Like the files of the --rules flag, rules are deduplicated by
+// their YAML entry name, and an entry of a later file replaces the earlier
+// entry of the same name as a whole.
+func loadRuleDirs(ctx context.Context, dirs []string) (map[string][]rule.InstRule, error) {
+	ruleSet := make(map[string][]rule.InstRule)
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, ex.Wrapf(err, "failed to stat rules dir %s", dir)
+		}
+		if !info.IsDir() {
+			return nil, ex.Newf("rules dir %s is not a directory", dir)
+		}
+		files, err := rulesFromDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		err = addRuleFiles(ruleSet, files, "-rules-dir", func(file string, rules []rule.InstRule) error {
+			return validateHooks(ctx, file, rules)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ruleSet, nil
+}
+
+// addRuleFiles parses the rule files, read for flag, into ruleSet. The rules
+// are grouped by their YAML entry name: a single entry can expand into several
+// rules (e.g. a do: sequence with multiple modifiers), all sharing that name,
+// and an entry of a later file replaces the earlier entry of the same name as
+// a whole. The rules of each file are passed to check, if any, first.
+func addRuleFiles(
+	ruleSet map[string][]rule.InstRule,
+	files []string,
+	flag string,
+	check func(file string, rules []rule.InstRule) error,
+) error {
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return ex.Wrapf(err, "failed to read %s from %s flag", file, flag)
+		}
+		rules, err := parseRuleFromYaml(content)
+		if err != nil {
+			return ex.Wrapf(err, "failed to parse %s", file)
+		}
+		if check != nil {
+			if err = check(file, rules); err != nil {
+				return err
+			}
+		}
+		grouped := make(map[string][]rule.InstRule)
+		for _, r := range rules {
+			grouped[r.GetName()] = append(grouped[r.GetName()], r)
+		}
+		maps.Copy(ruleSet, grouped)
+	}
+	return nil
+}
+
+// validateHooks checks that the hooks the rules of ruleFile inject exist, so
+// that a rule referencing a missing hook fails when it is loaded rather than
+// when the instrumented package is compiled. Hook packages are resolved from
+// the directory of the rule file, i.e. within the module declaring the rules.
+func validateHooks(ctx context.Context, ruleFile string, rules []rule.InstRule) error {
+	resolver := &hookResolver{
+		base: filepath.Dir(ruleFile),
+		dirs: make(map[string]string),
+	}
+	for _, r := range rules {
+		switch r := r.(type) {
+		case *rule.InstFuncRule:
+			for _, fn := range []string{r.Before, r.After} {
+				if fn != "" && resolver.file(ctx, r.Path, fn) == "" {
+					return ex.Newf("rule %q in %s: hook %s not found in package %q",
+						r.GetName(), ruleFile, fn, r.Path)
+				}
+			}
+		case *rule.InstFileRule:
+			dir := resolver.dir(ctx, r.Path)
+			if dir == "" {
+				return ex.Newf("rule %q in %s: package %q not found", r.GetName(), ruleFile, r.Path)
+			}
+			if _, err := os.Stat(filepath.Join(dir, r.File)); err != nil {
+				return ex.Newf("rule %q in %s: file %s not found in package %q",
+					r.GetName(), ruleFile, r.File, r.Path)
+			}
+		}
+	}
+	return nil
+}
+
+// mergeRules adds the user rules to the base rules. A user entry replaces all
+// the base rules of the same name.
+func mergeRules(base []rule.InstRule, user map[string][]rule.InstRule) []rule.InstRule {
+	merged := make([]rule.InstRule, 0, len(base))
+	for _, r := range base {
+		if _, overridden := user[r.GetName()]; !overridden {
+			merged = append(merged, r)
+		}
+	}
+	return append(merged, slices.Concat(slices.Collect(maps.Values(user))...)...)
+}
@@ -0,0 +1,156 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package setup
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
+)
+
+// writeRulesDir writes a module of hooks along with a rule file using them,
+// and returns the directory of the module.
+func writeRulesDir(t *testing.T, rules string) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFixtureFiles(t, dir, map[string]string{
+		"go.mod":           "module example.com/hooks\n\ngo 1.21\n",
+		"hooks.go":         "package hooks\n\nfunc BeforeServe() {}\n\nfunc AfterServe() {}\n",
+		"extra/extra.go":   "package extra\n",
+		"rules/otelc.yaml": rules,
+	})
+	return dir
+}
+
+func TestLoadRuleDirs(t *testing.T) {
+	base := writeCustomRules(t, "base.yaml", `h1:
+  target: main
+  func: Example
+  raw: "_ = 1"
+h2:
+  target: main
+  func: Example
+  raw: "_ = 2"`)
+	first := writeRulesDir(t, `h2:
+  target: main
+  where:
+    func: Serve
+  do:
+    - inject_hooks:
+        before: BeforeServe
+        after: AfterServe
+        path: example.com/hooks
+h3:
+  target: main
+  func: Example
+  raw: "_ = 3"`)
+	second := writeRulesDir(t, `h3:
+  target: main
+  do:
+    - add_file:
+        file: extra.go
+        path: example.com/hooks/extra`)
+	t.Setenv(util.EnvOtelcRules, "")
+
+	sp := newTestSetupPhase()
+	sp.ruleConfig = base
+	sp.ruleDirs = []string{first, second}
+	rules, err := sp.loadRules(t.Context())
+	require.NoError(t, err)
+
+	byName := make(map[string]rule.InstRule)
+	for _, r := range rules {
+		byName[r.GetName()] = r
+	}
+	require.Len(t, rules, 3)
+	assert.IsType(t, &rule.InstRawRule{}, byName["h1"], "rules not overridden are kept")
+	assert.IsType(t, &rule.InstFuncRule{}, byName["h2"], "user rules take precedence over the base rules")
+	assert.IsType(t, &rule.InstFileRule{}, byName["h3"], "later dirs take precedence over earlier ones")
+}
+
+func TestLoadRuleDirs_DefaultRules(t *testing.T) {
+	dir := writeRulesDir(t, `custom:
+  target: main
+  func: Example
+  raw: "_ = 1"`)
+	t.Setenv(util.EnvOtelcRules, "")
+
+	sp := newTestSetupPhase()
+	require.NoError(t, sp.extract())
+	defaults, err := sp.loadRules(t.Context())
+	require.NoError(t, err)
+
+	sp.ruleDirs = []string{dir}
+	rules, err := sp.loadRules(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, rules, len(defaults)+1, "user rules are loaded alongside the default ones")
+}
+
+func TestLoadRuleDirs_MissingHook(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    string
+		expected string
+	}{
+		{
+			name: "missing hook function",
+			rules: `serve:
+  target: main
+  where:
+    func: Serve
+  do:
+    - inject_hooks:
+        before: BeforeServe
+        after: AfterMissing
+        path: example.com/hooks`,
+			expected: `hook AfterMissing not found in package "example.com/hooks"`,
+		},
+		{
+			name: "missing hook package",
+			rules: `serve:
+  target: main
+  where:
+    func: Serve
+  do:
+    - inject_hooks:
+        before: BeforeServe
+        path: example.com/hooks/missing`,
+			expected: `hook BeforeServe not found in package "example.com/hooks/missing"`,
+		},
+		{
+			name: "missing file",
+			rules: `extra:
+  target: main
+  do:
+    - add_file:
+        file: missing.go
+        path: example.com/hooks/extra`,
+			expected: `file missing.go not found in package "example.com/hooks/extra"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeRulesDir(t, tt.rules)
+			_, err := loadRuleDirs(t.Context(), []string{dir})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+			assert.Contains(t, err.Error(), filepath.Join(dir, "rules", "otelc.yaml"))
+		})
+	}
+}
+
+func TestLoadRuleDirs_NotADirectory(t *testing.T) {
+	file := writeCustomRules(t, "r.otelc.yaml", `h1:
+  target: main
+  func: Example
+  raw: "_ = 1"`)
+	_, err := loadRuleDirs(t.Context(), []string{file})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not a directory")
+}
@@ -15,7 +15,7 @@ import (
 	"time"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
-	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/instrument"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/imports"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/pkgload"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
@@ -26,6 +26,17 @@ import (
 type SetupPhase struct {
 	logger     *slog.Logger
 	ruleConfig string
+	// ruleDirs are directories of user rules loaded on top of the rules above,
+	// see loadRuleDirs
+	ruleDirs []string
+	// allowlist is the file listing the instrumentation modules that may be
+	// injected, see checkAllowlist. Any module may be injected when empty.
+	allowlist string
+	// audited lists the dependencies added to the project, see audit
+	audited []auditEntry
+	// modFlag is the -mod of the build, from its arguments or GOFLAGS, which
+	// decides whether syncDeps vendors the added modules, see vendorMode
+	modFlag string
 }
 
 func (sp *SetupPhase) Info(msg string, args ...any)  { sp.logger.Info(msg, args...) }
@@ -92,6 +103,7 @@ var testFlagsWithValues = map[string]bool{
 	"-coverprofile":         true,
 	"-cpu":                  true,
 	"-cpuprofile":           true,
+	"-exec":                 true,
 	"-fuzz":                 true,
 	"-fuzzminimizetime":     true,
 	"-fuzztime":             true,
@@ -129,9 +141,14 @@ const commandLineArgumentsPackage = "command-line-arguments"
 //   - args ["-a", "cmd"] returns packages for the "cmd" package in the module
 //   - args ["-a", ".", "./cmd"] returns packages for both "." and "./cmd"
 //   - args [] returns packages for "."
+//
+// Packages are loaded for the target platform (GOOS/GOARCH from the environment)
+// with the build-context flags from args, such as -tags, so that file selection
+// by build constraints matches the actual build.
 func getBuildPackages(ctx context.Context, args []string) ([]*packages.Package, error) {
 	logger := util.LoggerFromContext(ctx)
 	mode := packages.NeedName | packages.NeedFiles | packages.NeedModule
+	buildFlags := extractBuildFlags(args)
 
 	pkgTargets, fileTargets, err := splitBuildTargets(args)
 	if err != nil {
@@ -144,7 +161,7 @@ func getBuildPackages(ctx context.Context, args []string) ([]*packages.Package,
 	)
 	switch {
 	case len(fileTargets) > 0:
-		pkgs, loadErr = pkgload.LoadPackages(ctx, mode, nil, fileTargets...)
+		pkgs, loadErr = pkgload.LoadPackages(ctx, mode, buildFlags, fileTargets...)
 		if loadErr != nil {
 			return nil, ex.Wrapf(loadErr, "failed to load packages for files %v", fileTargets)
 		}
@@ -153,12 +170,12 @@ func getBuildPackages(ctx context.Context, args []string) ([]*packages.Package,
 			return nil, ex.New("multiple packages found for file targets")
 		}
 	case len(pkgTargets) > 0:
-		pkgs, loadErr = pkgload.LoadPackages(ctx, mode, nil, pkgTargets...)
+		pkgs, loadErr = pkgload.LoadPackages(ctx, mode, buildFlags, pkgTargets...)
 		if loadErr != nil {
 			return nil, ex.Wrapf(loadErr, "failed to load packages for patterns %v", pkgTargets)
 		}
 	default:
-		pkgs, loadErr = pkgload.LoadPackages(ctx, mode, nil, ".")
+		pkgs, loadErr = pkgload.LoadPackages(ctx, mode, buildFlags, ".")
 		if loadErr != nil {
 			return nil, ex.Wrapf(loadErr, "failed to load packages for pattern .")
 		}
@@ -282,7 +299,7 @@ func (sp *SetupPhase) generateRuntimePerPackage(
 		}
 
 		// Introduce additional hook code by generating otelc.runtime.go
-		if err := sp.addDeps(ctx, matched, pkgDir); err != nil {
+		if err := sp.addDeps(ctx, matched, pkgDir, pkg.Name); err != nil {
 			return nil, ex.Wrapf(err, "adding deps for package at %s", pkgDir)
 		}
 		moduleDirs[moduleDir] = true
@@ -292,6 +309,13 @@ func (sp *SetupPhase) generateRuntimePerPackage(
 
 // Setup prepares the environment for further instrumentation.
 func Setup(ctx context.Context, cmd *cli.Command) error {
+	_, _, err := setup(ctx, cmd)
+	return err
+}
+
+// setup runs the setup phase and returns it along with the rule sets it
+// matched, which the instrument phase applies to the build.
+func setup(ctx context.Context, cmd *cli.Command) (*SetupPhase, []*rule.InstRuleSet, error) {
 	// Since Setup can be invoked in different contexts (i.e, via `otelc setup` or as part of `otelc go build`),
 	// we need to handle the arguments accordingly. If the command is `go build` or `go install`, we should trim the first argument
 	args := cmd.Args().Slice()
@@ -305,37 +329,45 @@ func Setup(ctx context.Context, cmd *cli.Command) error {
 
 	if isSetup() {
 		logger.InfoContext(ctx, "Setup has already been completed, skipping setup.")
-		return nil
+		return nil, nil, nil
 	}
 
 	sp := &SetupPhase{
 		logger:     logger,
 		ruleConfig: cmd.String("rules"),
+		ruleDirs:   cmd.StringSlice("rules-dir"),
+		allowlist:  cmd.String("allowlist"),
+		modFlag:    modFlagValue(args, os.Getenv("GOFLAGS")),
 	}
 
 	// Introduce additional hook code by generating otelc.runtime.go
 	// Use GetPackage to determine the build target directory
 	pkgs, err := getBuildPackages(ctx, args)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	// Find all dependencies of the project being build
 	deps, err := sp.findDeps(ctx, subcommand, args)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	// Extract the embedded pkg module into local directory
 	err = sp.extract()
 	if err != nil {
-		return ex.Wrapf(err, "extracting embedded instrumentation pkg")
+		return nil, nil, ex.Wrapf(err, "extracting embedded instrumentation pkg")
 	}
 
 	// Match the hook code with these dependencies
 	matched, err := sp.matchDeps(ctx, deps)
 	if err != nil {
-		return ex.Wrapf(err, "matching dependencies to hook rules")
+		return nil, nil, ex.Wrapf(err, "matching dependencies to hook rules")
+	}
+
+	// Refuse to inject modules the allowlist does not list, if any
+	if err = sp.checkAllowlist(matched); err != nil {
+		return nil, nil, err
 	}
 
 	// Track generated & modified files with state manager
@@ -355,27 +387,35 @@ func Setup(ctx context.Context, cmd *cli.Command) error {
 	// Generate otelc.runtime.go for all packages
 	moduleDirs, err := sp.generateRuntimePerPackage(ctx, pkgs, matched)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	// Backup go.mod, go.sum and go.work.sum files before modifying them
 	backupFiles, err := getBackupFiles(ctx, moduleDirs)
 	if err != nil {
-		return ex.Wrapf(err, "finding files to backup")
+		return nil, nil, ex.Wrapf(err, "finding files to backup")
 	}
 	if err = stateManager.TrackAll(backupFiles...); err != nil {
-		return ex.Wrapf(err, "tracking backup files")
+		return nil, nil, ex.Wrapf(err, "tracking backup files")
 	}
 
 	// Sync new dependencies to go.mod or vendor/modules.txt
 	for moduleDir := range moduleDirs {
 		if err = sp.syncDeps(ctx, matched, moduleDir); err != nil {
-			return ex.Wrapf(err, "syncing deps in module dir %s", moduleDir)
+			return nil, nil, ex.Wrapf(err, "syncing deps in module dir %s", moduleDir)
 		}
 	}
 
+	// Report every import and module added to the project for auditing
+	if err = sp.storeAudit(); err != nil {
+		return nil, nil, err
+	}
+
 	// Write the matched ruleset to matched.json for further instrument phase
-	return sp.store(ctx, matched, moduleDirs)
+	if err = sp.store(ctx, matched, moduleDirs); err != nil {
+		return nil, nil, err
+	}
+	return sp, matched, nil
 }
 
 // setupGoCache creates a persistent GOCACHE in .otelc-build/gocache if one isn't already set.
@@ -491,6 +531,17 @@ func extractBuildFlags(args []string) []string {
 	return append(valueFlags, enabledBoolFlags...)
 }
 
+// forceRebuild makes the go build flags in args force a rebuild with -a,
+// replacing the -a flags they may already hold. Otherwise the packages whose
+// sources and instrumentation did not change since the previous build are
+// taken from the build cache, see instrument.printToolID.
+func forceRebuild(args []string) []string {
+	rest := slices.DeleteFunc(slices.Clone(args), func(arg string) bool {
+		return arg == "-a" || strings.HasPrefix(arg, "-a=")
+	})
+	return append([]string{"-a"}, rest...)
+}
+
 // BuildWithToolexec builds the project with the toolexec mode
 func BuildWithToolexec(ctx context.Context, cmd *cli.Command) error {
 	args := cmd.Args().Slice()
@@ -513,6 +564,9 @@ func BuildWithToolexec(ctx context.Context, cmd *cli.Command) error {
 	newArgs = append(newArgs, insert)
 	// Add the rest
 	restArgs := args[1:]
+	if cmd.Bool("force-rebuild") {
+		restArgs = forceRebuild(restArgs)
+	}
 	if _, fileTargets, err2 := splitBuildTargets(restArgs); err2 == nil && len(fileTargets) > 0 {
 		// add otelc.runtime.go manually to command line for file targets
 		dir := filepath.Dir(fileTargets[0])
@@ -551,9 +605,11 @@ func GoBuild(ctx context.Context, cmd *cli.Command) error {
 	logger := util.LoggerFromContext(ctx)
 	ctx = ContextWithStateManager(ctx, NewStateManager())
 
-	// Clean up import tracking files from previous builds at the start
-	// to prevent stale data from affecting this build.
-	instrument.CleanupImportTrackingFiles()
+	// Clean up the package names resolved by previous builds at the start
+	// to prevent stale data from affecting this build. The import tracking
+	// files are kept: the instrumented packages this build reuses from the
+	// cache are not compiled again to track them.
+	imports.CleanupPackageNames()
 
 	if !cmd.Args().Present() {
 		return ex.Newf("no command provided. Only 'go build', 'go install' and 'go test' are supported")
@@ -578,7 +634,7 @@ func GoBuild(ctx context.Context, cmd *cli.Command) error {
 	statsEnabled := os.Getenv(util.EnvOtelcStats) != ""
 
 	setupStart := time.Now()
-	err := Setup(ctx, cmd)
+	sp, matched, err := setup(ctx, cmd)
 	if err != nil {
 		return err
 	}
@@ -587,6 +643,21 @@ func GoBuild(ctx context.Context, cmd *cli.Command) error {
 	}
 	logger.InfoContext(ctx, "Setup completed successfully")
 
+	// A dry run reports what setup matched and stops before the build; the
+	// cleanup above reverts the changes setup made to the project.
+	if cmd.Bool("dry-run") {
+		// Setup returns no phase when it was already completed, so there is
+		// nothing it matched to report
+		if sp == nil {
+			return ex.Newf("nothing to report for a dry run: setup has already been completed, run otelc cleanup first")
+		}
+		report := sp.dryRunReport(listMatchedRules(ctx, matched))
+		if cmd.Bool("json") {
+			return writeDryRunReportJSON(cmd.Writer, report)
+		}
+		return writeDryRunReport(cmd.Writer, report)
+	}
+
 	buildStart := time.Now()
 	err = BuildWithToolexec(ctx, cmd)
 	if err != nil {
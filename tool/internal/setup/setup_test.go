@@ -122,6 +122,23 @@ func TestGetPackages(t *testing.T) {
 	}
 }
 
+func TestGetPackages_BuildConstraints(t *testing.T) {
+	setupTestModule(t, nil)
+	tagged := filepath.Join("tagged", "main.go")
+	require.NoError(t, os.MkdirAll(filepath.Dir(tagged), 0o755))
+	require.NoError(t, os.WriteFile(tagged, []byte("//go:build special\n\npackage main\n\nfunc main() {}\n"), 0o644))
+
+	// Without the tag the constraint excludes every file, so there is nothing
+	// to build; with it, the package loads just as `go build -tags` sees it.
+	_, err := getBuildPackages(t.Context(), []string{"./tagged"})
+	require.Error(t, err)
+
+	pkgs, err := getBuildPackages(t.Context(), []string{"-tags=special", "./tagged"})
+	require.NoError(t, err)
+	require.Len(t, pkgs, 1)
+	assert.Equal(t, "testmodule/tagged", pkgs[0].PkgPath)
+}
+
 func TestSplitBuildTargets(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -207,6 +224,13 @@ func TestSplitBuildTargets(t *testing.T) {
 			notPkgTargets: []string{"off"},
 			expectError:   false,
 		},
+		{
+			name:          "go test -exec value is not a package",
+			targets:       []string{"-exec", "sudo", "./pkg"},
+			pkgTargets:    []string{"./pkg"},
+			notPkgTargets: []string{"sudo"},
+			expectError:   false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -542,3 +566,37 @@ func TestExtractBuildFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestForceRebuild(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "adds -a",
+			args:     []string{"-o", "app", "./cmd"},
+			expected: []string{"-a", "-o", "app", "./cmd"},
+		},
+		{
+			name:     "already forced",
+			args:     []string{"-a", "./cmd"},
+			expected: []string{"-a", "./cmd"},
+		},
+		{
+			name:     "already forced with a value",
+			args:     []string{"-o", "app", "-a=true", "./cmd"},
+			expected: []string{"-a", "-o", "app", "./cmd"},
+		},
+		{
+			name:     "disabled explicitly",
+			args:     []string{"-a=false", "./cmd"},
+			expected: []string{"-a", "./cmd"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, forceRebuild(tt.args))
+		})
+	}
+}
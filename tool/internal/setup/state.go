@@ -170,6 +170,21 @@ func (s *StateManager) Track(path string) error {
 	return nil
 }
 
+// TrackCreated records path, created after tracking started, so that Revert
+// removes it along with everything under it.
+func (s *StateManager) TrackCreated(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return ex.Wrapf(err, "failed to get absolute path for %s", path)
+	}
+
+	abs = filepath.Clean(abs)
+	if _, ok := s.files[abs]; !ok {
+		s.files[abs] = false
+	}
+	return nil
+}
+
 // Commit persists the tracked state to disk so it can be restored by a future
 // process.
 func (s *StateManager) Commit() error {
@@ -205,7 +220,7 @@ func (s *StateManager) Commit() error {
 // Revert restores all tracked files to the state they were in when tracked.
 //
 // Files that originally existed are restored from their snapshots. Files that
-// did not exist when tracked are removed if they exist.
+// did not exist when tracked are removed if they exist, directories included.
 func (s *StateManager) Revert() error {
 	var err error
 
@@ -214,7 +229,7 @@ func (s *StateManager) Revert() error {
 	for path, existed := range s.files {
 		if !existed {
 			if util.PathExists(path) {
-				err = ex.Join(err, os.Remove(path))
+				err = ex.Join(err, os.RemoveAll(path))
 			}
 			continue
 		}
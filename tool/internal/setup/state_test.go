@@ -339,6 +339,23 @@ func TestStateManagerRevert(t *testing.T) {
 	require.False(t, util.PathExists(generated))
 }
 
+func TestStateManagerRevert_Created(t *testing.T) {
+	tmp := t.TempDir()
+	t.Chdir(tmp)
+
+	created := filepath.Join(tmp, "vendor", "example.com")
+	mustWriteFile(t, filepath.Join(created, "dep", "dep.go"), "package dep")
+
+	stateManager := NewStateManager()
+	require.NoError(t, stateManager.TrackCreated(created))
+	require.Equal(t, map[string]bool{created: false}, stateManager.files)
+
+	require.NoError(t, stateManager.Revert())
+
+	require.False(t, util.PathExists(created))
+	require.True(t, util.PathExists(filepath.Join(tmp, "vendor")))
+}
+
 func TestStateManagerRoundTrip(t *testing.T) {
 	tmp := t.TempDir()
 	t.Chdir(tmp)
@@ -5,8 +5,10 @@ package setup
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	goversion "go/version"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -43,10 +45,178 @@ func writeGoMod(gomod string, modfile *modfile.File) error {
 	return nil
 }
 
+// runModTidy syncs go.mod and go.sum after the setup phase edited go.mod. go mod
+// tidy ignores any -mod of the build, e.g. -mod=readonly in GOFLAGS, which
+// then only applies to the build itself and finds go.mod up to date.
 func runModTidy(ctx context.Context, moduleDir string) error {
 	return util.RunCmdInDir(ctx, moduleDir, "go", "mod", "tidy")
 }
 
+// modFlagValue returns the value of the -mod flag that applies to the build:
+// the last one in args, else the last one in goflags.
+func modFlagValue(args []string, goflags string) string {
+	value := ""
+	for _, flags := range [][]string{strings.Fields(goflags), args} {
+		for i := 0; i < len(flags); i++ {
+			flag := flags[i]
+			if strings.HasPrefix(flag, "--") {
+				flag = flag[1:]
+			}
+			if v, ok := strings.CutPrefix(flag, "-mod="); ok {
+				value = v
+			} else if flag == "-mod" && i+1 < len(flags) {
+				value = flags[i+1]
+				i++
+			}
+		}
+	}
+	return value
+}
+
+// vendorMode reports whether the build loads the packages of the module in
+// moduleDir from its vendor directory, either because modFlag asks for it or,
+// when no -mod is given, because the module has a vendor/modules.txt.
+func vendorMode(moduleDir, modFlag string) bool {
+	if modFlag != "" {
+		return modFlag == "vendor"
+	}
+	return util.PathExists(filepath.Join(moduleDir, "vendor", "modules.txt"))
+}
+
+// runModVendor copies the modules added to go.mod into the vendor directory of
+// moduleDir and marks them explicit in vendor/modules.txt, without which a
+// vendored build rejects go.mod as inconsistent. The vendor directory is
+// tracked so that Revert restores it: vendor/modules.txt and the modules whose
+// vendored version changes are snapshotted beforehand, and whatever go mod
+// vendor creates is recorded for removal. The other modules are vendored
+// again as they were.
+func runModVendor(ctx context.Context, moduleDir string) error {
+	stateManager, _ := StateManagerFromContext(ctx)
+	vendorDir := filepath.Join(moduleDir, "vendor")
+
+	changed, err := changedVendorModules(moduleDir)
+	if err != nil {
+		return err
+	}
+	err = stateManager.Track(filepath.Join(vendorDir, "modules.txt"))
+	if err != nil {
+		return err
+	}
+	for _, modPath := range changed {
+		err = filepath.WalkDir(filepath.Join(vendorDir, filepath.FromSlash(modPath)),
+			func(path string, d fs.DirEntry, walkErr error) error {
+				if walkErr != nil {
+					return walkErr
+				}
+				if d.IsDir() {
+					return nil
+				}
+				return stateManager.Track(path)
+			})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return ex.Wrapf(err, "tracking vendored module %s", modPath)
+		}
+	}
+
+	existing := make(map[string]bool)
+	err = filepath.WalkDir(vendorDir, func(path string, _ fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		existing[path] = true
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return ex.Wrapf(err, "listing vendor directory %s", vendorDir)
+	}
+
+	err = util.RunCmdInDir(ctx, moduleDir, "go", "mod", "vendor")
+	if err != nil {
+		return err
+	}
+
+	err = filepath.WalkDir(vendorDir, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if existing[path] {
+			return nil
+		}
+		if err := stateManager.TrackCreated(path); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return ex.Wrapf(err, "tracking vendored modules in %s", vendorDir)
+	}
+	return nil
+}
+
+// changedVendorModules returns the modules of vendor/modules.txt in moduleDir
+// whose version or replacement differs in go.mod, or which go.mod no longer
+// requires: go mod vendor rewrites or removes their vendored files.
+func changedVendorModules(moduleDir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(moduleDir, "vendor", "modules.txt"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, ex.Wrapf(err, "failed to read vendor/modules.txt")
+	}
+	modFile, err := parseGoMod(filepath.Join(moduleDir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	required := make(map[string]string, len(modFile.Require))
+	for _, r := range modFile.Require {
+		required[r.Mod.Path] = vendoredVersion(modFile, r.Mod.Path, r.Mod.Version)
+	}
+
+	var changed []string
+	for line := range strings.SplitSeq(string(data), "\n") {
+		// Module lines read "# path version [=> replacement [version]]"
+		rest, ok := strings.CutPrefix(line, "# ")
+		if !ok {
+			continue
+		}
+		modPath, version, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		if required[modPath] != version {
+			changed = append(changed, modPath)
+		}
+	}
+	return changed, nil
+}
+
+// vendoredVersion returns how vendor/modules.txt lists the version of the
+// module modPath that go.mod requires, with its replacement if any.
+func vendoredVersion(modFile *modfile.File, modPath, version string) string {
+	var replace *modfile.Replace
+	for _, r := range modFile.Replace {
+		if r.Old.Path != modPath {
+			continue
+		}
+		if r.Old.Version == version {
+			replace = r
+			break
+		}
+		if r.Old.Version == "" {
+			replace = r
+		}
+	}
+	if replace == nil {
+		return version
+	}
+	version += " => " + replace.New.Path
+	if replace.New.Version != "" {
+		version += " " + replace.New.Version
+	}
+	return version
+}
+
 func addReplace(modfile *modfile.File, oldPath, newPath string) (bool, error) {
 	hasReplace := false
 	for _, r := range modfile.Replace {
@@ -65,20 +235,38 @@ func addReplace(modfile *modfile.File, oldPath, newPath string) (bool, error) {
 	return false, nil
 }
 
-// versionSnapshot records go directive and direct dep versions before tidy.
+// validateReplaceTarget checks that newPath, the local directory a replace
+// directive added for oldPath points at, holds a module. The directories are
+// extracted from the otelc binary, so a missing one means the extraction
+// failed or went elsewhere, which go mod tidy would otherwise only report as
+// an obscure module lookup error.
+func validateReplaceTarget(oldPath, newPath string) error {
+	goMod := filepath.Join(newPath, "go.mod")
+	if !util.PathExists(goMod) {
+		return ex.Newf("replace %s => %s points at no module: %s does not exist; "+
+			"the embedded instrumentation packages were not extracted there", oldPath, newPath, goMod)
+	}
+	return nil
+}
+
+// versionSnapshot records go directive and direct dep versions before tidy,
+// along with every required module, indirect ones included.
 type versionSnapshot struct {
 	goVersion string
 	deps      map[string]string
+	required  map[string]bool
 }
 
 func snapshotVersion(mf *modfile.File) versionSnapshot {
 	snap := versionSnapshot{
-		deps: make(map[string]string),
+		deps:     make(map[string]string),
+		required: make(map[string]bool),
 	}
 	if mf.Go != nil {
 		snap.goVersion = mf.Go.Version
 	}
 	for _, req := range mf.Require {
+		snap.required[req.Mod.Path] = true
 		if !req.Indirect {
 			snap.deps[req.Mod.Path] = req.Mod.Version
 		}
@@ -120,6 +308,26 @@ func (sp *SetupPhase) warnVersion(goModPath string, before versionSnapshot) erro
 	return nil
 }
 
+// auditModules audits the modules that go.mod requires after tidy but did not
+// require before.
+func (sp *SetupPhase) auditModules(goModPath string, before versionSnapshot) error {
+	after, err := parseGoMod(goModPath)
+	if err != nil {
+		return ex.Wrapf(err, "unable to audit added modules after go mod tidy")
+	}
+	for _, req := range after.Require {
+		if !before.required[req.Mod.Path] {
+			sp.audit(auditEntry{
+				Kind:    auditKindModule,
+				Path:    req.Mod.Path,
+				Version: req.Mod.Version,
+				Target:  goModPath,
+			})
+		}
+	}
+	return nil
+}
+
 func (sp *SetupPhase) syncDeps(ctx context.Context, matched []*rule.InstRuleSet, moduleDir string) error {
 	funcRules := []*rule.InstFuncRule{}
 	fileRules := []*rule.InstFileRule{}
@@ -178,6 +386,10 @@ func (sp *SetupPhase) syncDeps(ctx context.Context, matched []*rule.InstRuleSet,
 		}
 		changed = changed || added
 		if added {
+			// Replace directives the user wrote are left to the go command
+			if err = validateReplaceTarget(oldPath, newPath); err != nil {
+				return err
+			}
 			sp.Info("Replace dependency", "old", oldPath, "new", newPath)
 		}
 	}
@@ -193,11 +405,24 @@ func (sp *SetupPhase) syncDeps(ctx context.Context, matched []*rule.InstRuleSet,
 		if err != nil {
 			return ex.Wrapf(err, "running go mod tidy in %s", moduleDir)
 		}
+		// A vendored build loads every package from vendor/, so the modules
+		// tidy added must be vendored too.
+		if vendorMode(moduleDir, sp.modFlag) {
+			err = runModVendor(ctx, moduleDir)
+			if err != nil {
+				return ex.Wrapf(err, "running go mod vendor in %s", moduleDir)
+			}
+			sp.keepForDebug(filepath.Join(moduleDir, "vendor", "modules.txt"))
+		}
 		// Compare after tidy because MVS may raise existing consumer versions.
 		err = sp.warnVersion(goModFile, before)
 		if err != nil {
 			return err
 		}
+		err = sp.auditModules(goModFile, before)
+		if err != nil {
+			return err
+		}
 		sp.keepForDebug(goModFile)
 	}
 	return nil
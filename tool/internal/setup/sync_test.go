@@ -5,6 +5,7 @@ package setup
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
@@ -353,6 +354,266 @@ replace %s => /already/there
 			"replace "+util.OtelcInstRoot+"/net/http/client"))
 }
 
+func TestSyncDeps_MissingReplaceTarget(t *testing.T) {
+	goMod := "module example.com/test\n\ngo 1.21\n"
+	// The hook module of the rule was not extracted
+	tempDir, buildTempDir, goModPath := setupSyncDepsTest(t, goMod, nil)
+
+	sp := &SetupPhase{
+		logger: slog.Default(),
+	}
+	ruleSet := &rule.InstRuleSet{
+		FuncRules: map[string][]*rule.InstFuncRule{
+			"test.go": {{
+				InstBaseRule: rule.InstBaseRule{Name: "func"},
+				ModulePath:   util.OtelcInstRoot + "/net/http/client",
+			}},
+		},
+	}
+
+	err := sp.syncDeps(t.Context(), []*rule.InstRuleSet{ruleSet}, tempDir)
+	require.Error(t, err)
+	target := filepath.Join(buildTempDir, unzippedInstDir, "net/http/client")
+	assert.Contains(t, err.Error(), util.OtelcInstRoot+"/net/http/client => "+target)
+	assert.Contains(t, err.Error(), filepath.Join(target, "go.mod")+" does not exist")
+
+	// go.mod is left untouched
+	content, err := os.ReadFile(goModPath)
+	require.NoError(t, err)
+	assert.Equal(t, goMod, string(content))
+}
+
+func TestSyncDeps_ReadonlyMod(t *testing.T) {
+	// The user's build asks for -mod=readonly through GOFLAGS, as it would on
+	// CI. go mod tidy ignores it, so syncing still adds the requirement on the
+	// hook module.
+	t.Setenv("GOFLAGS", "-mod=readonly")
+
+	tempDir, buildTempDir, goModPath := setupSyncDepsTest(t, "module example.com/test\n\ngo 1.21\n", nil)
+	pkgDir := filepath.Join(buildTempDir, unzippedPkgDir)
+	require.NoError(t, os.WriteFile(filepath.Join(pkgDir, "hook.go"), []byte("package pkg\n\nfunc Hook() {}\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "main.go"), []byte(fmt.Sprintf(
+		"package main\n\nimport pkg %q\n\nfunc main() { pkg.Hook() }\n", util.OtelcPkgRoot)), 0o644))
+
+	sp := &SetupPhase{
+		logger: slog.Default(),
+	}
+	ruleSet := &rule.InstRuleSet{
+		FuncRules: map[string][]*rule.InstFuncRule{
+			"test.go": {{
+				InstBaseRule: rule.InstBaseRule{Name: "func"},
+				ModulePath:   util.OtelcPkgRoot,
+			}},
+		},
+	}
+	require.NoError(t, sp.syncDeps(t.Context(), []*rule.InstRuleSet{ruleSet}, tempDir))
+
+	content, err := os.ReadFile(goModPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "require "+util.OtelcPkgRoot)
+
+	// The build itself keeps the user's -mod=readonly and succeeds, since
+	// go.mod is already in sync.
+	require.NoError(t, util.RunCmdInDir(t.Context(), tempDir, "go", "build", "-mod=readonly", "./..."))
+}
+
+func TestSyncDeps_Vendor(t *testing.T) {
+	tempDir, buildTempDir, _ := setupSyncDepsTest(t, "module example.com/test\n\ngo 1.21\n", nil)
+	pkgDir := filepath.Join(buildTempDir, unzippedPkgDir)
+	require.NoError(t, os.WriteFile(filepath.Join(pkgDir, "hook.go"), []byte("package pkg\n\nfunc Hook() {}\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "main.go"), []byte(fmt.Sprintf(
+		"package main\n\nimport pkg %q\n\nfunc main() { pkg.Hook() }\n", util.OtelcPkgRoot)), 0o644))
+	modulesTxt := filepath.Join(tempDir, "vendor", "modules.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(modulesTxt), 0o755))
+	require.NoError(t, os.WriteFile(modulesTxt, nil, 0o644))
+
+	sp := &SetupPhase{
+		logger:  slog.Default(),
+		modFlag: "vendor",
+	}
+	ruleSet := &rule.InstRuleSet{
+		FuncRules: map[string][]*rule.InstFuncRule{
+			"test.go": {{
+				InstBaseRule: rule.InstBaseRule{Name: "func"},
+				ModulePath:   util.OtelcPkgRoot,
+			}},
+		},
+	}
+	stateManager := NewStateManager()
+	ctx := ContextWithStateManager(t.Context(), stateManager)
+	require.NoError(t, sp.syncDeps(ctx, []*rule.InstRuleSet{ruleSet}, tempDir))
+
+	content, err := os.ReadFile(modulesTxt)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "# "+util.OtelcPkgRoot+" ")
+	assert.Contains(t, string(content), "## explicit")
+	vendored := filepath.Join(tempDir, "vendor", filepath.FromSlash(util.OtelcPkgRoot))
+	assert.FileExists(t, filepath.Join(vendored, "hook.go"))
+
+	// The build loads the hook module from vendor/ and finds it consistent
+	// with go.mod.
+	require.NoError(t, util.RunCmdInDir(t.Context(), tempDir, "go", "build", "-mod=vendor", "./..."))
+
+	// Reverting restores vendor/modules.txt and removes the vendored modules.
+	require.NoError(t, stateManager.Revert())
+	content, err = os.ReadFile(modulesTxt)
+	require.NoError(t, err)
+	assert.Empty(t, content)
+	assert.NoDirExists(t, filepath.Join(tempDir, "vendor", "github.com"))
+}
+
+func TestChangedVendorModules(t *testing.T) {
+	moduleDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "go.mod"), []byte(`module example.com/test
+
+go 1.21
+
+require (
+	example.com/same v1.0.0
+	example.com/upgraded v1.2.0
+	example.com/local v1.0.0
+	example.com/added v1.0.0
+)
+
+replace example.com/local => ./local
+`), 0o644))
+
+	changed, err := changedVendorModules(moduleDir)
+	require.NoError(t, err)
+	assert.Empty(t, changed, "nothing is vendored yet")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(moduleDir, "vendor"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "vendor", "modules.txt"), []byte(`# example.com/same v1.0.0
+## explicit
+example.com/same
+# example.com/upgraded v1.1.0
+## explicit
+example.com/upgraded
+# example.com/local v1.0.0 => ./local
+## explicit
+example.com/local
+# example.com/dropped v0.1.0
+example.com/dropped
+`), 0o644))
+
+	changed, err = changedVendorModules(moduleDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/upgraded", "example.com/dropped"}, changed)
+}
+
+func TestModFlagValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		goflags  string
+		expected string
+	}{
+		{
+			name: "no -mod",
+			args: []string{"-a", "./cmd"},
+		},
+		{
+			name:     "joined form",
+			args:     []string{"-mod=vendor", "."},
+			expected: "vendor",
+		},
+		{
+			name:     "separate form",
+			args:     []string{"-mod", "vendor", "."},
+			expected: "vendor",
+		},
+		{
+			name:     "double dash",
+			args:     []string{"--mod=readonly"},
+			expected: "readonly",
+		},
+		{
+			name:     "from GOFLAGS",
+			goflags:  "-trimpath -mod=vendor",
+			expected: "vendor",
+		},
+		{
+			name:     "arguments override GOFLAGS",
+			args:     []string{"-mod=mod"},
+			goflags:  "-mod=vendor",
+			expected: "mod",
+		},
+		{
+			name:     "last one wins",
+			args:     []string{"-mod=mod", "-mod=vendor"},
+			expected: "vendor",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, modFlagValue(tt.args, tt.goflags))
+		})
+	}
+}
+
+func TestVendorMode(t *testing.T) {
+	plain := t.TempDir()
+	vendored := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(vendored, "vendor"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(vendored, "vendor", "modules.txt"), nil, 0o644))
+
+	assert.False(t, vendorMode(plain, ""))
+	assert.True(t, vendorMode(plain, "vendor"))
+	assert.True(t, vendorMode(vendored, ""))
+	assert.True(t, vendorMode(vendored, "vendor"))
+	assert.False(t, vendorMode(vendored, "mod"))
+	assert.False(t, vendorMode(vendored, "readonly"))
+}
+
+func TestSetupAudit_ListsInjectedModules(t *testing.T) {
+	tempDir, buildTempDir, _ := setupSyncDepsTest(t, "module example.com/test\n\ngo 1.21\n", []string{"net/http/client"})
+	hookPath := util.OtelcInstRoot + "/net/http/client"
+	require.NoError(t, os.WriteFile(
+		filepath.Join(buildTempDir, unzippedInstDir, "net/http/client", "hook.go"),
+		[]byte("package client\n"),
+		0o644,
+	))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644))
+
+	sp := &SetupPhase{
+		logger: slog.Default(),
+	}
+	ruleSet := &rule.InstRuleSet{
+		FuncRules: map[string][]*rule.InstFuncRule{
+			"test.go": {{
+				InstBaseRule: rule.InstBaseRule{Name: "func"},
+				Path:         hookPath,
+				ModulePath:   hookPath,
+			}},
+		},
+	}
+	matched := []*rule.InstRuleSet{ruleSet}
+	ctx := ContextWithStateManager(t.Context(), NewStateManager())
+	require.NoError(t, sp.addDeps(ctx, matched, tempDir, mainPackageName))
+	require.NoError(t, sp.syncDeps(ctx, matched, tempDir))
+	require.NoError(t, sp.storeAudit())
+
+	content, err := os.ReadFile(util.GetDependencyAuditFile())
+	require.NoError(t, err)
+	var report []auditEntry
+	require.NoError(t, json.Unmarshal(content, &report))
+	assert.Equal(t, sp.audited, report)
+
+	assert.Contains(t, report, auditEntry{
+		Kind:   auditKindImport,
+		Path:   hookPath,
+		Target: filepath.Join(tempDir, OtelcRuntimeFile),
+	})
+	var modules []string
+	for _, entry := range report {
+		if entry.Kind == auditKindModule {
+			assert.Equal(t, filepath.Join(tempDir, "go.mod"), entry.Target)
+			modules = append(modules, entry.Path)
+		}
+	}
+	assert.Contains(t, modules, hookPath)
+}
+
 func warnCapture() (*SetupPhase, *bytes.Buffer) {
 	var buf bytes.Buffer
 	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})
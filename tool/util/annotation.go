@@ -0,0 +1,48 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// EnvGitHubActions is set to "true" by GitHub Actions on every workflow run.
+const EnvGitHubActions = "GITHUB_ACTIONS"
+
+const annotationTitle = "otelc"
+
+// InGitHubActions reports whether the tool is running inside a GitHub Actions
+// workflow, where workflow commands printed to the output become annotations.
+func InGitHubActions() bool {
+	return os.Getenv(EnvGitHubActions) == "true"
+}
+
+//nolint:gochecknoglobals // This is a constant
+var annotationEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// FormatWarningAnnotation formats msg as a GitHub Actions `::warning::`
+// workflow command. Characters that would end the command early are escaped.
+func FormatWarningAnnotation(msg string) string {
+	return fmt.Sprintf("::warning title=%s::%s", annotationTitle, annotationEscaper.Replace(msg))
+}
+
+// AnnotationOutput is where AnnotateWarning writes the annotations, stderr
+// unless a test captures them.
+//
+//nolint:gochecknoglobals // allows us to capture CI annotations in tests
+var AnnotationOutput io.Writer = os.Stderr
+
+// AnnotateWarning writes msg to AnnotationOutput as a warning annotation when
+// running in GitHub Actions, so instrumentation drift surfaces in pull request
+// checks. It does nothing elsewhere; the regular log remains the source of
+// truth.
+func AnnotateWarning(msg string) {
+	if !InGitHubActions() {
+		return
+	}
+	_, _ = fmt.Fprintln(AnnotationOutput, FormatWarningAnnotation(msg))
+}
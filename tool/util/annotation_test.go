@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatWarningAnnotation(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      string
+		expected string
+	}{
+		{
+			name:     "plain message",
+			msg:      `rule "nethttp" matched nothing in net/http`,
+			expected: `::warning title=otelc::rule "nethttp" matched nothing in net/http`,
+		},
+		{
+			name:     "multi-line message is escaped",
+			msg:      "first\r\nsecond 100%",
+			expected: "::warning title=otelc::first%0D%0Asecond 100%25",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatWarningAnnotation(tt.msg))
+		})
+	}
+}
+
+// captureAnnotations points AnnotationOutput at a buffer for the test.
+func captureAnnotations(t *testing.T) *strings.Builder {
+	t.Helper()
+	var out strings.Builder
+	original := AnnotationOutput
+	AnnotationOutput = &out
+	t.Cleanup(func() { AnnotationOutput = original })
+	return &out
+}
+
+func TestAnnotateWarning(t *testing.T) {
+	t.Run("in GitHub Actions", func(t *testing.T) {
+		t.Setenv(EnvGitHubActions, "true")
+		out := captureAnnotations(t)
+		AnnotateWarning("drift")
+		assert.Equal(t, "::warning title=otelc::drift\n", out.String())
+	})
+
+	t.Run("multi-line message", func(t *testing.T) {
+		t.Setenv(EnvGitHubActions, "true")
+		out := captureAnnotations(t)
+		AnnotateWarning("rule failed:\nexpected func\n")
+		AnnotateWarning("drift")
+		// One workflow command per line, whatever the messages hold
+		assert.Equal(t, []string{
+			"::warning title=otelc::rule failed:%0Aexpected func%0A",
+			"::warning title=otelc::drift",
+		}, strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"))
+	})
+
+	t.Run("outside GitHub Actions", func(t *testing.T) {
+		t.Setenv(EnvGitHubActions, "")
+		out := captureAnnotations(t)
+		AnnotateWarning("drift")
+		assert.Empty(t, out.String())
+	})
+}
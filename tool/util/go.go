@@ -84,6 +84,15 @@ func IsLinkCommandWithArgs(args []string) bool {
 	return true
 }
 
+// IsToolIDCommandWithArgs checks if the args slice is the "-V=full" query the
+// go command runs to get the ID of the compile or link tool, which it uses in
+// the build cache keys of the packages and binaries the tool builds.
+func IsToolIDCommandWithArgs(args []string) bool {
+	return len(args) == 2 &&
+		(isCompileTool(args[0]) || isLinkTool(args[0])) &&
+		args[1] == "-V=full"
+}
+
 // isCgoCommand checks if the line is a cgo tool invocation with -objdir and -importpath flags.
 func IsCgoCommand(line string) bool {
 	return strings.Contains(line, "cgo") &&
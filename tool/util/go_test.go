@@ -387,6 +387,46 @@ func TestIsLinkArgs(t *testing.T) {
 	}
 }
 
+func TestIsToolIDArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{
+			name:     "compile tool ID",
+			args:     []string{"/usr/local/go/pkg/tool/linux_amd64/compile", "-V=full"},
+			expected: true,
+		},
+		{
+			name:     "link tool ID on Windows",
+			args:     []string{`C:\Program Files\Go\pkg\tool\windows_amd64\link.exe`, "-V=full"},
+			expected: true,
+		},
+		{
+			name:     "asm tool ID",
+			args:     []string{"/usr/local/go/pkg/tool/linux_amd64/asm", "-V=full"},
+			expected: false,
+		},
+		{
+			name:     "short version",
+			args:     []string{"/usr/local/go/pkg/tool/linux_amd64/compile", "-V"},
+			expected: false,
+		},
+		{
+			name:     "compile command",
+			args:     []string{"/usr/local/go/pkg/tool/linux_amd64/compile", "-V=full", "-o", "/tmp/output.a"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsToolIDCommandWithArgs(tt.args))
+		})
+	}
+}
+
 func TestIsCgoCommand(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -7,6 +7,8 @@ import (
 	"context"
 	"io"
 	"log/slog"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
 )
 
 type (
@@ -37,3 +39,51 @@ func LogWriterFromContext(ctx context.Context) io.Closer {
 	}
 	return writer
 }
+
+// WithWarningsTo returns a logger that logs as logger does and also prints the
+// warnings and errors it logs to w, for commands whose findings the user reads
+// on the terminal rather than in the debug log.
+func WithWarningsTo(logger *slog.Logger, w io.Writer) *slog.Logger {
+	return slog.New(warningsHandler{
+		Handler: logger.Handler(),
+		warnings: slog.NewTextHandler(w, &slog.HandlerOptions{
+			Level: slog.LevelWarn,
+			ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}),
+	})
+}
+
+// warningsHandler passes records to Handler, and those of level warning and
+// above to warnings too.
+type warningsHandler struct {
+	slog.Handler
+	warnings slog.Handler
+}
+
+func (h warningsHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.Handler.Enabled(ctx, level) || h.warnings.Enabled(ctx, level)
+}
+
+func (h warningsHandler) Handle(ctx context.Context, r slog.Record) error {
+	var err error
+	if h.Handler.Enabled(ctx, r.Level) {
+		err = h.Handler.Handle(ctx, r)
+	}
+	if h.warnings.Enabled(ctx, r.Level) {
+		err = ex.Join(err, h.warnings.Handle(ctx, r))
+	}
+	return err
+}
+
+func (h warningsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return warningsHandler{Handler: h.Handler.WithAttrs(attrs), warnings: h.warnings.WithAttrs(attrs)}
+}
+
+func (h warningsHandler) WithGroup(name string) slog.Handler {
+	return warningsHandler{Handler: h.Handler.WithGroup(name), warnings: h.warnings.WithGroup(name)}
+}
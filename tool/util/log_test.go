@@ -5,6 +5,8 @@ package util
 
 import (
 	"context"
+	"log/slog"
+	"strings"
 	"testing"
 )
 
@@ -39,3 +41,20 @@ func TestLogWriterFromContext(t *testing.T) {
 		}
 	})
 }
+
+func TestWithWarningsTo(t *testing.T) {
+	var logged, printed strings.Builder
+	base := slog.New(slog.NewTextHandler(&logged, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	logger := WithWarningsTo(base, &printed).With("rule", "server_hook")
+
+	logger.Debug("matching")
+	logger.Warn("rule matched nothing", "dep", "net/http")
+
+	if got := strings.Count(logged.String(), "\n"); got != 2 {
+		t.Errorf("expected both records in the log, got:\n%s", logged.String())
+	}
+	want := "level=WARN msg=\"rule matched nothing\" rule=server_hook dep=net/http\n"
+	if printed.String() != want {
+		t.Errorf("printed %q, want %q", printed.String(), want)
+	}
+}
@@ -22,12 +22,27 @@ const (
 	EnvOtelcStats = "OTELC_STATS"
 	// EnvOtelcDebug enables debug-level logging when set to "1".
 	// Set automatically when --debug is used; propagated to child processes.
-	EnvOtelcDebug    = "OTELC_DEBUG"
-	BuildTempDir     = ".otelc-build"
-	OtelcRoot        = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation"
-	OtelcPkgRoot     = OtelcRoot + "/pkg"
-	OtelcInstRoot    = OtelcRoot + "/instrumentation"
-	OtelcToolCmdRoot = OtelcRoot + "/tool/cmd/otelc"
+	EnvOtelcDebug = "OTELC_DEBUG"
+	// EnvOtelcOverhead makes the trampolines measure the time spent in the
+	// hooks when set to "1". Set automatically when --overhead is used;
+	// propagated to child processes.
+	EnvOtelcOverhead = "OTELC_OVERHEAD"
+	// EnvOtelcRenderFallback leaves a package uninstrumented, instead of
+	// failing the build, when its instrumented code cannot be rendered, when
+	// set to "1". Set automatically when --render-fallback is used; propagated
+	// to child processes.
+	EnvOtelcRenderFallback = "OTELC_RENDER_FALLBACK"
+	// EnvOtelcForceRebuild rebuilds every package instead of reusing those
+	// cached by a previous build, as --force-rebuild does.
+	EnvOtelcForceRebuild = "OTELC_FORCE_REBUILD"
+	// EnvOtelcAllowlist names the file listing the instrumentation modules
+	// that may be injected, as --allowlist does.
+	EnvOtelcAllowlist = "OTELC_ALLOWLIST"
+	BuildTempDir      = ".otelc-build"
+	OtelcRoot         = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation"
+	OtelcPkgRoot      = OtelcRoot + "/pkg"
+	OtelcInstRoot     = OtelcRoot + "/instrumentation"
+	OtelcToolCmdRoot  = OtelcRoot + "/tool/cmd/otelc"
 )
 
 func GetMatchedRuleFile() string {
@@ -35,17 +50,50 @@ func GetMatchedRuleFile() string {
 	return GetBuildTemp(matchedRuleFile)
 }
 
+// GetDependencyAuditFile returns the report of the imports and modules that
+// the setup phase added to the project.
+func GetDependencyAuditFile() string {
+	const dependencyAuditFile = "dependency_audit.json"
+	return GetBuildTemp(dependencyAuditFile)
+}
+
+// GetAddedImportsRootDir returns the directory holding the import tracking
+// files, one subdirectory per instrumentation key.
+func GetAddedImportsRootDir() string {
+	return GetBuildTemp("added_imports")
+}
+
+// GetAddedImportsDir returns the directory of the import tracking files of the
+// packages instrumented as key identifies. They outlive the build, as the
+// packages whose instrumented archives are cached are not compiled again.
+func GetAddedImportsDir(key string) string {
+	return filepath.Join(GetAddedImportsRootDir(), key)
+}
+
 // GetAddedImportsFileForProcess returns the per-process import tracking file.
 // Each compile process writes to its own file to avoid inter-process race conditions.
-func GetAddedImportsFileForProcess() string {
+func GetAddedImportsFileForProcess(key string) string {
 	pid := os.Getpid()
-	return GetBuildTemp(fmt.Sprintf("added_imports.%d.json", pid))
+	return filepath.Join(GetAddedImportsDir(key), fmt.Sprintf("added_imports.%d.json", pid))
 }
 
 // GetAddedImportsPattern returns the glob pattern for all import tracking files.
 // Used by the link phase to discover and merge all per-process import files.
-func GetAddedImportsPattern() string {
-	return GetBuildTemp("added_imports.*.json")
+func GetAddedImportsPattern(key string) string {
+	return filepath.Join(GetAddedImportsDir(key), "added_imports.*.json")
+}
+
+// GetPackageNamesFileForProcess returns the per-process file caching the
+// package names the process resolved with go list.
+func GetPackageNamesFileForProcess() string {
+	pid := os.Getpid()
+	return GetBuildTemp(fmt.Sprintf("package_names.%d.json", pid))
+}
+
+// GetPackageNamesPattern returns the glob pattern for all package name files.
+// Every compile process loads them all to share the names resolved before it.
+func GetPackageNamesPattern() string {
+	return GetBuildTemp("package_names.*.json")
 }
 
 func GetOtelcWorkDir() string {